@@ -7,9 +7,18 @@ import (
 )
 
 type DefineBinaryData struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag         *Uint16
+	Extended    *Uint32
+	CharacterID *Uint16
+	Reserved    *Uint32
+	Data        []byte
+	// File is the SWF file parsed from Data by ParseWithOptions. When File is
+	// set, Serialize embeds the serialized File instead of Data.
+	File *File
+	// FileError is the reason why File is nil although Data looks like a SWF
+	// file, such as corrupted data or the unsupported LZMA compression.
+	FileError error
+	data      *bytes.Buffer
 }
 
 func (v *DefineBinaryData) TagCode() TagCode {
@@ -21,7 +30,16 @@ func (v *DefineBinaryData) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBinaryData{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBinaryData{CharacterID: %d, Kind: %s, Data: %d bytes}", v.CharacterID.Value, v.Kind(), len(v.Data))
+}
+
+// Kind returns the format of the embedded data detected from its content.
+func (v *DefineBinaryData) Kind() BinaryDataKind {
+	if v == nil {
+		return BinaryDataUnknown
+	}
+
+	return DetectBinaryDataKind(v.Data)
 }
 
 func (v *DefineBinaryData) Bytes() []byte {
@@ -49,25 +67,41 @@ func (v *DefineBinaryData) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineBinaryData is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	characterIDData, err := v.CharacterID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	reserved := v.Reserved
+
+	if reserved == nil {
+		reserved = &Uint32{}
+	}
+
+	reservedData, err := reserved.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	blob := v.Data
+
+	if v.File != nil {
+		blob, err = v.File.Serialize()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize DefineBinaryData.File: %w", err)
+		}
+	}
+
+	body = append(body, characterIDData...)
+	body = append(body, reservedData...)
+	body = append(body, blob...)
 
-	return data, nil
+	return serializeTag(DefineBinaryDataTagCode, v.Extended, body)
 }
 
 func ParseDefineBinaryData(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBinaryData, error) {
@@ -92,10 +126,33 @@ func ParseDefineBinaryData(src io.Reader, tag *Uint16, extended *Uint32) (*Defin
 		return nil, fmt.Errorf("broken DefineBinaryData")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBinaryData.CharacterID: %w", err)
+	}
+
+	reserved, err := ReadUint32(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBinaryData.Reserved: %w", err)
+	}
+
+	blob, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBinaryData.Data: %w", err)
+	}
+
 	result := &DefineBinaryData{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:         tag,
+		Extended:    extended,
+		CharacterID: characterID,
+		Reserved:    reserved,
+		Data:        blob,
+		data:        data,
 	}
 
 	return result, nil
@@ -7,8 +7,11 @@ import (
 )
 
 type DefineBits struct {
-	Tag      *Uint16
-	Extended *Uint32
+	Tag         *Uint16
+	Extended    *Uint32
+	CharacterID *Uint16
+	// JpegData holds the image data without the encoding tables.
+	JpegData []byte
 	data     *bytes.Buffer
 }
 
@@ -21,7 +24,23 @@ func (v *DefineBits) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBits{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBits{CharacterID: %d, JpegData: %d bytes}", v.CharacterID.Value, len(v.JpegData))
+}
+
+// ExportJPEG returns the standalone JPEG file made of the tables and the image
+// data. The tables are stored in the JpegTables tag of the file.
+func (v *DefineBits) ExportJPEG(tables *JpegTables) ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot export because DefineBits is nil")
+	}
+
+	var tablesData []byte
+
+	if tables != nil {
+		tablesData = tables.JpegData
+	}
+
+	return ExportJPEG(tablesData, v.JpegData)
 }
 
 func (v *DefineBits) Bytes() []byte {
@@ -49,25 +68,15 @@ func (v *DefineBits) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineBits is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	extendedData, err := v.Extended.Serialize()
+	body, err := v.CharacterID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, v.JpegData...)
 
-	return data, nil
+	return serializeTag(DefineBitsTagCode, v.Extended, body)
 }
 
 func ParseDefineBits(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBits, error) {
@@ -92,10 +101,26 @@ func ParseDefineBits(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBits,
 		return nil, fmt.Errorf("broken DefineBits")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBits.CharacterID: %w", err)
+	}
+
+	blob, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBits.JpegData: %w", err)
+	}
+
 	result := &DefineBits{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:         tag,
+		Extended:    extended,
+		CharacterID: characterID,
+		JpegData:    blob,
+		data:        data,
 	}
 
 	return result, nil
@@ -7,9 +7,13 @@ import (
 )
 
 type DefineBitsJpeg2 struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag         *Uint16
+	Extended    *Uint32
+	CharacterID *Uint16
+	// ImageData holds JPEG data with its encoding tables, or PNG or GIF data
+	// since SWF 8.
+	ImageData []byte
+	data      *bytes.Buffer
 }
 
 func (v *DefineBitsJpeg2) TagCode() TagCode {
@@ -21,7 +25,29 @@ func (v *DefineBitsJpeg2) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBitsJpeg2{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBitsJpeg2{CharacterID: %d, ImageData: %d bytes}", v.CharacterID.Value, len(v.ImageData))
+}
+
+// Kind returns the format of the image data, which is JPEG, PNG or GIF.
+func (v *DefineBitsJpeg2) Kind() BinaryDataKind {
+	if v == nil {
+		return BinaryDataUnknown
+	}
+
+	return DetectBinaryDataKind(v.ImageData)
+}
+
+// ExportJPEG returns the image data as a standard JPEG file. It returns a
+// *NotJPEGError when the image data is PNG or GIF.
+func (v *DefineBitsJpeg2) ExportJPEG() ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot export because DefineBitsJpeg2 is nil")
+	}
+	if kind := v.Kind(); kind != BinaryDataJPEG {
+		return nil, &NotJPEGError{Kind: kind}
+	}
+
+	return ExportJPEG(nil, v.ImageData)
 }
 
 func (v *DefineBitsJpeg2) Bytes() []byte {
@@ -49,25 +75,15 @@ func (v *DefineBitsJpeg2) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineBitsJpeg2 is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.CharacterID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	body = append(body, v.ImageData...)
 
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(DefineBitsJpeg2TagCode, v.Extended, body)
 }
 
 func ParseDefineBitsJpeg2(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBitsJpeg2, error) {
@@ -92,10 +108,26 @@ func ParseDefineBitsJpeg2(src io.Reader, tag *Uint16, extended *Uint32) (*Define
 		return nil, fmt.Errorf("broken DefineBitsJpeg2")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg2.CharacterID: %w", err)
+	}
+
+	blob, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg2.ImageData: %w", err)
+	}
+
 	result := &DefineBitsJpeg2{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:         tag,
+		Extended:    extended,
+		CharacterID: characterID,
+		ImageData:   blob,
+		data:        data,
 	}
 
 	return result, nil
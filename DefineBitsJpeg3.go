@@ -3,13 +3,20 @@ package swf
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"io"
 )
 
 type DefineBitsJpeg3 struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag             *Uint16
+	Extended        *Uint32
+	CharacterID     *Uint16
+	AlphaDataOffset *Uint32
+	// ImageData holds JPEG data with its encoding tables, or PNG or GIF data.
+	ImageData []byte
+	// BitmapAlphaData holds the zlib compressed alpha plane of the JPEG data.
+	BitmapAlphaData []byte
+	data            *bytes.Buffer
 }
 
 func (v *DefineBitsJpeg3) TagCode() TagCode {
@@ -21,7 +28,54 @@ func (v *DefineBitsJpeg3) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBitsJpeg3{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBitsJpeg3{CharacterID: %d, Kind: %s, ImageData: %d bytes, BitmapAlphaData: %d bytes}", v.CharacterID.Value, v.Kind(), len(v.ImageData), len(v.BitmapAlphaData))
+}
+
+// Kind returns the format of the image data, which is JPEG, PNG or GIF.
+func (v *DefineBitsJpeg3) Kind() BinaryDataKind {
+	if v == nil {
+		return BinaryDataUnknown
+	}
+
+	return DetectBinaryDataKind(v.ImageData)
+}
+
+// ExportJPEG returns the image data without the alpha plane as a standard
+// JPEG file. It returns a *NotJPEGError when the image data is PNG or GIF.
+func (v *DefineBitsJpeg3) ExportJPEG() ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot export because DefineBitsJpeg3 is nil")
+	}
+	if kind := v.Kind(); kind != BinaryDataJPEG {
+		return nil, &NotJPEGError{Kind: kind}
+	}
+
+	return ExportJPEG(nil, v.ImageData)
+}
+
+// AlphaPlane returns the decompressed alpha plane, one byte per pixel row by
+// row. It is empty when the image data is PNG or GIF.
+func (v *DefineBitsJpeg3) AlphaPlane() ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineBitsJpeg3 is nil")
+	}
+
+	return decompressAlphaPlane(v.BitmapAlphaData)
+}
+
+// Image decodes the image data and merges the alpha plane into it.
+func (v *DefineBitsJpeg3) Image() (image.Image, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineBitsJpeg3 is nil")
+	}
+
+	img, err := decodeJpegWithAlpha(v.ImageData, v.BitmapAlphaData)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DefineBitsJpeg3: %w", err)
+	}
+
+	return img, nil
 }
 
 func (v *DefineBitsJpeg3) Bytes() []byte {
@@ -44,30 +98,32 @@ func (v *DefineBitsJpeg3) Bytes() []byte {
 	return data
 }
 
+// Serialize encodes the tag. The AlphaDataOffset is recomputed.
 func (v *DefineBitsJpeg3) Serialize() ([]byte, error) {
 	if v == nil {
 		return nil, fmt.Errorf("cannot serialize because DefineBitsJpeg3 is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	characterIDData, err := v.CharacterID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	alphaDataOffsetData, err := (&Uint32{Value: uint32(len(v.ImageData))}).Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	var body []byte
+
+	body = append(body, characterIDData...)
+	body = append(body, alphaDataOffsetData...)
+	body = append(body, v.ImageData...)
+	body = append(body, v.BitmapAlphaData...)
 
-	return data, nil
+	return serializeTag(DefineBitsJpeg3TagCode, v.Extended, body)
 }
 
 func ParseDefineBitsJpeg3(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBitsJpeg3, error) {
@@ -92,10 +148,43 @@ func ParseDefineBitsJpeg3(src io.Reader, tag *Uint16, extended *Uint32) (*Define
 		return nil, fmt.Errorf("broken DefineBitsJpeg3")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg3.CharacterID: %w", err)
+	}
+
+	alphaDataOffset, err := ReadUint32(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg3.AlphaDataOffset: %w", err)
+	}
+	if int64(alphaDataOffset.Value) > int64(body.Len()) {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg3.ImageData: AlphaDataOffset exceeds the tag: %d", alphaDataOffset.Value)
+	}
+
+	imageData := make([]byte, alphaDataOffset.Value)
+
+	if _, err := io.ReadFull(body, imageData); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg3.ImageData: %w", err)
+	}
+
+	bitmapAlphaData, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg3.BitmapAlphaData: %w", err)
+	}
+
 	result := &DefineBitsJpeg3{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:             tag,
+		Extended:        extended,
+		CharacterID:     characterID,
+		AlphaDataOffset: alphaDataOffset,
+		ImageData:       imageData,
+		BitmapAlphaData: bitmapAlphaData,
+		data:            data,
 	}
 
 	return result, nil
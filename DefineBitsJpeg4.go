@@ -3,13 +3,22 @@ package swf
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"io"
 )
 
 type DefineBitsJpeg4 struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag             *Uint16
+	Extended        *Uint32
+	CharacterID     *Uint16
+	AlphaDataOffset *Uint32
+	// DeblockParam is an 8.8 fixed-point number.
+	DeblockParam *Uint16
+	// ImageData holds JPEG data with its encoding tables, or PNG or GIF data.
+	ImageData []byte
+	// BitmapAlphaData holds the zlib compressed alpha plane of the JPEG data.
+	BitmapAlphaData []byte
+	data            *bytes.Buffer
 }
 
 func (v *DefineBitsJpeg4) TagCode() TagCode {
@@ -21,7 +30,64 @@ func (v *DefineBitsJpeg4) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBitsJpeg4{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBitsJpeg4{CharacterID: %d, Kind: %s, ImageData: %d bytes, BitmapAlphaData: %d bytes}", v.CharacterID.Value, v.Kind(), len(v.ImageData), len(v.BitmapAlphaData))
+}
+
+// Kind returns the format of the image data, which is JPEG, PNG or GIF.
+func (v *DefineBitsJpeg4) Kind() BinaryDataKind {
+	if v == nil {
+		return BinaryDataUnknown
+	}
+
+	return DetectBinaryDataKind(v.ImageData)
+}
+
+// ExportJPEG returns the image data without the alpha plane as a standard
+// JPEG file. It returns a *NotJPEGError when the image data is PNG or GIF.
+func (v *DefineBitsJpeg4) ExportJPEG() ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot export because DefineBitsJpeg4 is nil")
+	}
+	if kind := v.Kind(); kind != BinaryDataJPEG {
+		return nil, &NotJPEGError{Kind: kind}
+	}
+
+	return ExportJPEG(nil, v.ImageData)
+}
+
+// AlphaPlane returns the decompressed alpha plane, one byte per pixel row by
+// row. It is empty when the image data is PNG or GIF.
+func (v *DefineBitsJpeg4) AlphaPlane() ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineBitsJpeg4 is nil")
+	}
+
+	return decompressAlphaPlane(v.BitmapAlphaData)
+}
+
+// Deblock returns the strength of the deblocking filter. 0 disables the
+// filter.
+func (v *DefineBitsJpeg4) Deblock() float64 {
+	if v == nil || v.DeblockParam == nil {
+		return 0
+	}
+
+	return float64(v.DeblockParam.Value) / 256
+}
+
+// Image decodes the image data and merges the alpha plane into it.
+func (v *DefineBitsJpeg4) Image() (image.Image, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineBitsJpeg4 is nil")
+	}
+
+	img, err := decodeJpegWithAlpha(v.ImageData, v.BitmapAlphaData)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DefineBitsJpeg4: %w", err)
+	}
+
+	return img, nil
 }
 
 func (v *DefineBitsJpeg4) Bytes() []byte {
@@ -44,30 +110,46 @@ func (v *DefineBitsJpeg4) Bytes() []byte {
 	return data
 }
 
+// Serialize encodes the tag. The AlphaDataOffset is recomputed.
 func (v *DefineBitsJpeg4) Serialize() ([]byte, error) {
 	if v == nil {
 		return nil, fmt.Errorf("cannot serialize because DefineBitsJpeg4 is nil")
 	}
 
-	var data []byte
+	characterIDData, err := v.CharacterID.Serialize()
 
-	tagData, err := v.Tag.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	alphaDataOffsetData, err := (&Uint32{Value: uint32(len(v.ImageData))}).Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	var body []byte
+
+	body = append(body, characterIDData...)
+	body = append(body, alphaDataOffsetData...)
+
+	deblockParam := v.DeblockParam
+
+	if deblockParam == nil {
+		deblockParam = &Uint16{}
+	}
+
+	deblockParamData, err := deblockParam.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, deblockParamData...)
+	body = append(body, v.ImageData...)
+	body = append(body, v.BitmapAlphaData...)
 
-	return data, nil
+	return serializeTag(DefineBitsJpeg4TagCode, v.Extended, body)
 }
 
 func ParseDefineBitsJpeg4(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBitsJpeg4, error) {
@@ -92,10 +174,50 @@ func ParseDefineBitsJpeg4(src io.Reader, tag *Uint16, extended *Uint32) (*Define
 		return nil, fmt.Errorf("broken DefineBitsJpeg4")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg4.CharacterID: %w", err)
+	}
+
+	alphaDataOffset, err := ReadUint32(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg4.AlphaDataOffset: %w", err)
+	}
+
+	deblockParam, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg4.DeblockParam: %w", err)
+	}
+	if int64(alphaDataOffset.Value) > int64(body.Len()) {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg4.ImageData: AlphaDataOffset exceeds the tag: %d", alphaDataOffset.Value)
+	}
+
+	imageData := make([]byte, alphaDataOffset.Value)
+
+	if _, err := io.ReadFull(body, imageData); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg4.ImageData: %w", err)
+	}
+
+	bitmapAlphaData, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsJpeg4.BitmapAlphaData: %w", err)
+	}
+
 	result := &DefineBitsJpeg4{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:             tag,
+		Extended:        extended,
+		CharacterID:     characterID,
+		AlphaDataOffset: alphaDataOffset,
+		DeblockParam:    deblockParam,
+		ImageData:       imageData,
+		BitmapAlphaData: bitmapAlphaData,
+		data:            data,
 	}
 
 	return result, nil
@@ -3,13 +3,21 @@ package swf
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"io"
 )
 
 type DefineBitsLossless struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag                  *Uint16
+	Extended             *Uint32
+	CharacterID          *Uint16
+	BitmapFormat         *Uint8
+	BitmapWidth          *Uint16
+	BitmapHeight         *Uint16
+	BitmapColorTableSize *Uint8
+	// ZlibBitmapData holds the compressed COLORMAPDATA or BITMAPDATA.
+	ZlibBitmapData []byte
+	data           *bytes.Buffer
 }
 
 func (v *DefineBitsLossless) TagCode() TagCode {
@@ -21,7 +29,29 @@ func (v *DefineBitsLossless) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBitsLossless{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBitsLossless{CharacterID: %d, BitmapFormat: %d, BitmapWidth: %d, BitmapHeight: %d}", v.CharacterID.Value, v.BitmapFormat.Value, v.BitmapWidth.Value, v.BitmapHeight.Value)
+}
+
+// Image decodes the bitmap. The result is an *image.Paletted for the
+// colormapped format and an *image.RGBA otherwise.
+func (v *DefineBitsLossless) Image() (image.Image, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineBitsLossless is nil")
+	}
+
+	var colorTableSize int
+
+	if v.BitmapColorTableSize != nil {
+		colorTableSize = int(v.BitmapColorTableSize.Value) + 1
+	}
+
+	img, err := decodeLossless(v.BitmapFormat.Value, int(v.BitmapWidth.Value), int(v.BitmapHeight.Value), colorTableSize, v.ZlibBitmapData, false)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DefineBitsLossless: %w", err)
+	}
+
+	return img, nil
 }
 
 func (v *DefineBitsLossless) Bytes() []byte {
@@ -49,25 +79,28 @@ func (v *DefineBitsLossless) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineBitsLossless is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	for _, field := range []interface{ Serialize() ([]byte, error) }{v.CharacterID, v.BitmapFormat, v.BitmapWidth, v.BitmapHeight} {
+		fieldData, err := field.Serialize()
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	extendedData, err := v.Extended.Serialize()
+		body = append(body, fieldData...)
+	}
+	if v.BitmapFormat.Value == BitmapFormatColorMapped8 {
+		if v.BitmapColorTableSize == nil {
+			return nil, fmt.Errorf("failed to serialize DefineBitsLossless: BitmapColorTableSize is nil")
+		}
 
-	if err != nil {
-		return nil, err
+		body = append(body, v.BitmapColorTableSize.Value)
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, v.ZlibBitmapData...)
 
-	return data, nil
+	return serializeTag(DefineBitsLosslessTagCode, v.Extended, body)
 }
 
 func ParseDefineBitsLossless(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBitsLossless, error) {
@@ -92,11 +125,33 @@ func ParseDefineBitsLossless(src io.Reader, tag *Uint16, extended *Uint32) (*Def
 		return nil, fmt.Errorf("broken DefineBitsLossless")
 	}
 
+	body := bytes.NewReader(data.Bytes())
 	result := &DefineBitsLossless{
 		Tag:      tag,
 		Extended: extended,
 		data:     data,
 	}
 
+	if result.CharacterID, err = ReadUint16(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless.CharacterID: %w", err)
+	}
+	if result.BitmapFormat, err = ReadUint8(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless.BitmapFormat: %w", err)
+	}
+	if result.BitmapWidth, err = ReadUint16(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless.BitmapWidth: %w", err)
+	}
+	if result.BitmapHeight, err = ReadUint16(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless.BitmapHeight: %w", err)
+	}
+	if result.BitmapFormat.Value == BitmapFormatColorMapped8 {
+		if result.BitmapColorTableSize, err = ReadUint8(body); err != nil {
+			return nil, fmt.Errorf("failed to read DefineBitsLossless.BitmapColorTableSize: %w", err)
+		}
+	}
+	if result.ZlibBitmapData, err = io.ReadAll(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless.ZlibBitmapData: %w", err)
+	}
+
 	return result, nil
 }
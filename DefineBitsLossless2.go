@@ -3,13 +3,21 @@ package swf
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"io"
 )
 
 type DefineBitsLossless2 struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag                  *Uint16
+	Extended             *Uint32
+	CharacterID          *Uint16
+	BitmapFormat         *Uint8
+	BitmapWidth          *Uint16
+	BitmapHeight         *Uint16
+	BitmapColorTableSize *Uint8
+	// ZlibBitmapData holds the compressed COLORMAPDATA or BITMAPDATA.
+	ZlibBitmapData []byte
+	data           *bytes.Buffer
 }
 
 func (v *DefineBitsLossless2) TagCode() TagCode {
@@ -21,7 +29,30 @@ func (v *DefineBitsLossless2) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineBitsLossless2{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineBitsLossless2{CharacterID: %d, BitmapFormat: %d, BitmapWidth: %d, BitmapHeight: %d}", v.CharacterID.Value, v.BitmapFormat.Value, v.BitmapWidth.Value, v.BitmapHeight.Value)
+}
+
+// Image decodes the bitmap. The premultiplied colors of the tag are stored
+// as they are in an *image.Paletted of color.RGBA for the colormapped format and
+// in an *image.RGBA otherwise, both of which are premultiplied in Go as well.
+func (v *DefineBitsLossless2) Image() (image.Image, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineBitsLossless2 is nil")
+	}
+
+	var colorTableSize int
+
+	if v.BitmapColorTableSize != nil {
+		colorTableSize = int(v.BitmapColorTableSize.Value) + 1
+	}
+
+	img, err := decodeLossless(v.BitmapFormat.Value, int(v.BitmapWidth.Value), int(v.BitmapHeight.Value), colorTableSize, v.ZlibBitmapData, true)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DefineBitsLossless2: %w", err)
+	}
+
+	return img, nil
 }
 
 func (v *DefineBitsLossless2) Bytes() []byte {
@@ -49,25 +80,28 @@ func (v *DefineBitsLossless2) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineBitsLossless2 is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	for _, field := range []interface{ Serialize() ([]byte, error) }{v.CharacterID, v.BitmapFormat, v.BitmapWidth, v.BitmapHeight} {
+		fieldData, err := field.Serialize()
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	extendedData, err := v.Extended.Serialize()
+		body = append(body, fieldData...)
+	}
+	if v.BitmapFormat.Value == BitmapFormatColorMapped8 {
+		if v.BitmapColorTableSize == nil {
+			return nil, fmt.Errorf("failed to serialize DefineBitsLossless2: BitmapColorTableSize is nil")
+		}
 
-	if err != nil {
-		return nil, err
+		body = append(body, v.BitmapColorTableSize.Value)
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, v.ZlibBitmapData...)
 
-	return data, nil
+	return serializeTag(DefineBitsLossless2TagCode, v.Extended, body)
 }
 
 func ParseDefineBitsLossless2(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBitsLossless2, error) {
@@ -92,11 +126,33 @@ func ParseDefineBitsLossless2(src io.Reader, tag *Uint16, extended *Uint32) (*De
 		return nil, fmt.Errorf("broken DefineBitsLossless2")
 	}
 
+	body := bytes.NewReader(data.Bytes())
 	result := &DefineBitsLossless2{
 		Tag:      tag,
 		Extended: extended,
 		data:     data,
 	}
 
+	if result.CharacterID, err = ReadUint16(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless2.CharacterID: %w", err)
+	}
+	if result.BitmapFormat, err = ReadUint8(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless2.BitmapFormat: %w", err)
+	}
+	if result.BitmapWidth, err = ReadUint16(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless2.BitmapWidth: %w", err)
+	}
+	if result.BitmapHeight, err = ReadUint16(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless2.BitmapHeight: %w", err)
+	}
+	if result.BitmapFormat.Value == BitmapFormatColorMapped8 {
+		if result.BitmapColorTableSize, err = ReadUint8(body); err != nil {
+			return nil, fmt.Errorf("failed to read DefineBitsLossless2.BitmapColorTableSize: %w", err)
+		}
+	}
+	if result.ZlibBitmapData, err = io.ReadAll(body); err != nil {
+		return nil, fmt.Errorf("failed to read DefineBitsLossless2.ZlibBitmapData: %w", err)
+	}
+
 	return result, nil
 }
@@ -7,9 +7,14 @@ import (
 )
 
 type DefineButton struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag        *Uint16
+	Extended   *Uint32
+	ButtonID   *Uint16
+	Characters []*ButtonRecord
+	// Actions holds the ACTIONRECORD stream including the ActionEndFlag. The
+	// actions are executed when the button is released.
+	Actions []byte
+	data    *bytes.Buffer
 }
 
 func (v *DefineButton) TagCode() TagCode {
@@ -21,7 +26,7 @@ func (v *DefineButton) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineButton{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineButton{ButtonID: %d, Characters: %d, Actions: %d bytes}", v.ButtonID.Value, len(v.Characters), len(v.Actions))
 }
 
 func (v *DefineButton) Bytes() []byte {
@@ -49,25 +54,25 @@ func (v *DefineButton) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineButton is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	buttonIDData, err := v.ButtonID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	charactersData, err := serializeButtonRecords(v.Characters, 1)
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize DefineButton.Characters: %w", err)
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, buttonIDData...)
+	body = append(body, charactersData...)
+	body = append(body, v.Actions...)
 
-	return data, nil
+	return serializeTag(DefineButtonTagCode, v.Extended, body)
 }
 
 func ParseDefineButton(src io.Reader, tag *Uint16, extended *Uint32) (*DefineButton, error) {
@@ -92,10 +97,33 @@ func ParseDefineButton(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBut
 		return nil, fmt.Errorf("broken DefineButton")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	buttonID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton.ButtonID: %w", err)
+	}
+
+	characters, err := readButtonRecords(body, 1)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton.Characters: %w", err)
+	}
+
+	actions, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton.Actions: %w", err)
+	}
+
 	result := &DefineButton{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:        tag,
+		Extended:   extended,
+		ButtonID:   buttonID,
+		Characters: characters,
+		Actions:    actions,
+		data:       data,
 	}
 
 	return result, nil
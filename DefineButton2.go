@@ -7,9 +7,14 @@ import (
 )
 
 type DefineButton2 struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag          *Uint16
+	Extended     *Uint32
+	ButtonID     *Uint16
+	TrackAsMenu  bool
+	ActionOffset *Uint16
+	Characters   []*ButtonRecord
+	Actions      []*ButtonCondAction
+	data         *bytes.Buffer
 }
 
 func (v *DefineButton2) TagCode() TagCode {
@@ -21,7 +26,7 @@ func (v *DefineButton2) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineButton2{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineButton2{ButtonID: %d, TrackAsMenu: %v, Characters: %d, Actions: %d}", v.ButtonID.Value, v.TrackAsMenu, len(v.Characters), len(v.Actions))
 }
 
 func (v *DefineButton2) Bytes() []byte {
@@ -44,30 +49,57 @@ func (v *DefineButton2) Bytes() []byte {
 	return data
 }
 
+// Serialize encodes the tag. The ActionOffset and the CondActionSize of each
+// action are recomputed.
 func (v *DefineButton2) Serialize() ([]byte, error) {
 	if v == nil {
 		return nil, fmt.Errorf("cannot serialize because DefineButton2 is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	buttonIDData, err := v.ButtonID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	charactersData, err := serializeButtonRecords(v.Characters, 2)
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize DefineButton2.Characters: %w", err)
+	}
+
+	var trackAsMenu uint8
+
+	if v.TrackAsMenu {
+		trackAsMenu = 1
+	}
+
+	var actionOffset int
+
+	if len(v.Actions) > 0 {
+		actionOffset = 2 + len(charactersData)
+	}
+	if actionOffset > 0xffff {
+		return nil, fmt.Errorf("failed to serialize DefineButton2: too large characters: %d bytes", len(charactersData))
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, buttonIDData...)
+	body = append(body, trackAsMenu, uint8(actionOffset), uint8(actionOffset>>8))
+	body = append(body, charactersData...)
 
-	return data, nil
+	for i, action := range v.Actions {
+		actionData, err := action.Serialize(i == len(v.Actions)-1)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize DefineButton2.Actions[%d]: %w", i, err)
+		}
+
+		body = append(body, actionData...)
+	}
+
+	return serializeTag(DefineButton2TagCode, v.Extended, body)
 }
 
 func ParseDefineButton2(src io.Reader, tag *Uint16, extended *Uint32) (*DefineButton2, error) {
@@ -92,10 +124,56 @@ func ParseDefineButton2(src io.Reader, tag *Uint16, extended *Uint32) (*DefineBu
 		return nil, fmt.Errorf("broken DefineButton2")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	buttonID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton2.ButtonID: %w", err)
+	}
+
+	flags, err := ReadUint8(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton2 flags: %w", err)
+	}
+
+	actionOffset, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton2.ActionOffset: %w", err)
+	}
+
+	characters, err := readButtonRecords(body, 2)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButton2.Characters: %w", err)
+	}
+
+	actions := []*ButtonCondAction{}
+
+	if actionOffset.Value != 0 {
+		// The offset is counted from the ActionOffset field itself.
+		if _, err := body.Seek(int64(actionOffset.Value)+3, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read DefineButton2.Actions: %w", err)
+		}
+
+		actions, err = ReadButtonCondActions(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DefineButton2.Actions: %w", err)
+		}
+	}
+
 	result := &DefineButton2{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:          tag,
+		Extended:     extended,
+		ButtonID:     buttonID,
+		TrackAsMenu:  flags.Value&0b1 != 0,
+		ActionOffset: actionOffset,
+		Characters:   characters,
+		Actions:      actions,
+		data:         data,
 	}
 
 	return result, nil
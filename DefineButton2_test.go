@@ -0,0 +1,50 @@
+package swf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDefineButton2(t *testing.T) {
+	body := []byte{
+		// ButtonID, TrackAsMenu and ActionOffset
+		0x01, 0x00, 0x00, 0x0a, 0x00,
+		// BUTTONRECORD with an empty MATRIX and CXFORMWITHALPHA
+		0x0f, 0x02, 0x00, 0x01, 0x00, 0x00, 0x00,
+		// CharacterEndFlag
+		0x00,
+		// BUTTONCONDACTION: Release and KeyPress "a", ActionStop and ActionEndFlag
+		0x00, 0x00, 0x08, 0xc2, 0x07, 0x00,
+	}
+	tag := uint16(DefineButton2TagCode)<<6 | uint16(len(body))
+	data := append([]byte{byte(tag), byte(tag >> 8)}, body...)
+
+	content, err := parseContent(bytes.NewBuffer(data))
+
+	require.NoError(t, err)
+
+	button, ok := content.(*DefineButton2)
+
+	require.True(t, ok)
+	require.Equal(t, uint16(1), button.ButtonID.Value)
+	require.Len(t, button.Characters, 1)
+	require.Equal(t, uint16(2), button.Characters[0].CharacterID.Value)
+	require.True(t, button.Characters[0].StateHitTest)
+	require.Len(t, button.Actions, 1)
+	require.Equal(t, []string{"Release", "KeyPress a"}, button.Actions[0].Conditions())
+	require.Equal(t, []byte{0x07, 0x00}, button.Actions[0].Actions)
+	require.Equal(t, data, button.Bytes())
+
+	serialized, err := button.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, data, serialized)
+
+	file := &File{Contents: ContentSlice{button}}
+	buttons := file.Buttons()
+
+	require.Len(t, buttons, 1)
+	require.Len(t, buttons[0].Actions(), 1)
+}
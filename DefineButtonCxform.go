@@ -7,9 +7,11 @@ import (
 )
 
 type DefineButtonCxform struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag            *Uint16
+	Extended       *Uint32
+	ButtonID       *Uint16
+	ColorTransform *ColorTransform
+	data           *bytes.Buffer
 }
 
 func (v *DefineButtonCxform) TagCode() TagCode {
@@ -21,7 +23,7 @@ func (v *DefineButtonCxform) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineButtonCxform{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineButtonCxform{ButtonID: %d, ColorTransform: %s}", v.ButtonID.Value, v.ColorTransform)
 }
 
 func (v *DefineButtonCxform) Bytes() []byte {
@@ -49,25 +51,24 @@ func (v *DefineButtonCxform) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineButtonCxform is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	buttonIDData, err := v.ButtonID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	colorTransformData, err := v.ColorTransform.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, buttonIDData...)
+	body = append(body, colorTransformData...)
 
-	return data, nil
+	return serializeTag(DefineButtonCxformTagCode, v.Extended, body)
 }
 
 func ParseDefineButtonCxform(src io.Reader, tag *Uint16, extended *Uint32) (*DefineButtonCxform, error) {
@@ -92,10 +93,26 @@ func ParseDefineButtonCxform(src io.Reader, tag *Uint16, extended *Uint32) (*Def
 		return nil, fmt.Errorf("broken DefineButtonCxform")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	buttonID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButtonCxform.ButtonID: %w", err)
+	}
+
+	colorTransform, err := ReadColorTransform(body, false)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButtonCxform.ColorTransform: %w", err)
+	}
+
 	result := &DefineButtonCxform{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:            tag,
+		Extended:       extended,
+		ButtonID:       buttonID,
+		ColorTransform: colorTransform,
+		data:           data,
 	}
 
 	return result, nil
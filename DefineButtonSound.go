@@ -9,19 +9,55 @@ import (
 type DefineButtonSound struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	ButtonID *Uint16
+	// OverUpToIdle
+	ButtonSoundChar0 *Uint16
+	ButtonSoundInfo0 *SoundInfo
+	// IdleToOverUp
+	ButtonSoundChar1 *Uint16
+	ButtonSoundInfo1 *SoundInfo
+	// OverUpToOverDown
+	ButtonSoundChar2 *Uint16
+	ButtonSoundInfo2 *SoundInfo
+	// OverDownToOverUp
+	ButtonSoundChar3 *Uint16
+	ButtonSoundInfo3 *SoundInfo
+	data             *bytes.Buffer
 }
 
 func (v *DefineButtonSound) TagCode() TagCode {
 	return DefineButtonSoundTagCode
 }
 
+func (v *DefineButtonSound) sounds() []struct {
+	char **Uint16
+	info **SoundInfo
+} {
+	return []struct {
+		char **Uint16
+		info **SoundInfo
+	}{
+		{&v.ButtonSoundChar0, &v.ButtonSoundInfo0},
+		{&v.ButtonSoundChar1, &v.ButtonSoundInfo1},
+		{&v.ButtonSoundChar2, &v.ButtonSoundInfo2},
+		{&v.ButtonSoundChar3, &v.ButtonSoundInfo3},
+	}
+}
+
 func (v *DefineButtonSound) String() string {
 	if v == nil {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineButtonSound{%d bytes}", len(v.data.Bytes()))
+	ids := make([]uint16, 4)
+
+	for i, sound := range v.sounds() {
+		if *sound.char != nil {
+			ids[i] = (*sound.char).Value
+		}
+	}
+
+	return fmt.Sprintf("DefineButtonSound{ButtonID: %d, OverUpToIdle: %d, IdleToOverUp: %d, OverUpToOverDown: %d, OverDownToOverUp: %d}", v.ButtonID.Value, ids[0], ids[1], ids[2], ids[3])
 }
 
 func (v *DefineButtonSound) Bytes() []byte {
@@ -49,25 +85,44 @@ func (v *DefineButtonSound) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineButtonSound is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.ButtonID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	for i, sound := range v.sounds() {
+		char := *sound.char
 
-	if err != nil {
-		return nil, err
-	}
+		if char == nil {
+			char = &Uint16{}
+		}
+
+		charData, err := char.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		body = append(body, charData...)
+
+		if char.Value == 0 {
+			continue
+		}
+		if *sound.info == nil {
+			return nil, fmt.Errorf("failed to serialize DefineButtonSound.ButtonSoundInfo%d: SoundInfo is nil", i)
+		}
+
+		infoData, err := (*sound.info).Serialize()
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize DefineButtonSound.ButtonSoundInfo%d: %w", i, err)
+		}
 
-	return data, nil
+		body = append(body, infoData...)
+	}
+
+	return serializeTag(DefineButtonSoundTagCode, v.Extended, body)
 }
 
 func ParseDefineButtonSound(src io.Reader, tag *Uint16, extended *Uint32) (*DefineButtonSound, error) {
@@ -92,11 +147,42 @@ func ParseDefineButtonSound(src io.Reader, tag *Uint16, extended *Uint32) (*Defi
 		return nil, fmt.Errorf("broken DefineButtonSound")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	buttonID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineButtonSound.ButtonID: %w", err)
+	}
+
 	result := &DefineButtonSound{
 		Tag:      tag,
 		Extended: extended,
+		ButtonID: buttonID,
 		data:     data,
 	}
 
+	for i, sound := range result.sounds() {
+		char, err := ReadUint16(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DefineButtonSound.ButtonSoundChar%d: %w", i, err)
+		}
+
+		*sound.char = char
+
+		if char.Value == 0 {
+			continue
+		}
+
+		info, err := ReadSoundInfo(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DefineButtonSound.ButtonSoundInfo%d: %w", i, err)
+		}
+
+		*sound.info = info
+	}
+
 	return result, nil
 }
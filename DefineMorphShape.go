@@ -7,9 +7,10 @@ import (
 )
 
 type DefineMorphShape struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag        *Uint16
+	Extended   *Uint32
+	MorphShape *MorphShape
+	data       *bytes.Buffer
 }
 
 func (v *DefineMorphShape) TagCode() TagCode {
@@ -21,7 +22,7 @@ func (v *DefineMorphShape) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineMorphShape{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineMorphShape{ID: %d, MorphFillStyles: %d, MorphLineStyles: %d, StartEdges: %d, EndEdges: %d}", v.MorphShape.ID.Value, len(v.MorphShape.MorphFillStyles), len(v.MorphShape.MorphLineStyles), len(v.MorphShape.StartEdges), len(v.MorphShape.EndEdges))
 }
 
 func (v *DefineMorphShape) Bytes() []byte {
@@ -49,25 +50,13 @@ func (v *DefineMorphShape) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineMorphShape is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.MorphShape.Serialize()
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize DefineMorphShape.MorphShape: %w", err)
 	}
 
-	extendedData, err := v.Extended.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(DefineMorphShapeTagCode, v.Extended, body)
 }
 
 func ParseDefineMorphShape(src io.Reader, tag *Uint16, extended *Uint32) (*DefineMorphShape, error) {
@@ -92,10 +81,17 @@ func ParseDefineMorphShape(src io.Reader, tag *Uint16, extended *Uint32) (*Defin
 		return nil, fmt.Errorf("broken DefineMorphShape")
 	}
 
+	morphShape, err := ReadDefineMorphShape(bytes.NewReader(data.Bytes()), 1)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineMorphShape.MorphShape: %w", err)
+	}
+
 	result := &DefineMorphShape{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:        tag,
+		Extended:   extended,
+		MorphShape: morphShape,
+		data:       data,
 	}
 
 	return result, nil
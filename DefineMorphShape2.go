@@ -7,9 +7,10 @@ import (
 )
 
 type DefineMorphShape2 struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag        *Uint16
+	Extended   *Uint32
+	MorphShape *MorphShape
+	data       *bytes.Buffer
 }
 
 func (v *DefineMorphShape2) TagCode() TagCode {
@@ -21,7 +22,7 @@ func (v *DefineMorphShape2) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineMorphShape2{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineMorphShape2{ID: %d, MorphFillStyles: %d, MorphLineStyles: %d, StartEdges: %d, EndEdges: %d}", v.MorphShape.ID.Value, len(v.MorphShape.MorphFillStyles), len(v.MorphShape.MorphLineStyles), len(v.MorphShape.StartEdges), len(v.MorphShape.EndEdges))
 }
 
 func (v *DefineMorphShape2) Bytes() []byte {
@@ -49,25 +50,13 @@ func (v *DefineMorphShape2) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineMorphShape2 is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.MorphShape.Serialize()
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize DefineMorphShape2.MorphShape: %w", err)
 	}
 
-	extendedData, err := v.Extended.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(DefineMorphShape2TagCode, v.Extended, body)
 }
 
 func ParseDefineMorphShape2(src io.Reader, tag *Uint16, extended *Uint32) (*DefineMorphShape2, error) {
@@ -92,10 +81,17 @@ func ParseDefineMorphShape2(src io.Reader, tag *Uint16, extended *Uint32) (*Defi
 		return nil, fmt.Errorf("broken DefineMorphShape2")
 	}
 
+	morphShape, err := ReadDefineMorphShape(bytes.NewReader(data.Bytes()), 2)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineMorphShape2.MorphShape: %w", err)
+	}
+
 	result := &DefineMorphShape2{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:        tag,
+		Extended:   extended,
+		MorphShape: morphShape,
+		data:       data,
 	}
 
 	return result, nil
@@ -3,13 +3,16 @@ package swf
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"io"
 )
 
 type DefineScalingGrid struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag         *Uint16
+	Extended    *Uint32
+	CharacterID *Uint16
+	Splitter    *Rectangle
+	data        *bytes.Buffer
 }
 
 func (v *DefineScalingGrid) TagCode() TagCode {
@@ -21,7 +24,9 @@ func (v *DefineScalingGrid) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineScalingGrid{%d bytes}", len(v.data.Bytes()))
+	minX, maxX, minY, maxY := v.Splitter.Values()
+
+	return fmt.Sprintf("DefineScalingGrid{CharacterID: %d, Splitter: {%d %d %d %d}}", v.CharacterID.Value, minX, maxX, minY, maxY)
 }
 
 func (v *DefineScalingGrid) Bytes() []byte {
@@ -49,25 +54,71 @@ func (v *DefineScalingGrid) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineScalingGrid is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	characterIDData, err := v.CharacterID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	splitterData, err := v.Splitter.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, characterIDData...)
+	body = append(body, splitterData...)
+
+	return serializeTag(DefineScalingGridTagCode, v.Extended, body)
+}
+
+// Slices returns the nine source rectangles and the nine destination
+// rectangles of the 9-slice scaling in twips. The bounds are the bounds of the
+// character and the destination is the rectangle of width x height placed at
+// the top-left corner of the bounds. The slices are ordered from top-left to
+// bottom-right row by row. The corners keep their size unless the destination
+// is smaller than the corners, in which case they are shrunk proportionally.
+func (v *DefineScalingGrid) Slices(bounds image.Rectangle, width, height int) (source, destination [9]image.Rectangle) {
+	minX, maxX, minY, maxY := v.Splitter.Values()
+	inner := image.Rect(int(minX), int(minY), int(maxX), int(maxY)).Intersect(bounds)
+
+	if inner.Empty() {
+		// The player ignores a splitter outside of the bounds.
+		inner = bounds
+	}
+
+	xs := []int{bounds.Min.X, inner.Min.X, inner.Max.X, bounds.Max.X}
+	ys := []int{bounds.Min.Y, inner.Min.Y, inner.Max.Y, bounds.Max.Y}
+	dxs := scaleSlices(xs, width)
+	dys := scaleSlices(ys, height)
+
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 3; column++ {
+			source[row*3+column] = image.Rect(xs[column], ys[row], xs[column+1], ys[row+1])
+			destination[row*3+column] = image.Rect(dxs[column], dys[row], dxs[column+1], dys[row+1])
+		}
+	}
+
+	return source, destination
+}
+
+// scaleSlices returns the edges of the slices along an axis whose total size
+// becomes size.
+func scaleSlices(edges []int, size int) []int {
+	start := edges[1] - edges[0]
+	end := edges[3] - edges[2]
+
+	if start+end > size {
+		if start+end == 0 {
+			start, end = 0, 0
+		} else {
+			start, end = start*size/(start+end), size-start*size/(start+end)
+		}
+	}
 
-	return data, nil
+	return []int{edges[0], edges[0] + start, edges[0] + size - end, edges[0] + size}
 }
 
 func ParseDefineScalingGrid(src io.Reader, tag *Uint16, extended *Uint32) (*DefineScalingGrid, error) {
@@ -92,10 +143,26 @@ func ParseDefineScalingGrid(src io.Reader, tag *Uint16, extended *Uint32) (*Defi
 		return nil, fmt.Errorf("broken DefineScalingGrid")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineScalingGrid.CharacterID: %w", err)
+	}
+
+	splitter, err := ReadRectangle(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineScalingGrid.Splitter: %w", err)
+	}
+
 	result := &DefineScalingGrid{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:         tag,
+		Extended:    extended,
+		CharacterID: characterID,
+		Splitter:    splitter,
+		data:        data,
 	}
 
 	return result, nil
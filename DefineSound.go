@@ -2,14 +2,26 @@ package swf
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
+
+	"github.com/moutend/swf/adpcm"
 )
 
 type DefineSound struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag              *Uint16
+	Extended         *Uint32
+	SoundID          *Uint16
+	SoundFormat      SoundFormat
+	SoundRate        SoundRate
+	Is16Bit          bool
+	IsStereo         bool
+	SoundSampleCount *Uint32
+	// SoundData holds the encoded samples. The MP3 data starts with the
+	// SeekSamples field.
+	SoundData []byte
+	data      *bytes.Buffer
 }
 
 func (v *DefineSound) TagCode() TagCode {
@@ -21,7 +33,137 @@ func (v *DefineSound) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineSound{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineSound{SoundID: %d, SoundFormat: %s, SoundRate: %s, Is16Bit: %v, IsStereo: %v, SoundSampleCount: %d, SoundData: %d bytes}", v.SoundID.Value, v.SoundFormat, v.SoundRate, v.Is16Bit, v.IsStereo, v.SoundSampleCount.Value, len(v.SoundData))
+}
+
+// Channels returns 2 for a stereo sound and 1 for a mono sound.
+func (v *DefineSound) Channels() int {
+	if v != nil && v.IsStereo {
+		return 2
+	}
+
+	return 1
+}
+
+// ExportWAV returns the samples as a WAV file. Native-endian samples are
+// treated as little-endian, which is what the authoring tools on x86 write,
+// and ADPCM is decoded into 16-bit samples. It returns an
+// *UnsupportedSoundFormatError for the other codecs.
+func (v *DefineSound) ExportWAV() ([]byte, error) {
+	return v.ExportWAVWithByteOrder(binary.LittleEndian)
+}
+
+// ExportWAVWithByteOrder is the same as ExportWAV, but reads native-endian
+// 16-bit samples in the byte order, e.g. binary.BigEndian for the sounds
+// authored on PowerPC.
+func (v *DefineSound) ExportWAVWithByteOrder(order binary.ByteOrder) ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot export because DefineSound is nil")
+	}
+	if order == nil {
+		return nil, fmt.Errorf("cannot export because order is nil")
+	}
+
+	switch v.SoundFormat {
+	case SoundFormatUncompressedNativeEndian, SoundFormatUncompressedLittleEndian:
+		bitsPerSample := 8
+
+		if v.Is16Bit {
+			bitsPerSample = 16
+		}
+
+		return EncodeWAV(v.SoundData, v.SoundRate.Hz(), v.Channels(), bitsPerSample, isBigEndian(v.SoundFormat, order))
+	case SoundFormatADPCM:
+		samples, err := adpcm.DecodeBytes(v.SoundData, v.IsStereo)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to export DefineSound: %w", err)
+		}
+		if v.SoundSampleCount != nil {
+			if length := int(v.SoundSampleCount.Value) * v.Channels() * 2; length < len(samples) {
+				samples = samples[:length]
+			}
+		}
+
+		return EncodeWAV(samples, v.SoundRate.Hz(), v.Channels(), 16, false)
+	default:
+		return nil, &UnsupportedSoundFormatError{Format: v.SoundFormat}
+	}
+}
+
+// Samples decodes the uncompressed or ADPCM sound into signed 16-bit
+// samples, interleaved when the sound is stereo. 8-bit samples are scaled to
+// 16 bits and native-endian samples are treated as little-endian. It returns
+// an *UnsupportedSoundFormatError for the other codecs.
+func (v *DefineSound) Samples() ([]int16, error) {
+	return v.SamplesWithByteOrder(binary.LittleEndian)
+}
+
+// SamplesWithByteOrder is the same as Samples, but reads native-endian 16-bit
+// samples in the byte order.
+func (v *DefineSound) SamplesWithByteOrder(order binary.ByteOrder) ([]int16, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DefineSound is nil")
+	}
+	if order == nil {
+		return nil, fmt.Errorf("cannot decode because order is nil")
+	}
+	if !isBigEndian(v.SoundFormat, order) {
+		order = binary.LittleEndian
+	}
+
+	switch v.SoundFormat {
+	case SoundFormatUncompressedNativeEndian, SoundFormatUncompressedLittleEndian:
+		if !v.Is16Bit {
+			samples := make([]int16, len(v.SoundData))
+
+			for i, b := range v.SoundData {
+				samples[i] = int16(int(b)-128) << 8
+			}
+
+			return samples, nil
+		}
+
+		samples := make([]int16, len(v.SoundData)/2)
+
+		for i := range samples {
+			samples[i] = int16(order.Uint16(v.SoundData[i*2:]))
+		}
+
+		return samples, nil
+	case SoundFormatADPCM:
+		samples, err := adpcm.Decode(v.SoundData, v.IsStereo)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode DefineSound: %w", err)
+		}
+		if v.SoundSampleCount != nil {
+			if length := int(v.SoundSampleCount.Value) * v.Channels(); length < len(samples) {
+				samples = samples[:length]
+			}
+		}
+
+		return samples, nil
+	default:
+		return nil, &UnsupportedSoundFormatError{Format: v.SoundFormat}
+	}
+}
+
+// ExportMP3 returns the MP3 frames without the SeekSamples field, which any
+// MP3 player can play. It returns an *UnsupportedSoundFormatError for sounds
+// which are not MP3.
+func (v *DefineSound) ExportMP3() ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot export because DefineSound is nil")
+	}
+	if v.SoundFormat != SoundFormatMP3 {
+		return nil, &UnsupportedSoundFormatError{Format: v.SoundFormat}
+	}
+	if len(v.SoundData) < 2 {
+		return nil, fmt.Errorf("failed to export DefineSound: SeekSamples is missing")
+	}
+
+	return v.SoundData[2:], nil
 }
 
 func (v *DefineSound) Bytes() []byte {
@@ -49,25 +191,30 @@ func (v *DefineSound) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineSound is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.SoundID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	sampleCountData, err := v.SoundSampleCount.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	flags := soundFlags{
+		Format:   v.SoundFormat,
+		Rate:     v.SoundRate,
+		Is16Bit:  v.Is16Bit,
+		IsStereo: v.IsStereo,
+	}
+
+	body = append(body, flags.serialize())
+	body = append(body, sampleCountData...)
+	body = append(body, v.SoundData...)
 
-	return data, nil
+	return serializeTag(DefineSoundTagCode, v.Extended, body)
 }
 
 func ParseDefineSound(src io.Reader, tag *Uint16, extended *Uint32) (*DefineSound, error) {
@@ -92,10 +239,45 @@ func ParseDefineSound(src io.Reader, tag *Uint16, extended *Uint32) (*DefineSoun
 		return nil, fmt.Errorf("broken DefineSound")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	soundID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineSound.SoundID: %w", err)
+	}
+
+	flagsData, err := ReadUint8(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineSound flags: %w", err)
+	}
+
+	sampleCount, err := ReadUint32(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineSound.SoundSampleCount: %w", err)
+	}
+
+	soundData, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineSound.SoundData: %w", err)
+	}
+
+	flags := parseSoundFlags(flagsData.Value)
+
 	result := &DefineSound{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:              tag,
+		Extended:         extended,
+		SoundID:          soundID,
+		SoundFormat:      flags.Format,
+		SoundRate:        flags.Rate,
+		Is16Bit:          flags.Is16Bit,
+		IsStereo:         flags.IsStereo,
+		SoundSampleCount: sampleCount,
+		SoundData:        soundData,
+		data:             data,
 	}
 
 	return result, nil
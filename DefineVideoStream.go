@@ -7,9 +7,18 @@ import (
 )
 
 type DefineVideoStream struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag         *Uint16
+	Extended    *Uint32
+	CharacterID *Uint16
+	NumFrames   *Uint16
+	Width       *Uint16
+	Height      *Uint16
+	// Deblocking is 0 to use the setting of the video packets, 1 to turn off
+	// the filter, and 2 to 6 to select the level of the filter.
+	Deblocking uint8
+	Smoothing  bool
+	CodecID    VideoCodec
+	data       *bytes.Buffer
 }
 
 func (v *DefineVideoStream) TagCode() TagCode {
@@ -21,7 +30,7 @@ func (v *DefineVideoStream) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DefineVideoStream{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DefineVideoStream{CharacterID: %d, NumFrames: %d, Width: %d, Height: %d, Deblocking: %d, Smoothing: %v, CodecID: %s}", v.CharacterID.Value, v.NumFrames.Value, v.Width.Value, v.Height.Value, v.Deblocking, v.Smoothing, v.CodecID)
 }
 
 func (v *DefineVideoStream) Bytes() []byte {
@@ -49,25 +58,27 @@ func (v *DefineVideoStream) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DefineVideoStream is nil")
 	}
 
-	var data []byte
+	var body []byte
 
-	tagData, err := v.Tag.Serialize()
+	for _, field := range []*Uint16{v.CharacterID, v.NumFrames, v.Width, v.Height} {
+		fieldData, err := field.Serialize()
 
-	if err != nil {
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+
+		body = append(body, fieldData...)
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	flags := (v.Deblocking & 0b111) << 1
 
-	if err != nil {
-		return nil, err
+	if v.Smoothing {
+		flags |= 0b1
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, flags, uint8(v.CodecID))
 
-	return data, nil
+	return serializeTag(DefineVideoStreamTagCode, v.Extended, body)
 }
 
 func ParseDefineVideoStream(src io.Reader, tag *Uint16, extended *Uint32) (*DefineVideoStream, error) {
@@ -92,10 +103,55 @@ func ParseDefineVideoStream(src io.Reader, tag *Uint16, extended *Uint32) (*Defi
 		return nil, fmt.Errorf("broken DefineVideoStream")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	characterID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineVideoStream.CharacterID: %w", err)
+	}
+
+	numFrames, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineVideoStream.NumFrames: %w", err)
+	}
+
+	width, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineVideoStream.Width: %w", err)
+	}
+
+	height, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineVideoStream.Height: %w", err)
+	}
+
+	flags, err := ReadUint8(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineVideoStream flags: %w", err)
+	}
+
+	codecID, err := ReadUint8(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DefineVideoStream.CodecID: %w", err)
+	}
+
 	result := &DefineVideoStream{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:         tag,
+		Extended:    extended,
+		CharacterID: characterID,
+		NumFrames:   numFrames,
+		Width:       width,
+		Height:      height,
+		Deblocking:  flags.Value >> 1 & 0b111,
+		Smoothing:   flags.Value&0b1 != 0,
+		CodecID:     VideoCodec(codecID.Value),
+		data:        data,
 	}
 
 	return result, nil
@@ -4,12 +4,22 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+
+	"github.com/moutend/swf/abc"
 )
 
+// DoAbcLazyInitialize is the flag of DoAbc which defers the execution of
+// the ABC until it is needed.
+const DoAbcLazyInitialize = 0x00000001
+
 type DoAbc struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	Flags    *Uint32
+	Name     string
+	// ABC holds the abcFile.
+	ABC  []byte
+	data *bytes.Buffer
 }
 
 func (v *DoAbc) TagCode() TagCode {
@@ -21,7 +31,44 @@ func (v *DoAbc) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DoAbc{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DoAbc{Name: %q, ABC: %d bytes}", v.Name, len(v.ABC))
+}
+
+// DecodeAbc decodes the ABC with the abc package.
+func (v *DoAbc) DecodeAbc() (*abc.File, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DoAbc is nil")
+	}
+
+	return abc.Decode(v.ABC)
+}
+
+// EncodeAbc replaces the ABC with the encoded file.
+func (v *DoAbc) EncodeAbc(f *abc.File) error {
+	if v == nil {
+		return fmt.Errorf("cannot encode because DoAbc is nil")
+	}
+
+	data, err := abc.Encode(f)
+
+	if err != nil {
+		return err
+	}
+
+	v.ABC = data
+
+	return nil
+}
+
+// Decompile decompiles the ABC into ActionScript 3 source files.
+func (v *DoAbc) Decompile() ([]*abc.SourceFile, error) {
+	f, err := v.DecodeAbc()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Decompile()
 }
 
 func (v *DoAbc) Bytes() []byte {
@@ -49,25 +96,16 @@ func (v *DoAbc) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DoAbc is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	extendedData, err := v.Extended.Serialize()
+	flagsData, err := v.Flags.Serialize()
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize DoAbc.Flags: %w", err)
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body := append(flagsData, serializeString(v.Name)...)
+	body = append(body, v.ABC...)
 
-	return data, nil
+	return serializeTag(DoAbcTagCode, v.Extended, body)
 }
 
 func ParseDoAbc(src io.Reader, tag *Uint16, extended *Uint32) (*DoAbc, error) {
@@ -92,9 +130,26 @@ func ParseDoAbc(src io.Reader, tag *Uint16, extended *Uint32) (*DoAbc, error) {
 		return nil, fmt.Errorf("broken DoAbc")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	flags, err := ReadUint32(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DoAbc.Flags: %w", err)
+	}
+
+	name, err := readString(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DoAbc.Name: %w", err)
+	}
+
 	result := &DoAbc{
 		Tag:      tag,
 		Extended: extended,
+		Flags:    flags,
+		Name:     name,
+		ABC:      data.Bytes()[len(data.Bytes())-body.Len():],
 		data:     data,
 	}
 
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+
+	"github.com/moutend/swf/avm1"
 )
 
 type DoAction struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	// Actions holds the ACTIONRECORD stream including the ActionEndFlag.
+	Actions []byte
+	data    *bytes.Buffer
 }
 
 func (v *DoAction) TagCode() TagCode {
@@ -21,7 +25,72 @@ func (v *DoAction) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DoAction{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DoAction{Actions: %d bytes}", len(v.Actions))
+}
+
+// DecodeActions decodes the actions with the avm1 package.
+func (v *DoAction) DecodeActions() ([]avm1.Action, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DoAction is nil")
+	}
+
+	return avm1.Decode(v.Actions)
+}
+
+// Disassemble formats the actions as the assembly of the avm1 package.
+func (v *DoAction) Disassemble() (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("cannot disassemble because DoAction is nil")
+	}
+
+	return avm1.Disassemble(v.Actions)
+}
+
+// Assemble replaces the actions with the assembled text.
+func (v *DoAction) Assemble(text string) error {
+	if v == nil {
+		return fmt.Errorf("cannot assemble because DoAction is nil")
+	}
+
+	actions, err := avm1.Assemble(text)
+
+	if err != nil {
+		return err
+	}
+
+	v.Actions = actions
+
+	return nil
+}
+
+// Decompile lifts the actions into ActionScript 2 source.
+func (v *DoAction) Decompile() (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("cannot decompile because DoAction is nil")
+	}
+
+	actions, err := avm1.Decode(v.Actions)
+
+	if err != nil {
+		return "", err
+	}
+
+	return avm1.Decompile(actions)
+}
+
+// Graph builds the control-flow graph of the actions.
+func (v *DoAction) Graph() (*avm1.Graph, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot build graph because DoAction is nil")
+	}
+
+	actions, err := avm1.Decode(v.Actions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return avm1.BuildGraph(actions), nil
 }
 
 func (v *DoAction) Bytes() []byte {
@@ -49,25 +118,7 @@ func (v *DoAction) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DoAction is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	extendedData, err := v.Extended.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(DoActionTagCode, v.Extended, v.Actions)
 }
 
 func ParseDoAction(src io.Reader, tag *Uint16, extended *Uint32) (*DoAction, error) {
@@ -95,6 +146,7 @@ func ParseDoAction(src io.Reader, tag *Uint16, extended *Uint32) (*DoAction, err
 	result := &DoAction{
 		Tag:      tag,
 		Extended: extended,
+		Actions:  data.Bytes(),
 		data:     data,
 	}
 
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+
+	"github.com/moutend/swf/avm1"
 )
 
 type DoInitAction struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	// SpriteID is the sprite whose actions are initialized. The actions run
+	// once before the first use of the sprite.
+	SpriteID *Uint16
+	// Actions holds the ACTIONRECORD stream including the ActionEndFlag.
+	Actions []byte
+	data    *bytes.Buffer
 }
 
 func (v *DoInitAction) TagCode() TagCode {
@@ -21,7 +28,72 @@ func (v *DoInitAction) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("DoInitAction{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("DoInitAction{SpriteID: %d, Actions: %d bytes}", v.SpriteID.Value, len(v.Actions))
+}
+
+// DecodeActions decodes the actions with the avm1 package.
+func (v *DoInitAction) DecodeActions() ([]avm1.Action, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot decode because DoInitAction is nil")
+	}
+
+	return avm1.Decode(v.Actions)
+}
+
+// Disassemble formats the actions as the assembly of the avm1 package.
+func (v *DoInitAction) Disassemble() (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("cannot disassemble because DoInitAction is nil")
+	}
+
+	return avm1.Disassemble(v.Actions)
+}
+
+// Assemble replaces the actions with the assembled text.
+func (v *DoInitAction) Assemble(text string) error {
+	if v == nil {
+		return fmt.Errorf("cannot assemble because DoInitAction is nil")
+	}
+
+	actions, err := avm1.Assemble(text)
+
+	if err != nil {
+		return err
+	}
+
+	v.Actions = actions
+
+	return nil
+}
+
+// Decompile lifts the actions into ActionScript 2 source.
+func (v *DoInitAction) Decompile() (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("cannot decompile because DoInitAction is nil")
+	}
+
+	actions, err := avm1.Decode(v.Actions)
+
+	if err != nil {
+		return "", err
+	}
+
+	return avm1.Decompile(actions)
+}
+
+// Graph builds the control-flow graph of the actions.
+func (v *DoInitAction) Graph() (*avm1.Graph, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot build graph because DoInitAction is nil")
+	}
+
+	actions, err := avm1.Decode(v.Actions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return avm1.BuildGraph(actions), nil
 }
 
 func (v *DoInitAction) Bytes() []byte {
@@ -49,25 +121,15 @@ func (v *DoInitAction) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because DoInitAction is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	extendedData, err := v.Extended.Serialize()
+	body, err := v.SpriteID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, v.Actions...)
 
-	return data, nil
+	return serializeTag(DoInitActionTagCode, v.Extended, body)
 }
 
 func ParseDoInitAction(src io.Reader, tag *Uint16, extended *Uint32) (*DoInitAction, error) {
@@ -92,9 +154,25 @@ func ParseDoInitAction(src io.Reader, tag *Uint16, extended *Uint32) (*DoInitAct
 		return nil, fmt.Errorf("broken DoInitAction")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	spriteID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DoInitAction.SpriteID: %w", err)
+	}
+
+	actions, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DoInitAction.Actions: %w", err)
+	}
+
 	result := &DoInitAction{
 		Tag:      tag,
 		Extended: extended,
+		SpriteID: spriteID,
+		Actions:  actions,
 		data:     data,
 	}
 
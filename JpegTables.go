@@ -9,6 +9,8 @@ import (
 type JpegTables struct {
 	Tag      *Uint16
 	Extended *Uint32
+	// JpegData holds the encoding tables shared by the DefineBits tags.
+	JpegData []byte
 	data     *bytes.Buffer
 }
 
@@ -21,7 +23,7 @@ func (v *JpegTables) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("JpegTables{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("JpegTables{JpegData: %d bytes}", len(v.JpegData))
 }
 
 func (v *JpegTables) Bytes() []byte {
@@ -49,25 +51,7 @@ func (v *JpegTables) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because JpegTables is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	extendedData, err := v.Extended.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(JpegTablesTagCode, v.Extended, v.JpegData)
 }
 
 func ParseJpegTables(src io.Reader, tag *Uint16, extended *Uint32) (*JpegTables, error) {
@@ -95,6 +79,7 @@ func ParseJpegTables(src io.Reader, tag *Uint16, extended *Uint32) (*JpegTables,
 	result := &JpegTables{
 		Tag:      tag,
 		Extended: extended,
+		JpegData: append([]byte{}, data.Bytes()...),
 		data:     data,
 	}
 
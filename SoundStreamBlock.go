@@ -9,7 +9,11 @@ import (
 type SoundStreamBlock struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	// StreamSoundData holds the samples of a frame. The layout depends on the
+	// preceding SoundStreamHead or SoundStreamHead2. MP3 data starts with the
+	// SampleCount and SeekSamples fields.
+	StreamSoundData []byte
+	data            *bytes.Buffer
 }
 
 func (v *SoundStreamBlock) TagCode() TagCode {
@@ -21,7 +25,7 @@ func (v *SoundStreamBlock) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("SoundStreamBlock{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("SoundStreamBlock{StreamSoundData: %d bytes}", len(v.StreamSoundData))
 }
 
 func (v *SoundStreamBlock) Bytes() []byte {
@@ -49,25 +53,7 @@ func (v *SoundStreamBlock) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because SoundStreamBlock is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	extendedData, err := v.Extended.Serialize()
-
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(SoundStreamBlockTagCode, v.Extended, v.StreamSoundData)
 }
 
 func ParseSoundStreamBlock(src io.Reader, tag *Uint16, extended *Uint32) (*SoundStreamBlock, error) {
@@ -93,9 +79,10 @@ func ParseSoundStreamBlock(src io.Reader, tag *Uint16, extended *Uint32) (*Sound
 	}
 
 	result := &SoundStreamBlock{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:             tag,
+		Extended:        extended,
+		StreamSoundData: data.Bytes(),
+		data:            data,
 	}
 
 	return result, nil
@@ -7,9 +7,22 @@ import (
 )
 
 type SoundStreamHead struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag                    *Uint16
+	Extended               *Uint32
+	PlaybackSoundRate      SoundRate
+	PlaybackIs16Bit        bool
+	PlaybackIsStereo       bool
+	StreamSoundCompression SoundFormat
+	StreamSoundRate        SoundRate
+	StreamIs16Bit          bool
+	StreamIsStereo         bool
+	// StreamSoundSampleCount is the average number of samples in each
+	// SoundStreamBlock.
+	StreamSoundSampleCount *Uint16
+	// LatencySeek is present only when the stream is MP3. It is a signed
+	// number of samples to skip.
+	LatencySeek *Uint16
+	data        *bytes.Buffer
 }
 
 func (v *SoundStreamHead) TagCode() TagCode {
@@ -21,7 +34,16 @@ func (v *SoundStreamHead) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("SoundStreamHead{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("SoundStreamHead{StreamSoundCompression: %s, StreamSoundRate: %s, StreamIs16Bit: %v, StreamIsStereo: %v, StreamSoundSampleCount: %d}", v.StreamSoundCompression, v.StreamSoundRate, v.StreamIs16Bit, v.StreamIsStereo, v.StreamSoundSampleCount.Value)
+}
+
+func (v *SoundStreamHead) streamFlags() soundFlags {
+	return soundFlags{
+		Format:   v.StreamSoundCompression,
+		Rate:     v.StreamSoundRate,
+		Is16Bit:  v.StreamIs16Bit,
+		IsStereo: v.StreamIsStereo,
+	}
 }
 
 func (v *SoundStreamHead) Bytes() []byte {
@@ -49,25 +71,33 @@ func (v *SoundStreamHead) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because SoundStreamHead is nil")
 	}
 
-	var data []byte
+	playbackFlags := soundFlags{
+		Rate:     v.PlaybackSoundRate,
+		Is16Bit:  v.PlaybackIs16Bit,
+		IsStereo: v.PlaybackIsStereo,
+	}
+
+	body := []byte{playbackFlags.serialize(), v.streamFlags().serialize()}
 
-	tagData, err := v.Tag.Serialize()
+	sampleCountData, err := v.StreamSoundSampleCount.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	body = append(body, sampleCountData...)
 
-	if err != nil {
-		return nil, err
-	}
+	if v.StreamSoundCompression == SoundFormatMP3 && v.LatencySeek != nil {
+		latencySeekData, err := v.LatencySeek.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+		body = append(body, latencySeekData...)
+	}
 
-	return data, nil
+	return serializeTag(SoundStreamHeadTagCode, v.Extended, body)
 }
 
 func ParseSoundStreamHead(src io.Reader, tag *Uint16, extended *Uint32) (*SoundStreamHead, error) {
@@ -92,10 +122,53 @@ func ParseSoundStreamHead(src io.Reader, tag *Uint16, extended *Uint32) (*SoundS
 		return nil, fmt.Errorf("broken SoundStreamHead")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	playbackFlagsData, err := ReadUint8(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SoundStreamHead playback flags: %w", err)
+	}
+
+	streamFlagsData, err := ReadUint8(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SoundStreamHead stream flags: %w", err)
+	}
+
+	sampleCount, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SoundStreamHead.StreamSoundSampleCount: %w", err)
+	}
+
+	playbackFlags := parseSoundFlags(playbackFlagsData.Value)
+	streamFlags := parseSoundFlags(streamFlagsData.Value)
+
+	var latencySeek *Uint16
+
+	// Some encoders omit LatencySeek, so it is read only when it is there.
+	if streamFlags.Format == SoundFormatMP3 && body.Len() >= 2 {
+		latencySeek, err = ReadUint16(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SoundStreamHead.LatencySeek: %w", err)
+		}
+	}
+
 	result := &SoundStreamHead{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:                    tag,
+		Extended:               extended,
+		PlaybackSoundRate:      playbackFlags.Rate,
+		PlaybackIs16Bit:        playbackFlags.Is16Bit,
+		PlaybackIsStereo:       playbackFlags.IsStereo,
+		StreamSoundCompression: streamFlags.Format,
+		StreamSoundRate:        streamFlags.Rate,
+		StreamIs16Bit:          streamFlags.Is16Bit,
+		StreamIsStereo:         streamFlags.IsStereo,
+		StreamSoundSampleCount: sampleCount,
+		LatencySeek:            latencySeek,
+		data:                   data,
 	}
 
 	return result, nil
@@ -7,9 +7,11 @@ import (
 )
 
 type StartSound struct {
-	Tag      *Uint16
-	Extended *Uint32
-	data     *bytes.Buffer
+	Tag       *Uint16
+	Extended  *Uint32
+	SoundID   *Uint16
+	SoundInfo *SoundInfo
+	data      *bytes.Buffer
 }
 
 func (v *StartSound) TagCode() TagCode {
@@ -21,7 +23,7 @@ func (v *StartSound) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("StartSound{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("StartSound{SoundID: %d, SoundInfo: %s}", v.SoundID.Value, v.SoundInfo)
 }
 
 func (v *StartSound) Bytes() []byte {
@@ -49,25 +51,21 @@ func (v *StartSound) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because StartSound is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.SoundID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	soundInfoData, err := v.SoundInfo.Serialize()
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize StartSound.SoundInfo: %w", err)
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, soundInfoData...)
 
-	return data, nil
+	return serializeTag(StartSoundTagCode, v.Extended, body)
 }
 
 func ParseStartSound(src io.Reader, tag *Uint16, extended *Uint32) (*StartSound, error) {
@@ -92,10 +90,26 @@ func ParseStartSound(src io.Reader, tag *Uint16, extended *Uint32) (*StartSound,
 		return nil, fmt.Errorf("broken StartSound")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	soundID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read StartSound.SoundID: %w", err)
+	}
+
+	soundInfo, err := ReadSoundInfo(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read StartSound.SoundInfo: %w", err)
+	}
+
 	result := &StartSound{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:       tag,
+		Extended:  extended,
+		SoundID:   soundID,
+		SoundInfo: soundInfo,
+		data:      data,
 	}
 
 	return result, nil
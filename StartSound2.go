@@ -9,7 +9,11 @@ import (
 type StartSound2 struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	// SoundClassName is the ActionScript 3 class of the sound, which is
+	// linked to a DefineSound by SymbolClass.
+	SoundClassName string
+	SoundInfo      *SoundInfo
+	data           *bytes.Buffer
 }
 
 func (v *StartSound2) TagCode() TagCode {
@@ -21,7 +25,7 @@ func (v *StartSound2) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("StartSound2{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("StartSound2{SoundClassName: %q, SoundInfo: %s}", v.SoundClassName, v.SoundInfo)
 }
 
 func (v *StartSound2) Bytes() []byte {
@@ -49,25 +53,16 @@ func (v *StartSound2) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because StartSound2 is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	soundInfoData, err := v.SoundInfo.Serialize()
 
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to serialize StartSound2.SoundInfo: %w", err)
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	body := serializeString(v.SoundClassName)
+	body = append(body, soundInfoData...)
 
-	if err != nil {
-		return nil, err
-	}
-
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(StartSound2TagCode, v.Extended, body)
 }
 
 func ParseStartSound2(src io.Reader, tag *Uint16, extended *Uint32) (*StartSound2, error) {
@@ -92,10 +87,26 @@ func ParseStartSound2(src io.Reader, tag *Uint16, extended *Uint32) (*StartSound
 		return nil, fmt.Errorf("broken StartSound2")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	soundClassName, err := readString(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read StartSound2.SoundClassName: %w", err)
+	}
+
+	soundInfo, err := ReadSoundInfo(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read StartSound2.SoundInfo: %w", err)
+	}
+
 	result := &StartSound2{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:            tag,
+		Extended:       extended,
+		SoundClassName: soundClassName,
+		SoundInfo:      soundInfo,
+		data:           data,
 	}
 
 	return result, nil
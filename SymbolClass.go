@@ -6,9 +6,17 @@ import (
 	"io"
 )
 
+// Symbol links a character to an ActionScript 3 class. The CharacterID 0
+// denotes the main timeline, whose class is the document class.
+type Symbol struct {
+	CharacterID *Uint16
+	Name        string
+}
+
 type SymbolClass struct {
 	Tag      *Uint16
 	Extended *Uint32
+	Symbols  []*Symbol
 	data     *bytes.Buffer
 }
 
@@ -21,7 +29,7 @@ func (v *SymbolClass) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("SymbolClass{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("SymbolClass{Symbols: %d}", len(v.Symbols))
 }
 
 func (v *SymbolClass) Bytes() []byte {
@@ -48,26 +56,24 @@ func (v *SymbolClass) Serialize() ([]byte, error) {
 	if v == nil {
 		return nil, fmt.Errorf("cannot serialize because SymbolClass is nil")
 	}
+	if len(v.Symbols) > 0xffff {
+		return nil, fmt.Errorf("failed to serialize SymbolClass: too many symbols: %d", len(v.Symbols))
+	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body := []byte{uint8(len(v.Symbols)), uint8(len(v.Symbols) >> 8)}
 
-	if err != nil {
-		return nil, err
-	}
+	for i, symbol := range v.Symbols {
+		characterIDData, err := symbol.CharacterID.Serialize()
 
-	extendedData, err := v.Extended.Serialize()
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize SymbolClass.Symbols[%d]: %w", i, err)
+		}
 
-	if err != nil {
-		return nil, err
+		body = append(body, characterIDData...)
+		body = append(body, serializeString(symbol.Name)...)
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
-
-	return data, nil
+	return serializeTag(SymbolClassTagCode, v.Extended, body)
 }
 
 func ParseSymbolClass(src io.Reader, tag *Uint16, extended *Uint32) (*SymbolClass, error) {
@@ -92,9 +98,39 @@ func ParseSymbolClass(src io.Reader, tag *Uint16, extended *Uint32) (*SymbolClas
 		return nil, fmt.Errorf("broken SymbolClass")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	numSymbols, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SymbolClass.NumSymbols: %w", err)
+	}
+
+	symbols := make([]*Symbol, numSymbols.Value)
+
+	for i := range symbols {
+		characterID, err := ReadUint16(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SymbolClass.Symbols[%d].CharacterID: %w", i, err)
+		}
+
+		name, err := readString(body)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SymbolClass.Symbols[%d].Name: %w", i, err)
+		}
+
+		symbols[i] = &Symbol{
+			CharacterID: characterID,
+			Name:        name,
+		}
+	}
+
 	result := &SymbolClass{
 		Tag:      tag,
 		Extended: extended,
+		Symbols:  symbols,
 		data:     data,
 	}
 
@@ -9,7 +9,13 @@ import (
 type VideoFrame struct {
 	Tag      *Uint16
 	Extended *Uint32
-	data     *bytes.Buffer
+	// StreamID is the CharacterID of the DefineVideoStream.
+	StreamID *Uint16
+	// FrameNum is the zero-based index of the frame in the video stream.
+	FrameNum *Uint16
+	// VideoData holds the packet encoded with the codec of the stream.
+	VideoData []byte
+	data      *bytes.Buffer
 }
 
 func (v *VideoFrame) TagCode() TagCode {
@@ -21,7 +27,7 @@ func (v *VideoFrame) String() string {
 		return "<nil>"
 	}
 
-	return fmt.Sprintf("VideoFrame{%d bytes}", len(v.data.Bytes()))
+	return fmt.Sprintf("VideoFrame{StreamID: %d, FrameNum: %d, VideoData: %d bytes}", v.StreamID.Value, v.FrameNum.Value, len(v.VideoData))
 }
 
 func (v *VideoFrame) Bytes() []byte {
@@ -49,25 +55,22 @@ func (v *VideoFrame) Serialize() ([]byte, error) {
 		return nil, fmt.Errorf("cannot serialize because VideoFrame is nil")
 	}
 
-	var data []byte
-
-	tagData, err := v.Tag.Serialize()
+	body, err := v.StreamID.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	extendedData, err := v.Extended.Serialize()
+	frameNumData, err := v.FrameNum.Serialize()
 
 	if err != nil {
 		return nil, err
 	}
 
-	data = append(data, tagData...)
-	data = append(data, extendedData...)
-	data = append(data, v.data.Bytes()...)
+	body = append(body, frameNumData...)
+	body = append(body, v.VideoData...)
 
-	return data, nil
+	return serializeTag(VideoFrameTagCode, v.Extended, body)
 }
 
 func ParseVideoFrame(src io.Reader, tag *Uint16, extended *Uint32) (*VideoFrame, error) {
@@ -92,10 +95,33 @@ func ParseVideoFrame(src io.Reader, tag *Uint16, extended *Uint32) (*VideoFrame,
 		return nil, fmt.Errorf("broken VideoFrame")
 	}
 
+	body := bytes.NewReader(data.Bytes())
+
+	streamID, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VideoFrame.StreamID: %w", err)
+	}
+
+	frameNum, err := ReadUint16(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VideoFrame.FrameNum: %w", err)
+	}
+
+	videoData, err := io.ReadAll(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VideoFrame.VideoData: %w", err)
+	}
+
 	result := &VideoFrame{
-		Tag:      tag,
-		Extended: extended,
-		data:     data,
+		Tag:       tag,
+		Extended:  extended,
+		StreamID:  streamID,
+		FrameNum:  frameNum,
+		VideoData: videoData,
+		data:      data,
 	}
 
 	return result, nil
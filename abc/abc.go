@@ -0,0 +1,334 @@
+// Package abc decodes ActionScript Byte Code, which is the content of the
+// DoAbc tag.
+package abc
+
+import "fmt"
+
+// File is a decoded abcFile. The fields which refer to the constant pool or
+// to the other tables hold the raw u30 indices.
+type File struct {
+	MinorVersion uint16
+	MajorVersion uint16
+	ConstantPool ConstantPool
+	Methods      []*Method
+	Metadata     []*Metadata
+	// Instances and Classes have the same length, and Classes[i] is the
+	// static part of Instances[i].
+	Instances    []*Instance
+	Classes      []*Class
+	Scripts      []*Script
+	MethodBodies []*MethodBody
+}
+
+// ConstantPool holds the constants. Index 0 of each pool is the implicit
+// default entry, which is not encoded. A pool is empty when its count is
+// encoded as 0, and it has the single default entry when the count is 1.
+type ConstantPool struct {
+	Integers      []int32
+	UIntegers     []uint32
+	Doubles       []float64
+	Strings       []string
+	Namespaces    []*Namespace
+	NamespaceSets [][]uint32
+	Multinames    []*Multiname
+}
+
+// String returns the string of the index, or "" for the index 0 and the
+// indices out of range.
+func (p *ConstantPool) String(index uint32) string {
+	if index == 0 || int(index) >= len(p.Strings) {
+		return ""
+	}
+
+	return p.Strings[index]
+}
+
+// NamespaceKind is the kind of a Namespace.
+type NamespaceKind uint8
+
+const (
+	KindPrivateNamespace         NamespaceKind = 0x05
+	KindNamespace                NamespaceKind = 0x08
+	KindPackageNamespace         NamespaceKind = 0x16
+	KindPackageInternalNamespace NamespaceKind = 0x17
+	KindProtectedNamespace       NamespaceKind = 0x18
+	KindExplicitNamespace        NamespaceKind = 0x19
+	KindStaticProtectedNamespace NamespaceKind = 0x1a
+)
+
+func (k NamespaceKind) String() string {
+	switch k {
+	case KindPrivateNamespace:
+		return "PrivateNamespace"
+	case KindNamespace:
+		return "Namespace"
+	case KindPackageNamespace:
+		return "PackageNamespace"
+	case KindPackageInternalNamespace:
+		return "PackageInternalNs"
+	case KindProtectedNamespace:
+		return "ProtectedNamespace"
+	case KindExplicitNamespace:
+		return "ExplicitNamespace"
+	case KindStaticProtectedNamespace:
+		return "StaticProtectedNs"
+	default:
+		return fmt.Sprintf("NamespaceKind(0x%02x)", uint8(k))
+	}
+}
+
+type Namespace struct {
+	Kind NamespaceKind
+	Name uint32
+}
+
+// MultinameKind is the kind of a Multiname.
+type MultinameKind uint8
+
+const (
+	KindQName       MultinameKind = 0x07
+	KindQNameA      MultinameKind = 0x0d
+	KindRTQName     MultinameKind = 0x0f
+	KindRTQNameA    MultinameKind = 0x10
+	KindRTQNameL    MultinameKind = 0x11
+	KindRTQNameLA   MultinameKind = 0x12
+	KindMultiname   MultinameKind = 0x09
+	KindMultinameA  MultinameKind = 0x0e
+	KindMultinameL  MultinameKind = 0x1b
+	KindMultinameLA MultinameKind = 0x1c
+	KindTypeName    MultinameKind = 0x1d
+)
+
+func (k MultinameKind) String() string {
+	switch k {
+	case KindQName:
+		return "QName"
+	case KindQNameA:
+		return "QNameA"
+	case KindRTQName:
+		return "RTQName"
+	case KindRTQNameA:
+		return "RTQNameA"
+	case KindRTQNameL:
+		return "RTQNameL"
+	case KindRTQNameLA:
+		return "RTQNameLA"
+	case KindMultiname:
+		return "Multiname"
+	case KindMultinameA:
+		return "MultinameA"
+	case KindMultinameL:
+		return "MultinameL"
+	case KindMultinameLA:
+		return "MultinameLA"
+	case KindTypeName:
+		return "TypeName"
+	default:
+		return fmt.Sprintf("MultinameKind(0x%02x)", uint8(k))
+	}
+}
+
+// Multiname is a name in the constant pool. Which fields are used depends
+// on Kind:
+//
+//   - QName, QNameA: Namespace and Name
+//   - RTQName, RTQNameA: Name
+//   - RTQNameL, RTQNameLA: none
+//   - Multiname, MultinameA: Name and NamespaceSet
+//   - MultinameL, MultinameLA: NamespaceSet
+//   - TypeName: Name, which is the index of the generic type's multiname,
+//     and Parameters
+type Multiname struct {
+	Kind         MultinameKind
+	Namespace    uint32
+	Name         uint32
+	NamespaceSet uint32
+	Parameters   []uint32
+}
+
+// MethodFlags is the flags of a Method.
+type MethodFlags uint8
+
+const (
+	MethodNeedArguments  MethodFlags = 0x01
+	MethodNeedActivation MethodFlags = 0x02
+	MethodNeedRest       MethodFlags = 0x04
+	MethodHasOptional    MethodFlags = 0x08
+	MethodIgnoreRest     MethodFlags = 0x10
+	MethodExplicit       MethodFlags = 0x20
+	MethodSetDxns        MethodFlags = 0x40
+	MethodHasParamNames  MethodFlags = 0x80
+)
+
+// ConstantKind is the kind of a constant value, which tells the pool that
+// the value index refers to.
+type ConstantKind uint8
+
+const (
+	ConstantUndefined                ConstantKind = 0x00
+	ConstantUtf8                     ConstantKind = 0x01
+	ConstantInt                      ConstantKind = 0x03
+	ConstantUInt                     ConstantKind = 0x04
+	ConstantPrivateNamespace         ConstantKind = 0x05
+	ConstantDouble                   ConstantKind = 0x06
+	ConstantNamespace                ConstantKind = 0x08
+	ConstantFalse                    ConstantKind = 0x0a
+	ConstantTrue                     ConstantKind = 0x0b
+	ConstantNull                     ConstantKind = 0x0c
+	ConstantPackageNamespace         ConstantKind = 0x16
+	ConstantPackageInternalNamespace ConstantKind = 0x17
+	ConstantProtectedNamespace       ConstantKind = 0x18
+	ConstantExplicitNamespace        ConstantKind = 0x19
+	ConstantStaticProtectedNamespace ConstantKind = 0x1a
+)
+
+// Option is the default value of an optional parameter.
+type Option struct {
+	Value uint32
+	Kind  ConstantKind
+}
+
+// Method is a method_info, which is the signature of a method.
+type Method struct {
+	ParamTypes []uint32
+	ReturnType uint32
+	Name       uint32
+	Flags      MethodFlags
+	// Options is encoded when Flags has MethodHasOptional.
+	Options []Option
+	// ParamNames is encoded when Flags has MethodHasParamNames.
+	ParamNames []uint32
+}
+
+// MetadataItem is a key-value pair of Metadata. Key 0 denotes an item
+// without key.
+type MetadataItem struct {
+	Key   uint32
+	Value uint32
+}
+
+// Metadata is a metadata_info, such as [Embed] and [Event].
+type Metadata struct {
+	Name  uint32
+	Items []MetadataItem
+}
+
+// InstanceFlags is the flags of an Instance.
+type InstanceFlags uint8
+
+const (
+	InstanceSealed      InstanceFlags = 0x01
+	InstanceFinal       InstanceFlags = 0x02
+	InstanceInterface   InstanceFlags = 0x04
+	InstanceProtectedNs InstanceFlags = 0x08
+)
+
+// Instance is an instance_info, which is the instance part of a class.
+type Instance struct {
+	Name      uint32
+	SuperName uint32
+	Flags     InstanceFlags
+	// ProtectedNs is encoded when Flags has InstanceProtectedNs.
+	ProtectedNs uint32
+	Interfaces  []uint32
+	Init        uint32
+	Traits      []*Trait
+}
+
+// Class is a class_info, which is the static part of a class.
+type Class struct {
+	Init   uint32
+	Traits []*Trait
+}
+
+// Script is a script_info.
+type Script struct {
+	Init   uint32
+	Traits []*Trait
+}
+
+// TraitKind is the kind of a Trait.
+type TraitKind uint8
+
+const (
+	TraitSlot     TraitKind = 0
+	TraitMethod   TraitKind = 1
+	TraitGetter   TraitKind = 2
+	TraitSetter   TraitKind = 3
+	TraitClass    TraitKind = 4
+	TraitFunction TraitKind = 5
+	TraitConst    TraitKind = 6
+)
+
+func (k TraitKind) String() string {
+	switch k {
+	case TraitSlot:
+		return "Slot"
+	case TraitMethod:
+		return "Method"
+	case TraitGetter:
+		return "Getter"
+	case TraitSetter:
+		return "Setter"
+	case TraitClass:
+		return "Class"
+	case TraitFunction:
+		return "Function"
+	case TraitConst:
+		return "Const"
+	default:
+		return fmt.Sprintf("TraitKind(%d)", uint8(k))
+	}
+}
+
+// TraitAttributes is the attributes of a Trait.
+type TraitAttributes uint8
+
+const (
+	TraitFinal    TraitAttributes = 0x1
+	TraitOverride TraitAttributes = 0x2
+	TraitMetadata TraitAttributes = 0x4
+)
+
+// Trait is a traits_info. Which fields are used depends on Kind:
+//
+//   - Slot, Const: ID is the slot_id, Type and Value and ValueKind
+//   - Method, Getter, Setter: ID is the disp_id, Index is the method
+//   - Class: ID is the slot_id, Index is the class
+//   - Function: ID is the slot_id, Index is the method
+//
+// ValueKind is encoded only when Value is not 0.
+type Trait struct {
+	Name       uint32
+	Kind       TraitKind
+	Attributes TraitAttributes
+	ID         uint32
+	Type       uint32
+	Value      uint32
+	ValueKind  ConstantKind
+	Index      uint32
+	// Metadata is encoded when Attributes has TraitMetadata.
+	Metadata []uint32
+}
+
+// Exception is an exception_info of a MethodBody. From, To and Target are
+// byte offsets in the code.
+type Exception struct {
+	From    uint32
+	To      uint32
+	Target  uint32
+	Type    uint32
+	VarName uint32
+}
+
+// MethodBody is a method_body_info.
+type MethodBody struct {
+	Method         uint32
+	MaxStack       uint32
+	LocalCount     uint32
+	InitScopeDepth uint32
+	MaxScopeDepth  uint32
+	Code           []byte
+	Exceptions     []*Exception
+	Traits         []*Trait
+}
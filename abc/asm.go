@@ -0,0 +1,1231 @@
+package abc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The assembly has one instruction per line, in the style of RABCDasm. The
+// operands are separated by commas, and the constants are written as their
+// values:
+//
+//	getlex QName(PackageNamespace("flash.display"), "Sprite")
+//	callproperty Multiname("trace", [PackageNamespace("")]), 1
+//	pushdouble 1.5
+//	iftrue L1
+//	lookupswitch L1, [L2, L3]
+//
+// A label is written as "L1:" on its own line. A constant which cannot be
+// written as its value, such as one of the duplicated entries of a pool, is
+// written as its index like "#3", and so is a branch offset which does not
+// land at an instruction. The exceptions follow the code:
+//
+//	try from L0 to L1 target L2 type QName(PackageNamespace(""), "Error") name null
+//
+// Comments start with ";".
+
+// errNotFound is returned when a constant is not in the pool and the
+// assembler must not add it.
+var errNotFound = errors.New("constant is not in the pool")
+
+// maxNameDepth limits the nesting of the multinames to format.
+const maxNameDepth = 8
+
+type tokenKind uint8
+
+const (
+	tokenWord tokenKind = iota + 1
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(line); {
+		c := line[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i += 1
+		case c == ';':
+			return tokens, nil
+		case c == '"':
+			quoted, err := strconv.QuotedPrefix(line[i:])
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid string: %s", line[i:])
+			}
+
+			value, err := strconv.Unquote(quoted)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid string: %s", quoted)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: value})
+			i += len(quoted)
+		case strings.IndexByte("()[]<>,", c) >= 0:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c)})
+			i += 1
+		default:
+			end := i
+
+			for end < len(line) && strings.IndexByte(" \t\r;\"()[]<>,", line[end]) < 0 {
+				end += 1
+			}
+
+			tokens = append(tokens, token{kind: tokenWord, text: line[i:end]})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens   []token
+	position int
+}
+
+func (p *parser) atEnd() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *parser) peek(text string) bool {
+	return !p.atEnd() && p.tokens[p.position].kind != tokenString && p.tokens[p.position].text == text
+}
+
+func (p *parser) next() (token, error) {
+	if p.atEnd() {
+		return token{}, fmt.Errorf("unexpected end of line")
+	}
+
+	t := p.tokens[p.position]
+	p.position += 1
+
+	return t, nil
+}
+
+func (p *parser) word() (string, error) {
+	t, err := p.next()
+
+	if err != nil {
+		return "", err
+	}
+	if t.kind != tokenWord {
+		return "", fmt.Errorf("expected word but got %q", t.text)
+	}
+
+	return t.text, nil
+}
+
+func (p *parser) expect(text string) error {
+	if !p.peek(text) {
+		if p.atEnd() {
+			return fmt.Errorf("expected %q but got end of line", text)
+		}
+
+		return fmt.Errorf("expected %q but got %q", text, p.tokens[p.position].text)
+	}
+
+	p.position += 1
+
+	return nil
+}
+
+// raw parses "#N", which is an index or an offset written as it is.
+func (p *parser) raw() (int64, bool, error) {
+	if p.atEnd() || p.tokens[p.position].kind != tokenWord || !strings.HasPrefix(p.tokens[p.position].text, "#") {
+		return 0, false, nil
+	}
+
+	text := p.tokens[p.position].text[1:]
+	p.position += 1
+
+	value, err := strconv.ParseInt(text, 0, 64)
+
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid number: %s", text)
+	}
+
+	return value, true, nil
+}
+
+func (p *parser) index() (uint32, bool, error) {
+	value, ok, err := p.raw()
+
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if value < 0 || value >= 1<<30 {
+		return 0, false, fmt.Errorf("index is out of range: %d", value)
+	}
+
+	return uint32(value), true, nil
+}
+
+// assembler resolves the constants against the pool of f. When add is
+// true, the constants which are not in the pool are added.
+type assembler struct {
+	f   *File
+	add bool
+}
+
+func (a *assembler) parseString(p *parser) (uint32, error) {
+	if index, ok, err := p.index(); ok || err != nil {
+		return index, err
+	}
+	if p.peek("null") {
+		p.position += 1
+
+		return 0, nil
+	}
+
+	t, err := p.next()
+
+	if err != nil {
+		return 0, err
+	}
+	if t.kind != tokenString {
+		return 0, fmt.Errorf("expected string but got %q", t.text)
+	}
+
+	pool := &a.f.ConstantPool
+
+	for i := 1; i < len(pool.Strings); i++ {
+		if pool.Strings[i] == t.text {
+			return uint32(i), nil
+		}
+	}
+	if !a.add {
+		return 0, errNotFound
+	}
+	if len(pool.Strings) == 0 {
+		pool.Strings = append(pool.Strings, "")
+	}
+
+	pool.Strings = append(pool.Strings, t.text)
+
+	return uint32(len(pool.Strings) - 1), nil
+}
+
+func (a *assembler) parseNumber(p *parser, kind OperandKind) (uint32, error) {
+	if index, ok, err := p.index(); ok || err != nil {
+		return index, err
+	}
+
+	text, err := p.word()
+
+	if err != nil {
+		return 0, err
+	}
+
+	pool := &a.f.ConstantPool
+
+	switch kind {
+	case OperandInt:
+		value, err := strconv.ParseInt(text, 10, 32)
+
+		if err != nil {
+			return 0, fmt.Errorf("invalid int: %s", text)
+		}
+		for i := 1; i < len(pool.Integers); i++ {
+			if pool.Integers[i] == int32(value) {
+				return uint32(i), nil
+			}
+		}
+		if !a.add {
+			return 0, errNotFound
+		}
+		if len(pool.Integers) == 0 {
+			pool.Integers = append(pool.Integers, 0)
+		}
+
+		pool.Integers = append(pool.Integers, int32(value))
+
+		return uint32(len(pool.Integers) - 1), nil
+	case OperandUInt:
+		value, err := strconv.ParseUint(text, 10, 32)
+
+		if err != nil {
+			return 0, fmt.Errorf("invalid uint: %s", text)
+		}
+		for i := 1; i < len(pool.UIntegers); i++ {
+			if pool.UIntegers[i] == uint32(value) {
+				return uint32(i), nil
+			}
+		}
+		if !a.add {
+			return 0, errNotFound
+		}
+		if len(pool.UIntegers) == 0 {
+			pool.UIntegers = append(pool.UIntegers, 0)
+		}
+
+		pool.UIntegers = append(pool.UIntegers, uint32(value))
+
+		return uint32(len(pool.UIntegers) - 1), nil
+	default:
+		value, err := parseDouble(text)
+
+		if err != nil {
+			return 0, err
+		}
+		for i := 1; i < len(pool.Doubles); i++ {
+			if math.Float64bits(pool.Doubles[i]) == math.Float64bits(value) {
+				return uint32(i), nil
+			}
+		}
+		if !a.add {
+			return 0, errNotFound
+		}
+		if len(pool.Doubles) == 0 {
+			pool.Doubles = append(pool.Doubles, math.NaN())
+		}
+
+		pool.Doubles = append(pool.Doubles, value)
+
+		return uint32(len(pool.Doubles) - 1), nil
+	}
+}
+
+func parseDouble(text string) (float64, error) {
+	switch text {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid double: %s", text)
+	}
+
+	return value, nil
+}
+
+func formatDouble(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var namespaceKinds = []NamespaceKind{
+	KindPrivateNamespace,
+	KindNamespace,
+	KindPackageNamespace,
+	KindPackageInternalNamespace,
+	KindProtectedNamespace,
+	KindExplicitNamespace,
+	KindStaticProtectedNamespace,
+}
+
+func (a *assembler) parseNamespace(p *parser) (uint32, error) {
+	if index, ok, err := p.index(); ok || err != nil {
+		return index, err
+	}
+	if p.peek("null") {
+		p.position += 1
+
+		return 0, nil
+	}
+
+	name, err := p.word()
+
+	if err != nil {
+		return 0, err
+	}
+
+	ns := &Namespace{}
+
+	for _, kind := range namespaceKinds {
+		if kind.String() == name {
+			ns.Kind = kind
+		}
+	}
+	if ns.Kind == 0 {
+		return 0, fmt.Errorf("unknown namespace kind: %s", name)
+	}
+	if err := p.expect("("); err != nil {
+		return 0, err
+	}
+	if ns.Name, err = a.parseString(p); err != nil {
+		return 0, err
+	}
+	if err := p.expect(")"); err != nil {
+		return 0, err
+	}
+
+	pool := &a.f.ConstantPool
+
+	for i := 1; i < len(pool.Namespaces); i++ {
+		if pool.Namespaces[i] != nil && *pool.Namespaces[i] == *ns {
+			return uint32(i), nil
+		}
+	}
+	if !a.add {
+		return 0, errNotFound
+	}
+	if len(pool.Namespaces) == 0 {
+		pool.Namespaces = append(pool.Namespaces, &Namespace{})
+	}
+
+	pool.Namespaces = append(pool.Namespaces, ns)
+
+	return uint32(len(pool.Namespaces) - 1), nil
+}
+
+func equalIndices(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *assembler) parseNamespaceSet(p *parser) (uint32, error) {
+	if index, ok, err := p.index(); ok || err != nil {
+		return index, err
+	}
+	if p.peek("null") {
+		p.position += 1
+
+		return 0, nil
+	}
+	if err := p.expect("["); err != nil {
+		return 0, err
+	}
+
+	set := []uint32{}
+
+	for !p.peek("]") {
+		if len(set) > 0 {
+			if err := p.expect(","); err != nil {
+				return 0, err
+			}
+		}
+
+		ns, err := a.parseNamespace(p)
+
+		if err != nil {
+			return 0, err
+		}
+
+		set = append(set, ns)
+	}
+
+	p.position += 1
+
+	pool := &a.f.ConstantPool
+
+	for i := 1; i < len(pool.NamespaceSets); i++ {
+		if equalIndices(pool.NamespaceSets[i], set) {
+			return uint32(i), nil
+		}
+	}
+	if !a.add {
+		return 0, errNotFound
+	}
+	if len(pool.NamespaceSets) == 0 {
+		pool.NamespaceSets = append(pool.NamespaceSets, nil)
+	}
+
+	pool.NamespaceSets = append(pool.NamespaceSets, set)
+
+	return uint32(len(pool.NamespaceSets) - 1), nil
+}
+
+var multinameKinds = []MultinameKind{
+	KindQName,
+	KindQNameA,
+	KindRTQName,
+	KindRTQNameA,
+	KindRTQNameL,
+	KindRTQNameLA,
+	KindMultiname,
+	KindMultinameA,
+	KindMultinameL,
+	KindMultinameLA,
+	KindTypeName,
+}
+
+func (a *assembler) parseMultiname(p *parser) (uint32, error) {
+	if index, ok, err := p.index(); ok || err != nil {
+		return index, err
+	}
+	if p.peek("null") {
+		p.position += 1
+
+		return 0, nil
+	}
+
+	name, err := p.word()
+
+	if err != nil {
+		return 0, err
+	}
+
+	m := &Multiname{}
+
+	for _, kind := range multinameKinds {
+		if kind.String() == name {
+			m.Kind = kind
+		}
+	}
+	if m.Kind == 0 {
+		return 0, fmt.Errorf("unknown multiname kind: %s", name)
+	}
+	if err := p.expect("("); err != nil {
+		return 0, err
+	}
+
+	switch m.Kind {
+	case KindQName, KindQNameA:
+		if m.Namespace, err = a.parseNamespace(p); err != nil {
+			return 0, err
+		}
+		if err := p.expect(","); err != nil {
+			return 0, err
+		}
+		if m.Name, err = a.parseString(p); err != nil {
+			return 0, err
+		}
+	case KindRTQName, KindRTQNameA:
+		if m.Name, err = a.parseString(p); err != nil {
+			return 0, err
+		}
+	case KindRTQNameL, KindRTQNameLA:
+	case KindMultiname, KindMultinameA:
+		if m.Name, err = a.parseString(p); err != nil {
+			return 0, err
+		}
+		if err := p.expect(","); err != nil {
+			return 0, err
+		}
+		if m.NamespaceSet, err = a.parseNamespaceSet(p); err != nil {
+			return 0, err
+		}
+	case KindMultinameL, KindMultinameLA:
+		if m.NamespaceSet, err = a.parseNamespaceSet(p); err != nil {
+			return 0, err
+		}
+	case KindTypeName:
+		if m.Name, err = a.parseMultiname(p); err != nil {
+			return 0, err
+		}
+		if err := p.expect("<"); err != nil {
+			return 0, err
+		}
+
+		m.Parameters = []uint32{}
+
+		for !p.peek(">") {
+			if len(m.Parameters) > 0 {
+				if err := p.expect(","); err != nil {
+					return 0, err
+				}
+			}
+
+			parameter, err := a.parseMultiname(p)
+
+			if err != nil {
+				return 0, err
+			}
+
+			m.Parameters = append(m.Parameters, parameter)
+		}
+
+		p.position += 1
+	}
+	if err := p.expect(")"); err != nil {
+		return 0, err
+	}
+
+	pool := &a.f.ConstantPool
+
+	for i := 1; i < len(pool.Multinames); i++ {
+		if pool.Multinames[i] != nil && equalMultinames(pool.Multinames[i], m) {
+			return uint32(i), nil
+		}
+	}
+	if !a.add {
+		return 0, errNotFound
+	}
+	if len(pool.Multinames) == 0 {
+		pool.Multinames = append(pool.Multinames, &Multiname{})
+	}
+
+	pool.Multinames = append(pool.Multinames, m)
+
+	return uint32(len(pool.Multinames) - 1), nil
+}
+
+func equalMultinames(a, b *Multiname) bool {
+	return a.Kind == b.Kind &&
+		a.Namespace == b.Namespace &&
+		a.Name == b.Name &&
+		a.NamespaceSet == b.NamespaceSet &&
+		equalIndices(a.Parameters, b.Parameters)
+}
+
+// formatter formats the constants of f. Each constant is formatted as its
+// value only when the value is resolved to the same index again.
+type formatter struct {
+	f     *File
+	cache map[string]string
+}
+
+func newFormatter(f *File) *formatter {
+	return &formatter{f: f, cache: map[string]string{}}
+}
+
+func (m *formatter) verify(index uint32, text string, parse func(a *assembler, p *parser) (uint32, error)) string {
+	key := fmt.Sprintf("%d %s", index, text)
+
+	if result, ok := m.cache[key]; ok {
+		return result
+	}
+
+	result := fmt.Sprintf("#%d", index)
+	tokens, err := tokenize(text)
+
+	if err == nil {
+		p := &parser{tokens: tokens}
+
+		if resolved, err := parse(&assembler{f: m.f}, p); err == nil && resolved == index && p.atEnd() {
+			result = text
+		}
+	}
+
+	m.cache[key] = result
+
+	return result
+}
+
+func (m *formatter) str(index uint32) string {
+	if index == 0 {
+		return "null"
+	}
+	if int(index) >= len(m.f.ConstantPool.Strings) {
+		return fmt.Sprintf("#%d", index)
+	}
+
+	return m.verify(index, strconv.Quote(m.f.ConstantPool.Strings[index]), (*assembler).parseString)
+}
+
+func (m *formatter) number(kind OperandKind, index uint32) string {
+	pool := &m.f.ConstantPool
+
+	switch {
+	case kind == OperandInt && index > 0 && int(index) < len(pool.Integers):
+		return m.verify(index, strconv.FormatInt(int64(pool.Integers[index]), 10), parseInt)
+	case kind == OperandUInt && index > 0 && int(index) < len(pool.UIntegers):
+		return m.verify(index, strconv.FormatUint(uint64(pool.UIntegers[index]), 10), parseUInt)
+	case kind == OperandDouble && index > 0 && int(index) < len(pool.Doubles):
+		return m.verify(index, formatDouble(pool.Doubles[index]), parseDoubleIndex)
+	default:
+		return fmt.Sprintf("#%d", index)
+	}
+}
+
+func parseInt(a *assembler, p *parser) (uint32, error) {
+	return a.parseNumber(p, OperandInt)
+}
+
+func parseUInt(a *assembler, p *parser) (uint32, error) {
+	return a.parseNumber(p, OperandUInt)
+}
+
+func parseDoubleIndex(a *assembler, p *parser) (uint32, error) {
+	return a.parseNumber(p, OperandDouble)
+}
+
+func (m *formatter) namespace(index uint32) string {
+	if index == 0 {
+		return "null"
+	}
+	if int(index) >= len(m.f.ConstantPool.Namespaces) {
+		return fmt.Sprintf("#%d", index)
+	}
+
+	ns := m.f.ConstantPool.Namespaces[index]
+
+	if ns == nil {
+		return fmt.Sprintf("#%d", index)
+	}
+
+	text := fmt.Sprintf("%s(%s)", ns.Kind, m.str(ns.Name))
+
+	return m.verify(index, text, (*assembler).parseNamespace)
+}
+
+func (m *formatter) namespaceSet(index uint32) string {
+	if index == 0 {
+		return "null"
+	}
+	if int(index) >= len(m.f.ConstantPool.NamespaceSets) {
+		return fmt.Sprintf("#%d", index)
+	}
+
+	var namespaces []string
+
+	for _, ns := range m.f.ConstantPool.NamespaceSets[index] {
+		namespaces = append(namespaces, m.namespace(ns))
+	}
+
+	text := "[" + strings.Join(namespaces, ", ") + "]"
+
+	return m.verify(index, text, (*assembler).parseNamespaceSet)
+}
+
+func (m *formatter) multiname(index uint32, depth int) string {
+	if index == 0 {
+		return "null"
+	}
+	if int(index) >= len(m.f.ConstantPool.Multinames) || depth > maxNameDepth {
+		return fmt.Sprintf("#%d", index)
+	}
+
+	v := m.f.ConstantPool.Multinames[index]
+
+	if v == nil {
+		return fmt.Sprintf("#%d", index)
+	}
+
+	var text string
+
+	switch v.Kind {
+	case KindQName, KindQNameA:
+		text = fmt.Sprintf("%s(%s, %s)", v.Kind, m.namespace(v.Namespace), m.str(v.Name))
+	case KindRTQName, KindRTQNameA:
+		text = fmt.Sprintf("%s(%s)", v.Kind, m.str(v.Name))
+	case KindRTQNameL, KindRTQNameLA:
+		text = fmt.Sprintf("%s()", v.Kind)
+	case KindMultiname, KindMultinameA:
+		text = fmt.Sprintf("%s(%s, %s)", v.Kind, m.str(v.Name), m.namespaceSet(v.NamespaceSet))
+	case KindMultinameL, KindMultinameLA:
+		text = fmt.Sprintf("%s(%s)", v.Kind, m.namespaceSet(v.NamespaceSet))
+	case KindTypeName:
+		var parameters []string
+
+		for _, parameter := range v.Parameters {
+			parameters = append(parameters, m.multiname(parameter, depth+1))
+		}
+
+		text = fmt.Sprintf("%s(%s<%s>)", v.Kind, m.multiname(v.Name, depth+1), strings.Join(parameters, ", "))
+	default:
+		return fmt.Sprintf("#%d", index)
+	}
+
+	return m.verify(index, text, (*assembler).parseMultiname)
+}
+
+// formatInstruction formats the instruction. The labels map the indices of
+// the instructions to their labels.
+func (m *formatter) formatInstruction(instruction *Instruction, labels map[int]string) string {
+	kinds, _ := instruction.Opcode.Operands()
+	operands := instruction.Operands
+
+	var texts []string
+
+	branch := func(b Branch) string {
+		if label, ok := labels[b.Target]; ok && b.Target >= 0 {
+			return label
+		}
+
+		return fmt.Sprintf("#%d", b.Offset)
+	}
+
+	for _, kind := range kinds {
+		switch kind {
+		case OperandBranch:
+			texts = append(texts, branch(instruction.Branches[0]))
+
+			continue
+		case OperandSwitch:
+			var cases []string
+
+			for _, b := range instruction.Branches[1:] {
+				cases = append(cases, branch(b))
+			}
+
+			texts = append(texts, branch(instruction.Branches[0]), "["+strings.Join(cases, ", ")+"]")
+
+			continue
+		}
+
+		value := operands[0]
+		operands = operands[1:]
+
+		switch kind {
+		case OperandByte:
+			texts = append(texts, strconv.Itoa(int(int8(value))))
+		case OperandShort:
+			if value > 0xffff {
+				texts = append(texts, fmt.Sprintf("#%d", value))
+			} else {
+				texts = append(texts, strconv.Itoa(int(int16(value))))
+			}
+		case OperandInt, OperandUInt, OperandDouble:
+			texts = append(texts, m.number(kind, value))
+		case OperandString:
+			texts = append(texts, m.str(value))
+		case OperandNamespace:
+			texts = append(texts, m.namespace(value))
+		case OperandMultiname:
+			texts = append(texts, m.multiname(value, 0))
+		default:
+			texts = append(texts, strconv.FormatUint(uint64(value), 10))
+		}
+	}
+
+	if len(texts) == 0 {
+		return instruction.Opcode.String()
+	}
+
+	return instruction.Opcode.String() + " " + strings.Join(texts, ", ")
+}
+
+// Disassemble formats the code and the exceptions of the method body as
+// assembly.
+func (f *File) Disassemble(body *MethodBody) (string, error) {
+	if f == nil {
+		return "", fmt.Errorf("cannot disassemble because File is nil")
+	}
+	if body == nil {
+		return "", fmt.Errorf("cannot disassemble because MethodBody is nil")
+	}
+
+	instructions, err := DecodeCode(body.Code)
+
+	if err != nil {
+		return "", err
+	}
+
+	offsets, err := CodeOffsets(instructions)
+
+	if err != nil {
+		return "", err
+	}
+
+	index := make(map[uint32]int, len(offsets))
+
+	for i, offset := range offsets {
+		index[uint32(offset)] = i
+	}
+
+	targets := map[int]bool{}
+
+	for _, instruction := range instructions {
+		for _, b := range instruction.Branches {
+			if b.Target >= 0 {
+				targets[b.Target] = true
+			}
+		}
+	}
+	for _, e := range body.Exceptions {
+		for _, offset := range []uint32{e.From, e.To, e.Target} {
+			if i, ok := index[offset]; ok {
+				targets[i] = true
+			}
+		}
+	}
+
+	var sorted []int
+
+	for i := range targets {
+		sorted = append(sorted, i)
+	}
+
+	sort.Ints(sorted)
+
+	labels := map[int]string{}
+
+	for n, i := range sorted {
+		labels[i] = fmt.Sprintf("L%d", n)
+	}
+
+	m := newFormatter(f)
+	builder := &strings.Builder{}
+
+	fmt.Fprintf(builder, "; method %d, maxstack %d, localcount %d, initscopedepth %d, maxscopedepth %d\n", body.Method, body.MaxStack, body.LocalCount, body.InitScopeDepth, body.MaxScopeDepth)
+
+	for i := 0; i <= len(instructions); i++ {
+		if label, ok := labels[i]; ok {
+			fmt.Fprintf(builder, "%s:\n", label)
+		}
+		if i < len(instructions) {
+			fmt.Fprintf(builder, "  %s\n", m.formatInstruction(instructions[i], labels))
+		}
+	}
+	for _, e := range body.Exceptions {
+		position := func(offset uint32) string {
+			if i, ok := index[offset]; ok {
+				return labels[i]
+			}
+
+			return fmt.Sprintf("#%d", offset)
+		}
+
+		fmt.Fprintf(builder, "try from %s to %s target %s type %s name %s\n", position(e.From), position(e.To), position(e.Target), m.multiname(e.Type, 0), m.multiname(e.VarName, 0))
+	}
+
+	return builder.String(), nil
+}
+
+type pendingBranch struct {
+	instruction int
+	branch      int
+	label       string
+	line        int
+}
+
+type pendingException struct {
+	positions [3]string
+	offsets   [3]uint32
+	exception *Exception
+	line      int
+}
+
+// Assemble replaces the code and the exceptions of the method body with the
+// assembled text, and recomputes the branch offsets, MaxStack, LocalCount
+// and MaxScopeDepth. The constants which are not in the pool are added.
+func (f *File) Assemble(body *MethodBody, text string) error {
+	if f == nil {
+		return fmt.Errorf("cannot assemble because File is nil")
+	}
+	if body == nil {
+		return fmt.Errorf("cannot assemble because MethodBody is nil")
+	}
+
+	a := &assembler{f: f, add: true}
+	labels := map[string]int{}
+
+	var instructions []*Instruction
+	var branches []pendingBranch
+	var exceptions []pendingException
+
+	for n, line := range strings.Split(text, "\n") {
+		tokens, err := tokenize(line)
+
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n+1, err)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) == 1 && tokens[0].kind == tokenWord && strings.HasSuffix(tokens[0].text, ":") {
+			label := strings.TrimSuffix(tokens[0].text, ":")
+
+			if _, ok := labels[label]; ok {
+				return fmt.Errorf("line %d: duplicate label %s", n+1, label)
+			}
+
+			labels[label] = len(instructions)
+
+			continue
+		}
+
+		p := &parser{tokens: tokens}
+
+		if p.peek("try") {
+			p.position += 1
+
+			e, err := a.parseException(p)
+
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n+1, err)
+			}
+
+			e.line = n + 1
+			exceptions = append(exceptions, e)
+
+			continue
+		}
+
+		instruction, labelNames, err := a.parseInstruction(p)
+
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n+1, err)
+		}
+		for i, label := range labelNames {
+			if label != "" {
+				branches = append(branches, pendingBranch{instruction: len(instructions), branch: i, label: label, line: n + 1})
+			}
+		}
+
+		instructions = append(instructions, instruction)
+	}
+	for _, b := range branches {
+		target, ok := labels[b.label]
+
+		if !ok {
+			return fmt.Errorf("line %d: undefined label %s", b.line, b.label)
+		}
+
+		instructions[b.instruction].Branches[b.branch].Target = target
+	}
+	if err := RelocateCode(instructions); err != nil {
+		return err
+	}
+
+	code, err := EncodeCode(instructions)
+
+	if err != nil {
+		return err
+	}
+
+	offsets, err := CodeOffsets(instructions)
+
+	if err != nil {
+		return err
+	}
+
+	result := make([]*Exception, len(exceptions))
+
+	for i, e := range exceptions {
+		for j, label := range e.positions {
+			if label == "" {
+				continue
+			}
+
+			target, ok := labels[label]
+
+			if !ok {
+				return fmt.Errorf("line %d: undefined label %s", e.line, label)
+			}
+
+			e.offsets[j] = uint32(offsets[target])
+		}
+
+		e.exception.From, e.exception.To, e.exception.Target = e.offsets[0], e.offsets[1], e.offsets[2]
+		result[i] = e.exception
+	}
+
+	body.Code = code
+	body.Exceptions = result
+
+	return f.ComputeLimits(body)
+}
+
+func (a *assembler) parseException(p *parser) (pendingException, error) {
+	e := pendingException{exception: &Exception{}}
+
+	for i, keyword := range []string{"from", "to", "target"} {
+		if err := p.expect(keyword); err != nil {
+			return e, err
+		}
+
+		offset, ok, err := p.index()
+
+		if err != nil {
+			return e, err
+		}
+		if ok {
+			e.offsets[i] = offset
+
+			continue
+		}
+		if e.positions[i], err = p.word(); err != nil {
+			return e, err
+		}
+	}
+
+	var err error
+
+	if err := p.expect("type"); err != nil {
+		return e, err
+	}
+	if e.exception.Type, err = a.parseMultiname(p); err != nil {
+		return e, err
+	}
+	if err := p.expect("name"); err != nil {
+		return e, err
+	}
+	if e.exception.VarName, err = a.parseMultiname(p); err != nil {
+		return e, err
+	}
+	if !p.atEnd() {
+		return e, fmt.Errorf("unexpected %q", p.tokens[p.position].text)
+	}
+
+	return e, nil
+}
+
+// parseInstruction parses an instruction. It returns the labels of the
+// branches, which are empty for the raw offsets.
+func (a *assembler) parseInstruction(p *parser) (*Instruction, []string, error) {
+	name, err := p.word()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opcode, ok := LookupOpcode(name)
+
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown instruction: %s", name)
+	}
+
+	kinds, _ := opcode.Operands()
+	instruction := &Instruction{Opcode: opcode}
+
+	var labels []string
+
+	branch := func() error {
+		offset, ok, err := p.raw()
+
+		if err != nil {
+			return err
+		}
+		if ok {
+			if offset < -1<<23 || offset >= 1<<23 {
+				return fmt.Errorf("offset is out of range: %d", offset)
+			}
+
+			instruction.Branches = append(instruction.Branches, Branch{Offset: int32(offset), Target: -1})
+			labels = append(labels, "")
+
+			return nil
+		}
+
+		label, err := p.word()
+
+		if err != nil {
+			return err
+		}
+
+		instruction.Branches = append(instruction.Branches, Branch{Target: -1})
+		labels = append(labels, label)
+
+		return nil
+	}
+
+	for i, kind := range kinds {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var value uint32
+
+		switch kind {
+		case OperandBranch:
+			err = branch()
+		case OperandSwitch:
+			if err := branch(); err != nil {
+				return nil, nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, nil, err
+			}
+			if err := p.expect("["); err != nil {
+				return nil, nil, err
+			}
+			for !p.peek("]") {
+				if len(instruction.Branches) > 1 {
+					if err := p.expect(","); err != nil {
+						return nil, nil, err
+					}
+				}
+				if err := branch(); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			p.position += 1
+		case OperandInt, OperandUInt, OperandDouble:
+			value, err = a.parseNumber(p, kind)
+		case OperandString:
+			value, err = a.parseString(p)
+		case OperandNamespace:
+			value, err = a.parseNamespace(p)
+		case OperandMultiname:
+			value, err = a.parseMultiname(p)
+		default:
+			value, err = parseInteger(p, kind)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if kind != OperandBranch && kind != OperandSwitch {
+			instruction.Operands = append(instruction.Operands, value)
+		}
+	}
+	if !p.atEnd() {
+		return nil, nil, fmt.Errorf("unexpected %q", p.tokens[p.position].text)
+	}
+
+	return instruction, labels, nil
+}
+
+// parseInteger parses the operands written as integers.
+func parseInteger(p *parser, kind OperandKind) (uint32, error) {
+	if value, ok, err := p.index(); ok || err != nil {
+		return value, err
+	}
+
+	text, err := p.word()
+
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer: %s", text)
+	}
+
+	switch kind {
+	case OperandByte:
+		if value < math.MinInt8 || value > math.MaxInt8 {
+			return 0, fmt.Errorf("byte is out of range: %d", value)
+		}
+
+		return uint32(uint8(int8(value))), nil
+	case OperandShort:
+		if value < math.MinInt16 || value > math.MaxInt16 {
+			return 0, fmt.Errorf("short is out of range: %d", value)
+		}
+
+		return uint32(uint16(int16(value))), nil
+	case OperandU8:
+		if value < 0 || value > math.MaxUint8 {
+			return 0, fmt.Errorf("byte is out of range: %d", value)
+		}
+	default:
+		if value < 0 || value >= 1<<30 {
+			return 0, fmt.Errorf("u30 is out of range: %d", value)
+		}
+	}
+
+	return uint32(value), nil
+}
@@ -0,0 +1,93 @@
+package abc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisassemble(t *testing.T) {
+	f, err := Decode(newTestABC())
+
+	require.NoError(t, err)
+
+	text, err := f.Disassemble(f.MethodBodies[0])
+
+	require.NoError(t, err)
+	require.Equal(t, `; method 0, maxstack 1, localcount 2, initscopedepth 0, maxscopedepth 1
+L0:
+  getlocal0
+  pushscope
+  returnvoid
+L1:
+try from L0 to L1 target L1 type null name null
+`, text)
+}
+
+func TestAssemble(t *testing.T) {
+	f, err := Decode(newTestABC())
+
+	require.NoError(t, err)
+
+	body := f.MethodBodies[0]
+
+	require.NoError(t, f.Assemble(body, `
+  getlocal0
+  pushscope
+  findpropstrict Multiname("trace", [PackageNamespace(null)])
+  pushbyte -1
+  pushshort -300
+  pushstring "hi"
+  pushint 5 ; reuses the constant
+  pushdouble 1.5
+  callpropvoid Multiname("trace", [PackageNamespace(null)]), 5
+L0:
+  getlocal 3
+  lookupswitch L1, [L0, L1]
+L1:
+  getlocal1
+  iffalse L0
+  returnvoid
+L2:
+  throw
+try from L0 to L1 target L2 type QName(PackageNamespace("pkg"), "Foo") name null
+`))
+	require.Equal(t, uint32(6), body.MaxStack)
+	require.Equal(t, uint32(4), body.LocalCount)
+	require.Equal(t, uint32(1), body.MaxScopeDepth)
+	require.Equal(t, []int32{0, 5}, f.ConstantPool.Integers)
+	require.Equal(t, []*Exception{{From: 19, To: 32, Target: 38, Type: 1}}, body.Exceptions)
+
+	instructions, err := DecodeCode(body.Code)
+
+	require.NoError(t, err)
+	require.Equal(t, OpLookupSwitch, instructions[10].Opcode)
+	require.Equal(t, []Branch{{Offset: 11, Target: 11}, {Offset: -2, Target: 9}, {Offset: 11, Target: 11}}, instructions[10].Branches)
+
+	text, err := f.Disassemble(body)
+
+	require.NoError(t, err)
+
+	code := body.Code
+	strings := len(f.ConstantPool.Strings)
+
+	require.NoError(t, f.Assemble(body, text))
+	require.Equal(t, code, body.Code)
+	require.Len(t, f.ConstantPool.Strings, strings)
+}
+
+func TestAssembleErrors(t *testing.T) {
+	f, err := Decode(newTestABC())
+
+	require.NoError(t, err)
+
+	for _, text := range []string{
+		"jump L9",
+		"pushbyte 200",
+		"callproperty QName(PackageNamespace(\"pkg\"), \"Foo\")",
+		"unknown",
+		"pop",
+	} {
+		require.Error(t, f.Assemble(f.MethodBodies[0], text), text)
+	}
+}
@@ -0,0 +1,306 @@
+package abc
+
+import "fmt"
+
+// Branch is a branch offset and the index of the instruction it lands at.
+// Target is -1 when the offset lands in the middle of an instruction or
+// outside of the code, and the number of instructions when it lands at the
+// end of the code.
+type Branch struct {
+	Offset int32
+	Target int
+}
+
+// Instruction is a decoded AVM2 instruction.
+type Instruction struct {
+	Opcode Opcode
+	// Operands holds the operands other than OperandBranch and
+	// OperandSwitch, in the order of Opcode.Operands.
+	Operands []uint32
+	// Branches holds the branch of the branch instructions, or the default
+	// branch followed by the cases of lookupswitch.
+	Branches []Branch
+}
+
+// DecodeCode decodes the code of a method body. The branch targets are
+// resolved.
+func DecodeCode(code []byte) ([]*Instruction, error) {
+	r := &reader{data: code}
+
+	var instructions []*Instruction
+	var offsets []int
+
+	for r.remaining() > 0 {
+		offset := r.position
+		opcode := Opcode(code[offset])
+		r.position += 1
+
+		kinds, ok := opcode.Operands()
+
+		if !ok {
+			return nil, fmt.Errorf("failed to decode code at offset %d: unknown opcode 0x%02x", offset, uint8(opcode))
+		}
+
+		instruction := &Instruction{Opcode: opcode}
+
+		for _, kind := range kinds {
+			if err := r.readOperand(instruction, kind); err != nil {
+				return nil, fmt.Errorf("failed to decode %s at offset %d: %w", opcode, offset, err)
+			}
+		}
+
+		instructions = append(instructions, instruction)
+		offsets = append(offsets, offset)
+	}
+
+	offsets = append(offsets, len(code))
+	resolveBranches(instructions, offsets)
+
+	return instructions, nil
+}
+
+func (r *reader) readS24() (int32, error) {
+	if r.remaining() < 3 {
+		return 0, fmt.Errorf("s24 is truncated")
+	}
+
+	data := r.data[r.position:]
+	value := int32(uint32(data[0])|uint32(data[1])<<8|uint32(data[2])<<16) << 8 >> 8
+	r.position += 3
+
+	return value, nil
+}
+
+func (r *reader) readOperand(instruction *Instruction, kind OperandKind) error {
+	switch kind {
+	case OperandU8, OperandByte:
+		value, err := r.readU8()
+
+		if err != nil {
+			return err
+		}
+
+		instruction.Operands = append(instruction.Operands, uint32(value))
+	case OperandBranch:
+		offset, err := r.readS24()
+
+		if err != nil {
+			return err
+		}
+
+		instruction.Branches = append(instruction.Branches, Branch{Offset: offset, Target: -1})
+	case OperandSwitch:
+		offset, err := r.readS24()
+
+		if err != nil {
+			return err
+		}
+
+		count, err := r.readU30()
+
+		if err != nil {
+			return err
+		}
+		if int(count) >= r.remaining()/3 {
+			return fmt.Errorf("case count is too large: %d", count)
+		}
+
+		instruction.Branches = append(instruction.Branches, Branch{Offset: offset, Target: -1})
+
+		for i := 0; i <= int(count); i++ {
+			offset, err := r.readS24()
+
+			if err != nil {
+				return err
+			}
+
+			instruction.Branches = append(instruction.Branches, Branch{Offset: offset, Target: -1})
+		}
+	default:
+		value, err := r.readU30()
+
+		if err != nil {
+			return err
+		}
+
+		instruction.Operands = append(instruction.Operands, value)
+	}
+
+	return nil
+}
+
+// resolveBranches sets the branch targets from the offsets of the
+// instructions, which has the length of the code as the last element.
+func resolveBranches(instructions []*Instruction, offsets []int) {
+	index := make(map[int]int, len(offsets))
+
+	for i, offset := range offsets {
+		index[offset] = i
+	}
+
+	for i, instruction := range instructions {
+		base := offsets[i+1]
+
+		if instruction.Opcode == OpLookupSwitch {
+			base = offsets[i]
+		}
+		for j := range instruction.Branches {
+			branch := &instruction.Branches[j]
+
+			if target, ok := index[base+int(branch.Offset)]; ok {
+				branch.Target = target
+			} else {
+				branch.Target = -1
+			}
+		}
+	}
+}
+
+// EncodeCode encodes the instructions. The branch offsets are written as
+// they are, so call RelocateCode first after editing the instructions.
+func EncodeCode(instructions []*Instruction) ([]byte, error) {
+	w := &writer{}
+
+	for i, instruction := range instructions {
+		if err := w.instruction(instruction); err != nil {
+			return nil, fmt.Errorf("failed to encode instructions[%d]: %w", i, err)
+		}
+	}
+
+	return w.data, nil
+}
+
+func (w *writer) s24(value int32) {
+	w.data = append(w.data, uint8(value), uint8(value>>8), uint8(value>>16))
+}
+
+func (w *writer) instruction(instruction *Instruction) error {
+	if instruction == nil {
+		return fmt.Errorf("instruction is nil")
+	}
+
+	kinds, ok := instruction.Opcode.Operands()
+
+	if !ok {
+		return fmt.Errorf("unknown opcode 0x%02x", uint8(instruction.Opcode))
+	}
+
+	operands := instruction.Operands
+	branches := instruction.Branches
+
+	for _, branch := range branches {
+		if branch.Offset < -1<<23 || branch.Offset >= 1<<23 {
+			return fmt.Errorf("%s has too far branch: %d", instruction.Opcode, branch.Offset)
+		}
+	}
+
+	w.u8(uint8(instruction.Opcode))
+
+	for _, kind := range kinds {
+		switch kind {
+		case OperandBranch:
+			if len(branches) != 1 {
+				return fmt.Errorf("%s must have a branch", instruction.Opcode)
+			}
+
+			w.s24(branches[0].Offset)
+		case OperandSwitch:
+			if len(branches) < 2 {
+				return fmt.Errorf("%s must have a default and a case at least", instruction.Opcode)
+			}
+
+			w.s24(branches[0].Offset)
+			w.count(len(branches) - 2)
+
+			for _, branch := range branches[1:] {
+				w.s24(branch.Offset)
+			}
+		default:
+			if len(operands) == 0 {
+				return fmt.Errorf("%s has too few operands", instruction.Opcode)
+			}
+
+			value := operands[0]
+			operands = operands[1:]
+
+			switch kind {
+			case OperandU8, OperandByte:
+				if value > 0xff {
+					return fmt.Errorf("%s has too large operand: %d", instruction.Opcode, value)
+				}
+
+				w.u8(uint8(value))
+			default:
+				w.u30(value)
+			}
+		}
+	}
+	if len(operands) > 0 {
+		return fmt.Errorf("%s has too many operands", instruction.Opcode)
+	}
+	if len(branches) > 0 && !hasBranches(kinds) {
+		return fmt.Errorf("%s has branches", instruction.Opcode)
+	}
+
+	return w.err
+}
+
+func hasBranches(kinds []OperandKind) bool {
+	for _, kind := range kinds {
+		if kind == OperandBranch || kind == OperandSwitch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CodeOffsets returns the byte offset of each instruction as encoded. The
+// extra last element is the length of the whole code.
+func CodeOffsets(instructions []*Instruction) ([]int, error) {
+	offsets := make([]int, len(instructions)+1)
+
+	for i, instruction := range instructions {
+		w := &writer{}
+
+		if err := w.instruction(instruction); err != nil {
+			return nil, fmt.Errorf("failed to encode instructions[%d]: %w", i, err)
+		}
+
+		offsets[i+1] = offsets[i] + len(w.data)
+	}
+
+	return offsets, nil
+}
+
+// RelocateCode recomputes the branch offsets from the branch targets. The
+// branches whose target is -1 keep their offsets.
+func RelocateCode(instructions []*Instruction) error {
+	offsets, err := CodeOffsets(instructions)
+
+	if err != nil {
+		return err
+	}
+
+	for i, instruction := range instructions {
+		base := offsets[i+1]
+
+		if instruction.Opcode == OpLookupSwitch {
+			base = offsets[i]
+		}
+		for j := range instruction.Branches {
+			branch := &instruction.Branches[j]
+
+			if branch.Target < 0 {
+				continue
+			}
+			if branch.Target > len(instructions) {
+				return fmt.Errorf("failed to relocate instructions[%d]: target %d is out of range", i, branch.Target)
+			}
+
+			branch.Offset = int32(offsets[branch.Target] - base)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,760 @@
+package abc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// reader reads the primitive types of ABC.
+type reader struct {
+	data     []byte
+	position int
+}
+
+func (r *reader) remaining() int {
+	return len(r.data) - r.position
+}
+
+func (r *reader) readU8() (uint8, error) {
+	if r.remaining() < 1 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	value := r.data[r.position]
+	r.position += 1
+
+	return value, nil
+}
+
+func (r *reader) readU16() (uint16, error) {
+	if r.remaining() < 2 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	value := binary.LittleEndian.Uint16(r.data[r.position:])
+	r.position += 2
+
+	return value, nil
+}
+
+// readU32 reads a variable-length unsigned integer of up to 5 bytes.
+func (r *reader) readU32() (uint32, error) {
+	var value uint32
+
+	for i := 0; i < 5; i++ {
+		b, err := r.readU8()
+
+		if err != nil {
+			return 0, err
+		}
+
+		value |= uint32(b&0x7f) << (7 * i)
+
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	return value, nil
+}
+
+// readU30 reads a variable-length integer which must fit in 30 bits.
+func (r *reader) readU30() (uint32, error) {
+	value, err := r.readU32()
+
+	if err != nil {
+		return 0, err
+	}
+	if value >= 1<<30 {
+		return 0, fmt.Errorf("u30 is out of range: %d", value)
+	}
+
+	return value, nil
+}
+
+func (r *reader) readS32() (int32, error) {
+	value, err := r.readU32()
+
+	return int32(value), err
+}
+
+func (r *reader) readD64() (float64, error) {
+	if r.remaining() < 8 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	value := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.position:]))
+	r.position += 8
+
+	return value, nil
+}
+
+func (r *reader) readBytes(length int) ([]byte, error) {
+	if r.remaining() < length {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	value := append([]byte{}, r.data[r.position:r.position+length]...)
+	r.position += length
+
+	return value, nil
+}
+
+func (r *reader) readString() (string, error) {
+	length, err := r.readU30()
+
+	if err != nil {
+		return "", err
+	}
+
+	value, err := r.readBytes(int(length))
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// readCount reads the count of a list. The count is checked against the
+// remaining bytes, since each entry takes at least one byte.
+func (r *reader) readCount() (int, error) {
+	count, err := r.readU30()
+
+	if err != nil {
+		return 0, err
+	}
+	if int(count) > r.remaining() {
+		return 0, fmt.Errorf("count is too large: %d", count)
+	}
+
+	return int(count), nil
+}
+
+func (r *reader) readU30s(count int) ([]uint32, error) {
+	values := make([]uint32, count)
+
+	for i := range values {
+		value, err := r.readU30()
+
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// readPoolCount reads the count of a constant pool, which is the length of
+// the pool including the implicit entry 0.
+func (r *reader) readPoolCount() (int, error) {
+	count, err := r.readU30()
+
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 && int(count)-1 > r.remaining() {
+		return 0, fmt.Errorf("count is too large: %d", count)
+	}
+
+	return int(count), nil
+}
+
+// Decode decodes an abcFile.
+func Decode(data []byte) (*File, error) {
+	r := &reader{data: data}
+	f := &File{}
+
+	minorVersion, err := r.readU16()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.MinorVersion: %w", err)
+	}
+
+	majorVersion, err := r.readU16()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.MajorVersion: %w", err)
+	}
+
+	f.MinorVersion = minorVersion
+	f.MajorVersion = majorVersion
+
+	if err := r.readConstantPool(&f.ConstantPool); err != nil {
+		return nil, fmt.Errorf("failed to read File.ConstantPool: %w", err)
+	}
+
+	methodCount, err := r.readCount()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.MethodCount: %w", err)
+	}
+
+	f.Methods = make([]*Method, methodCount)
+
+	for i := range f.Methods {
+		if f.Methods[i], err = r.readMethod(); err != nil {
+			return nil, fmt.Errorf("failed to read File.Methods[%d]: %w", i, err)
+		}
+	}
+
+	metadataCount, err := r.readCount()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.MetadataCount: %w", err)
+	}
+
+	f.Metadata = make([]*Metadata, metadataCount)
+
+	for i := range f.Metadata {
+		if f.Metadata[i], err = r.readMetadata(); err != nil {
+			return nil, fmt.Errorf("failed to read File.Metadata[%d]: %w", i, err)
+		}
+	}
+
+	classCount, err := r.readCount()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.ClassCount: %w", err)
+	}
+
+	f.Instances = make([]*Instance, classCount)
+	f.Classes = make([]*Class, classCount)
+
+	for i := range f.Instances {
+		if f.Instances[i], err = r.readInstance(); err != nil {
+			return nil, fmt.Errorf("failed to read File.Instances[%d]: %w", i, err)
+		}
+	}
+	for i := range f.Classes {
+		if f.Classes[i], err = r.readClass(); err != nil {
+			return nil, fmt.Errorf("failed to read File.Classes[%d]: %w", i, err)
+		}
+	}
+
+	scriptCount, err := r.readCount()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.ScriptCount: %w", err)
+	}
+
+	f.Scripts = make([]*Script, scriptCount)
+
+	for i := range f.Scripts {
+		if f.Scripts[i], err = r.readScript(); err != nil {
+			return nil, fmt.Errorf("failed to read File.Scripts[%d]: %w", i, err)
+		}
+	}
+
+	bodyCount, err := r.readCount()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read File.MethodBodyCount: %w", err)
+	}
+
+	f.MethodBodies = make([]*MethodBody, bodyCount)
+
+	for i := range f.MethodBodies {
+		if f.MethodBodies[i], err = r.readMethodBody(); err != nil {
+			return nil, fmt.Errorf("failed to read File.MethodBodies[%d]: %w", i, err)
+		}
+	}
+	if r.remaining() > 0 {
+		return nil, fmt.Errorf("failed to decode File: %d bytes remain", r.remaining())
+	}
+
+	return f, nil
+}
+
+func (r *reader) readConstantPool(p *ConstantPool) error {
+	count, err := r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read Integers count: %w", err)
+	}
+
+	p.Integers = make([]int32, count)
+
+	for i := 1; i < count; i++ {
+		if p.Integers[i], err = r.readS32(); err != nil {
+			return fmt.Errorf("failed to read Integers[%d]: %w", i, err)
+		}
+	}
+
+	count, err = r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read UIntegers count: %w", err)
+	}
+
+	p.UIntegers = make([]uint32, count)
+
+	for i := 1; i < count; i++ {
+		if p.UIntegers[i], err = r.readU32(); err != nil {
+			return fmt.Errorf("failed to read UIntegers[%d]: %w", i, err)
+		}
+	}
+
+	count, err = r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read Doubles count: %w", err)
+	}
+
+	p.Doubles = make([]float64, count)
+
+	if count > 0 {
+		p.Doubles[0] = math.NaN()
+	}
+	for i := 1; i < count; i++ {
+		if p.Doubles[i], err = r.readD64(); err != nil {
+			return fmt.Errorf("failed to read Doubles[%d]: %w", i, err)
+		}
+	}
+
+	count, err = r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read Strings count: %w", err)
+	}
+
+	p.Strings = make([]string, count)
+
+	for i := 1; i < count; i++ {
+		if p.Strings[i], err = r.readString(); err != nil {
+			return fmt.Errorf("failed to read Strings[%d]: %w", i, err)
+		}
+	}
+
+	count, err = r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read Namespaces count: %w", err)
+	}
+
+	p.Namespaces = make([]*Namespace, count)
+
+	if count > 0 {
+		p.Namespaces[0] = &Namespace{}
+	}
+	for i := 1; i < count; i++ {
+		kind, err := r.readU8()
+
+		if err != nil {
+			return fmt.Errorf("failed to read Namespaces[%d].Kind: %w", i, err)
+		}
+
+		name, err := r.readU30()
+
+		if err != nil {
+			return fmt.Errorf("failed to read Namespaces[%d].Name: %w", i, err)
+		}
+
+		p.Namespaces[i] = &Namespace{Kind: NamespaceKind(kind), Name: name}
+	}
+
+	count, err = r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read NamespaceSets count: %w", err)
+	}
+
+	p.NamespaceSets = make([][]uint32, count)
+
+	for i := 1; i < count; i++ {
+		setCount, err := r.readCount()
+
+		if err != nil {
+			return fmt.Errorf("failed to read NamespaceSets[%d] count: %w", i, err)
+		}
+		if p.NamespaceSets[i], err = r.readU30s(setCount); err != nil {
+			return fmt.Errorf("failed to read NamespaceSets[%d]: %w", i, err)
+		}
+	}
+
+	count, err = r.readPoolCount()
+
+	if err != nil {
+		return fmt.Errorf("failed to read Multinames count: %w", err)
+	}
+
+	p.Multinames = make([]*Multiname, count)
+
+	if count > 0 {
+		p.Multinames[0] = &Multiname{}
+	}
+	for i := 1; i < count; i++ {
+		if p.Multinames[i], err = r.readMultiname(); err != nil {
+			return fmt.Errorf("failed to read Multinames[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *reader) readMultiname() (*Multiname, error) {
+	kind, err := r.readU8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Multiname{Kind: MultinameKind(kind)}
+
+	switch m.Kind {
+	case KindQName, KindQNameA:
+		if m.Namespace, err = r.readU30(); err != nil {
+			return nil, err
+		}
+		if m.Name, err = r.readU30(); err != nil {
+			return nil, err
+		}
+	case KindRTQName, KindRTQNameA:
+		if m.Name, err = r.readU30(); err != nil {
+			return nil, err
+		}
+	case KindRTQNameL, KindRTQNameLA:
+	case KindMultiname, KindMultinameA:
+		if m.Name, err = r.readU30(); err != nil {
+			return nil, err
+		}
+		if m.NamespaceSet, err = r.readU30(); err != nil {
+			return nil, err
+		}
+	case KindMultinameL, KindMultinameLA:
+		if m.NamespaceSet, err = r.readU30(); err != nil {
+			return nil, err
+		}
+	case KindTypeName:
+		if m.Name, err = r.readU30(); err != nil {
+			return nil, err
+		}
+
+		count, err := r.readCount()
+
+		if err != nil {
+			return nil, err
+		}
+		if m.Parameters, err = r.readU30s(count); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown kind: %s", m.Kind)
+	}
+
+	return m, nil
+}
+
+func (r *reader) readMethod() (*Method, error) {
+	paramCount, err := r.readCount()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Method{}
+
+	if m.ReturnType, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if m.ParamTypes, err = r.readU30s(paramCount); err != nil {
+		return nil, err
+	}
+	if m.Name, err = r.readU30(); err != nil {
+		return nil, err
+	}
+
+	flags, err := r.readU8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.Flags = MethodFlags(flags)
+
+	if m.Flags&MethodHasOptional != 0 {
+		optionCount, err := r.readCount()
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.Options = make([]Option, optionCount)
+
+		for i := range m.Options {
+			value, err := r.readU30()
+
+			if err != nil {
+				return nil, err
+			}
+
+			kind, err := r.readU8()
+
+			if err != nil {
+				return nil, err
+			}
+
+			m.Options[i] = Option{Value: value, Kind: ConstantKind(kind)}
+		}
+	}
+	if m.Flags&MethodHasParamNames != 0 {
+		if m.ParamNames, err = r.readU30s(paramCount); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (r *reader) readMetadata() (*Metadata, error) {
+	name, err := r.readU30()
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := r.readCount()
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := r.readU30s(count)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := r.readU30s(count)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Metadata{Name: name, Items: make([]MetadataItem, count)}
+
+	for i := range m.Items {
+		m.Items[i] = MetadataItem{Key: keys[i], Value: values[i]}
+	}
+
+	return m, nil
+}
+
+func (r *reader) readInstance() (*Instance, error) {
+	var err error
+
+	v := &Instance{}
+
+	if v.Name, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if v.SuperName, err = r.readU30(); err != nil {
+		return nil, err
+	}
+
+	flags, err := r.readU8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	v.Flags = InstanceFlags(flags)
+
+	if v.Flags&InstanceProtectedNs != 0 {
+		if v.ProtectedNs, err = r.readU30(); err != nil {
+			return nil, err
+		}
+	}
+
+	count, err := r.readCount()
+
+	if err != nil {
+		return nil, err
+	}
+	if v.Interfaces, err = r.readU30s(count); err != nil {
+		return nil, err
+	}
+	if v.Init, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if v.Traits, err = r.readTraits(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *reader) readClass() (*Class, error) {
+	init, err := r.readU30()
+
+	if err != nil {
+		return nil, err
+	}
+
+	traits, err := r.readTraits()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Class{Init: init, Traits: traits}, nil
+}
+
+func (r *reader) readScript() (*Script, error) {
+	init, err := r.readU30()
+
+	if err != nil {
+		return nil, err
+	}
+
+	traits, err := r.readTraits()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Script{Init: init, Traits: traits}, nil
+}
+
+func (r *reader) readTraits() ([]*Trait, error) {
+	count, err := r.readCount()
+
+	if err != nil {
+		return nil, err
+	}
+
+	traits := make([]*Trait, count)
+
+	for i := range traits {
+		if traits[i], err = r.readTrait(); err != nil {
+			return nil, fmt.Errorf("failed to read Traits[%d]: %w", i, err)
+		}
+	}
+
+	return traits, nil
+}
+
+func (r *reader) readTrait() (*Trait, error) {
+	name, err := r.readU30()
+
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := r.readU8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Trait{
+		Name:       name,
+		Kind:       TraitKind(kind & 0x0f),
+		Attributes: TraitAttributes(kind >> 4),
+	}
+
+	if t.ID, err = r.readU30(); err != nil {
+		return nil, err
+	}
+
+	switch t.Kind {
+	case TraitSlot, TraitConst:
+		if t.Type, err = r.readU30(); err != nil {
+			return nil, err
+		}
+		if t.Value, err = r.readU30(); err != nil {
+			return nil, err
+		}
+		if t.Value != 0 {
+			valueKind, err := r.readU8()
+
+			if err != nil {
+				return nil, err
+			}
+
+			t.ValueKind = ConstantKind(valueKind)
+		}
+	case TraitMethod, TraitGetter, TraitSetter, TraitClass, TraitFunction:
+		if t.Index, err = r.readU30(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown kind: %s", t.Kind)
+	}
+	if t.Attributes&TraitMetadata != 0 {
+		count, err := r.readCount()
+
+		if err != nil {
+			return nil, err
+		}
+		if t.Metadata, err = r.readU30s(count); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (r *reader) readMethodBody() (*MethodBody, error) {
+	var err error
+
+	b := &MethodBody{}
+
+	if b.Method, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if b.MaxStack, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if b.LocalCount, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if b.InitScopeDepth, err = r.readU30(); err != nil {
+		return nil, err
+	}
+	if b.MaxScopeDepth, err = r.readU30(); err != nil {
+		return nil, err
+	}
+
+	codeLength, err := r.readU30()
+
+	if err != nil {
+		return nil, err
+	}
+	if b.Code, err = r.readBytes(int(codeLength)); err != nil {
+		return nil, err
+	}
+
+	count, err := r.readCount()
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.Exceptions = make([]*Exception, count)
+
+	for i := range b.Exceptions {
+		values, err := r.readU30s(5)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Exceptions[%d]: %w", i, err)
+		}
+
+		b.Exceptions[i] = &Exception{
+			From:    values[0],
+			To:      values[1],
+			Target:  values[2],
+			Type:    values[3],
+			VarName: values[4],
+		}
+	}
+	if b.Traits, err = r.readTraits(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
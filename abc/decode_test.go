@@ -0,0 +1,115 @@
+package abc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestABC returns an abcFile defining the class pkg.Foo, whose field x has
+// the [Event(name="change")] metadata.
+func newTestABC() []byte {
+	var data []byte
+
+	// Version
+	data = append(data, 0x10, 0x00, 0x2e, 0x00)
+	// Integers, UIntegers and Doubles
+	data = append(data, 0x02, 0x05, 0x00, 0x00)
+	// Strings
+	data = append(data, 0x09)
+
+	for _, s := range []string{"pkg", "Foo", "Object", "x", "int", "Event", "name", "change"} {
+		data = append(data, uint8(len(s)))
+		data = append(data, s...)
+	}
+
+	// Namespaces and NamespaceSets
+	data = append(data, 0x03, 0x16, 0x01, 0x16, 0x00, 0x00)
+	// Multinames
+	data = append(data, 0x06,
+		0x07, 0x01, 0x02,
+		0x07, 0x02, 0x03,
+		0x07, 0x02, 0x04,
+		0x07, 0x02, 0x05,
+		0x1d, 0x02, 0x01, 0x04,
+	)
+	// Methods
+	data = append(data, 0x02,
+		0x01, 0x00, 0x04, 0x00, 0x88, 0x01, 0x01, 0x03, 0x04,
+		0x00, 0x00, 0x00, 0x00,
+	)
+	// Metadata
+	data = append(data, 0x01, 0x06, 0x01, 0x07, 0x08)
+	// Instances and Classes
+	data = append(data, 0x01,
+		0x01, 0x02, 0x09, 0x01, 0x00, 0x00, 0x01, 0x03, 0x40, 0x01, 0x04, 0x01, 0x03, 0x01, 0x00,
+		0x01, 0x00,
+	)
+	// Scripts
+	data = append(data, 0x01, 0x01, 0x01, 0x01, 0x04, 0x01, 0x00)
+	// MethodBodies
+	data = append(data, 0x01,
+		0x00, 0x01, 0x02, 0x00, 0x01, 0x03, 0xd0, 0x30, 0x47,
+		0x01, 0x00, 0x03, 0x03, 0x00, 0x00,
+		0x00,
+	)
+
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	f, err := Decode(newTestABC())
+
+	require.NoError(t, err)
+	require.Equal(t, uint16(46), f.MajorVersion)
+
+	pool := f.ConstantPool
+
+	require.Equal(t, []int32{0, 5}, pool.Integers)
+	require.Empty(t, pool.UIntegers)
+	require.Len(t, pool.Strings, 9)
+	require.Equal(t, "change", pool.String(8))
+	require.Equal(t, &Namespace{Kind: KindPackageNamespace, Name: 1}, pool.Namespaces[1])
+	require.Equal(t, &Multiname{Kind: KindTypeName, Name: 2, Parameters: []uint32{4}}, pool.Multinames[5])
+
+	require.Len(t, f.Methods, 2)
+	require.Equal(t, &Method{
+		ParamTypes: []uint32{4},
+		Flags:      MethodHasOptional | MethodHasParamNames,
+		Options:    []Option{{Value: 1, Kind: ConstantInt}},
+		ParamNames: []uint32{4},
+	}, f.Methods[0])
+	require.Equal(t, []*Metadata{{Name: 6, Items: []MetadataItem{{Key: 7, Value: 8}}}}, f.Metadata)
+
+	require.Len(t, f.Instances, 1)
+	require.Equal(t, InstanceSealed|InstanceProtectedNs, f.Instances[0].Flags)
+	require.Equal(t, uint32(1), f.Instances[0].ProtectedNs)
+	require.Equal(t, []*Trait{{
+		Name:       3,
+		Kind:       TraitSlot,
+		Attributes: TraitMetadata,
+		ID:         1,
+		Type:       4,
+		Value:      1,
+		ValueKind:  ConstantInt,
+		Metadata:   []uint32{0},
+	}}, f.Instances[0].Traits)
+	require.Equal(t, uint32(1), f.Classes[0].Init)
+	require.Equal(t, TraitClass, f.Scripts[0].Traits[0].Kind)
+
+	require.Len(t, f.MethodBodies, 1)
+	require.Equal(t, []byte{0xd0, 0x30, 0x47}, f.MethodBodies[0].Code)
+	require.Equal(t, []*Exception{{From: 0, To: 3, Target: 3}}, f.MethodBodies[0].Exceptions)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	data := newTestABC()
+
+	_, err := Decode(data[:len(data)-1])
+
+	require.Error(t, err)
+
+	_, err = Decode(append(data, 0x00))
+
+	require.Error(t, err)
+}
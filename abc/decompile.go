@@ -0,0 +1,352 @@
+package abc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SourceFile is an ActionScript 3 source file decompiled from ABC.
+type SourceFile struct {
+	// Path is the path of the file, such as "flash/display/Sprite.as".
+	Path string
+	// Source is the ActionScript 3 source code.
+	Source string
+}
+
+// Decompile decompiles the classes and the package-level definitions of the
+// scripts into ActionScript 3 source files. The method bodies which cannot
+// be lifted into structured statements are left as the comments of their
+// disassembly.
+func (f *File) Decompile() ([]*SourceFile, error) {
+	if f == nil {
+		return nil, fmt.Errorf("cannot decompile because File is nil")
+	}
+
+	var files []*SourceFile
+
+	for i, script := range f.Scripts {
+		if script == nil {
+			continue
+		}
+		for j, trait := range script.Traits {
+			if trait == nil {
+				continue
+			}
+
+			file, err := f.decompileTrait(trait)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to decompile Scripts[%d].Traits[%d]: %w", i, j, err)
+			}
+
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// body returns the method body of the method, or nil if the method is
+// native or abstract.
+func (f *File) body(method uint32) *MethodBody {
+	for _, body := range f.MethodBodies {
+		if body != nil && body.Method == method {
+			return body
+		}
+	}
+
+	return nil
+}
+
+func (f *File) decompileTrait(trait *Trait) (*SourceFile, error) {
+	pkg, _ := f.packageOf(trait.Name)
+	n := &names{f: f, pkg: pkg, imports: map[string]bool{}}
+	w := &sourceWriter{depth: 1}
+
+	if trait.Kind == TraitClass {
+		for _, metadata := range trait.Metadata {
+			w.line(n.metadata(metadata))
+		}
+		if err := f.writeClass(w, n, trait); err != nil {
+			return nil, err
+		}
+	} else {
+		f.writeTrait(w, n, trait, false, false)
+	}
+
+	var imports []string
+
+	for name := range n.imports {
+		imports = append(imports, name)
+	}
+
+	sort.Strings(imports)
+
+	source := &sourceWriter{}
+
+	if pkg == "" {
+		source.line("package {")
+	} else {
+		source.line("package " + pkg + " {")
+	}
+
+	source.depth = 1
+
+	for _, name := range imports {
+		source.line("import " + name + ";")
+	}
+	if len(imports) > 0 {
+		source.line("")
+	}
+
+	source.builder.WriteString(w.builder.String())
+	source.depth = 0
+	source.line("}")
+
+	path := f.Name(trait.Name) + ".as"
+
+	if pkg != "" {
+		path = strings.ReplaceAll(pkg, ".", "/") + "/" + path
+	}
+
+	return &SourceFile{Path: path, Source: source.builder.String()}, nil
+}
+
+func (f *File) writeClass(w *sourceWriter, n *names, trait *Trait) error {
+	if int(trait.Index) >= len(f.Instances) || int(trait.Index) >= len(f.Classes) || f.Instances[trait.Index] == nil || f.Classes[trait.Index] == nil {
+		return fmt.Errorf("class %d is out of range", trait.Index)
+	}
+
+	instance := f.Instances[trait.Index]
+	class := f.Classes[trait.Index]
+	name := f.Name(instance.Name)
+	isInterface := instance.Flags&InstanceInterface != 0
+
+	var interfaces []string
+
+	for _, i := range instance.Interfaces {
+		interfaces = append(interfaces, n.name(i))
+	}
+
+	header := f.visibility(instance.Name)
+
+	if isInterface {
+		header += " interface " + name
+
+		if len(interfaces) > 0 {
+			header += " extends " + strings.Join(interfaces, ", ")
+		}
+	} else {
+		if instance.Flags&InstanceFinal != 0 {
+			header += " final"
+		}
+		if instance.Flags&InstanceSealed == 0 {
+			header += " dynamic"
+		}
+
+		header += " class " + name
+
+		if instance.SuperName != 0 && f.QualifiedName(instance.SuperName) != "Object" {
+			header += " extends " + n.name(instance.SuperName)
+		}
+		if len(interfaces) > 0 {
+			header += " implements " + strings.Join(interfaces, ", ")
+		}
+	}
+
+	// The fields are written together, and the other members are
+	// separated by blank lines.
+	fields := &sourceWriter{depth: w.depth + 1}
+
+	var members []*sourceWriter
+
+	member := func(trait *Trait, static bool) {
+		if trait == nil {
+			return
+		}
+		if trait.Kind == TraitSlot || trait.Kind == TraitConst {
+			f.writeTrait(fields, n, trait, static, isInterface)
+
+			return
+		}
+
+		m := &sourceWriter{depth: w.depth + 1}
+		f.writeTrait(m, n, trait, static, isInterface)
+		members = append(members, m)
+	}
+
+	for _, trait := range class.Traits {
+		member(trait, true)
+	}
+	for _, trait := range instance.Traits {
+		member(trait, false)
+	}
+
+	var sections []string
+
+	if fields.builder.Len() > 0 {
+		sections = append(sections, fields.builder.String())
+	}
+	if body := f.body(class.Init); body != nil && !isInterface {
+		if stmts := f.liftBody(n, body, 0); len(stmts) > 0 {
+			initializer := &sourceWriter{depth: w.depth + 1}
+
+			for _, s := range stmts {
+				s.write(initializer)
+			}
+
+			sections = append(sections, initializer.builder.String())
+		}
+	}
+	if !isInterface && int(instance.Init) < len(f.Methods) && f.Methods[instance.Init] != nil {
+		constructor := &sourceWriter{depth: w.depth + 1}
+		signature := f.signature(n, f.Methods[instance.Init], true)
+		f.writeMethod(constructor, n, "public function "+name+signature, instance.Init)
+		sections = append(sections, constructor.builder.String())
+	}
+	for _, m := range members {
+		sections = append(sections, m.builder.String())
+	}
+
+	w.line(header + " {")
+	w.builder.WriteString(strings.Join(sections, "\n"))
+	w.line("}")
+
+	return nil
+}
+
+// writeTrait writes the field or the method of the trait.
+func (f *File) writeTrait(w *sourceWriter, n *names, trait *Trait, static, isInterface bool) {
+	for _, metadata := range trait.Metadata {
+		w.line(n.metadata(metadata))
+	}
+
+	var modifiers []string
+
+	if !isInterface {
+		if trait.Attributes&TraitOverride != 0 {
+			modifiers = append(modifiers, "override")
+		}
+
+		modifiers = append(modifiers, f.visibility(trait.Name))
+
+		if trait.Attributes&TraitFinal != 0 && trait.Kind != TraitSlot && trait.Kind != TraitConst {
+			modifiers = append(modifiers, "final")
+		}
+		if static {
+			modifiers = append(modifiers, "static")
+		}
+	}
+
+	prefix := strings.Join(append(modifiers, ""), " ")
+	name := f.Name(trait.Name)
+
+	switch trait.Kind {
+	case TraitSlot, TraitConst:
+		keyword := "var"
+
+		if trait.Kind == TraitConst {
+			keyword = "const"
+		}
+
+		line := prefix + keyword + " " + name + ":" + n.typeName(trait.Type)
+
+		if trait.Value != 0 {
+			line += " = " + n.constant(trait.ValueKind, trait.Value)
+		}
+
+		w.line(line + ";")
+	case TraitMethod, TraitGetter, TraitSetter, TraitFunction:
+		if int(trait.Index) >= len(f.Methods) || f.Methods[trait.Index] == nil {
+			w.line(fmt.Sprintf("// method %d of %s is out of range", trait.Index, name))
+
+			return
+		}
+
+		accessor := ""
+
+		switch trait.Kind {
+		case TraitGetter:
+			accessor = "get "
+		case TraitSetter:
+			accessor = "set "
+		}
+
+		header := prefix + "function " + accessor + name + f.signature(n, f.Methods[trait.Index], false)
+
+		if isInterface {
+			w.line(header + ";")
+		} else {
+			f.writeMethod(w, n, header, trait.Index)
+		}
+	default:
+		w.line(fmt.Sprintf("// %s %s is not supported", trait.Kind, name))
+	}
+}
+
+func (f *File) writeMethod(w *sourceWriter, n *names, header string, method uint32) {
+	body := f.body(method)
+
+	if body == nil {
+		w.line(header + ";")
+
+		return
+	}
+
+	w.line(header + " {")
+	w.block(f.liftBody(n, body, 0))
+	w.line("}")
+}
+
+// signature formats the parameters and the return type of the method.
+func (f *File) signature(n *names, m *Method, constructor bool) string {
+	var parameters []string
+
+	for i, name := range f.paramNames(m) {
+		parameter := name + ":" + n.typeName(m.ParamTypes[i])
+
+		if k := i - (len(m.ParamTypes) - len(m.Options)); k >= 0 {
+			parameter += " = " + n.constant(m.Options[k].Kind, m.Options[k].Value)
+		}
+
+		parameters = append(parameters, parameter)
+	}
+	if m.Flags&MethodNeedRest != 0 {
+		parameters = append(parameters, "...rest")
+	}
+
+	signature := "(" + strings.Join(parameters, ", ") + ")"
+
+	if !constructor {
+		signature += ":" + n.typeName(m.ReturnType)
+	}
+
+	return signature
+}
+
+// liftBody lifts the method body into statements, or the comments of its
+// disassembly if it fails.
+func (f *File) liftBody(n *names, body *MethodBody, depth int) []stmt {
+	l, err := f.newLifter(n, body, depth)
+
+	if err == nil {
+		var stmts []stmt
+
+		if stmts, err = l.lift(); err == nil {
+			return stmts
+		}
+	}
+
+	stmts := []stmt{&stmtLine{text: "// failed to decompile: " + err.Error()}}
+	text, err := f.Disassemble(body)
+
+	if err != nil {
+		return stmts
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		stmts = append(stmts, &stmtLine{text: "// " + line})
+	}
+
+	return stmts
+}
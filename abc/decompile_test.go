@@ -0,0 +1,320 @@
+package abc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClass returns a File whose pkg.Foo is replaced with a class having
+// fields, a constructor, a getter and methods assembled from the text.
+func newTestClass(t *testing.T) *File {
+	f, err := Decode(newTestABC())
+
+	require.NoError(t, err)
+
+	a := &assembler{f: f, add: true}
+	multiname := func(text string) uint32 {
+		tokens, err := tokenize(text)
+
+		require.NoError(t, err)
+
+		index, err := a.parseMultiname(&parser{tokens: tokens})
+
+		require.NoError(t, err)
+
+		return index
+	}
+	str := func(s string) uint32 {
+		tokens, err := tokenize(quote(s))
+
+		require.NoError(t, err)
+
+		index, err := a.parseString(&parser{tokens: tokens})
+
+		require.NoError(t, err)
+
+		return index
+	}
+
+	size := multiname(`QName(PrivateNamespace("pkg:Foo"), "_size")`)
+	number := multiname(`QName(PackageNamespace(""), "Number")`)
+	void := multiname(`QName(PackageNamespace(""), "void")`)
+	event := multiname(`QName(PackageNamespace("flash.events"), "Event")`)
+
+	f.Methods = []*Method{
+		{ParamTypes: []uint32{number}, Flags: MethodHasOptional | MethodHasParamNames, Options: []Option{{Value: 1, Kind: ConstantInt}}, ParamNames: []uint32{str("size")}},
+		{},
+		{ReturnType: number},
+		{ParamTypes: []uint32{4}, ReturnType: void, Flags: MethodHasParamNames, ParamNames: []uint32{str("n")}},
+		{ParamTypes: []uint32{event}, ReturnType: void, Flags: MethodHasParamNames, ParamNames: []uint32{str("event")}},
+	}
+	f.Metadata = []*Metadata{{Name: str("Event"), Items: []MetadataItem{{Key: str("name"), Value: str("change")}, {Key: str("type"), Value: str("flash.events.Event")}}}}
+	f.Instances[0] = &Instance{
+		Name:      1,
+		SuperName: multiname(`QName(PackageNamespace("flash.display"), "Sprite")`),
+		Flags:     InstanceSealed,
+		Init:      0,
+		Traits: []*Trait{
+			{Name: size, Kind: TraitSlot, ID: 1, Type: number},
+			{Name: multiname(`QName(PackageNamespace(""), "size")`), Kind: TraitGetter, Index: 2},
+			{Name: multiname(`QName(PackageNamespace(""), "grow")`), Kind: TraitMethod, Index: 3},
+			{Name: multiname(`QName(PackageNamespace(""), "onChange")`), Kind: TraitMethod, Attributes: TraitFinal, Index: 4},
+		},
+	}
+	f.Classes[0] = &Class{
+		Init: 1,
+		Traits: []*Trait{
+			{Name: multiname(`QName(PackageNamespace(""), "MAX")`), Kind: TraitConst, ID: 1, Type: 4, Value: 1, ValueKind: ConstantInt},
+		},
+	}
+	f.Scripts[0].Traits[0].Attributes = TraitMetadata
+	f.Scripts[0].Traits[0].Metadata = []uint32{0}
+
+	codes := []string{`
+  getlocal0
+  pushscope
+  getlocal0
+  constructsuper 0
+  getlocal0
+  getlocal1
+  initproperty QName(PrivateNamespace("pkg:Foo"), "_size")
+  returnvoid
+`, `
+  getlocal0
+  pushscope
+  returnvoid
+`, `
+  getlocal0
+  pushscope
+  getlocal0
+  getproperty QName(PrivateNamespace("pkg:Foo"), "_size")
+  returnvalue
+`, `
+  getlocal0
+  pushscope
+  debug 1, "i", 1, 0
+  pushbyte 0
+  convert_i
+  setlocal2
+  jump L1
+L0:
+  label
+  getlocal0
+  getlocal0
+  getproperty QName(PrivateNamespace("pkg:Foo"), "_size")
+  pushbyte 2
+  multiply
+  setproperty QName(PrivateNamespace("pkg:Foo"), "_size")
+  inclocal_i 2
+L1:
+  getlocal2
+  getlocal1
+  iflt L0
+  getlocal0
+  getproperty QName(PrivateNamespace("pkg:Foo"), "_size")
+  pushbyte 100
+  greaterthan
+  dup
+  iffalse L2
+  pop
+  getlocal1
+  pushbyte 1
+  greaterthan
+L2:
+  iffalse L3
+  findpropstrict QName(PackageNamespace(""), "trace")
+  pushstring "big"
+  callpropvoid QName(PackageNamespace(""), "trace"), 1
+  jump L4
+L3:
+  findpropstrict QName(PackageNamespace(""), "trace")
+  getlocal1
+  pushbyte 0
+  greaterthan
+  iffalse L5
+  pushstring "small"
+  jump L6
+L5:
+  pushstring "none"
+L6:
+  callpropvoid QName(PackageNamespace(""), "trace"), 1
+L4:
+  returnvoid
+`, `
+  getlocal0
+  pushscope
+  findpropstrict QName(PackageNamespace(""), "dispatchEvent")
+  getlocal1
+  callpropvoid QName(PackageNamespace(""), "dispatchEvent"), 1
+  returnvoid
+`}
+
+	f.MethodBodies = nil
+
+	for i, code := range codes {
+		body := &MethodBody{Method: uint32(i)}
+
+		require.NoError(t, f.Assemble(body, code))
+
+		f.MethodBodies = append(f.MethodBodies, body)
+	}
+
+	return f
+}
+
+func TestDecompile(t *testing.T) {
+	files, err := newTestClass(t).Decompile()
+
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "pkg/Foo.as", files[0].Path)
+	require.Equal(t, `package pkg {
+    import flash.display.Sprite;
+    import flash.events.Event;
+
+    [Event(name="change", type="flash.events.Event")]
+    public class Foo extends Sprite {
+        public static const MAX:int = 5;
+        private var _size:Number;
+
+        public function Foo(size:Number = 5) {
+            super();
+            this._size = size;
+        }
+
+        public function get size():Number {
+            return this._size;
+        }
+
+        public function grow(n:int):void {
+            var i:int = 0;
+            while (i < n) {
+                this._size = this._size * 2;
+                i++;
+            }
+            if (this._size > 100 && n > 1) {
+                trace("big");
+            } else {
+                trace(n > 0 ? "small" : "none");
+            }
+        }
+
+        public final function onChange(event:Event):void {
+            dispatchEvent(event);
+        }
+    }
+}
+`, files[0].Source)
+}
+
+func TestDecompileStatements(t *testing.T) {
+	f := newTestClass(t)
+
+	require.NoError(t, f.Assemble(f.MethodBodies[3], `
+  getlocal0
+  pushscope
+  pushbyte 0
+  setlocal 3
+  getlocal1
+  coerce_a
+  setlocal 4
+  jump L1
+L0:
+  label
+  getlocal 4
+  getlocal 3
+  nextname
+  coerce_s
+  setlocal2
+  findpropstrict QName(PackageNamespace(""), "trace")
+  getlocal2
+  callpropvoid QName(PackageNamespace(""), "trace"), 1
+L1:
+  hasnext2 4, 3
+  iftrue L0
+L2:
+  findpropstrict QName(PackageNamespace(""), "foo")
+  callpropvoid QName(PackageNamespace(""), "foo"), 0
+L3:
+  jump L5
+L4:
+  getlocal0
+  pushscope
+  newcatch 0
+  dup
+  setlocal 5
+  dup
+  pushscope
+  swap
+  setslot 1
+  findpropstrict QName(PackageNamespace(""), "trace")
+  getscopeobject 1
+  getslot 1
+  callpropvoid QName(PackageNamespace(""), "trace"), 1
+  popscope
+  kill 5
+L5:
+  getlocal1
+  pushbyte 3
+  ifngt L6
+  findpropstrict QName(PackageNamespace(""), "a")
+  callpropvoid QName(PackageNamespace(""), "a"), 0
+  jump L7
+L6:
+  getlocal1
+  pushbyte 1
+  ifne L7
+  findpropstrict QName(PackageNamespace(""), "b")
+  newfunction 1
+  callpropvoid QName(PackageNamespace(""), "b"), 1
+L7:
+  returnvoid
+try from L2 to L3 target L4 type QName(PackageNamespace(""), "Error") name QName(PackageNamespace(""), "e")
+`))
+
+	files, err := f.Decompile()
+
+	require.NoError(t, err)
+	require.Contains(t, files[0].Source, `        public function grow(n:int):void {
+            for (var local2:String in n) {
+                trace(local2);
+            }
+            try {
+                foo();
+            } catch (e:Error) {
+                trace(e);
+            }
+            if (n > 3) {
+                a();
+            } else if (n == 1) {
+                b(function():* {
+                });
+            }
+        }
+`)
+}
+
+func TestDecompileFallback(t *testing.T) {
+	f := newTestClass(t)
+
+	require.NoError(t, f.Assemble(f.MethodBodies[2], `
+  getlocal0
+  lookupswitch L0, [L0]
+L0:
+  returnvoid
+`))
+
+	files, err := f.Decompile()
+
+	require.NoError(t, err)
+	require.Contains(t, files[0].Source, `        public function get size():Number {
+            // failed to decompile: switch at instruction 1 is not supported
+            // ; method 2, maxstack 1, localcount 1, initscopedepth 0, maxscopedepth 0
+            //   getlocal0
+            //   lookupswitch L0, [L0]
+            // L0:
+            //   returnvoid
+        }
+`)
+}
@@ -0,0 +1,351 @@
+package abc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// writer writes the primitive types of ABC. The first error is kept, and
+// the writes after it are ignored.
+type writer struct {
+	data []byte
+	err  error
+}
+
+func (w *writer) u8(value uint8) {
+	w.data = append(w.data, value)
+}
+
+func (w *writer) u16(value uint16) {
+	w.data = append(w.data, uint8(value), uint8(value>>8))
+}
+
+// u32 writes the variable-length unsigned integer in the fewest bytes.
+func (w *writer) u32(value uint32) {
+	for value >= 0x80 {
+		w.data = append(w.data, uint8(value)|0x80)
+		value >>= 7
+	}
+
+	w.data = append(w.data, uint8(value))
+}
+
+func (w *writer) u30(value uint32) {
+	if value >= 1<<30 && w.err == nil {
+		w.err = fmt.Errorf("u30 is out of range: %d", value)
+	}
+
+	w.u32(value)
+}
+
+func (w *writer) u30s(values []uint32) {
+	for _, value := range values {
+		w.u30(value)
+	}
+}
+
+func (w *writer) count(count int) {
+	w.u30(uint32(count))
+}
+
+func (w *writer) d64(value float64) {
+	var data [8]byte
+
+	binary.LittleEndian.PutUint64(data[:], math.Float64bits(value))
+	w.data = append(w.data, data[:]...)
+}
+
+func (w *writer) string(value string) {
+	w.count(len(value))
+	w.data = append(w.data, value...)
+}
+
+// Encode encodes the abcFile. The integers are written in the fewest bytes,
+// so the output is identical to the input of Decode unless the input has
+// redundant bytes in its integers.
+func Encode(f *File) ([]byte, error) {
+	if f == nil {
+		return nil, fmt.Errorf("cannot encode because File is nil")
+	}
+	if len(f.Instances) != len(f.Classes) {
+		return nil, fmt.Errorf("cannot encode because File has %d instances and %d classes", len(f.Instances), len(f.Classes))
+	}
+
+	w := &writer{}
+	w.u16(f.MinorVersion)
+	w.u16(f.MajorVersion)
+
+	if err := w.constantPool(&f.ConstantPool); err != nil {
+		return nil, fmt.Errorf("failed to encode File.ConstantPool: %w", err)
+	}
+
+	w.count(len(f.Methods))
+
+	for i, m := range f.Methods {
+		if err := w.method(m); err != nil {
+			return nil, fmt.Errorf("failed to encode File.Methods[%d]: %w", i, err)
+		}
+	}
+
+	w.count(len(f.Metadata))
+
+	for i, m := range f.Metadata {
+		if m == nil {
+			return nil, fmt.Errorf("failed to encode File.Metadata[%d]: metadata is nil", i)
+		}
+
+		w.u30(m.Name)
+		w.count(len(m.Items))
+
+		for _, item := range m.Items {
+			w.u30(item.Key)
+		}
+		for _, item := range m.Items {
+			w.u30(item.Value)
+		}
+	}
+
+	w.count(len(f.Instances))
+
+	for i, v := range f.Instances {
+		if err := w.instance(v); err != nil {
+			return nil, fmt.Errorf("failed to encode File.Instances[%d]: %w", i, err)
+		}
+	}
+	for i, v := range f.Classes {
+		if v == nil {
+			return nil, fmt.Errorf("failed to encode File.Classes[%d]: class is nil", i)
+		}
+
+		w.u30(v.Init)
+
+		if err := w.traits(v.Traits); err != nil {
+			return nil, fmt.Errorf("failed to encode File.Classes[%d]: %w", i, err)
+		}
+	}
+
+	w.count(len(f.Scripts))
+
+	for i, v := range f.Scripts {
+		if v == nil {
+			return nil, fmt.Errorf("failed to encode File.Scripts[%d]: script is nil", i)
+		}
+
+		w.u30(v.Init)
+
+		if err := w.traits(v.Traits); err != nil {
+			return nil, fmt.Errorf("failed to encode File.Scripts[%d]: %w", i, err)
+		}
+	}
+
+	w.count(len(f.MethodBodies))
+
+	for i, b := range f.MethodBodies {
+		if err := w.methodBody(b); err != nil {
+			return nil, fmt.Errorf("failed to encode File.MethodBodies[%d]: %w", i, err)
+		}
+	}
+	if w.err != nil {
+		return nil, fmt.Errorf("failed to encode File: %w", w.err)
+	}
+
+	return w.data, nil
+}
+
+func (w *writer) constantPool(p *ConstantPool) error {
+	w.count(len(p.Integers))
+
+	for i := 1; i < len(p.Integers); i++ {
+		w.u32(uint32(p.Integers[i]))
+	}
+
+	w.count(len(p.UIntegers))
+
+	for i := 1; i < len(p.UIntegers); i++ {
+		w.u32(p.UIntegers[i])
+	}
+
+	w.count(len(p.Doubles))
+
+	for i := 1; i < len(p.Doubles); i++ {
+		w.d64(p.Doubles[i])
+	}
+
+	w.count(len(p.Strings))
+
+	for i := 1; i < len(p.Strings); i++ {
+		w.string(p.Strings[i])
+	}
+
+	w.count(len(p.Namespaces))
+
+	for i := 1; i < len(p.Namespaces); i++ {
+		ns := p.Namespaces[i]
+
+		if ns == nil {
+			return fmt.Errorf("Namespaces[%d] is nil", i)
+		}
+
+		w.u8(uint8(ns.Kind))
+		w.u30(ns.Name)
+	}
+
+	w.count(len(p.NamespaceSets))
+
+	for i := 1; i < len(p.NamespaceSets); i++ {
+		w.count(len(p.NamespaceSets[i]))
+		w.u30s(p.NamespaceSets[i])
+	}
+
+	w.count(len(p.Multinames))
+
+	for i := 1; i < len(p.Multinames); i++ {
+		if err := w.multiname(p.Multinames[i]); err != nil {
+			return fmt.Errorf("failed to encode Multinames[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) multiname(m *Multiname) error {
+	if m == nil {
+		return fmt.Errorf("multiname is nil")
+	}
+
+	w.u8(uint8(m.Kind))
+
+	switch m.Kind {
+	case KindQName, KindQNameA:
+		w.u30(m.Namespace)
+		w.u30(m.Name)
+	case KindRTQName, KindRTQNameA:
+		w.u30(m.Name)
+	case KindRTQNameL, KindRTQNameLA:
+	case KindMultiname, KindMultinameA:
+		w.u30(m.Name)
+		w.u30(m.NamespaceSet)
+	case KindMultinameL, KindMultinameLA:
+		w.u30(m.NamespaceSet)
+	case KindTypeName:
+		w.u30(m.Name)
+		w.count(len(m.Parameters))
+		w.u30s(m.Parameters)
+	default:
+		return fmt.Errorf("unknown kind: %s", m.Kind)
+	}
+
+	return nil
+}
+
+func (w *writer) method(m *Method) error {
+	if m == nil {
+		return fmt.Errorf("method is nil")
+	}
+	if m.Flags&MethodHasParamNames != 0 && len(m.ParamNames) != len(m.ParamTypes) {
+		return fmt.Errorf("method has %d parameters and %d names", len(m.ParamTypes), len(m.ParamNames))
+	}
+
+	w.count(len(m.ParamTypes))
+	w.u30(m.ReturnType)
+	w.u30s(m.ParamTypes)
+	w.u30(m.Name)
+	w.u8(uint8(m.Flags))
+
+	if m.Flags&MethodHasOptional != 0 {
+		w.count(len(m.Options))
+
+		for _, option := range m.Options {
+			w.u30(option.Value)
+			w.u8(uint8(option.Kind))
+		}
+	}
+	if m.Flags&MethodHasParamNames != 0 {
+		w.u30s(m.ParamNames)
+	}
+
+	return nil
+}
+
+func (w *writer) instance(v *Instance) error {
+	if v == nil {
+		return fmt.Errorf("instance is nil")
+	}
+
+	w.u30(v.Name)
+	w.u30(v.SuperName)
+	w.u8(uint8(v.Flags))
+
+	if v.Flags&InstanceProtectedNs != 0 {
+		w.u30(v.ProtectedNs)
+	}
+
+	w.count(len(v.Interfaces))
+	w.u30s(v.Interfaces)
+	w.u30(v.Init)
+
+	return w.traits(v.Traits)
+}
+
+func (w *writer) traits(traits []*Trait) error {
+	w.count(len(traits))
+
+	for i, t := range traits {
+		if t == nil {
+			return fmt.Errorf("Traits[%d] is nil", i)
+		}
+		if t.Kind > 0x0f || t.Attributes > 0x0f {
+			return fmt.Errorf("Traits[%d] has invalid kind: %s", i, t.Kind)
+		}
+
+		w.u30(t.Name)
+		w.u8(uint8(t.Attributes)<<4 | uint8(t.Kind))
+		w.u30(t.ID)
+
+		switch t.Kind {
+		case TraitSlot, TraitConst:
+			w.u30(t.Type)
+			w.u30(t.Value)
+
+			if t.Value != 0 {
+				w.u8(uint8(t.ValueKind))
+			}
+		case TraitMethod, TraitGetter, TraitSetter, TraitClass, TraitFunction:
+			w.u30(t.Index)
+		default:
+			return fmt.Errorf("Traits[%d] has unknown kind: %s", i, t.Kind)
+		}
+		if t.Attributes&TraitMetadata != 0 {
+			w.count(len(t.Metadata))
+			w.u30s(t.Metadata)
+		}
+	}
+
+	return nil
+}
+
+func (w *writer) methodBody(b *MethodBody) error {
+	if b == nil {
+		return fmt.Errorf("method body is nil")
+	}
+
+	w.u30(b.Method)
+	w.u30(b.MaxStack)
+	w.u30(b.LocalCount)
+	w.u30(b.InitScopeDepth)
+	w.u30(b.MaxScopeDepth)
+	w.count(len(b.Code))
+	w.data = append(w.data, b.Code...)
+	w.count(len(b.Exceptions))
+
+	for i, e := range b.Exceptions {
+		if e == nil {
+			return fmt.Errorf("Exceptions[%d] is nil", i)
+		}
+
+		w.u30s([]uint32{e.From, e.To, e.Target, e.Type, e.VarName})
+	}
+
+	return w.traits(b.Traits)
+}
@@ -0,0 +1,91 @@
+package abc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCorpus() [][]byte {
+	empty := make([]byte, 16)
+	empty[2] = 0x2e
+
+	pools := []byte{
+		0x10, 0x00, 0x2e, 0x00,
+		0x03, 0xff, 0xff, 0xff, 0xff, 0x0f, 0xac, 0x02,
+		0x02, 0xff, 0xff, 0xff, 0xff, 0x0f,
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x3f,
+		0x01,
+		0x01,
+		0x02, 0x00,
+		0x03, 0x1b, 0x01, 0x11,
+		0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	return [][]byte{empty, pools, newTestABC()}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, data := range newTestCorpus() {
+		f, err := Decode(data)
+
+		require.NoError(t, err)
+
+		encoded, err := Encode(f)
+
+		require.NoError(t, err)
+		require.Equal(t, data, encoded)
+	}
+}
+
+func TestEncodeModified(t *testing.T) {
+	f, err := Decode(newTestABC())
+
+	require.NoError(t, err)
+
+	f.ConstantPool.Strings[2] = "Bar"
+	f.MethodBodies[0].Code = append(make([]byte, 200), 0x47)
+
+	encoded, err := Encode(f)
+
+	require.NoError(t, err)
+
+	g, err := Decode(encoded)
+
+	require.NoError(t, err)
+	require.Equal(t, "Bar", g.ConstantPool.String(2))
+	require.Len(t, g.MethodBodies[0].Code, 201)
+
+	f.Metadata[0].Name = 1 << 30
+
+	_, err = Encode(f)
+
+	require.Error(t, err)
+}
+
+func FuzzEncode(f *testing.F) {
+	for _, data := range newTestCorpus() {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := Decode(data)
+
+		if err != nil {
+			return
+		}
+
+		encoded, err := Encode(decoded)
+
+		require.NoError(t, err)
+
+		decoded, err = Decode(encoded)
+
+		require.NoError(t, err)
+
+		reencoded, err := Encode(decoded)
+
+		require.NoError(t, err)
+		require.Equal(t, encoded, reencoded)
+	})
+}
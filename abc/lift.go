@@ -0,0 +1,1321 @@
+package abc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The precedences of the ActionScript 3 operators.
+const (
+	precAssign = iota + 1
+	precTernary
+	precOr
+	precAnd
+	precBitOr
+	precBitXor
+	precBitAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precUnary
+	precPostfix
+	precCall
+	precPrimary
+)
+
+// maxFunctionDepth limits the nesting of the function expressions to lift.
+const maxFunctionDepth = 8
+
+type exprKind uint8
+
+const (
+	exprValue exprKind = iota
+	// exprScope is an object on the scope chain, whose properties are
+	// written without the object.
+	exprScope
+	exprActivation
+	exprCatch
+	exprHasNext
+	exprNextName
+	exprNextValue
+)
+
+type expr struct {
+	text string
+	prec int
+	kind exprKind
+	// operand is the operand of "!", which is used for the negation.
+	operand *expr
+	// op is the operator of a comparison.
+	op    string
+	left  *expr
+	right *expr
+	// typ is the type which the value is coerced to.
+	typ string
+	// registers is the object and the index registers of hasnext2.
+	registers [2]uint32
+	// object is the object enumerated by nextname and nextvalue.
+	object *expr
+	// slots names the slots of an activation or a catch scope.
+	slots map[uint32]string
+	pure  bool
+}
+
+func value(text string, prec int) *expr {
+	return &expr{text: text, prec: prec}
+}
+
+func literal(text string) *expr {
+	return &expr{text: text, prec: precPrimary, pure: true}
+}
+
+func (e *expr) paren(prec int) string {
+	if e.prec < prec {
+		return "(" + e.text + ")"
+	}
+
+	return e.text
+}
+
+var flippedOperators = map[string]string{
+	"==":  "!=",
+	"!=":  "==",
+	"===": "!==",
+	"!==": "===",
+}
+
+func binaryExpr(op string, prec int, left, right *expr) *expr {
+	return &expr{
+		text:  left.paren(prec) + " " + op + " " + right.paren(prec+1),
+		prec:  prec,
+		op:    op,
+		left:  left,
+		right: right,
+		pure:  left.pure && right.pure,
+	}
+}
+
+func not(e *expr) *expr {
+	if e.operand != nil {
+		return e.operand
+	}
+	if flipped, ok := flippedOperators[e.op]; ok {
+		return binaryExpr(flipped, precEquality, e.left, e.right)
+	}
+
+	return &expr{text: "!" + e.paren(precUnary), prec: precUnary, operand: e, pure: e.pure}
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r >= 0x80 {
+			continue
+		}
+		if i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+type stmt interface {
+	write(w *sourceWriter)
+}
+
+type sourceWriter struct {
+	builder strings.Builder
+	depth   int
+}
+
+func (w *sourceWriter) line(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			w.builder.WriteString("\n")
+
+			continue
+		}
+
+		w.builder.WriteString(strings.Repeat("    ", w.depth))
+		w.builder.WriteString(line)
+		w.builder.WriteString("\n")
+	}
+}
+
+func (w *sourceWriter) block(stmts []stmt) {
+	w.depth += 1
+
+	for _, s := range stmts {
+		s.write(w)
+	}
+
+	w.depth -= 1
+}
+
+type stmtLine struct {
+	text string
+}
+
+func (s *stmtLine) write(w *sourceWriter) {
+	w.line(s.text)
+}
+
+// stmtAssign is an assignment. Register is the assigned register, or -1
+// if the target is not a register.
+type stmtAssign struct {
+	target   string
+	value    *expr
+	register int
+}
+
+func (s *stmtAssign) write(w *sourceWriter) {
+	w.line(s.target + " = " + s.value.text + ";")
+}
+
+type stmtIf struct {
+	condition *expr
+	then      []stmt
+	otherwise []stmt
+}
+
+func (s *stmtIf) write(w *sourceWriter) {
+	w.line("if (" + s.condition.text + ") {")
+	w.block(s.then)
+
+	for len(s.otherwise) > 0 {
+		if next, ok := s.otherwise[0].(*stmtIf); ok && len(s.otherwise) == 1 {
+			w.line("} else if (" + next.condition.text + ") {")
+			w.block(next.then)
+			s = next
+
+			continue
+		}
+
+		w.line("} else {")
+		w.block(s.otherwise)
+
+		break
+	}
+
+	w.line("}")
+}
+
+type stmtBlock struct {
+	header string
+	body   []stmt
+	footer string
+}
+
+func (s *stmtBlock) write(w *sourceWriter) {
+	w.line(s.header + " {")
+	w.block(s.body)
+	w.line("}" + s.footer)
+}
+
+type catchClause struct {
+	header string
+	body   []stmt
+}
+
+type stmtTry struct {
+	body    []stmt
+	catches []catchClause
+}
+
+func (s *stmtTry) write(w *sourceWriter) {
+	w.line("try {")
+	w.block(s.body)
+
+	for _, c := range s.catches {
+		w.line("} " + c.header + " {")
+		w.block(c.body)
+	}
+
+	w.line("}")
+}
+
+type frame struct {
+	stack  []*expr
+	scopes []*expr
+	stmts  []stmt
+}
+
+func (fr *frame) fork() *frame {
+	return &frame{
+		stack:  append([]*expr{}, fr.stack...),
+		scopes: append([]*expr{}, fr.scopes...),
+	}
+}
+
+func (fr *frame) push(e *expr) {
+	fr.stack = append(fr.stack, e)
+}
+
+func (fr *frame) emit(s stmt) {
+	fr.stmts = append(fr.stmts, s)
+}
+
+type loop struct {
+	head int
+	exit int
+}
+
+// lifter lifts the code of a method body into statements.
+type lifter struct {
+	f            *File
+	n            *names
+	body         *MethodBody
+	instructions []*Instruction
+	offsets      []int
+	index        map[uint32]int
+	registers    map[uint32]string
+	declared     map[uint32]bool
+	hidden       map[uint32]*expr
+	entered      map[[2]int]bool
+	loops        []loop
+	depth        int
+	// catchName is the variable name of the catch being lifted.
+	catchName string
+	err       error
+}
+
+func (l *lifter) pop(fr *frame) *expr {
+	if len(fr.stack) == 0 {
+		if l.err == nil {
+			l.err = fmt.Errorf("stack underflow")
+		}
+
+		return literal("undefined")
+	}
+
+	e := fr.stack[len(fr.stack)-1]
+	fr.stack = fr.stack[:len(fr.stack)-1]
+
+	return e
+}
+
+func (l *lifter) popArgs(fr *frame, count uint32) []*expr {
+	if int(count) > len(fr.stack) {
+		l.pop(&frame{})
+
+		return nil
+	}
+
+	args := make([]*expr, count)
+
+	for i := int(count) - 1; i >= 0; i-- {
+		args[i] = l.pop(fr)
+	}
+
+	return args
+}
+
+func joinArgs(args []*expr) string {
+	texts := make([]string, len(args))
+
+	for i, arg := range args {
+		texts[i] = arg.paren(precAssign)
+	}
+
+	return strings.Join(texts, ", ")
+}
+
+// paramNames returns the names of the parameters of the method.
+func (f *File) paramNames(m *Method) []string {
+	names := make([]string, len(m.ParamTypes))
+
+	for i := range names {
+		names[i] = fmt.Sprintf("param%d", i+1)
+
+		if m.Flags&MethodHasParamNames != 0 && i < len(m.ParamNames) {
+			if name := f.ConstantPool.String(m.ParamNames[i]); isIdentifier(name) {
+				names[i] = name
+			}
+		}
+	}
+
+	return names
+}
+
+func (f *File) newLifter(n *names, body *MethodBody, depth int) (*lifter, error) {
+	instructions, err := DecodeCode(body.Code)
+
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, err := CodeOffsets(instructions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l := &lifter{
+		f:            f,
+		n:            n,
+		body:         body,
+		instructions: instructions,
+		offsets:      offsets,
+		index:        map[uint32]int{},
+		registers:    map[uint32]string{0: "this"},
+		declared:     map[uint32]bool{},
+		hidden:       map[uint32]*expr{},
+		entered:      map[[2]int]bool{},
+		depth:        depth,
+	}
+
+	for i, offset := range offsets {
+		l.index[uint32(offset)] = i
+	}
+	if int(body.Method) < len(f.Methods) && f.Methods[body.Method] != nil {
+		m := f.Methods[body.Method]
+
+		for i, name := range f.paramNames(m) {
+			l.registers[uint32(i+1)] = name
+		}
+
+		next := uint32(len(m.ParamTypes) + 1)
+
+		if m.Flags&MethodNeedRest != 0 {
+			l.registers[next] = "rest"
+		} else if m.Flags&MethodNeedArguments != 0 {
+			l.registers[next] = "arguments"
+		}
+	}
+	for register := range l.registers {
+		l.declared[register] = true
+	}
+	for _, instruction := range instructions {
+		// The debug instruction names a local, whose register is the
+		// operand plus 1.
+		if instruction.Opcode == OpDebug && instruction.Operands[0] == 1 {
+			register := instruction.Operands[2] + 1
+
+			if name := f.ConstantPool.String(instruction.Operands[1]); isIdentifier(name) && !l.declared[register] {
+				l.registers[register] = name
+			}
+		}
+	}
+
+	return l, nil
+}
+
+func (l *lifter) register(r uint32) string {
+	if name, ok := l.registers[r]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("local%d", r)
+}
+
+// lift lifts the whole code. The trailing return of a void method is
+// dropped.
+func (l *lifter) lift() ([]stmt, error) {
+	fr := &frame{}
+
+	if err := l.run(0, len(l.instructions), fr); err != nil {
+		return nil, err
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+	if n := len(fr.stmts); n > 0 {
+		if s, ok := fr.stmts[n-1].(*stmtLine); ok && s.text == "return;" {
+			fr.stmts = fr.stmts[:n-1]
+		}
+	}
+
+	return fr.stmts, nil
+}
+
+func isConditional(opcode Opcode) bool {
+	return opcode >= OpIfNlt && opcode <= OpIfStrictNe && opcode != OpJump
+}
+
+func (l *lifter) currentLoop() *loop {
+	if len(l.loops) == 0 {
+		return nil
+	}
+
+	return &l.loops[len(l.loops)-1]
+}
+
+// jump returns the statement of a jump to the target, which must be break
+// or continue of the current loop.
+func (l *lifter) jump(target int) (stmt, bool) {
+	if lp := l.currentLoop(); lp != nil {
+		switch target {
+		case lp.exit:
+			return &stmtLine{text: "break;"}, true
+		case lp.head:
+			return &stmtLine{text: "continue;"}, true
+		}
+	}
+
+	return nil, false
+}
+
+func (l *lifter) run(start, end int, fr *frame) error {
+	for i := start; i < end; {
+		if l.err != nil {
+			return l.err
+		}
+
+		next, ok, err := l.try(i, end, fr)
+
+		if err != nil {
+			return err
+		}
+		if !ok {
+			next, ok, err = l.loop(i, end, fr)
+		}
+		if err != nil {
+			return err
+		}
+		if ok {
+			i = next
+
+			continue
+		}
+
+		instruction := l.instructions[i]
+
+		switch {
+		case isConditional(instruction.Opcode):
+			if i, err = l.conditional(i, end, fr); err != nil {
+				return err
+			}
+		case instruction.Opcode == OpJump:
+			s, ok := l.jump(instruction.Branches[0].Target)
+
+			if !ok {
+				return fmt.Errorf("unstructured jump at instruction %d", i)
+			}
+
+			fr.emit(s)
+			i += 1
+		case instruction.Opcode == OpLookupSwitch:
+			return fmt.Errorf("switch at instruction %d is not supported", i)
+		default:
+			if err := l.step(instruction, fr); err != nil {
+				return fmt.Errorf("failed to lift instruction %d: %w", i, err)
+			}
+
+			i += 1
+		}
+	}
+
+	return l.err
+}
+
+// condition pops the operands of the conditional branch and returns the
+// condition on which the branch is taken.
+func (l *lifter) condition(instruction *Instruction, fr *frame) *expr {
+	switch instruction.Opcode {
+	case OpIfTrue:
+		return l.pop(fr)
+	case OpIfFalse:
+		return not(l.pop(fr))
+	}
+
+	right := l.pop(fr)
+	left := l.pop(fr)
+
+	switch instruction.Opcode {
+	case OpIfEq:
+		return binaryExpr("==", precEquality, left, right)
+	case OpIfNe:
+		return binaryExpr("!=", precEquality, left, right)
+	case OpIfStrictEq:
+		return binaryExpr("===", precEquality, left, right)
+	case OpIfStrictNe:
+		return binaryExpr("!==", precEquality, left, right)
+	case OpIfLt:
+		return binaryExpr("<", precRelational, left, right)
+	case OpIfLe:
+		return binaryExpr("<=", precRelational, left, right)
+	case OpIfGt:
+		return binaryExpr(">", precRelational, left, right)
+	case OpIfGe:
+		return binaryExpr(">=", precRelational, left, right)
+	case OpIfNlt:
+		return not(binaryExpr("<", precRelational, left, right))
+	case OpIfNle:
+		return not(binaryExpr("<=", precRelational, left, right))
+	case OpIfNgt:
+		return not(binaryExpr(">", precRelational, left, right))
+	default:
+		return not(binaryExpr(">=", precRelational, left, right))
+	}
+}
+
+// conditional lifts the forward conditional branch at i into a logical
+// operator, a ternary operator or an if statement, and returns the index
+// after them.
+func (l *lifter) conditional(i, end int, fr *frame) (int, error) {
+	instruction := l.instructions[i]
+	target := instruction.Branches[0].Target
+
+	if s, ok := l.jump(target); ok {
+		fr.emit(&stmtIf{condition: l.condition(instruction, fr), then: []stmt{s}})
+
+		return i + 1, nil
+	}
+	if target <= i || target > end {
+		return 0, fmt.Errorf("unstructured branch at instruction %d", i)
+	}
+
+	// The logical operators are compiled as "a; dup; iffalse L; pop; b; L:".
+	if (instruction.Opcode == OpIfTrue || instruction.Opcode == OpIfFalse) && i > 0 && l.instructions[i-1].Opcode == OpDup && i+1 < target && l.instructions[i+1].Opcode == OpPop {
+		l.pop(fr)
+		left := l.pop(fr)
+		right := fr.fork()
+
+		if err := l.run(i+2, target, right); err != nil {
+			return 0, err
+		}
+		if len(right.stmts) > 0 || len(right.stack) != len(fr.stack)+1 {
+			return 0, fmt.Errorf("unbalanced logical operator at instruction %d", i)
+		}
+		if instruction.Opcode == OpIfFalse {
+			fr.push(binaryExpr("&&", precAnd, left, right.stack[len(right.stack)-1]))
+		} else {
+			fr.push(binaryExpr("||", precOr, left, right.stack[len(right.stack)-1]))
+		}
+
+		return target, nil
+	}
+
+	condition := not(l.condition(instruction, fr))
+	thenEnd, elseEnd := target, -1
+
+	if last := l.instructions[target-1]; target-1 > i && last.Opcode == OpJump {
+		skip := last.Branches[0].Target
+
+		if _, ok := l.jump(skip); !ok && skip > target && skip <= end {
+			thenEnd, elseEnd = target-1, skip
+		}
+	}
+
+	then := fr.fork()
+
+	if err := l.run(i+1, thenEnd, then); err != nil {
+		return 0, err
+	}
+	if elseEnd < 0 {
+		if len(then.stack) != len(fr.stack) {
+			return 0, fmt.Errorf("unbalanced if at instruction %d", i)
+		}
+
+		fr.emit(&stmtIf{condition: condition, then: then.stmts})
+
+		return target, nil
+	}
+
+	otherwise := fr.fork()
+
+	if err := l.run(target, elseEnd, otherwise); err != nil {
+		return 0, err
+	}
+	if len(then.stmts) == 0 && len(otherwise.stmts) == 0 && len(then.stack) == len(fr.stack)+1 && len(otherwise.stack) == len(fr.stack)+1 {
+		a, b := then.stack[len(then.stack)-1], otherwise.stack[len(otherwise.stack)-1]
+		fr.push(&expr{
+			text: condition.paren(precOr) + " ? " + a.paren(precAssign) + " : " + b.paren(precAssign),
+			prec: precTernary,
+			pure: condition.pure && a.pure && b.pure,
+		})
+
+		return elseEnd, nil
+	}
+	if len(then.stack) != len(fr.stack) || len(otherwise.stack) != len(fr.stack) {
+		return 0, fmt.Errorf("unbalanced if at instruction %d", i)
+	}
+
+	fr.emit(&stmtIf{condition: condition, then: then.stmts, otherwise: otherwise.stmts})
+
+	return elseEnd, nil
+}
+
+// loop lifts the loop starting at i. The while loops are compiled as
+// "jump L2; L1: label; body; L2: condition; iftrue L1", and the do-while
+// loops as "L1: label; body; condition; iftrue L1".
+func (l *lifter) loop(i, end int, fr *frame) (int, bool, error) {
+	instruction := l.instructions[i]
+
+	if instruction.Opcode == OpJump {
+		head := instruction.Branches[0].Target
+
+		if head <= i+1 || head >= end || l.instructions[i+1].Opcode != OpLabel {
+			return 0, false, nil
+		}
+
+		tail := l.backwardBranch(i+1, head, end)
+
+		if tail < 0 {
+			return 0, false, nil
+		}
+
+		cond := fr.fork()
+
+		if err := l.run(head, tail, cond); err != nil {
+			return 0, false, err
+		}
+
+		condition := l.condition(l.instructions[tail], cond)
+
+		if len(cond.stmts) > 0 || len(cond.stack) != len(fr.stack) {
+			return 0, false, fmt.Errorf("unbalanced loop condition at instruction %d", tail)
+		}
+
+		l.loops = append(l.loops, loop{head: head, exit: tail + 1})
+		body := fr.fork()
+		err := l.run(i+2, head, body)
+		l.loops = l.loops[:len(l.loops)-1]
+
+		if err != nil {
+			return 0, false, err
+		}
+		if len(body.stack) != len(fr.stack) {
+			return 0, false, fmt.Errorf("unbalanced loop at instruction %d", i)
+		}
+
+		fr.emit(l.whileLoop(condition, body.stmts, fr))
+
+		return tail + 1, true, nil
+	}
+	if instruction.Opcode == OpLabel {
+		tail := l.backwardBranch(i, i+1, end)
+
+		if tail < 0 {
+			return 0, false, nil
+		}
+
+		l.loops = append(l.loops, loop{head: -1, exit: tail + 1})
+		body := fr.fork()
+		err := l.run(i+1, tail, body)
+		l.loops = l.loops[:len(l.loops)-1]
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		condition := l.condition(l.instructions[tail], body)
+
+		if len(body.stack) != len(fr.stack) {
+			return 0, false, fmt.Errorf("unbalanced loop at instruction %d", i)
+		}
+
+		fr.emit(&stmtBlock{header: "do", body: body.stmts, footer: " while (" + condition.text + ");"})
+
+		return tail + 1, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// backwardBranch returns the index of the first conditional branch in
+// [from, end) which goes back to the target, or -1.
+func (l *lifter) backwardBranch(target, from, end int) int {
+	for j := from; j < end; j++ {
+		if instruction := l.instructions[j]; isConditional(instruction.Opcode) && instruction.Branches[0].Target == target {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// whileLoop returns the while loop, or the for-in loop when the condition
+// is hasnext2. The assignments of the registers of hasnext2 before the loop
+// are removed.
+func (l *lifter) whileLoop(condition *expr, body []stmt, fr *frame) stmt {
+	if condition.kind != exprHasNext || len(body) == 0 {
+		return &stmtBlock{header: "while (" + condition.text + ")", body: body}
+	}
+
+	first, ok := body[0].(*stmtAssign)
+
+	if !ok || (first.value.kind != exprNextName && first.value.kind != exprNextValue) {
+		return &stmtBlock{header: "while (" + condition.text + ")", body: body}
+	}
+
+	object := first.value.object
+
+	for k := len(fr.stmts) - 1; k >= 0; k-- {
+		if s, ok := fr.stmts[k].(*stmtAssign); ok && s.register == int(condition.registers[0]) {
+			object = s.value
+			fr.stmts = append(fr.stmts[:k], fr.stmts[k+1:]...)
+
+			break
+		}
+	}
+	for k := len(fr.stmts) - 1; k >= 0; k-- {
+		if s, ok := fr.stmts[k].(*stmtAssign); ok && s.register == int(condition.registers[1]) {
+			fr.stmts = append(fr.stmts[:k], fr.stmts[k+1:]...)
+
+			break
+		}
+	}
+
+	header := "for (" + first.target + " in " + object.paren(precRelational+1) + ")"
+
+	if first.value.kind == exprNextValue {
+		header = "for each (" + first.target + " in " + object.paren(precRelational+1) + ")"
+	}
+
+	return &stmtBlock{header: header, body: body[1:]}
+}
+
+// try lifts the try statement starting at i. The try block is followed by
+// a jump over the handlers, and each handler ends with a jump to the same
+// place.
+func (l *lifter) try(i, end int, fr *frame) (int, bool, error) {
+	var group []*Exception
+
+	to := -1
+
+	for _, e := range l.body.Exceptions {
+		from, ok := l.index[e.From]
+
+		if !ok || from != i {
+			continue
+		}
+
+		last, ok := l.index[e.To]
+
+		if !ok || l.entered[[2]int{i, last}] {
+			continue
+		}
+		if last > to {
+			group, to = nil, last
+		}
+		if last == to {
+			group = append(group, e)
+		}
+	}
+	if group == nil {
+		return 0, false, nil
+	}
+	if to >= end || l.instructions[to].Opcode != OpJump {
+		return 0, false, fmt.Errorf("unstructured try at instruction %d", i)
+	}
+
+	exit := l.instructions[to].Branches[0].Target
+
+	if exit <= to || exit > end {
+		return 0, false, fmt.Errorf("unstructured try at instruction %d", i)
+	}
+
+	l.entered[[2]int{i, to}] = true
+	body := fr.fork()
+
+	if err := l.run(i, to, body); err != nil {
+		return 0, false, err
+	}
+
+	sort.Slice(group, func(a, b int) bool {
+		return group[a].Target < group[b].Target
+	})
+
+	s := &stmtTry{body: body.stmts}
+
+	for k, e := range group {
+		if e.VarName == 0 {
+			return 0, false, fmt.Errorf("finally at instruction %d is not supported", i)
+		}
+
+		start, ok := l.index[e.Target]
+
+		if !ok {
+			return 0, false, fmt.Errorf("unstructured catch at instruction %d", i)
+		}
+
+		stop := exit
+
+		if k+1 < len(group) {
+			if stop, ok = l.index[group[k+1].Target]; !ok {
+				return 0, false, fmt.Errorf("unstructured catch at instruction %d", i)
+			}
+		}
+		if stop > start && l.instructions[stop-1].Opcode == OpJump && l.instructions[stop-1].Branches[0].Target == exit {
+			stop -= 1
+		}
+
+		name := l.n.name(e.VarName)
+		// The scope stack is emptied when the handler is entered.
+		handler := &frame{stack: []*expr{literal(name)}}
+		l.catchName = name
+
+		if err := l.run(start, stop, handler); err != nil {
+			return 0, false, err
+		}
+
+		s.catches = append(s.catches, catchClause{
+			header: fmt.Sprintf("catch (%s:%s)", name, l.n.typeName(e.Type)),
+			body:   handler.stmts,
+		})
+	}
+
+	fr.emit(s)
+
+	return exit, true, nil
+}
+
+// member returns the text to access the property of the object. The parts
+// of a runtime multiname are popped from the stack.
+func (l *lifter) member(fr *frame, index uint32) func(object *expr) *expr {
+	m := l.f.multiname(index)
+
+	var suffix string
+
+	attribute := ""
+
+	if m != nil {
+		switch m.Kind {
+		case KindQNameA, KindRTQNameA, KindRTQNameLA, KindMultinameA, KindMultinameLA:
+			attribute = "@"
+		}
+	}
+
+	bare := ""
+
+	switch {
+	case m != nil && (m.Kind == KindMultinameL || m.Kind == KindMultinameLA):
+		key := l.pop(fr)
+		suffix = "[" + key.text + "]"
+	case m != nil && (m.Kind == KindRTQNameL || m.Kind == KindRTQNameLA):
+		key := l.pop(fr)
+		ns := l.pop(fr)
+		suffix = "." + attribute + ns.paren(precPrimary) + "::[" + key.text + "]"
+	case m != nil && (m.Kind == KindRTQName || m.Kind == KindRTQNameA):
+		ns := l.pop(fr)
+		suffix = "." + attribute + ns.paren(precPrimary) + "::" + l.f.Name(index)
+	default:
+		name := l.n.name(index)
+
+		if isIdentifier(name) {
+			suffix = "." + attribute + name
+			bare = attribute + name
+		} else {
+			suffix = "[" + quote(name) + "]"
+		}
+	}
+
+	return func(object *expr) *expr {
+		switch {
+		case object.kind == exprScope && bare != "":
+			return value(bare, precPrimary)
+		case object.kind == exprActivation || object.kind == exprCatch:
+			return value(strings.TrimPrefix(suffix, "."), precPrimary)
+		default:
+			return value(object.paren(precCall)+suffix, precCall)
+		}
+	}
+}
+
+func (l *lifter) slot(object *expr, id uint32) string {
+	if name, ok := object.slots[id]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("%s.slot%d", object.paren(precCall), id)
+}
+
+var binaryOperators = map[Opcode]struct {
+	op   string
+	prec int
+}{
+	OpAdd:           {"+", precAdditive},
+	OpAddI:          {"+", precAdditive},
+	OpSubtract:      {"-", precAdditive},
+	OpSubtractI:     {"-", precAdditive},
+	OpMultiply:      {"*", precMultiplicative},
+	OpMultiplyI:     {"*", precMultiplicative},
+	OpDivide:        {"/", precMultiplicative},
+	OpModulo:        {"%", precMultiplicative},
+	OpLShift:        {"<<", precShift},
+	OpRShift:        {">>", precShift},
+	OpURShift:       {">>>", precShift},
+	OpBitAnd:        {"&", precBitAnd},
+	OpBitOr:         {"|", precBitOr},
+	OpBitXor:        {"^", precBitXor},
+	OpEquals:        {"==", precEquality},
+	OpStrictEquals:  {"===", precEquality},
+	OpLessThan:      {"<", precRelational},
+	OpLessEquals:    {"<=", precRelational},
+	OpGreaterThan:   {">", precRelational},
+	OpGreaterEquals: {">=", precRelational},
+	OpInstanceOf:    {"instanceof", precRelational},
+	OpIsTypeLate:    {"is", precRelational},
+	OpAsTypeLate:    {"as", precRelational},
+	OpIn:            {"in", precRelational},
+}
+
+var coercions = map[Opcode]string{
+	OpConvertS: "String",
+	OpCoerceS:  "String",
+	OpConvertI: "int",
+	OpCoerceI:  "int",
+	OpConvertU: "uint",
+	OpCoerceU:  "uint",
+	OpConvertD: "Number",
+	OpCoerceD:  "Number",
+	OpConvertB: "Boolean",
+	OpCoerceB:  "Boolean",
+	OpConvertO: "Object",
+	OpCoerceO:  "Object",
+	OpCoerceA:  "*",
+}
+
+func coerce(e *expr, typ string) *expr {
+	c := *e
+	c.typ = typ
+
+	return &c
+}
+
+func (l *lifter) setLocal(fr *frame, r uint32, v *expr) {
+	if v.kind == exprScope || v.kind == exprActivation || v.kind == exprCatch {
+		l.hidden[r] = v
+
+		return
+	}
+
+	name := l.register(r)
+
+	if v.op == "+" && v.left.text == name && v.right.text == "1" {
+		fr.emit(&stmtLine{text: name + "++;"})
+
+		return
+	}
+	if v.op == "-" && v.left.text == name && v.right.text == "1" {
+		fr.emit(&stmtLine{text: name + "--;"})
+
+		return
+	}
+
+	target := name
+
+	if !l.declared[r] {
+		l.declared[r] = true
+		target = "var " + name
+
+		if v.typ != "" {
+			target += ":" + v.typ
+		}
+	}
+
+	fr.emit(&stmtAssign{target: target, value: v, register: int(r)})
+}
+
+func (l *lifter) getLocal(fr *frame, r uint32) {
+	if v, ok := l.hidden[r]; ok {
+		fr.push(v)
+
+		return
+	}
+
+	fr.push(&expr{text: l.register(r), prec: precPrimary, pure: true})
+}
+
+func (l *lifter) call(fr *frame, instruction *Instruction) *expr {
+	args := l.popArgs(fr, instruction.Operands[1])
+	access := l.member(fr, instruction.Operands[0])
+	callee := access(l.pop(fr))
+
+	return value(callee.paren(precCall)+"("+joinArgs(args)+")", precCall)
+}
+
+// function lifts the method as a function expression.
+func (l *lifter) function(index uint32) *expr {
+	body := l.f.body(index)
+	signature := "function"
+
+	if int(index) < len(l.f.Methods) && l.f.Methods[index] != nil {
+		signature += l.f.signature(l.n, l.f.Methods[index], false)
+	} else {
+		signature += "()"
+	}
+	if body == nil || l.depth >= maxFunctionDepth {
+		return value(signature+" {}", precPrimary)
+	}
+
+	w := &sourceWriter{}
+	w.line(signature + " {")
+	w.block(l.f.liftBody(l.n, body, l.depth+1))
+	w.line("}")
+
+	return value(strings.TrimSuffix(w.builder.String(), "\n"), precPrimary)
+}
+
+func (l *lifter) step(instruction *Instruction, fr *frame) error {
+	operands := instruction.Operands
+
+	if operator, ok := binaryOperators[instruction.Opcode]; ok {
+		right := l.pop(fr)
+		left := l.pop(fr)
+		fr.push(binaryExpr(operator.op, operator.prec, left, right))
+
+		return nil
+	}
+	if typ, ok := coercions[instruction.Opcode]; ok {
+		fr.push(coerce(l.pop(fr), typ))
+
+		return nil
+	}
+
+	switch instruction.Opcode {
+	case OpLabel, OpNop, OpBkpt, OpDebug, OpDebugLine, OpDebugFile, OpBkptLine, OpTimestamp, OpKill, OpSxi1, OpSxi8, OpSxi16, OpEscXElem, OpEscXAttr, OpCheckFilter:
+	case OpPushScope:
+		fr.scopes = append(fr.scopes, l.pop(fr))
+	case OpPopScope:
+		if len(fr.scopes) > 0 {
+			fr.scopes = fr.scopes[:len(fr.scopes)-1]
+		}
+	case OpPushWith:
+		return fmt.Errorf("with is not supported")
+	case OpThrow:
+		fr.emit(&stmtLine{text: "throw " + l.pop(fr).text + ";"})
+	case OpReturnVoid:
+		fr.emit(&stmtLine{text: "return;"})
+	case OpReturnValue:
+		fr.emit(&stmtLine{text: "return " + l.pop(fr).text + ";"})
+	case OpDxns:
+		fr.emit(&stmtLine{text: "default xml namespace = " + quote(l.f.ConstantPool.String(operands[0])) + ";"})
+	case OpDxnsLate:
+		fr.emit(&stmtLine{text: "default xml namespace = " + l.pop(fr).text + ";"})
+	case OpPushNull:
+		fr.push(literal("null"))
+	case OpPushUndefined:
+		fr.push(literal("undefined"))
+	case OpPushTrue:
+		fr.push(literal("true"))
+	case OpPushFalse:
+		fr.push(literal("false"))
+	case OpPushNaN:
+		fr.push(literal("NaN"))
+	case OpPushByte:
+		fr.push(literal(fmt.Sprint(int8(operands[0]))))
+	case OpPushShort:
+		fr.push(literal(fmt.Sprint(int16(operands[0]))))
+	case OpPushString:
+		fr.push(literal(quote(l.f.ConstantPool.String(operands[0]))))
+	case OpPushInt:
+		fr.push(literal(l.n.constant(ConstantInt, operands[0])))
+	case OpPushUInt:
+		fr.push(literal(l.n.constant(ConstantUInt, operands[0])))
+	case OpPushDouble:
+		fr.push(literal(l.n.constant(ConstantDouble, operands[0])))
+	case OpPushNamespace:
+		fr.push(literal(l.n.constant(ConstantNamespace, operands[0])))
+	case OpPop:
+		if v := l.pop(fr); !v.pure && v.kind == exprValue {
+			fr.emit(&stmtLine{text: v.text + ";"})
+		}
+	case OpDup:
+		v := l.pop(fr)
+		fr.push(v)
+		fr.push(v)
+	case OpSwap:
+		a := l.pop(fr)
+		b := l.pop(fr)
+		fr.push(a)
+		fr.push(b)
+	case OpGetLocal, OpGetLocal0, OpGetLocal1, OpGetLocal2, OpGetLocal3:
+		l.getLocal(fr, registers(instruction)[0])
+	case OpSetLocal, OpSetLocal0, OpSetLocal1, OpSetLocal2, OpSetLocal3:
+		l.setLocal(fr, registers(instruction)[0], l.pop(fr))
+	case OpIncLocal, OpIncLocalI:
+		fr.emit(&stmtLine{text: l.register(operands[0]) + "++;"})
+	case OpDecLocal, OpDecLocalI:
+		fr.emit(&stmtLine{text: l.register(operands[0]) + "--;"})
+	case OpIncrement, OpIncrementI:
+		fr.push(binaryExpr("+", precAdditive, l.pop(fr), literal("1")))
+	case OpDecrement, OpDecrementI:
+		fr.push(binaryExpr("-", precAdditive, l.pop(fr), literal("1")))
+	case OpNegate, OpNegateI:
+		v := l.pop(fr)
+		fr.push(&expr{text: "-" + v.paren(precUnary), prec: precUnary, pure: v.pure})
+	case OpBitNot:
+		v := l.pop(fr)
+		fr.push(&expr{text: "~" + v.paren(precUnary), prec: precUnary, pure: v.pure})
+	case OpNot:
+		fr.push(not(l.pop(fr)))
+	case OpTypeOf:
+		v := l.pop(fr)
+		fr.push(&expr{text: "typeof " + v.paren(precUnary), prec: precUnary, pure: v.pure})
+	case OpCoerce:
+		fr.push(coerce(l.pop(fr), l.n.typeName(operands[0])))
+	case OpAsType:
+		fr.push(binaryExpr("as", precRelational, l.pop(fr), literal(l.n.typeName(operands[0]))))
+	case OpIsType:
+		fr.push(binaryExpr("is", precRelational, l.pop(fr), literal(l.n.typeName(operands[0]))))
+	case OpGetLex:
+		fr.push(&expr{text: l.n.name(operands[0]), prec: precPrimary, pure: true})
+	case OpFindPropStrict, OpFindProperty, OpFindDef:
+		m := l.f.multiname(operands[0])
+
+		if m != nil && instruction.Opcode != OpFindDef {
+			for k := l.f.runtimeNames(operands[0]); k > 0; k-- {
+				l.pop(fr)
+			}
+		}
+
+		fr.push(&expr{text: "this", prec: precPrimary, kind: exprScope, pure: true})
+	case OpGetGlobalScope, OpGetOuterScope:
+		fr.push(&expr{text: "this", prec: precPrimary, kind: exprScope, pure: true})
+	case OpGetScopeObject:
+		if int(operands[0]) < len(fr.scopes) {
+			fr.push(fr.scopes[operands[0]])
+		} else {
+			fr.push(&expr{text: "this", prec: precPrimary, kind: exprScope, pure: true})
+		}
+	case OpGetProperty:
+		access := l.member(fr, operands[0])
+		fr.push(access(l.pop(fr)))
+	case OpSetProperty, OpInitProperty:
+		v := l.pop(fr)
+		access := l.member(fr, operands[0])
+		target := access(l.pop(fr))
+		fr.emit(&stmtAssign{target: target.text, value: v, register: -1})
+	case OpDeleteProperty:
+		access := l.member(fr, operands[0])
+		target := access(l.pop(fr))
+		fr.push(value("delete "+target.text, precUnary))
+	case OpGetDescendants:
+		access := l.member(fr, operands[0])
+		target := access(l.pop(fr))
+		fr.push(value(strings.Replace(target.text, ".", "..", 1), precCall))
+	case OpGetSuper:
+		access := l.member(fr, operands[0])
+		l.pop(fr)
+		fr.push(access(literal("super")))
+	case OpSetSuper:
+		v := l.pop(fr)
+		access := l.member(fr, operands[0])
+		l.pop(fr)
+		fr.emit(&stmtAssign{target: access(literal("super")).text, value: v, register: -1})
+	case OpCallProperty, OpCallPropLex:
+		fr.push(l.call(fr, instruction))
+	case OpCallPropVoid:
+		fr.emit(&stmtLine{text: l.call(fr, instruction).text + ";"})
+	case OpCallSuper, OpCallSuperVoid:
+		args := l.popArgs(fr, operands[1])
+		access := l.member(fr, operands[0])
+		l.pop(fr)
+		call := access(literal("super")).text + "(" + joinArgs(args) + ")"
+
+		if instruction.Opcode == OpCallSuperVoid {
+			fr.emit(&stmtLine{text: call + ";"})
+		} else {
+			fr.push(value(call, precCall))
+		}
+	case OpConstructProp:
+		args := l.popArgs(fr, operands[1])
+		access := l.member(fr, operands[0])
+		class := access(l.pop(fr))
+		fr.push(value("new "+class.paren(precCall)+"("+joinArgs(args)+")", precCall))
+	case OpConstructSuper:
+		args := l.popArgs(fr, operands[0])
+		l.pop(fr)
+		fr.emit(&stmtLine{text: "super(" + joinArgs(args) + ");"})
+	case OpConstruct:
+		args := l.popArgs(fr, operands[0])
+		class := l.pop(fr)
+		fr.push(value("new "+class.paren(precCall)+"("+joinArgs(args)+")", precCall))
+	case OpCall:
+		args := l.popArgs(fr, operands[0])
+		l.pop(fr)
+		callee := l.pop(fr)
+		fr.push(value(callee.paren(precCall)+"("+joinArgs(args)+")", precCall))
+	case OpCallMethod, OpCallStatic:
+		args := l.popArgs(fr, operands[1])
+		object := l.pop(fr)
+		fr.push(value(fmt.Sprintf("%s.method%d(%s)", object.paren(precCall), operands[0], joinArgs(args)), precCall))
+	case OpApplyType:
+		args := l.popArgs(fr, operands[0])
+		base := l.pop(fr)
+		fr.push(value(base.paren(precCall)+".<"+joinArgs(args)+">", precCall))
+	case OpNewArray:
+		args := l.popArgs(fr, operands[0])
+		fr.push(value("["+joinArgs(args)+"]", precPrimary))
+	case OpNewObject:
+		args := l.popArgs(fr, operands[0]*2)
+
+		var properties []string
+
+		for k := 0; k+1 < len(args); k += 2 {
+			key := args[k].text
+
+			if name := strings.Trim(key, `"`); len(key) > 2 && key[0] == '"' && isIdentifier(name) {
+				key = name
+			}
+
+			properties = append(properties, key+": "+args[k+1].paren(precAssign))
+		}
+
+		fr.push(value("{"+strings.Join(properties, ", ")+"}", precPrimary))
+	case OpNewFunction:
+		fr.push(l.function(operands[0]))
+	case OpNewClass:
+		l.pop(fr)
+		fr.push(value(l.className(operands[0]), precPrimary))
+	case OpNewActivation:
+		slots := map[uint32]string{}
+
+		for _, t := range l.body.Traits {
+			slots[t.ID] = l.f.Name(t.Name)
+		}
+
+		fr.push(&expr{text: "this", prec: precPrimary, kind: exprActivation, slots: slots, pure: true})
+	case OpNewCatch:
+		fr.push(&expr{text: "this", prec: precPrimary, kind: exprCatch, slots: map[uint32]string{1: l.catchName}, pure: true})
+	case OpGetSlot:
+		object := l.pop(fr)
+		fr.push(value(l.slot(object, operands[0]), precCall))
+	case OpSetSlot:
+		v := l.pop(fr)
+		object := l.pop(fr)
+
+		if object.kind != exprCatch {
+			fr.emit(&stmtAssign{target: l.slot(object, operands[0]), value: v, register: -1})
+		}
+	case OpGetGlobalSlot:
+		fr.push(value(fmt.Sprintf("globalSlot%d", operands[0]), precPrimary))
+	case OpSetGlobalSlot:
+		fr.emit(&stmtAssign{target: fmt.Sprintf("globalSlot%d", operands[0]), value: l.pop(fr), register: -1})
+	case OpHasNext2:
+		fr.push(&expr{text: fmt.Sprintf("hasnext2(%s, %s)", l.register(operands[0]), l.register(operands[1])), prec: precCall, kind: exprHasNext, registers: [2]uint32{operands[0], operands[1]}})
+	case OpNextName, OpNextValue:
+		l.pop(fr)
+		object := l.pop(fr)
+		kind := exprNextName
+
+		if instruction.Opcode == OpNextValue {
+			kind = exprNextValue
+		}
+
+		fr.push(&expr{text: instruction.Opcode.String() + "(" + object.text + ")", prec: precCall, kind: kind, object: object})
+	default:
+		return fmt.Errorf("%s is not supported", instruction.Opcode)
+	}
+
+	return nil
+}
+
+// className returns the name of the class of the index.
+func (l *lifter) className(index uint32) string {
+	if int(index) < len(l.f.Instances) && l.f.Instances[index] != nil {
+		return l.n.name(l.f.Instances[index].Name)
+	}
+
+	return fmt.Sprintf("class%d", index)
+}
@@ -0,0 +1,259 @@
+package abc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// namespace returns the namespace of the index, or nil if there is none.
+func (f *File) namespace(index uint32) *Namespace {
+	if index == 0 || int(index) >= len(f.ConstantPool.Namespaces) {
+		return nil
+	}
+
+	return f.ConstantPool.Namespaces[index]
+}
+
+// multiname returns the multiname of the index, or nil if there is none.
+func (f *File) multiname(index uint32) *Multiname {
+	if index == 0 || int(index) >= len(f.ConstantPool.Multinames) {
+		return nil
+	}
+
+	return f.ConstantPool.Multinames[index]
+}
+
+// packageOf returns the package of the QName, or false if the multiname is
+// not a QName in a package namespace.
+func (f *File) packageOf(index uint32) (string, bool) {
+	m := f.multiname(index)
+
+	if m == nil || (m.Kind != KindQName && m.Kind != KindQNameA) {
+		return "", false
+	}
+
+	ns := f.namespace(m.Namespace)
+
+	if ns == nil || (ns.Kind != KindPackageNamespace && ns.Kind != KindPackageInternalNamespace) {
+		return "", false
+	}
+
+	return f.ConstantPool.String(ns.Name), true
+}
+
+// Name returns the local name of the multiname, such as "Sprite" of
+// flash.display.Sprite. It returns "*" for the index 0, which denotes any
+// name or any type.
+func (f *File) Name(index uint32) string {
+	return f.name(index, 0)
+}
+
+func (f *File) name(index uint32, depth int) string {
+	if index == 0 {
+		return "*"
+	}
+
+	m := f.multiname(index)
+
+	if m == nil || depth > maxNameDepth {
+		return fmt.Sprintf("multiname%d", index)
+	}
+
+	switch m.Kind {
+	case KindTypeName:
+		var parameters []string
+
+		for _, parameter := range m.Parameters {
+			parameters = append(parameters, f.name(parameter, depth+1))
+		}
+
+		return fmt.Sprintf("%s.<%s>", f.name(m.Name, depth+1), strings.Join(parameters, ", "))
+	case KindRTQNameL, KindRTQNameLA, KindMultinameL, KindMultinameLA:
+		return "*"
+	default:
+		if m.Name == 0 {
+			return "*"
+		}
+
+		return f.ConstantPool.String(m.Name)
+	}
+}
+
+// QualifiedName returns the name of the multiname with its package, such as
+// "flash.display.Sprite". The names out of packages are returned as
+// Name returns them.
+func (f *File) QualifiedName(index uint32) string {
+	return f.qualifiedName(index, 0)
+}
+
+func (f *File) qualifiedName(index uint32, depth int) string {
+	m := f.multiname(index)
+
+	if m != nil && m.Kind == KindTypeName && depth <= maxNameDepth {
+		var parameters []string
+
+		for _, parameter := range m.Parameters {
+			parameters = append(parameters, f.qualifiedName(parameter, depth+1))
+		}
+
+		return fmt.Sprintf("%s.<%s>", f.qualifiedName(m.Name, depth+1), strings.Join(parameters, ", "))
+	}
+	if pkg, ok := f.packageOf(index); ok && pkg != "" {
+		return pkg + "." + f.Name(index)
+	}
+
+	return f.Name(index)
+}
+
+// visibility returns the attribute of the namespace of the trait name.
+func (f *File) visibility(index uint32) string {
+	m := f.multiname(index)
+
+	if m == nil {
+		return "public"
+	}
+
+	ns := f.namespace(m.Namespace)
+
+	if ns == nil {
+		return "public"
+	}
+
+	switch ns.Kind {
+	case KindPrivateNamespace:
+		return "private"
+	case KindProtectedNamespace, KindStaticProtectedNamespace:
+		return "protected"
+	case KindPackageInternalNamespace:
+		return "internal"
+	default:
+		return "public"
+	}
+}
+
+// names formats the names in a file of the package, and collects the
+// imports needed by them.
+type names struct {
+	f       *File
+	pkg     string
+	imports map[string]bool
+}
+
+func (n *names) name(index uint32) string {
+	if pkg, ok := n.f.packageOf(index); ok && pkg != "" && pkg != n.pkg {
+		n.imports[pkg+"."+n.f.Name(index)] = true
+	}
+
+	return n.f.Name(index)
+}
+
+// typeName formats the type of the multiname.
+func (n *names) typeName(index uint32) string {
+	m := n.f.multiname(index)
+
+	if m != nil && m.Kind == KindTypeName {
+		var parameters []string
+
+		for _, parameter := range m.Parameters {
+			parameters = append(parameters, n.typeName(parameter))
+		}
+
+		return fmt.Sprintf("%s.<%s>", n.name(m.Name), strings.Join(parameters, ", "))
+	}
+
+	return n.name(index)
+}
+
+// constant formats the constant of the value index.
+func (n *names) constant(kind ConstantKind, index uint32) string {
+	pool := &n.f.ConstantPool
+
+	switch kind {
+	case ConstantInt:
+		if int(index) < len(pool.Integers) {
+			return fmt.Sprint(pool.Integers[index])
+		}
+	case ConstantUInt:
+		if int(index) < len(pool.UIntegers) {
+			return fmt.Sprint(pool.UIntegers[index])
+		}
+	case ConstantDouble:
+		if int(index) < len(pool.Doubles) {
+			return formatDouble(pool.Doubles[index])
+		}
+	case ConstantUtf8:
+		return quote(pool.String(index))
+	case ConstantTrue:
+		return "true"
+	case ConstantFalse:
+		return "false"
+	case ConstantNull:
+		return "null"
+	case ConstantUndefined:
+		return "undefined"
+	default:
+		if ns := n.f.namespace(index); ns != nil {
+			return quote(pool.String(ns.Name))
+		}
+	}
+
+	return "undefined"
+}
+
+// quote formats the string as an ActionScript string literal.
+func quote(s string) string {
+	builder := &strings.Builder{}
+	builder.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(builder, `\x%02x`, r)
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+
+	builder.WriteByte('"')
+
+	return builder.String()
+}
+
+// metadata formats the metadata like [Event(name="change")].
+func (n *names) metadata(index uint32) string {
+	if int(index) >= len(n.f.Metadata) || n.f.Metadata[index] == nil {
+		return ""
+	}
+
+	m := n.f.Metadata[index]
+
+	var items []string
+
+	for _, item := range m.Items {
+		if item.Key == 0 {
+			items = append(items, quote(n.f.ConstantPool.String(item.Value)))
+		} else {
+			items = append(items, fmt.Sprintf("%s=%s", n.f.ConstantPool.String(item.Key), quote(n.f.ConstantPool.String(item.Value))))
+		}
+	}
+
+	name := n.f.ConstantPool.String(m.Name)
+
+	if len(items) == 0 {
+		return fmt.Sprintf("[%s]", name)
+	}
+
+	return fmt.Sprintf("[%s(%s)]", name, strings.Join(items, ", "))
+}
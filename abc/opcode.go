@@ -0,0 +1,421 @@
+package abc
+
+import "fmt"
+
+// Opcode identifies an AVM2 instruction.
+type Opcode uint8
+
+const (
+	OpBkpt           Opcode = 0x01
+	OpNop            Opcode = 0x02
+	OpThrow          Opcode = 0x03
+	OpGetSuper       Opcode = 0x04
+	OpSetSuper       Opcode = 0x05
+	OpDxns           Opcode = 0x06
+	OpDxnsLate       Opcode = 0x07
+	OpKill           Opcode = 0x08
+	OpLabel          Opcode = 0x09
+	OpIfNlt          Opcode = 0x0c
+	OpIfNle          Opcode = 0x0d
+	OpIfNgt          Opcode = 0x0e
+	OpIfNge          Opcode = 0x0f
+	OpJump           Opcode = 0x10
+	OpIfTrue         Opcode = 0x11
+	OpIfFalse        Opcode = 0x12
+	OpIfEq           Opcode = 0x13
+	OpIfNe           Opcode = 0x14
+	OpIfLt           Opcode = 0x15
+	OpIfLe           Opcode = 0x16
+	OpIfGt           Opcode = 0x17
+	OpIfGe           Opcode = 0x18
+	OpIfStrictEq     Opcode = 0x19
+	OpIfStrictNe     Opcode = 0x1a
+	OpLookupSwitch   Opcode = 0x1b
+	OpPushWith       Opcode = 0x1c
+	OpPopScope       Opcode = 0x1d
+	OpNextName       Opcode = 0x1e
+	OpHasNext        Opcode = 0x1f
+	OpPushNull       Opcode = 0x20
+	OpPushUndefined  Opcode = 0x21
+	OpNextValue      Opcode = 0x23
+	OpPushByte       Opcode = 0x24
+	OpPushShort      Opcode = 0x25
+	OpPushTrue       Opcode = 0x26
+	OpPushFalse      Opcode = 0x27
+	OpPushNaN        Opcode = 0x28
+	OpPop            Opcode = 0x29
+	OpDup            Opcode = 0x2a
+	OpSwap           Opcode = 0x2b
+	OpPushString     Opcode = 0x2c
+	OpPushInt        Opcode = 0x2d
+	OpPushUInt       Opcode = 0x2e
+	OpPushDouble     Opcode = 0x2f
+	OpPushScope      Opcode = 0x30
+	OpPushNamespace  Opcode = 0x31
+	OpHasNext2       Opcode = 0x32
+	OpLi8            Opcode = 0x35
+	OpLi16           Opcode = 0x36
+	OpLi32           Opcode = 0x37
+	OpLf32           Opcode = 0x38
+	OpLf64           Opcode = 0x39
+	OpSi8            Opcode = 0x3a
+	OpSi16           Opcode = 0x3b
+	OpSi32           Opcode = 0x3c
+	OpSf32           Opcode = 0x3d
+	OpSf64           Opcode = 0x3e
+	OpNewFunction    Opcode = 0x40
+	OpCall           Opcode = 0x41
+	OpConstruct      Opcode = 0x42
+	OpCallMethod     Opcode = 0x43
+	OpCallStatic     Opcode = 0x44
+	OpCallSuper      Opcode = 0x45
+	OpCallProperty   Opcode = 0x46
+	OpReturnVoid     Opcode = 0x47
+	OpReturnValue    Opcode = 0x48
+	OpConstructSuper Opcode = 0x49
+	OpConstructProp  Opcode = 0x4a
+	OpCallPropLex    Opcode = 0x4c
+	OpCallSuperVoid  Opcode = 0x4e
+	OpCallPropVoid   Opcode = 0x4f
+	OpSxi1           Opcode = 0x50
+	OpSxi8           Opcode = 0x51
+	OpSxi16          Opcode = 0x52
+	OpApplyType      Opcode = 0x53
+	OpNewObject      Opcode = 0x55
+	OpNewArray       Opcode = 0x56
+	OpNewActivation  Opcode = 0x57
+	OpNewClass       Opcode = 0x58
+	OpGetDescendants Opcode = 0x59
+	OpNewCatch       Opcode = 0x5a
+	OpFindPropStrict Opcode = 0x5d
+	OpFindProperty   Opcode = 0x5e
+	OpFindDef        Opcode = 0x5f
+	OpGetLex         Opcode = 0x60
+	OpSetProperty    Opcode = 0x61
+	OpGetLocal       Opcode = 0x62
+	OpSetLocal       Opcode = 0x63
+	OpGetGlobalScope Opcode = 0x64
+	OpGetScopeObject Opcode = 0x65
+	OpGetProperty    Opcode = 0x66
+	OpGetOuterScope  Opcode = 0x67
+	OpInitProperty   Opcode = 0x68
+	OpDeleteProperty Opcode = 0x6a
+	OpGetSlot        Opcode = 0x6c
+	OpSetSlot        Opcode = 0x6d
+	OpGetGlobalSlot  Opcode = 0x6e
+	OpSetGlobalSlot  Opcode = 0x6f
+	OpConvertS       Opcode = 0x70
+	OpEscXElem       Opcode = 0x71
+	OpEscXAttr       Opcode = 0x72
+	OpConvertI       Opcode = 0x73
+	OpConvertU       Opcode = 0x74
+	OpConvertD       Opcode = 0x75
+	OpConvertB       Opcode = 0x76
+	OpConvertO       Opcode = 0x77
+	OpCheckFilter    Opcode = 0x78
+	OpCoerce         Opcode = 0x80
+	OpCoerceB        Opcode = 0x81
+	OpCoerceA        Opcode = 0x82
+	OpCoerceI        Opcode = 0x83
+	OpCoerceD        Opcode = 0x84
+	OpCoerceS        Opcode = 0x85
+	OpAsType         Opcode = 0x86
+	OpAsTypeLate     Opcode = 0x87
+	OpCoerceU        Opcode = 0x88
+	OpCoerceO        Opcode = 0x89
+	OpNegate         Opcode = 0x90
+	OpIncrement      Opcode = 0x91
+	OpIncLocal       Opcode = 0x92
+	OpDecrement      Opcode = 0x93
+	OpDecLocal       Opcode = 0x94
+	OpTypeOf         Opcode = 0x95
+	OpNot            Opcode = 0x96
+	OpBitNot         Opcode = 0x97
+	OpAdd            Opcode = 0xa0
+	OpSubtract       Opcode = 0xa1
+	OpMultiply       Opcode = 0xa2
+	OpDivide         Opcode = 0xa3
+	OpModulo         Opcode = 0xa4
+	OpLShift         Opcode = 0xa5
+	OpRShift         Opcode = 0xa6
+	OpURShift        Opcode = 0xa7
+	OpBitAnd         Opcode = 0xa8
+	OpBitOr          Opcode = 0xa9
+	OpBitXor         Opcode = 0xaa
+	OpEquals         Opcode = 0xab
+	OpStrictEquals   Opcode = 0xac
+	OpLessThan       Opcode = 0xad
+	OpLessEquals     Opcode = 0xae
+	OpGreaterThan    Opcode = 0xaf
+	OpGreaterEquals  Opcode = 0xb0
+	OpInstanceOf     Opcode = 0xb1
+	OpIsType         Opcode = 0xb2
+	OpIsTypeLate     Opcode = 0xb3
+	OpIn             Opcode = 0xb4
+	OpIncrementI     Opcode = 0xc0
+	OpDecrementI     Opcode = 0xc1
+	OpIncLocalI      Opcode = 0xc2
+	OpDecLocalI      Opcode = 0xc3
+	OpNegateI        Opcode = 0xc4
+	OpAddI           Opcode = 0xc5
+	OpSubtractI      Opcode = 0xc6
+	OpMultiplyI      Opcode = 0xc7
+	OpGetLocal0      Opcode = 0xd0
+	OpGetLocal1      Opcode = 0xd1
+	OpGetLocal2      Opcode = 0xd2
+	OpGetLocal3      Opcode = 0xd3
+	OpSetLocal0      Opcode = 0xd4
+	OpSetLocal1      Opcode = 0xd5
+	OpSetLocal2      Opcode = 0xd6
+	OpSetLocal3      Opcode = 0xd7
+	OpDebug          Opcode = 0xef
+	OpDebugLine      Opcode = 0xf0
+	OpDebugFile      Opcode = 0xf1
+	OpBkptLine       Opcode = 0xf2
+	OpTimestamp      Opcode = 0xf3
+)
+
+// OperandKind is the kind of an operand, which tells how the operand is
+// encoded and which table it refers to.
+type OperandKind uint8
+
+const (
+	// OperandU8 is a byte.
+	OperandU8 OperandKind = iota + 1
+	// OperandByte is a signed byte.
+	OperandByte
+	// OperandShort is a u30 which is truncated to a signed 16-bit integer.
+	OperandShort
+	// OperandU30 is a register, a slot, an argument count or so on.
+	OperandU30
+	OperandInt
+	OperandUInt
+	OperandDouble
+	OperandString
+	OperandNamespace
+	OperandMultiname
+	OperandMethod
+	OperandClass
+	OperandException
+	// OperandBranch is an s24 offset from the end of the instruction.
+	OperandBranch
+	// OperandSwitch is the default offset, the case count and the case
+	// offsets of lookupswitch, which are relative to the instruction.
+	OperandSwitch
+)
+
+// stackFlag tells how the operands change the stack effect.
+type stackFlag uint8
+
+const (
+	// stackArgs pops as many values as the last operand.
+	stackArgs stackFlag = iota + 1
+	// stackPairs pops twice as many values as the last operand.
+	stackPairs
+)
+
+type opcodeInfo struct {
+	name     string
+	operands []OperandKind
+	pop      int
+	push     int
+	flag     stackFlag
+}
+
+var opcodes = map[Opcode]opcodeInfo{
+	OpBkpt:           {"bkpt", nil, 0, 0, 0},
+	OpNop:            {"nop", nil, 0, 0, 0},
+	OpThrow:          {"throw", nil, 1, 0, 0},
+	OpGetSuper:       {"getsuper", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpSetSuper:       {"setsuper", []OperandKind{OperandMultiname}, 2, 0, 0},
+	OpDxns:           {"dxns", []OperandKind{OperandString}, 0, 0, 0},
+	OpDxnsLate:       {"dxnslate", nil, 1, 0, 0},
+	OpKill:           {"kill", []OperandKind{OperandU30}, 0, 0, 0},
+	OpLabel:          {"label", nil, 0, 0, 0},
+	OpIfNlt:          {"ifnlt", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfNle:          {"ifnle", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfNgt:          {"ifngt", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfNge:          {"ifnge", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpJump:           {"jump", []OperandKind{OperandBranch}, 0, 0, 0},
+	OpIfTrue:         {"iftrue", []OperandKind{OperandBranch}, 1, 0, 0},
+	OpIfFalse:        {"iffalse", []OperandKind{OperandBranch}, 1, 0, 0},
+	OpIfEq:           {"ifeq", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfNe:           {"ifne", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfLt:           {"iflt", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfLe:           {"ifle", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfGt:           {"ifgt", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfGe:           {"ifge", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfStrictEq:     {"ifstricteq", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpIfStrictNe:     {"ifstrictne", []OperandKind{OperandBranch}, 2, 0, 0},
+	OpLookupSwitch:   {"lookupswitch", []OperandKind{OperandSwitch}, 1, 0, 0},
+	OpPushWith:       {"pushwith", nil, 1, 0, 0},
+	OpPopScope:       {"popscope", nil, 0, 0, 0},
+	OpNextName:       {"nextname", nil, 2, 1, 0},
+	OpHasNext:        {"hasnext", nil, 2, 1, 0},
+	OpPushNull:       {"pushnull", nil, 0, 1, 0},
+	OpPushUndefined:  {"pushundefined", nil, 0, 1, 0},
+	OpNextValue:      {"nextvalue", nil, 2, 1, 0},
+	OpPushByte:       {"pushbyte", []OperandKind{OperandByte}, 0, 1, 0},
+	OpPushShort:      {"pushshort", []OperandKind{OperandShort}, 0, 1, 0},
+	OpPushTrue:       {"pushtrue", nil, 0, 1, 0},
+	OpPushFalse:      {"pushfalse", nil, 0, 1, 0},
+	OpPushNaN:        {"pushnan", nil, 0, 1, 0},
+	OpPop:            {"pop", nil, 1, 0, 0},
+	OpDup:            {"dup", nil, 1, 2, 0},
+	OpSwap:           {"swap", nil, 2, 2, 0},
+	OpPushString:     {"pushstring", []OperandKind{OperandString}, 0, 1, 0},
+	OpPushInt:        {"pushint", []OperandKind{OperandInt}, 0, 1, 0},
+	OpPushUInt:       {"pushuint", []OperandKind{OperandUInt}, 0, 1, 0},
+	OpPushDouble:     {"pushdouble", []OperandKind{OperandDouble}, 0, 1, 0},
+	OpPushScope:      {"pushscope", nil, 1, 0, 0},
+	OpPushNamespace:  {"pushnamespace", []OperandKind{OperandNamespace}, 0, 1, 0},
+	OpHasNext2:       {"hasnext2", []OperandKind{OperandU30, OperandU30}, 0, 1, 0},
+	OpLi8:            {"li8", nil, 1, 1, 0},
+	OpLi16:           {"li16", nil, 1, 1, 0},
+	OpLi32:           {"li32", nil, 1, 1, 0},
+	OpLf32:           {"lf32", nil, 1, 1, 0},
+	OpLf64:           {"lf64", nil, 1, 1, 0},
+	OpSi8:            {"si8", nil, 2, 0, 0},
+	OpSi16:           {"si16", nil, 2, 0, 0},
+	OpSi32:           {"si32", nil, 2, 0, 0},
+	OpSf32:           {"sf32", nil, 2, 0, 0},
+	OpSf64:           {"sf64", nil, 2, 0, 0},
+	OpNewFunction:    {"newfunction", []OperandKind{OperandMethod}, 0, 1, 0},
+	OpCall:           {"call", []OperandKind{OperandU30}, 2, 1, stackArgs},
+	OpConstruct:      {"construct", []OperandKind{OperandU30}, 1, 1, stackArgs},
+	OpCallMethod:     {"callmethod", []OperandKind{OperandU30, OperandU30}, 1, 1, stackArgs},
+	OpCallStatic:     {"callstatic", []OperandKind{OperandMethod, OperandU30}, 1, 1, stackArgs},
+	OpCallSuper:      {"callsuper", []OperandKind{OperandMultiname, OperandU30}, 1, 1, stackArgs},
+	OpCallProperty:   {"callproperty", []OperandKind{OperandMultiname, OperandU30}, 1, 1, stackArgs},
+	OpReturnVoid:     {"returnvoid", nil, 0, 0, 0},
+	OpReturnValue:    {"returnvalue", nil, 1, 0, 0},
+	OpConstructSuper: {"constructsuper", []OperandKind{OperandU30}, 1, 0, stackArgs},
+	OpConstructProp:  {"constructprop", []OperandKind{OperandMultiname, OperandU30}, 1, 1, stackArgs},
+	OpCallPropLex:    {"callproplex", []OperandKind{OperandMultiname, OperandU30}, 1, 1, stackArgs},
+	OpCallSuperVoid:  {"callsupervoid", []OperandKind{OperandMultiname, OperandU30}, 1, 0, stackArgs},
+	OpCallPropVoid:   {"callpropvoid", []OperandKind{OperandMultiname, OperandU30}, 1, 0, stackArgs},
+	OpSxi1:           {"sxi1", nil, 1, 1, 0},
+	OpSxi8:           {"sxi8", nil, 1, 1, 0},
+	OpSxi16:          {"sxi16", nil, 1, 1, 0},
+	OpApplyType:      {"applytype", []OperandKind{OperandU30}, 1, 1, stackArgs},
+	OpNewObject:      {"newobject", []OperandKind{OperandU30}, 0, 1, stackPairs},
+	OpNewArray:       {"newarray", []OperandKind{OperandU30}, 0, 1, stackArgs},
+	OpNewActivation:  {"newactivation", nil, 0, 1, 0},
+	OpNewClass:       {"newclass", []OperandKind{OperandClass}, 1, 1, 0},
+	OpGetDescendants: {"getdescendants", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpNewCatch:       {"newcatch", []OperandKind{OperandException}, 0, 1, 0},
+	OpFindPropStrict: {"findpropstrict", []OperandKind{OperandMultiname}, 0, 1, 0},
+	OpFindProperty:   {"findproperty", []OperandKind{OperandMultiname}, 0, 1, 0},
+	OpFindDef:        {"finddef", []OperandKind{OperandMultiname}, 0, 1, 0},
+	OpGetLex:         {"getlex", []OperandKind{OperandMultiname}, 0, 1, 0},
+	OpSetProperty:    {"setproperty", []OperandKind{OperandMultiname}, 2, 0, 0},
+	OpGetLocal:       {"getlocal", []OperandKind{OperandU30}, 0, 1, 0},
+	OpSetLocal:       {"setlocal", []OperandKind{OperandU30}, 1, 0, 0},
+	OpGetGlobalScope: {"getglobalscope", nil, 0, 1, 0},
+	OpGetScopeObject: {"getscopeobject", []OperandKind{OperandU8}, 0, 1, 0},
+	OpGetProperty:    {"getproperty", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpGetOuterScope:  {"getouterscope", []OperandKind{OperandU30}, 0, 1, 0},
+	OpInitProperty:   {"initproperty", []OperandKind{OperandMultiname}, 2, 0, 0},
+	OpDeleteProperty: {"deleteproperty", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpGetSlot:        {"getslot", []OperandKind{OperandU30}, 1, 1, 0},
+	OpSetSlot:        {"setslot", []OperandKind{OperandU30}, 2, 0, 0},
+	OpGetGlobalSlot:  {"getglobalslot", []OperandKind{OperandU30}, 0, 1, 0},
+	OpSetGlobalSlot:  {"setglobalslot", []OperandKind{OperandU30}, 1, 0, 0},
+	OpConvertS:       {"convert_s", nil, 1, 1, 0},
+	OpEscXElem:       {"esc_xelem", nil, 1, 1, 0},
+	OpEscXAttr:       {"esc_xattr", nil, 1, 1, 0},
+	OpConvertI:       {"convert_i", nil, 1, 1, 0},
+	OpConvertU:       {"convert_u", nil, 1, 1, 0},
+	OpConvertD:       {"convert_d", nil, 1, 1, 0},
+	OpConvertB:       {"convert_b", nil, 1, 1, 0},
+	OpConvertO:       {"convert_o", nil, 1, 1, 0},
+	OpCheckFilter:    {"checkfilter", nil, 1, 1, 0},
+	OpCoerce:         {"coerce", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpCoerceB:        {"coerce_b", nil, 1, 1, 0},
+	OpCoerceA:        {"coerce_a", nil, 1, 1, 0},
+	OpCoerceI:        {"coerce_i", nil, 1, 1, 0},
+	OpCoerceD:        {"coerce_d", nil, 1, 1, 0},
+	OpCoerceS:        {"coerce_s", nil, 1, 1, 0},
+	OpAsType:         {"astype", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpAsTypeLate:     {"astypelate", nil, 2, 1, 0},
+	OpCoerceU:        {"coerce_u", nil, 1, 1, 0},
+	OpCoerceO:        {"coerce_o", nil, 1, 1, 0},
+	OpNegate:         {"negate", nil, 1, 1, 0},
+	OpIncrement:      {"increment", nil, 1, 1, 0},
+	OpIncLocal:       {"inclocal", []OperandKind{OperandU30}, 0, 0, 0},
+	OpDecrement:      {"decrement", nil, 1, 1, 0},
+	OpDecLocal:       {"declocal", []OperandKind{OperandU30}, 0, 0, 0},
+	OpTypeOf:         {"typeof", nil, 1, 1, 0},
+	OpNot:            {"not", nil, 1, 1, 0},
+	OpBitNot:         {"bitnot", nil, 1, 1, 0},
+	OpAdd:            {"add", nil, 2, 1, 0},
+	OpSubtract:       {"subtract", nil, 2, 1, 0},
+	OpMultiply:       {"multiply", nil, 2, 1, 0},
+	OpDivide:         {"divide", nil, 2, 1, 0},
+	OpModulo:         {"modulo", nil, 2, 1, 0},
+	OpLShift:         {"lshift", nil, 2, 1, 0},
+	OpRShift:         {"rshift", nil, 2, 1, 0},
+	OpURShift:        {"urshift", nil, 2, 1, 0},
+	OpBitAnd:         {"bitand", nil, 2, 1, 0},
+	OpBitOr:          {"bitor", nil, 2, 1, 0},
+	OpBitXor:         {"bitxor", nil, 2, 1, 0},
+	OpEquals:         {"equals", nil, 2, 1, 0},
+	OpStrictEquals:   {"strictequals", nil, 2, 1, 0},
+	OpLessThan:       {"lessthan", nil, 2, 1, 0},
+	OpLessEquals:     {"lessequals", nil, 2, 1, 0},
+	OpGreaterThan:    {"greaterthan", nil, 2, 1, 0},
+	OpGreaterEquals:  {"greaterequals", nil, 2, 1, 0},
+	OpInstanceOf:     {"instanceof", nil, 2, 1, 0},
+	OpIsType:         {"istype", []OperandKind{OperandMultiname}, 1, 1, 0},
+	OpIsTypeLate:     {"istypelate", nil, 2, 1, 0},
+	OpIn:             {"in", nil, 2, 1, 0},
+	OpIncrementI:     {"increment_i", nil, 1, 1, 0},
+	OpDecrementI:     {"decrement_i", nil, 1, 1, 0},
+	OpIncLocalI:      {"inclocal_i", []OperandKind{OperandU30}, 0, 0, 0},
+	OpDecLocalI:      {"declocal_i", []OperandKind{OperandU30}, 0, 0, 0},
+	OpNegateI:        {"negate_i", nil, 1, 1, 0},
+	OpAddI:           {"add_i", nil, 2, 1, 0},
+	OpSubtractI:      {"subtract_i", nil, 2, 1, 0},
+	OpMultiplyI:      {"multiply_i", nil, 2, 1, 0},
+	OpGetLocal0:      {"getlocal0", nil, 0, 1, 0},
+	OpGetLocal1:      {"getlocal1", nil, 0, 1, 0},
+	OpGetLocal2:      {"getlocal2", nil, 0, 1, 0},
+	OpGetLocal3:      {"getlocal3", nil, 0, 1, 0},
+	OpSetLocal0:      {"setlocal0", nil, 1, 0, 0},
+	OpSetLocal1:      {"setlocal1", nil, 1, 0, 0},
+	OpSetLocal2:      {"setlocal2", nil, 1, 0, 0},
+	OpSetLocal3:      {"setlocal3", nil, 1, 0, 0},
+	OpDebug:          {"debug", []OperandKind{OperandU8, OperandString, OperandU8, OperandU30}, 0, 0, 0},
+	OpDebugLine:      {"debugline", []OperandKind{OperandU30}, 0, 0, 0},
+	OpDebugFile:      {"debugfile", []OperandKind{OperandString}, 0, 0, 0},
+	OpBkptLine:       {"bkptline", []OperandKind{OperandU30}, 0, 0, 0},
+	OpTimestamp:      {"timestamp", nil, 0, 0, 0},
+}
+
+// String returns the mnemonic of the opcode.
+func (o Opcode) String() string {
+	if info, ok := opcodes[o]; ok {
+		return info.name
+	}
+
+	return fmt.Sprintf("op_%02x", uint8(o))
+}
+
+// Operands returns the kinds of the operands. It returns false when the
+// opcode is unknown.
+func (o Opcode) Operands() ([]OperandKind, bool) {
+	info, ok := opcodes[o]
+
+	return info.operands, ok
+}
+
+// LookupOpcode returns the opcode of the mnemonic.
+func LookupOpcode(name string) (Opcode, bool) {
+	for opcode, info := range opcodes {
+		if info.name == name {
+			return opcode, true
+		}
+	}
+
+	return 0, false
+}
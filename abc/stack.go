@@ -0,0 +1,208 @@
+package abc
+
+import "fmt"
+
+// runtimeNames returns the number of values which the multiname takes from
+// the stack at runtime.
+func (f *File) runtimeNames(index uint32) int {
+	if int(index) >= len(f.ConstantPool.Multinames) || f.ConstantPool.Multinames[index] == nil {
+		return 0
+	}
+
+	switch f.ConstantPool.Multinames[index].Kind {
+	case KindRTQName, KindRTQNameA, KindMultinameL, KindMultinameLA:
+		return 1
+	case KindRTQNameL, KindRTQNameLA:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// StackEffect returns the numbers of the values which the instruction pops
+// from and pushes to the operand stack.
+func (f *File) StackEffect(instruction *Instruction) (int, int) {
+	info := opcodes[instruction.Opcode]
+	pop := info.pop
+
+	if len(instruction.Operands) > 0 {
+		last := int(instruction.Operands[len(instruction.Operands)-1])
+
+		switch info.flag {
+		case stackArgs:
+			pop += last
+		case stackPairs:
+			pop += 2 * last
+		}
+	}
+	for i, kind := range info.operands {
+		if kind == OperandMultiname && i < len(instruction.Operands) {
+			pop += f.runtimeNames(instruction.Operands[i])
+		}
+	}
+
+	return pop, info.push
+}
+
+// scopeEffect returns the change of the scope depth by the instruction.
+func scopeEffect(opcode Opcode) int {
+	switch opcode {
+	case OpPushScope, OpPushWith:
+		return 1
+	case OpPopScope:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// fallsThrough reports whether the next instruction can follow the
+// instruction.
+func fallsThrough(opcode Opcode) bool {
+	switch opcode {
+	case OpJump, OpLookupSwitch, OpThrow, OpReturnVoid, OpReturnValue:
+		return false
+	default:
+		return true
+	}
+}
+
+// registers returns the registers which the instruction uses.
+func registers(instruction *Instruction) []uint32 {
+	switch instruction.Opcode {
+	case OpGetLocal, OpSetLocal, OpKill, OpIncLocal, OpDecLocal, OpIncLocalI, OpDecLocalI, OpHasNext2:
+		return instruction.Operands
+	case OpGetLocal0, OpGetLocal1, OpGetLocal2, OpGetLocal3:
+		return []uint32{uint32(instruction.Opcode - OpGetLocal0)}
+	case OpSetLocal0, OpSetLocal1, OpSetLocal2, OpSetLocal3:
+		return []uint32{uint32(instruction.Opcode - OpSetLocal0)}
+	default:
+		return nil
+	}
+}
+
+// ComputeLimits sets MaxStack, LocalCount and MaxScopeDepth of the method
+// body from its code, which is traced from the entry and the exception
+// handlers. InitScopeDepth is kept.
+func (f *File) ComputeLimits(body *MethodBody) error {
+	if f == nil {
+		return fmt.Errorf("cannot compute limits because File is nil")
+	}
+	if body == nil {
+		return fmt.Errorf("cannot compute limits because MethodBody is nil")
+	}
+
+	instructions, err := DecodeCode(body.Code)
+
+	if err != nil {
+		return err
+	}
+
+	offsets, err := CodeOffsets(instructions)
+
+	if err != nil {
+		return err
+	}
+
+	type state struct {
+		stack int
+		scope int
+	}
+
+	states := make([]*state, len(instructions))
+	maxStack, maxScope := 0, 0
+
+	var queue []int
+
+	enter := func(i int, s state) error {
+		if i < 0 || i >= len(instructions) {
+			return nil
+		}
+		if states[i] == nil {
+			states[i] = &s
+			queue = append(queue, i)
+
+			return nil
+		}
+		if *states[i] != s {
+			return fmt.Errorf("inconsistent stack at instruction %d: %d/%d and %d/%d", i, states[i].stack, states[i].scope, s.stack, s.scope)
+		}
+
+		return nil
+	}
+
+	if len(instructions) > 0 {
+		enter(0, state{})
+	}
+	for _, e := range body.Exceptions {
+		for i, offset := range offsets {
+			if uint32(offset) == e.Target {
+				if err := enter(i, state{stack: 1}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+
+		s := *states[i]
+		instruction := instructions[i]
+		pop, push := f.StackEffect(instruction)
+
+		if s.stack < pop {
+			return fmt.Errorf("stack underflow at instruction %d: %s", i, instruction.Opcode)
+		}
+
+		s.stack += push - pop
+		s.scope += scopeEffect(instruction.Opcode)
+
+		if s.scope < 0 {
+			return fmt.Errorf("scope underflow at instruction %d", i)
+		}
+		if s.stack > maxStack {
+			maxStack = s.stack
+		}
+		if s.scope > maxScope {
+			maxScope = s.scope
+		}
+		if states[i].stack > maxStack {
+			maxStack = states[i].stack
+		}
+		for _, b := range instruction.Branches {
+			if err := enter(b.Target, s); err != nil {
+				return err
+			}
+		}
+		if fallsThrough(instruction.Opcode) {
+			if err := enter(i+1, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	localCount := uint32(1)
+
+	if int(body.Method) < len(f.Methods) && f.Methods[body.Method] != nil {
+		m := f.Methods[body.Method]
+		localCount += uint32(len(m.ParamTypes))
+
+		if m.Flags&(MethodNeedArguments|MethodNeedRest) != 0 {
+			localCount += 1
+		}
+	}
+	for _, instruction := range instructions {
+		for _, register := range registers(instruction) {
+			if register+1 > localCount {
+				localCount = register + 1
+			}
+		}
+	}
+
+	body.MaxStack = uint32(maxStack)
+	body.LocalCount = localCount
+	body.MaxScopeDepth = body.InitScopeDepth + uint32(maxScope)
+
+	return nil
+}
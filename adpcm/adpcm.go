@@ -0,0 +1,165 @@
+// Package adpcm decodes the ADPCM variant used by SWF sounds.
+//
+// The data starts with a 2-bit code size followed by packets of 4096 samples
+// per channel. Each packet starts with a 16-bit sample and a 6-bit step index
+// for each channel, followed by the codes of the remaining 4095 samples
+// interleaved by channel. The last packet may be shorter.
+//
+// The package does not write WAV files, because the WAV encoder lives in the
+// root package. Wrap the result of Decode with swf.EncodeWAV16, or call
+// ExportWAV of swf.DefineSound and swf.SoundStream.
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SamplesPerPacket is the number of samples per channel in a full packet.
+const SamplesPerPacket = 4096
+
+var stepTable = [89]int32{
+	7, 8, 9, 10, 11, 12, 13, 14, 16, 17,
+	19, 21, 23, 25, 28, 31, 34, 37, 41, 45,
+	50, 55, 60, 66, 73, 80, 88, 97, 107, 118,
+	130, 143, 157, 173, 190, 209, 230, 253, 279, 307,
+	337, 371, 408, 449, 494, 544, 598, 658, 724, 796,
+	876, 963, 1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066,
+	2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358,
+	5894, 6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899,
+	15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794, 32767,
+}
+
+// indexTables holds the step index adjustments for 2 to 5 bits per sample,
+// indexed by the magnitude of the code.
+var indexTables = [4][]int32{
+	{-1, 2},
+	{-1, -1, 2, 4},
+	{-1, -1, -1, -1, 2, 4, 6, 8},
+	{-1, -1, -1, -1, -1, -1, -1, -1, 1, 2, 4, 6, 8, 10, 13, 16},
+}
+
+type bitReader struct {
+	data     []byte
+	position int
+}
+
+func (r *bitReader) remaining() int {
+	return len(r.data)*8 - r.position
+}
+
+func (r *bitReader) read(n int) uint32 {
+	var value uint32
+
+	for i := 0; i < n; i++ {
+		b := r.data[r.position/8] >> (7 - r.position%8) & 1
+		value = value<<1 | uint32(b)
+		r.position += 1
+	}
+
+	return value
+}
+
+type channelState struct {
+	predictor int32
+	stepIndex int32
+}
+
+// decode updates the state with the code and returns the new sample.
+func (s *channelState) decode(code uint32, codeSize int) int16 {
+	signMask := uint32(1) << (codeSize - 1)
+	step := stepTable[s.stepIndex]
+
+	// difference is (magnitude + 0.5) * step / 2^(codeSize-2) computed with
+	// shifts, the same way the player does.
+	var difference int32
+
+	for k := signMask >> 1; k != 0; k >>= 1 {
+		if code&k != 0 {
+			difference += step
+		}
+
+		step >>= 1
+	}
+
+	difference += step
+
+	if code&signMask != 0 {
+		s.predictor -= difference
+	} else {
+		s.predictor += difference
+	}
+	if s.predictor > 32767 {
+		s.predictor = 32767
+	}
+	if s.predictor < -32768 {
+		s.predictor = -32768
+	}
+
+	s.stepIndex += indexTables[codeSize-2][code&^signMask]
+
+	if s.stepIndex < 0 {
+		s.stepIndex = 0
+	}
+	if s.stepIndex > 88 {
+		s.stepIndex = 88
+	}
+
+	return int16(s.predictor)
+}
+
+// Decode decodes the ADPCMSOUNDDATA of DefineSound or SoundStreamBlock into
+// 16-bit samples. The samples of a stereo sound are interleaved. The padding
+// at the end of the data may produce a few extra samples, so the callers
+// that know the sample count should truncate the result.
+func Decode(data []byte, stereo bool) ([]int16, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("failed to decode ADPCM: data is empty")
+	}
+
+	channels := 1
+
+	if stereo {
+		channels = 2
+	}
+
+	reader := &bitReader{data: data}
+	codeSize := int(reader.read(2)) + 2
+	states := make([]channelState, channels)
+
+	var samples []int16
+
+	for reader.remaining() >= 22*channels {
+		for i := range states {
+			states[i].predictor = int32(int16(reader.read(16)))
+			// The 6-bit step index is at most 63, which is in stepTable.
+			states[i].stepIndex = int32(reader.read(6))
+			samples = append(samples, int16(states[i].predictor))
+		}
+		for count := 1; count < SamplesPerPacket && reader.remaining() >= codeSize*channels; count++ {
+			for i := range states {
+				samples = append(samples, states[i].decode(reader.read(codeSize), codeSize))
+			}
+		}
+	}
+
+	return samples, nil
+}
+
+// DecodeBytes decodes the data the same way as Decode and returns the samples
+// as little-endian bytes, which is the layout of a 16-bit PCM WAV file.
+func DecodeBytes(data []byte, stereo bool) ([]byte, error) {
+	samples, err := Decode(data, stereo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, len(samples)*2)
+
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(result[i*2:], uint16(sample))
+	}
+
+	return result, nil
+}
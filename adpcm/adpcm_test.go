@@ -0,0 +1,66 @@
+package adpcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type bitWriter struct {
+	data  []byte
+	count int
+}
+
+func (w *bitWriter) write(value uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.count%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+
+		w.data[len(w.data)-1] |= uint8(value>>i&1) << (7 - w.count%8)
+		w.count += 1
+	}
+}
+
+func TestDecodeMono(t *testing.T) {
+	w := &bitWriter{}
+
+	// 4 bits per sample, initial sample 1000 and step index 0.
+	w.write(2, 2)
+	w.write(1000, 16)
+	w.write(0, 6)
+	// +7 adds 7 + 3 + 1 + 0 = 11 and moves the step index by 8.
+	w.write(0b0111, 4)
+	// -0 subtracts 16 >> 3 = 2.
+	w.write(0b1000, 4)
+
+	samples, err := Decode(w.data, false)
+
+	require.NoError(t, err)
+	require.Equal(t, []int16{1000, 1011, 1009}, samples[:3])
+}
+
+func TestDecodeStereo(t *testing.T) {
+	w := &bitWriter{}
+
+	// 2 bits per sample.
+	w.write(0, 2)
+	w.write(uint32(uint16(100)), 16)
+	w.write(0, 6)
+	w.write(uint32(0xff9c), 16)
+	w.write(0, 6)
+	w.write(0b01, 2)
+	w.write(0b11, 2)
+
+	samples, err := Decode(w.data, true)
+
+	require.NoError(t, err)
+	// +1: 7 + 3 = 10, -1: -10.
+	require.Equal(t, []int16{100, -100, 110, -110}, samples[:4])
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	_, err := Decode(nil, false)
+
+	require.Error(t, err)
+}
@@ -0,0 +1,350 @@
+package avm1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action is a decoded ACTIONRECORD. The actions without payload are
+// represented by their ActionCode, and the others by the Action* types.
+type Action interface {
+	Code() ActionCode
+}
+
+// ActionRaw holds an action with payload which is unknown or whose payload
+// cannot be re-encoded to the same bytes, such as a payload with trailing
+// garbage.
+type ActionRaw struct {
+	ActionCode ActionCode
+	Data       []byte
+}
+
+func (a *ActionRaw) Code() ActionCode {
+	return a.ActionCode
+}
+
+type ActionGotoFrame struct {
+	Frame uint16
+}
+
+func (a *ActionGotoFrame) Code() ActionCode {
+	return CodeGotoFrame
+}
+
+type ActionGetURL struct {
+	URL    string
+	Target string
+}
+
+func (a *ActionGetURL) Code() ActionCode {
+	return CodeGetURL
+}
+
+type ActionStoreRegister struct {
+	Register uint8
+}
+
+func (a *ActionStoreRegister) Code() ActionCode {
+	return CodeStoreRegister
+}
+
+type ActionConstantPool struct {
+	Constants []string
+}
+
+func (a *ActionConstantPool) Code() ActionCode {
+	return CodeConstantPool
+}
+
+type ActionWaitForFrame struct {
+	Frame     uint16
+	SkipCount uint8
+}
+
+func (a *ActionWaitForFrame) Code() ActionCode {
+	return CodeWaitForFrame
+}
+
+type ActionSetTarget struct {
+	TargetName string
+}
+
+func (a *ActionSetTarget) Code() ActionCode {
+	return CodeSetTarget
+}
+
+type ActionGotoLabel struct {
+	Label string
+}
+
+func (a *ActionGotoLabel) Code() ActionCode {
+	return CodeGotoLabel
+}
+
+type ActionWaitForFrame2 struct {
+	SkipCount uint8
+}
+
+func (a *ActionWaitForFrame2) Code() ActionCode {
+	return CodeWaitForFrame2
+}
+
+// Block is a range of actions which follows an action and belongs to it,
+// such as the body of a function. Size is the length in bytes as encoded and
+// Count is the number of actions. Count is -1 when Size does not end at the
+// boundary of an action.
+type Block struct {
+	Size  uint16
+	Count int
+}
+
+// DefineFunction2Flags tells which variables are preloaded into registers
+// and which are suppressed.
+type DefineFunction2Flags uint16
+
+const (
+	PreloadParent     DefineFunction2Flags = 1 << 15
+	PreloadRoot       DefineFunction2Flags = 1 << 14
+	SuppressSuper     DefineFunction2Flags = 1 << 13
+	PreloadSuper      DefineFunction2Flags = 1 << 12
+	SuppressArguments DefineFunction2Flags = 1 << 11
+	PreloadArguments  DefineFunction2Flags = 1 << 10
+	SuppressThis      DefineFunction2Flags = 1 << 9
+	PreloadThis       DefineFunction2Flags = 1 << 8
+	PreloadGlobal     DefineFunction2Flags = 1 << 0
+)
+
+func (f DefineFunction2Flags) Contains(flag DefineFunction2Flags) bool {
+	return f&flag == flag
+}
+
+var flagNames = []struct {
+	flag DefineFunction2Flags
+	name string
+}{
+	{PreloadParent, "PreloadParent"},
+	{PreloadRoot, "PreloadRoot"},
+	{SuppressSuper, "SuppressSuper"},
+	{PreloadSuper, "PreloadSuper"},
+	{SuppressArguments, "SuppressArguments"},
+	{PreloadArguments, "PreloadArguments"},
+	{SuppressThis, "SuppressThis"},
+	{PreloadThis, "PreloadThis"},
+	{PreloadGlobal, "PreloadGlobal"},
+}
+
+func (f DefineFunction2Flags) String() string {
+	var names []string
+
+	for _, flag := range flagNames {
+		if f.Contains(flag.flag) {
+			names = append(names, flag.name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// RegisterParam is a parameter of DefineFunction2. The parameter is stored in
+// the register, or in a variable when Register is 0.
+type RegisterParam struct {
+	Register uint8
+	Name     string
+}
+
+type ActionDefineFunction2 struct {
+	FunctionName  string
+	RegisterCount uint8
+	Flags         DefineFunction2Flags
+	Parameters    []RegisterParam
+	Body          Block
+}
+
+func (a *ActionDefineFunction2) Code() ActionCode {
+	return CodeDefineFunction2
+}
+
+// ActionTry is followed by the try block, the catch block and the finally
+// block. The catch variable is stored in CatchRegister when CatchInRegister
+// is true, and in the variable CatchName otherwise.
+type ActionTry struct {
+	CatchInRegister bool
+	HasFinallyBlock bool
+	HasCatchBlock   bool
+	CatchName       string
+	CatchRegister   uint8
+	Try             Block
+	Catch           Block
+	Finally         Block
+}
+
+func (a *ActionTry) Code() ActionCode {
+	return CodeTry
+}
+
+type ActionWith struct {
+	Body Block
+}
+
+func (a *ActionWith) Code() ActionCode {
+	return CodeWith
+}
+
+// PushType is the type of a value pushed by ActionPush.
+type PushType uint8
+
+const (
+	PushString     PushType = 0
+	PushFloat      PushType = 1
+	PushNull       PushType = 2
+	PushUndefined  PushType = 3
+	PushRegister   PushType = 4
+	PushBoolean    PushType = 5
+	PushDouble     PushType = 6
+	PushInteger    PushType = 7
+	PushConstant8  PushType = 8
+	PushConstant16 PushType = 9
+)
+
+func (t PushType) String() string {
+	switch t {
+	case PushString:
+		return "String"
+	case PushFloat:
+		return "Float"
+	case PushNull:
+		return "Null"
+	case PushUndefined:
+		return "Undefined"
+	case PushRegister:
+		return "Register"
+	case PushBoolean:
+		return "Boolean"
+	case PushDouble:
+		return "Double"
+	case PushInteger:
+		return "Integer"
+	case PushConstant8:
+		return "Constant8"
+	case PushConstant16:
+		return "Constant16"
+	default:
+		return fmt.Sprintf("PushType(%d)", uint8(t))
+	}
+}
+
+// PushValue is a value pushed by ActionPush. Only the field for the type is
+// used.
+type PushValue struct {
+	Type     PushType
+	String   string
+	Float    float32
+	Double   float64
+	Integer  int32
+	Register uint8
+	Boolean  bool
+	// Constant is an index of the constant pool.
+	Constant uint16
+}
+
+func (v PushValue) GoString() string {
+	switch v.Type {
+	case PushString:
+		return fmt.Sprintf("%q", v.String)
+	case PushFloat:
+		return fmt.Sprintf("%vf", v.Float)
+	case PushNull:
+		return "null"
+	case PushUndefined:
+		return "undefined"
+	case PushRegister:
+		return fmt.Sprintf("r%d", v.Register)
+	case PushBoolean:
+		return fmt.Sprint(v.Boolean)
+	case PushDouble:
+		return fmt.Sprint(v.Double)
+	case PushInteger:
+		return fmt.Sprint(v.Integer)
+	case PushConstant8, PushConstant16:
+		return fmt.Sprintf("c%d", v.Constant)
+	default:
+		return v.Type.String()
+	}
+}
+
+type ActionPush struct {
+	Values []PushValue
+}
+
+func (a *ActionPush) Code() ActionCode {
+	return CodePush
+}
+
+// Branch is the destination of ActionJump and ActionIf. Offset is counted in
+// bytes from the end of the branch action as encoded, and Target is the index
+// of the destination action. Target is len(actions) when the branch leaves
+// the actions, and -1 when Offset does not hit the boundary of an action.
+type Branch struct {
+	Offset int16
+	Target int
+}
+
+type ActionJump struct {
+	Branch
+}
+
+func (a *ActionJump) Code() ActionCode {
+	return CodeJump
+}
+
+type ActionIf struct {
+	Branch
+}
+
+func (a *ActionIf) Code() ActionCode {
+	return CodeIf
+}
+
+// Send methods of ActionGetURL2.
+const (
+	SendVarsNone = 0
+	SendVarsGet  = 1
+	SendVarsPost = 2
+)
+
+type ActionGetURL2 struct {
+	SendVarsMethod uint8
+	LoadTarget     bool
+	LoadVariables  bool
+}
+
+func (a *ActionGetURL2) Code() ActionCode {
+	return CodeGetURL2
+}
+
+type ActionDefineFunction struct {
+	FunctionName string
+	Params       []string
+	Body         Block
+}
+
+func (a *ActionDefineFunction) Code() ActionCode {
+	return CodeDefineFunction
+}
+
+// ActionCall has a length field even though it has no payload.
+type ActionCall struct{}
+
+func (a *ActionCall) Code() ActionCode {
+	return CodeCall
+}
+
+type ActionGotoFrame2 struct {
+	HasSceneBias bool
+	Play         bool
+	SceneBias    uint16
+}
+
+func (a *ActionGotoFrame2) Code() ActionCode {
+	return CodeGotoFrame2
+}
@@ -0,0 +1,1194 @@
+package avm1
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The assembly has one action per line. The mnemonic is the name of the
+// action code with a lowercase first letter, such as getVariable. Branches
+// and the ends of blocks refer to labels, which are written as "L1:" on their
+// own lines. A branch or a block which does not end at the boundary of an
+// action is written with a byte count such as "#-3" instead of a label.
+// Everything after ";" is a comment.
+//
+//	constantPool "x"
+//	push c:0, 1, 2.5, float:0.5, "s", r:1, true, null, undefined
+//	defineFunction2 "f" (r:1 "a", r:0 "b") registers=2 flags=PreloadThis end=L1
+//	  push r:1
+//	  return
+//	L1:
+//	if L2
+//	try "e" hasCatch catch=L3 finally=L3 end=L4
+//	raw 0xf0 2a00
+
+// Disassemble decodes the ACTIONRECORD stream and formats it as assembly.
+func Disassemble(data []byte) (string, error) {
+	actions, err := Decode(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	return Format(actions)
+}
+
+// Assemble parses the assembly and encodes it into an ACTIONRECORD stream.
+func Assemble(text string) ([]byte, error) {
+	actions, err := Parse(text)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(actions)
+}
+
+func mnemonic(code ActionCode) string {
+	name, ok := codeNames[code]
+
+	if !ok {
+		return fmt.Sprintf("op 0x%02x", uint8(code))
+	}
+
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// blocksOf returns the blocks which follow the action.
+func blocksOf(action Action) []*Block {
+	switch v := action.(type) {
+	case *ActionDefineFunction:
+		return []*Block{&v.Body}
+	case *ActionDefineFunction2:
+		return []*Block{&v.Body}
+	case *ActionWith:
+		return []*Block{&v.Body}
+	case *ActionTry:
+		return []*Block{&v.Try, &v.Catch, &v.Finally}
+	default:
+		return nil
+	}
+}
+
+// branchOf returns the branch of ActionJump and ActionIf.
+func branchOf(action Action) *Branch {
+	switch v := action.(type) {
+	case *ActionJump:
+		return &v.Branch
+	case *ActionIf:
+		return &v.Branch
+	default:
+		return nil
+	}
+}
+
+// blockEnds returns the index of the action after each block, or nil when a
+// block has no count.
+func blockEnds(i int, blocks []*Block) []int {
+	var ends []int
+
+	start := i + 1
+
+	for _, block := range blocks {
+		if block.Count < 0 {
+			return nil
+		}
+
+		start += block.Count
+		ends = append(ends, start)
+	}
+
+	return ends
+}
+
+// Format formats the actions as assembly.
+func Format(actions []Action) (string, error) {
+	targets := map[int]bool{}
+	depths := make([]int, len(actions)+1)
+
+	for i, action := range actions {
+		if branch := branchOf(action); branch != nil && branch.Target >= 0 {
+			targets[branch.Target] = true
+		}
+
+		ends := blockEnds(i, blocksOf(action))
+
+		for _, end := range ends {
+			targets[end] = true
+		}
+		if len(ends) > 0 {
+			for j := i + 1; j < ends[len(ends)-1] && j < len(depths); j++ {
+				depths[j] += 1
+			}
+		}
+	}
+
+	var indices []int
+
+	for index := range targets {
+		if index > len(actions) {
+			return "", fmt.Errorf("target %d is out of range", index)
+		}
+
+		indices = append(indices, index)
+	}
+
+	sort.Ints(indices)
+
+	labels := map[int]string{}
+
+	for n, index := range indices {
+		labels[index] = fmt.Sprintf("L%d", n+1)
+	}
+
+	builder := &strings.Builder{}
+
+	for i := 0; i <= len(actions); i++ {
+		if label, ok := labels[i]; ok {
+			fmt.Fprintf(builder, "%s:\n", label)
+		}
+		if i == len(actions) {
+			break
+		}
+
+		line, err := formatAction(actions[i], i, labels)
+
+		if err != nil {
+			return "", fmt.Errorf("failed to format actions[%d]: %w", i, err)
+		}
+
+		builder.WriteString(strings.Repeat("  ", depths[i]))
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), nil
+}
+
+func formatBranch(branch Branch, labels map[int]string) string {
+	if branch.Target < 0 {
+		return fmt.Sprintf("#%d", branch.Offset)
+	}
+
+	return labels[branch.Target]
+}
+
+// formatBlocks formats the ends of the blocks as key=value pairs.
+func formatBlocks(i int, blocks []*Block, keys []string, labels map[int]string) string {
+	ends := blockEnds(i, blocks)
+	parts := make([]string, len(blocks))
+
+	for j, block := range blocks {
+		if ends == nil {
+			parts[j] = fmt.Sprintf("%s=#%d", keys[j], block.Size)
+		} else {
+			parts[j] = fmt.Sprintf("%s=%s", keys[j], labels[ends[j]])
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func formatAction(action Action, i int, labels map[int]string) (string, error) {
+	name := mnemonic(action.Code())
+
+	switch v := action.(type) {
+	case ActionCode:
+		if v.HasPayload() {
+			return "", fmt.Errorf("code 0x%02x must have payload", uint8(v))
+		}
+
+		return name, nil
+	case *ActionRaw:
+		return fmt.Sprintf("raw 0x%02x %s", uint8(v.ActionCode), hex.EncodeToString(v.Data)), nil
+	case *ActionGotoFrame:
+		return fmt.Sprintf("%s %d", name, v.Frame), nil
+	case *ActionGetURL:
+		return fmt.Sprintf("%s %s %s", name, strconv.Quote(v.URL), strconv.Quote(v.Target)), nil
+	case *ActionStoreRegister:
+		return fmt.Sprintf("%s r:%d", name, v.Register), nil
+	case *ActionConstantPool:
+		constants := make([]string, len(v.Constants))
+
+		for j, constant := range v.Constants {
+			constants[j] = strconv.Quote(constant)
+		}
+
+		return strings.TrimSpace(name + " " + strings.Join(constants, ", ")), nil
+	case *ActionWaitForFrame:
+		return fmt.Sprintf("%s %d %d", name, v.Frame, v.SkipCount), nil
+	case *ActionSetTarget:
+		return fmt.Sprintf("%s %s", name, strconv.Quote(v.TargetName)), nil
+	case *ActionGotoLabel:
+		return fmt.Sprintf("%s %s", name, strconv.Quote(v.Label)), nil
+	case *ActionWaitForFrame2:
+		return fmt.Sprintf("%s %d", name, v.SkipCount), nil
+	case *ActionDefineFunction2:
+		params := make([]string, len(v.Parameters))
+
+		for j, param := range v.Parameters {
+			params[j] = fmt.Sprintf("r:%d %s", param.Register, strconv.Quote(param.Name))
+		}
+
+		line := fmt.Sprintf("%s %s (%s) registers=%d", name, strconv.Quote(v.FunctionName), strings.Join(params, ", "), v.RegisterCount)
+
+		if v.Flags != 0 {
+			line += " flags=" + formatFlags(v.Flags)
+		}
+
+		return line + " " + formatBlocks(i, blocksOf(v), []string{"end"}, labels), nil
+	case *ActionTry:
+		line := name
+
+		if v.CatchInRegister {
+			line += fmt.Sprintf(" r:%d", v.CatchRegister)
+		} else {
+			line += " " + strconv.Quote(v.CatchName)
+		}
+		if v.HasCatchBlock {
+			line += " hasCatch"
+		}
+		if v.HasFinallyBlock {
+			line += " hasFinally"
+		}
+
+		return line + " " + formatBlocks(i, blocksOf(v), []string{"catch", "finally", "end"}, labels), nil
+	case *ActionWith:
+		return name + " " + formatBlocks(i, blocksOf(v), []string{"end"}, labels), nil
+	case *ActionPush:
+		values := make([]string, len(v.Values))
+
+		for j, value := range v.Values {
+			s, err := formatPushValue(value)
+
+			if err != nil {
+				return "", err
+			}
+
+			values[j] = s
+		}
+
+		return name + " " + strings.Join(values, ", "), nil
+	case *ActionJump:
+		return name + " " + formatBranch(v.Branch, labels), nil
+	case *ActionIf:
+		return name + " " + formatBranch(v.Branch, labels), nil
+	case *ActionGetURL2:
+		line := fmt.Sprintf("%s method=%d", name, v.SendVarsMethod)
+
+		if v.LoadTarget {
+			line += " loadTarget"
+		}
+		if v.LoadVariables {
+			line += " loadVariables"
+		}
+
+		return line, nil
+	case *ActionDefineFunction:
+		params := make([]string, len(v.Params))
+
+		for j, param := range v.Params {
+			params[j] = strconv.Quote(param)
+		}
+
+		return fmt.Sprintf("%s %s (%s) %s", name, strconv.Quote(v.FunctionName), strings.Join(params, ", "), formatBlocks(i, blocksOf(v), []string{"end"}, labels)), nil
+	case *ActionCall:
+		return name, nil
+	case *ActionGotoFrame2:
+		line := name
+
+		if v.Play {
+			line += " play"
+		}
+		if v.HasSceneBias {
+			line += fmt.Sprintf(" sceneBias=%d", v.SceneBias)
+		}
+
+		return line, nil
+	default:
+		return "", fmt.Errorf("unknown action type %T", action)
+	}
+}
+
+// formatFlags joins the names of the flags. Reserved bits are written in
+// hexadecimal.
+func formatFlags(flags DefineFunction2Flags) string {
+	var names []string
+
+	for _, f := range flagNames {
+		if flags.Contains(f.flag) {
+			names = append(names, f.name)
+			flags &^= f.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("0x%04x", uint16(flags)))
+	}
+
+	return strings.Join(names, "|")
+}
+
+func formatNumber(value float64, bitSize int) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	}
+
+	s := strconv.FormatFloat(value, 'g', -1, bitSize)
+
+	if !strings.ContainsAny(s, ".eN") {
+		s += ".0"
+	}
+
+	return s
+}
+
+func formatPushValue(value PushValue) (string, error) {
+	switch value.Type {
+	case PushString:
+		return strconv.Quote(value.String), nil
+	case PushFloat:
+		if math.IsNaN(float64(value.Float)) {
+			return fmt.Sprintf("float:0x%08x", math.Float32bits(value.Float)), nil
+		}
+
+		return fmt.Sprintf("float:%s", formatNumber(float64(value.Float), 32)), nil
+	case PushNull:
+		return "null", nil
+	case PushUndefined:
+		return "undefined", nil
+	case PushRegister:
+		return fmt.Sprintf("r:%d", value.Register), nil
+	case PushBoolean:
+		return strconv.FormatBool(value.Boolean), nil
+	case PushDouble:
+		if math.IsNaN(value.Double) {
+			if math.Float64bits(value.Double) == math.Float64bits(math.NaN()) {
+				return "NaN", nil
+			}
+
+			return fmt.Sprintf("double:0x%016x", math.Float64bits(value.Double)), nil
+		}
+
+		return formatNumber(value.Double, 64), nil
+	case PushInteger:
+		return strconv.Itoa(int(value.Integer)), nil
+	case PushConstant8:
+		return fmt.Sprintf("c:%d", value.Constant), nil
+	case PushConstant16:
+		return fmt.Sprintf("c16:%d", value.Constant), nil
+	default:
+		return "", fmt.Errorf("unknown push type: %s", value.Type)
+	}
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(line); {
+		c := line[i]
+
+		switch {
+		case c == ';':
+			return tokens, nil
+		case c == ' ' || c == '\t' || c == '\r':
+			i += 1
+		case c == '"':
+			quoted, err := strconv.QuotedPrefix(line[i:])
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at column %d", i+1)
+			}
+
+			text, err := strconv.Unquote(quoted)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at column %d", i+1)
+			}
+
+			tokens = append(tokens, token{text: text, quoted: true})
+			i += len(quoted)
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, token{text: string(c)})
+			i += 1
+		default:
+			j := i
+
+			for j < len(line) && !strings.ContainsRune(" \t\r;\"(),", rune(line[j])) {
+				j += 1
+			}
+
+			tokens = append(tokens, token{text: line[i:j]})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// reference is a label or a byte count which is resolved after all labels
+// are known.
+type reference struct {
+	label  string
+	offset int
+	raw    bool
+}
+
+func parseReference(s string) (reference, error) {
+	if strings.HasPrefix(s, "#") {
+		offset, err := strconv.Atoi(s[1:])
+
+		if err != nil {
+			return reference{}, fmt.Errorf("invalid byte count %q", s)
+		}
+
+		return reference{offset: offset, raw: true}, nil
+	}
+	if s == "" {
+		return reference{}, fmt.Errorf("reference is empty")
+	}
+
+	return reference{label: s}, nil
+}
+
+type pendingBranch struct {
+	line   int
+	branch *Branch
+	ref    reference
+}
+
+type pendingBlocks struct {
+	line   int
+	index  int
+	blocks []*Block
+	refs   []reference
+}
+
+// parser holds the state of Parse.
+type parser struct {
+	actions  []Action
+	labels   map[string]int
+	branches []pendingBranch
+	blocks   []pendingBlocks
+}
+
+// Parse parses the assembly into actions. The branch offsets and the block
+// sizes are computed from the labels.
+func Parse(text string) ([]Action, error) {
+	p := &parser{labels: map[string]int{}}
+
+	for n, line := range strings.Split(text, "\n") {
+		if err := p.parseLine(n+1, line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+	}
+	for _, pending := range p.branches {
+		if pending.ref.raw {
+			if pending.ref.offset < math.MinInt16 || pending.ref.offset > math.MaxInt16 {
+				return nil, fmt.Errorf("line %d: too far branch: %d bytes", pending.line, pending.ref.offset)
+			}
+
+			pending.branch.Offset = int16(pending.ref.offset)
+			pending.branch.Target = -1
+
+			continue
+		}
+
+		index, ok := p.labels[pending.ref.label]
+
+		if !ok {
+			return nil, fmt.Errorf("line %d: undefined label %q", pending.line, pending.ref.label)
+		}
+
+		pending.branch.Target = index
+	}
+	for _, pending := range p.blocks {
+		if err := p.resolveBlocks(pending); err != nil {
+			return nil, fmt.Errorf("line %d: %w", pending.line, err)
+		}
+	}
+	if err := Relocate(p.actions); err != nil {
+		return nil, err
+	}
+
+	return p.actions, nil
+}
+
+func (p *parser) resolveBlocks(pending pendingBlocks) error {
+	raw := pending.refs[0].raw
+
+	for _, ref := range pending.refs {
+		if ref.raw != raw {
+			return fmt.Errorf("cannot mix labels and byte counts")
+		}
+	}
+
+	start := pending.index + 1
+
+	for i, ref := range pending.refs {
+		block := pending.blocks[i]
+
+		if raw {
+			if ref.offset < 0 || ref.offset > 0xffff {
+				return fmt.Errorf("invalid block size: %d", ref.offset)
+			}
+
+			block.Size = uint16(ref.offset)
+			block.Count = -1
+
+			continue
+		}
+
+		end, ok := p.labels[ref.label]
+
+		if !ok {
+			return fmt.Errorf("undefined label %q", ref.label)
+		}
+		if end < start {
+			return fmt.Errorf("label %q is before the start of the block", ref.label)
+		}
+
+		block.Count = end - start
+		start = end
+	}
+
+	return nil
+}
+
+func (p *parser) parseLine(n int, line string) error {
+	tokens, err := tokenize(line)
+
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	if first := tokens[0]; len(tokens) == 1 && !first.quoted && strings.HasSuffix(first.text, ":") {
+		label := strings.TrimSuffix(first.text, ":")
+
+		if _, ok := p.labels[label]; ok {
+			return fmt.Errorf("duplicate label %q", label)
+		}
+
+		p.labels[label] = len(p.actions)
+
+		return nil
+	}
+
+	action, err := p.parseAction(n, tokens)
+
+	if err != nil {
+		return err
+	}
+
+	p.actions = append(p.actions, action)
+
+	return nil
+}
+
+// options splits the tokens into key=value options and flag words.
+func options(tokens []token) (map[string]string, error) {
+	result := map[string]string{}
+
+	for _, t := range tokens {
+		if t.quoted {
+			return nil, fmt.Errorf("unexpected string %q", t.text)
+		}
+
+		key, value, _ := strings.Cut(t.text, "=")
+
+		if _, ok := result[key]; ok {
+			return nil, fmt.Errorf("duplicate option %q", key)
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func takeOption(opts map[string]string, key string) (string, bool) {
+	value, ok := opts[key]
+
+	delete(opts, key)
+
+	return value, ok
+}
+
+func checkOptions(opts map[string]string) error {
+	for key := range opts {
+		return fmt.Errorf("unknown option %q", key)
+	}
+
+	return nil
+}
+
+func parseUint(s string, bitSize int) (uint64, error) {
+	value, err := strconv.ParseUint(s, 0, bitSize)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	return value, nil
+}
+
+func parseRegister(t token) (uint8, error) {
+	if t.quoted || !strings.HasPrefix(t.text, "r:") {
+		return 0, fmt.Errorf("expected register but got %q", t.text)
+	}
+
+	value, err := parseUint(t.text[2:], 8)
+
+	return uint8(value), err
+}
+
+func expectString(tokens []token, i int) (string, error) {
+	if i >= len(tokens) || !tokens[i].quoted {
+		return "", fmt.Errorf("expected string")
+	}
+
+	return tokens[i].text, nil
+}
+
+// splitList splits the tokens between parentheses or of a comma separated
+// list into the items.
+func splitList(tokens []token) [][]token {
+	var items [][]token
+	var item []token
+
+	for _, t := range tokens {
+		if !t.quoted && t.text == "," {
+			items = append(items, item)
+			item = nil
+
+			continue
+		}
+
+		item = append(item, t)
+	}
+	if item != nil || items != nil {
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// parenthesized returns the tokens between the parentheses starting at i and
+// the index after the closing parenthesis.
+func parenthesized(tokens []token, i int) ([]token, int, error) {
+	if i >= len(tokens) || tokens[i].quoted || tokens[i].text != "(" {
+		return nil, 0, fmt.Errorf("expected (")
+	}
+
+	for j := i + 1; j < len(tokens); j++ {
+		if !tokens[j].quoted && tokens[j].text == ")" {
+			return tokens[i+1 : j], j + 1, nil
+		}
+	}
+
+	return nil, 0, fmt.Errorf("expected )")
+}
+
+func (p *parser) addBlocks(n int, action Action, opts map[string]string, keys []string) error {
+	pending := pendingBlocks{line: n, index: len(p.actions), blocks: blocksOf(action)}
+
+	for _, key := range keys {
+		value, ok := takeOption(opts, key)
+
+		if !ok {
+			return fmt.Errorf("missing option %q", key)
+		}
+
+		ref, err := parseReference(value)
+
+		if err != nil {
+			return err
+		}
+
+		pending.refs = append(pending.refs, ref)
+	}
+
+	p.blocks = append(p.blocks, pending)
+
+	return nil
+}
+
+func (p *parser) addBranch(n int, branch *Branch, tokens []token) error {
+	if len(tokens) != 1 || tokens[0].quoted {
+		return fmt.Errorf("expected a label or a byte count")
+	}
+
+	ref, err := parseReference(tokens[0].text)
+
+	if err != nil {
+		return err
+	}
+
+	p.branches = append(p.branches, pendingBranch{line: n, branch: branch, ref: ref})
+
+	return nil
+}
+
+func (p *parser) parseAction(n int, tokens []token) (Action, error) {
+	if tokens[0].quoted {
+		return nil, fmt.Errorf("expected mnemonic but got string")
+	}
+
+	name := tokens[0].text
+	args := tokens[1:]
+
+	switch name {
+	case "op":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("op takes a code")
+		}
+
+		code, err := parseUint(args[0].text, 8)
+
+		if err != nil {
+			return nil, err
+		}
+		if ActionCode(code).HasPayload() {
+			return nil, fmt.Errorf("code 0x%02x has payload, use raw", code)
+		}
+
+		return ActionCode(code), nil
+	case "raw":
+		if len(args) < 1 || len(args) > 2 {
+			return nil, fmt.Errorf("raw takes a code and hexadecimal data")
+		}
+
+		code, err := parseUint(args[0].text, 8)
+
+		if err != nil {
+			return nil, err
+		}
+		if !ActionCode(code).HasPayload() {
+			return nil, fmt.Errorf("code 0x%02x has no payload, use op", code)
+		}
+
+		var data []byte
+
+		if len(args) == 2 {
+			if data, err = hex.DecodeString(args[1].text); err != nil {
+				return nil, fmt.Errorf("invalid hexadecimal data %q", args[1].text)
+			}
+		}
+
+		return &ActionRaw{ActionCode: ActionCode(code), Data: data}, nil
+	}
+
+	code, ok := LookupCode(string(unicode.ToUpper(rune(name[0]))) + name[1:])
+
+	if !ok || mnemonic(code) != name {
+		return nil, fmt.Errorf("unknown mnemonic %q", name)
+	}
+	if !code.HasPayload() {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("%s takes no operand", name)
+		}
+
+		return code, nil
+	}
+
+	switch code {
+	case CodeGotoFrame:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes a frame", name)
+		}
+
+		frame, err := parseUint(args[0].text, 16)
+
+		return &ActionGotoFrame{Frame: uint16(frame)}, err
+	case CodeGetURL:
+		url, err := expectString(args, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		target, err := expectString(args, 1)
+
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s takes a URL and a target", name)
+		}
+
+		return &ActionGetURL{URL: url, Target: target}, nil
+	case CodeStoreRegister:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes a register", name)
+		}
+
+		register, err := parseRegister(args[0])
+
+		return &ActionStoreRegister{Register: register}, err
+	case CodeConstantPool:
+		result := &ActionConstantPool{Constants: []string{}}
+
+		for _, item := range splitList(args) {
+			if len(item) != 1 || !item[0].quoted {
+				return nil, fmt.Errorf("expected string")
+			}
+
+			result.Constants = append(result.Constants, item[0].text)
+		}
+
+		return result, nil
+	case CodeWaitForFrame:
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s takes a frame and a skip count", name)
+		}
+
+		frame, err := parseUint(args[0].text, 16)
+
+		if err != nil {
+			return nil, err
+		}
+
+		skipCount, err := parseUint(args[1].text, 8)
+
+		return &ActionWaitForFrame{Frame: uint16(frame), SkipCount: uint8(skipCount)}, err
+	case CodeSetTarget:
+		target, err := expectString(args, 0)
+
+		if err == nil && len(args) != 1 {
+			err = fmt.Errorf("%s takes a target", name)
+		}
+
+		return &ActionSetTarget{TargetName: target}, err
+	case CodeGotoLabel:
+		label, err := expectString(args, 0)
+
+		if err == nil && len(args) != 1 {
+			err = fmt.Errorf("%s takes a label", name)
+		}
+
+		return &ActionGotoLabel{Label: label}, err
+	case CodeWaitForFrame2:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s takes a skip count", name)
+		}
+
+		skipCount, err := parseUint(args[0].text, 8)
+
+		return &ActionWaitForFrame2{SkipCount: uint8(skipCount)}, err
+	case CodeDefineFunction, CodeDefineFunction2:
+		return p.parseFunction(n, code, args)
+	case CodeTry:
+		return p.parseTry(n, args)
+	case CodeWith:
+		result := &ActionWith{}
+
+		opts, err := options(args)
+
+		if err != nil {
+			return nil, err
+		}
+		if err := p.addBlocks(n, result, opts, []string{"end"}); err != nil {
+			return nil, err
+		}
+
+		return result, checkOptions(opts)
+	case CodePush:
+		result := &ActionPush{}
+
+		for _, item := range splitList(args) {
+			if len(item) != 1 {
+				return nil, fmt.Errorf("expected a single value")
+			}
+
+			value, err := parsePushValue(item[0])
+
+			if err != nil {
+				return nil, err
+			}
+
+			result.Values = append(result.Values, value)
+		}
+
+		return result, nil
+	case CodeJump:
+		result := &ActionJump{}
+
+		return result, p.addBranch(n, &result.Branch, args)
+	case CodeIf:
+		result := &ActionIf{}
+
+		return result, p.addBranch(n, &result.Branch, args)
+	case CodeGetURL2:
+		opts, err := options(args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result := &ActionGetURL2{}
+
+		if value, ok := takeOption(opts, "method"); ok {
+			method, err := parseUint(value, 2)
+
+			if err != nil {
+				return nil, err
+			}
+
+			result.SendVarsMethod = uint8(method)
+		}
+
+		_, result.LoadTarget = takeOption(opts, "loadTarget")
+		_, result.LoadVariables = takeOption(opts, "loadVariables")
+
+		return result, checkOptions(opts)
+	case CodeCall:
+		if len(args) != 0 {
+			return nil, fmt.Errorf("%s takes no operand", name)
+		}
+
+		return &ActionCall{}, nil
+	case CodeGotoFrame2:
+		opts, err := options(args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result := &ActionGotoFrame2{}
+
+		_, result.Play = takeOption(opts, "play")
+
+		if value, ok := takeOption(opts, "sceneBias"); ok {
+			sceneBias, err := parseUint(value, 16)
+
+			if err != nil {
+				return nil, err
+			}
+
+			result.HasSceneBias = true
+			result.SceneBias = uint16(sceneBias)
+		}
+
+		return result, checkOptions(opts)
+	default:
+		return nil, fmt.Errorf("unknown mnemonic %q", name)
+	}
+}
+
+func (p *parser) parseFunction(n int, code ActionCode, args []token) (Action, error) {
+	functionName, err := expectString(args, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	params, next, err := parenthesized(args, 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := options(args[next:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result Action
+
+	if code == CodeDefineFunction {
+		function := &ActionDefineFunction{FunctionName: functionName, Params: []string{}}
+
+		for _, item := range splitList(params) {
+			if len(item) != 1 || !item[0].quoted {
+				return nil, fmt.Errorf("expected parameter name")
+			}
+
+			function.Params = append(function.Params, item[0].text)
+		}
+
+		result = function
+	} else {
+		function := &ActionDefineFunction2{FunctionName: functionName, Parameters: []RegisterParam{}}
+
+		for _, item := range splitList(params) {
+			if len(item) != 2 || !item[1].quoted {
+				return nil, fmt.Errorf("expected register and parameter name")
+			}
+
+			register, err := parseRegister(item[0])
+
+			if err != nil {
+				return nil, err
+			}
+
+			function.Parameters = append(function.Parameters, RegisterParam{Register: register, Name: item[1].text})
+		}
+		if value, ok := takeOption(opts, "registers"); ok {
+			registers, err := parseUint(value, 8)
+
+			if err != nil {
+				return nil, err
+			}
+
+			function.RegisterCount = uint8(registers)
+		}
+		if value, ok := takeOption(opts, "flags"); ok {
+			if function.Flags, err = parseFlags(value); err != nil {
+				return nil, err
+			}
+		}
+
+		result = function
+	}
+	if err := p.addBlocks(n, result, opts, []string{"end"}); err != nil {
+		return nil, err
+	}
+
+	return result, checkOptions(opts)
+}
+
+func (p *parser) parseTry(n int, args []token) (Action, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("try takes a catch variable or register")
+	}
+
+	result := &ActionTry{}
+
+	if args[0].quoted {
+		result.CatchName = args[0].text
+	} else {
+		register, err := parseRegister(args[0])
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.CatchInRegister = true
+		result.CatchRegister = register
+	}
+
+	opts, err := options(args[1:])
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, result.HasCatchBlock = takeOption(opts, "hasCatch")
+	_, result.HasFinallyBlock = takeOption(opts, "hasFinally")
+
+	if err := p.addBlocks(n, result, opts, []string{"catch", "finally", "end"}); err != nil {
+		return nil, err
+	}
+
+	return result, checkOptions(opts)
+}
+
+func parseFlags(s string) (DefineFunction2Flags, error) {
+	var flags DefineFunction2Flags
+
+	for _, name := range strings.Split(s, "|") {
+		found := false
+
+		for _, f := range flagNames {
+			if f.name == name {
+				flags |= f.flag
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+
+		value, err := parseUint(name, 16)
+
+		if err != nil {
+			return 0, fmt.Errorf("unknown flag %q", name)
+		}
+
+		flags |= DefineFunction2Flags(value)
+	}
+
+	return flags, nil
+}
+
+func parseNumber(s string, bitSize int) (float64, error) {
+	switch s {
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+
+	value, err := strconv.ParseFloat(s, bitSize)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	return value, nil
+}
+
+func parsePushValue(t token) (PushValue, error) {
+	if t.quoted {
+		return PushValue{Type: PushString, String: t.text}, nil
+	}
+
+	s := t.text
+
+	switch {
+	case s == "null":
+		return PushValue{Type: PushNull}, nil
+	case s == "undefined":
+		return PushValue{Type: PushUndefined}, nil
+	case s == "true" || s == "false":
+		return PushValue{Type: PushBoolean, Boolean: s == "true"}, nil
+	case strings.HasPrefix(s, "r:"):
+		register, err := parseRegister(t)
+
+		return PushValue{Type: PushRegister, Register: register}, err
+	case strings.HasPrefix(s, "c16:"):
+		index, err := parseUint(s[4:], 16)
+
+		return PushValue{Type: PushConstant16, Constant: uint16(index)}, err
+	case strings.HasPrefix(s, "c:"):
+		index, err := parseUint(s[2:], 8)
+
+		return PushValue{Type: PushConstant8, Constant: uint16(index)}, err
+	case strings.HasPrefix(s, "float:0x"):
+		bits, err := parseUint(s[6:], 32)
+
+		return PushValue{Type: PushFloat, Float: math.Float32frombits(uint32(bits))}, err
+	case strings.HasPrefix(s, "float:"):
+		value, err := parseNumber(s[6:], 32)
+
+		return PushValue{Type: PushFloat, Float: float32(value)}, err
+	case strings.HasPrefix(s, "double:0x"):
+		bits, err := parseUint(s[7:], 64)
+
+		return PushValue{Type: PushDouble, Double: math.Float64frombits(bits)}, err
+	}
+	if integer, err := strconv.ParseInt(s, 10, 32); err == nil {
+		return PushValue{Type: PushInteger, Integer: int32(integer)}, nil
+	}
+
+	value, err := parseNumber(s, 64)
+
+	return PushValue{Type: PushDouble, Double: value}, err
+}
@@ -0,0 +1,117 @@
+package avm1
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssembleRoundTrip(t *testing.T) {
+	actions := []Action{
+		&ActionConstantPool{Constants: []string{"x", "a\"b\n"}},
+		&ActionPush{Values: []PushValue{
+			{Type: PushConstant8, Constant: 0},
+			{Type: PushInteger, Integer: -3},
+			{Type: PushDouble, Double: 1},
+			{Type: PushDouble, Double: math.Copysign(0, -1)},
+			{Type: PushDouble, Double: math.Inf(-1)},
+			{Type: PushDouble, Double: math.NaN()},
+			{Type: PushFloat, Float: 0.1},
+			{Type: PushBoolean, Boolean: false},
+			{Type: PushNull},
+			{Type: PushUndefined},
+			{Type: PushRegister, Register: 2},
+			{Type: PushString, String: "\xff;"},
+			{Type: PushConstant16, Constant: 1},
+		}},
+		&ActionDefineFunction2{
+			FunctionName:  "f",
+			RegisterCount: 3,
+			Flags:         PreloadThis | PreloadGlobal | 0x0002,
+			Parameters:    []RegisterParam{{Register: 1, Name: "a"}, {Name: "b"}},
+			Body:          Block{Count: 2},
+		},
+		&ActionPush{Values: []PushValue{{Type: PushRegister, Register: 1}}},
+		CodeReturn,
+		&ActionTry{
+			CatchInRegister: true,
+			CatchRegister:   4,
+			HasFinallyBlock: true,
+			Try:             Block{Count: 1},
+			Catch:           Block{Count: 0},
+			Finally:         Block{Count: 1},
+		},
+		CodeStop,
+		CodePlay,
+		&ActionIf{Branch: Branch{Target: 12}},
+		&ActionJump{Branch: Branch{Target: 0}},
+		&ActionDefineFunction{FunctionName: "", Params: []string{"p"}, Body: Block{Count: 0}},
+		&ActionGetURL2{SendVarsMethod: SendVarsGet, LoadVariables: true},
+		CodeEnd,
+	}
+
+	require.NoError(t, Relocate(actions))
+
+	data, err := Encode(actions)
+
+	require.NoError(t, err)
+
+	data = append(data, 0x99, 0x02, 0x00, 0xfd, 0xff, 0xf0, 0x01, 0x00, 0x2a, 0x70)
+
+	text, err := Disassemble(data)
+
+	require.NoError(t, err)
+
+	assembled, err := Assemble(text)
+
+	require.NoError(t, err)
+	require.Equal(t, data, assembled)
+}
+
+func TestAssembleLabels(t *testing.T) {
+	text := `
+; count down from 3
+  push "i", 3
+  setVariable
+loop:
+  push "i"
+  getVariable
+  not
+  if done
+  push "i", "i"
+  getVariable
+  decrement
+  setVariable
+  jump loop
+done:
+  end
+`
+
+	data, err := Assemble(text)
+
+	require.NoError(t, err)
+
+	actions, err := Decode(data)
+
+	require.NoError(t, err)
+	require.Len(t, actions, 12)
+	require.Equal(t, 11, actions[5].(*ActionIf).Target)
+	require.Equal(t, 2, actions[10].(*ActionJump).Target)
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, text := range []string{
+		"jump nowhere",
+		"push 1 2",
+		"unknownAction",
+		"stop 1",
+		"L1:\nL1:",
+		"with end=#1 extra",
+		"push r:256",
+	} {
+		_, err := Assemble(text)
+
+		require.Error(t, err, text)
+	}
+}
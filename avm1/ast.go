@@ -0,0 +1,555 @@
+package avm1
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Operator precedences of the decompiled expressions. A greater value binds
+// tighter.
+const (
+	precFunction = iota + 1
+	precAssign
+	precTernary
+	precOr
+	precAnd
+	precBitOr
+	precBitXor
+	precBitAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precUnary
+	precCall
+	precPrimary
+)
+
+// expr is an expression of the decompiled source.
+type expr interface {
+	precedence() int
+	format() string
+}
+
+// paren formats the expression and wraps it with parentheses when it binds
+// looser than the precedence.
+func paren(e expr, precedence int) string {
+	if e.precedence() < precedence {
+		return "(" + e.format() + ")"
+	}
+
+	return e.format()
+}
+
+func formatList(exprs []expr) string {
+	items := make([]string, len(exprs))
+
+	for i, e := range exprs {
+		items[i] = paren(e, precAssign)
+	}
+
+	return strings.Join(items, ", ")
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPath reports whether s is a dotted path of identifiers such as
+// "_root.clip.x".
+func isPath(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if !isIdentifier(part) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// exprName is an identifier, a path or a keyword such as null.
+type exprName struct {
+	name string
+}
+
+func (e *exprName) precedence() int {
+	return precPrimary
+}
+
+func (e *exprName) format() string {
+	return e.name
+}
+
+type exprString struct {
+	value string
+}
+
+func (e *exprString) precedence() int {
+	return precPrimary
+}
+
+func (e *exprString) format() string {
+	return strconv.Quote(e.value)
+}
+
+type exprNumber struct {
+	value float64
+	text  string
+}
+
+func newNumber(value float64, bitSize int) *exprNumber {
+	var text string
+
+	switch {
+	case math.IsNaN(value):
+		text = "NaN"
+	case math.IsInf(value, 1):
+		text = "Infinity"
+	case math.IsInf(value, -1):
+		text = "-Infinity"
+	case value == 0 || math.Abs(value) >= 1e-6 && math.Abs(value) < 1e21:
+		text = strconv.FormatFloat(value, 'f', -1, bitSize)
+	default:
+		text = strconv.FormatFloat(value, 'g', -1, bitSize)
+	}
+
+	return &exprNumber{value: value, text: text}
+}
+
+func (e *exprNumber) precedence() int {
+	if strings.HasPrefix(e.text, "-") {
+		return precUnary
+	}
+
+	return precPrimary
+}
+
+func (e *exprNumber) format() string {
+	return e.text
+}
+
+// integer returns the value when it is a non-negative integer.
+func (e *exprNumber) integer() (int, bool) {
+	if e.value < 0 || e.value > math.MaxUint16 || e.value != math.Trunc(e.value) {
+		return 0, false
+	}
+
+	return int(e.value), true
+}
+
+type exprRegister struct {
+	number uint8
+	name   string
+}
+
+func (e *exprRegister) precedence() int {
+	return precPrimary
+}
+
+func (e *exprRegister) format() string {
+	return e.name
+}
+
+type exprMember struct {
+	object expr
+	name   expr
+}
+
+func (e *exprMember) precedence() int {
+	return precCall
+}
+
+func (e *exprMember) format() string {
+	if s, ok := e.name.(*exprString); ok && isIdentifier(s.value) {
+		return paren(e.object, precCall) + "." + s.value
+	}
+
+	return paren(e.object, precCall) + "[" + e.name.format() + "]"
+}
+
+type exprCall struct {
+	callee expr
+	args   []expr
+	isNew  bool
+}
+
+func (e *exprCall) precedence() int {
+	return precCall
+}
+
+func (e *exprCall) format() string {
+	s := paren(e.callee, precCall) + "(" + formatList(e.args) + ")"
+
+	if e.isNew {
+		s = "new " + s
+	}
+
+	return s
+}
+
+func newCall(name string, args ...expr) *exprCall {
+	return &exprCall{callee: &exprName{name: name}, args: args}
+}
+
+// exprUnary is a prefix operator. The operator includes the trailing space
+// of keywords such as "typeof ".
+type exprUnary struct {
+	op      string
+	operand expr
+}
+
+func (e *exprUnary) precedence() int {
+	return precUnary
+}
+
+func (e *exprUnary) format() string {
+	return e.op + paren(e.operand, precUnary)
+}
+
+type exprBinary struct {
+	op    string
+	prec  int
+	left  expr
+	right expr
+}
+
+func (e *exprBinary) precedence() int {
+	return e.prec
+}
+
+func (e *exprBinary) format() string {
+	return paren(e.left, e.prec) + " " + e.op + " " + paren(e.right, e.prec+1)
+}
+
+type exprTernary struct {
+	cond expr
+	yes  expr
+	no   expr
+}
+
+func (e *exprTernary) precedence() int {
+	return precTernary
+}
+
+func (e *exprTernary) format() string {
+	return paren(e.cond, precTernary+1) + " ? " + paren(e.yes, precAssign) + " : " + paren(e.no, precTernary)
+}
+
+type exprAssign struct {
+	target expr
+	value  expr
+}
+
+func (e *exprAssign) precedence() int {
+	return precAssign
+}
+
+func (e *exprAssign) format() string {
+	return paren(e.target, precCall) + " = " + paren(e.value, precAssign)
+}
+
+type exprArray struct {
+	items []expr
+}
+
+func (e *exprArray) precedence() int {
+	return precPrimary
+}
+
+func (e *exprArray) format() string {
+	return "[" + formatList(e.items) + "]"
+}
+
+type exprObject struct {
+	names  []expr
+	values []expr
+}
+
+func (e *exprObject) precedence() int {
+	return precPrimary
+}
+
+func (e *exprObject) format() string {
+	items := make([]string, len(e.names))
+
+	for i := range e.names {
+		name := e.names[i].format()
+
+		if s, ok := e.names[i].(*exprString); ok && isIdentifier(s.value) {
+			name = s.value
+		}
+
+		items[i] = name + ": " + paren(e.values[i], precAssign)
+	}
+
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// exprEnumerate is the property names of the object pushed by Enumerate and
+// Enumerate2, which is consumed by a for-in loop.
+type exprEnumerate struct {
+	object expr
+}
+
+func (e *exprEnumerate) precedence() int {
+	return precCall
+}
+
+func (e *exprEnumerate) format() string {
+	return "enumerate(" + e.object.format() + ")"
+}
+
+type exprFunction struct {
+	name   string
+	params []string
+	body   []stmt
+}
+
+func (e *exprFunction) precedence() int {
+	return precFunction
+}
+
+func (e *exprFunction) format() string {
+	return e.declare("")
+}
+
+// declare formats the function with the prefix such as "static ".
+func (e *exprFunction) declare(prefix string) string {
+	w := &writer{}
+	w.line(prefix + "function " + e.name + "(" + strings.Join(e.params, ", ") + ") {")
+	w.block(e.body)
+	w.line("}")
+
+	return strings.TrimSuffix(w.String(), "\n")
+}
+
+// negate returns the logical negation of the expression.
+func negate(e expr) expr {
+	switch v := e.(type) {
+	case *exprUnary:
+		if v.op == "!" {
+			return v.operand
+		}
+	case *exprBinary:
+		inverse := map[string]string{"==": "!=", "!=": "==", "===": "!==", "!==": "==="}
+
+		if op, ok := inverse[v.op]; ok {
+			return &exprBinary{op: op, prec: v.prec, left: v.left, right: v.right}
+		}
+	}
+
+	return &exprUnary{op: "!", operand: e}
+}
+
+// isPure reports whether the expression can be dropped without side effects.
+func isPure(e expr) bool {
+	switch e.(type) {
+	case *exprName, *exprString, *exprNumber, *exprRegister:
+		return true
+	default:
+		return false
+	}
+}
+
+// writer writes indented lines.
+type writer struct {
+	builder strings.Builder
+	depth   int
+}
+
+func (w *writer) String() string {
+	return w.builder.String()
+}
+
+// line writes the text which may span multiple lines at the current depth.
+func (w *writer) line(text string) {
+	for _, s := range strings.Split(text, "\n") {
+		w.builder.WriteString(strings.Repeat("    ", w.depth))
+		w.builder.WriteString(s)
+		w.builder.WriteString("\n")
+	}
+}
+
+// block writes the statements one level deeper.
+func (w *writer) block(stmts []stmt) {
+	w.depth += 1
+
+	for _, s := range stmts {
+		s.write(w)
+	}
+
+	w.depth -= 1
+}
+
+// stmt is a statement of the decompiled source.
+type stmt interface {
+	write(w *writer)
+}
+
+type stmtExpr struct {
+	e expr
+}
+
+func (s *stmtExpr) write(w *writer) {
+	w.line(s.e.format() + ";")
+}
+
+type stmtVar struct {
+	name  string
+	value expr
+}
+
+func (s *stmtVar) write(w *writer) {
+	if s.value == nil {
+		w.line("var " + s.name + ";")
+	} else {
+		w.line("var " + s.name + " = " + paren(s.value, precAssign) + ";")
+	}
+}
+
+type stmtFunction struct {
+	function *exprFunction
+}
+
+func (s *stmtFunction) write(w *writer) {
+	w.line(s.function.format())
+}
+
+// stmtText is a statement written as it is, such as break or a line of
+// disassembly.
+type stmtText struct {
+	text string
+}
+
+func (s *stmtText) write(w *writer) {
+	w.line(s.text)
+}
+
+type stmtReturn struct {
+	value expr
+}
+
+func (s *stmtReturn) write(w *writer) {
+	if s.value == nil {
+		w.line("return;")
+	} else {
+		w.line("return " + s.value.format() + ";")
+	}
+}
+
+type stmtThrow struct {
+	value expr
+}
+
+func (s *stmtThrow) write(w *writer) {
+	w.line("throw " + s.value.format() + ";")
+}
+
+type stmtExtends struct {
+	subclass   expr
+	superclass expr
+}
+
+func (s *stmtExtends) write(w *writer) {
+	w.line(s.subclass.format() + " extends " + s.superclass.format() + ";")
+}
+
+type stmtImplements struct {
+	class      expr
+	interfaces []expr
+}
+
+func (s *stmtImplements) write(w *writer) {
+	w.line(s.class.format() + " implements " + formatList(s.interfaces) + ";")
+}
+
+type stmtIf struct {
+	cond expr
+	then []stmt
+	els  []stmt
+}
+
+func (s *stmtIf) write(w *writer) {
+	w.line("if (" + s.cond.format() + ") {")
+	w.block(s.then)
+
+	for len(s.els) > 0 {
+		elseIf, ok := s.els[0].(*stmtIf)
+
+		if !ok || len(s.els) != 1 {
+			w.line("} else {")
+			w.block(s.els)
+
+			break
+		}
+
+		w.line("} else if (" + elseIf.cond.format() + ") {")
+		w.block(elseIf.then)
+		s = elseIf
+	}
+
+	w.line("}")
+}
+
+// stmtBlock is a statement with a header and a body, such as with.
+type stmtBlock struct {
+	header string
+	body   []stmt
+}
+
+func (s *stmtBlock) write(w *writer) {
+	w.line(s.header + " {")
+	w.block(s.body)
+	w.line("}")
+}
+
+type stmtDoWhile struct {
+	body []stmt
+	cond expr
+}
+
+func (s *stmtDoWhile) write(w *writer) {
+	w.line("do {")
+	w.block(s.body)
+	w.line("} while (" + s.cond.format() + ");")
+}
+
+type stmtTry struct {
+	body        []stmt
+	hasCatch    bool
+	catchName   string
+	catchBody   []stmt
+	hasFinally  bool
+	finallyBody []stmt
+}
+
+func (s *stmtTry) write(w *writer) {
+	w.line("try {")
+	w.block(s.body)
+
+	if s.hasCatch {
+		w.line("} catch (" + s.catchName + ") {")
+		w.block(s.catchBody)
+	}
+	if s.hasFinally {
+		w.line("} finally {")
+		w.block(s.finallyBody)
+	}
+
+	w.line("}")
+}
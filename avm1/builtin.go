@@ -0,0 +1,475 @@
+package avm1
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func arg(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return Undefined{}
+}
+
+func (m *Machine) method(o *Object, name string, native NativeFunction) {
+	o.Set(name, NewNativeFunction(native))
+}
+
+// installBuiltins defines the built-in objects which are needed to resolve
+// strings and URLs computed at runtime.
+func (m *Machine) installBuiltins() {
+	m.objectPrototype = NewObject()
+	m.arrayPrototype = NewObject()
+	m.arrayPrototype.Prototype = m.objectPrototype
+	m.stringPrototype = NewObject()
+	m.stringPrototype.Prototype = m.objectPrototype
+	m.movieClipPrototype = NewObject()
+	m.movieClipPrototype.Prototype = m.objectPrototype
+	m.Root.Prototype = m.movieClipPrototype
+
+	object := NewNativeFunction(func(m *Machine, this Value, args []Value) (Value, error) {
+		o := NewObject()
+		o.Prototype = m.objectPrototype
+
+		return o, nil
+	})
+	object.Set("prototype", m.objectPrototype)
+	m.Global.Set("Object", object)
+
+	array := NewNativeFunction(func(m *Machine, this Value, args []Value) (Value, error) {
+		a := NewArray()
+
+		if n, ok := arg(args, 0).(float64); ok && len(args) == 1 {
+			a.Set("length", n)
+		} else {
+			a = NewArray(args...)
+		}
+
+		a.Prototype = m.arrayPrototype
+
+		return a, nil
+	})
+	array.Set("prototype", m.arrayPrototype)
+	m.Global.Set("Array", array)
+
+	str := NewNativeFunction(func(m *Machine, this Value, args []Value) (Value, error) {
+		return ToString(arg(args, 0)), nil
+	})
+	str.Set("prototype", m.stringPrototype)
+	m.method(str, "fromCharCode", func(m *Machine, this Value, args []Value) (Value, error) {
+		var builder strings.Builder
+
+		for _, a := range args {
+			builder.WriteRune(rune(ToNumber(a)))
+		}
+
+		return builder.String(), nil
+	})
+	m.Global.Set("String", str)
+
+	m.method(m.Global, "Number", func(m *Machine, this Value, args []Value) (Value, error) {
+		return ToNumber(arg(args, 0)), nil
+	})
+	m.method(m.Global, "Boolean", func(m *Machine, this Value, args []Value) (Value, error) {
+		return ToBoolean(arg(args, 0)), nil
+	})
+	m.method(m.Global, "parseInt", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := strings.TrimSpace(ToString(arg(args, 0)))
+		radix := int(ToNumber(arg(args, 1)))
+
+		if _, ok := arg(args, 1).(Undefined); ok {
+			radix = 10
+
+			if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+				s, radix = s[2:], 16
+			}
+		}
+
+		end := 0
+
+		for end < len(s) {
+			if _, err := strconv.ParseInt(s[:end+1], radix, 64); err != nil && !(end == 0 && (s[0] == '-' || s[0] == '+')) {
+				break
+			}
+
+			end += 1
+		}
+
+		n, err := strconv.ParseInt(s[:end], radix, 64)
+
+		if err != nil {
+			return math.NaN(), nil
+		}
+
+		return float64(n), nil
+	})
+	m.method(m.Global, "parseFloat", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := strings.TrimSpace(ToString(arg(args, 0)))
+
+		for end := len(s); end > 0; end-- {
+			if n, err := strconv.ParseFloat(s[:end], 64); err == nil {
+				return n, nil
+			}
+		}
+
+		return math.NaN(), nil
+	})
+	m.method(m.Global, "isNaN", func(m *Machine, this Value, args []Value) (Value, error) {
+		return math.IsNaN(ToNumber(arg(args, 0))), nil
+	})
+	m.method(m.Global, "escape", func(m *Machine, this Value, args []Value) (Value, error) {
+		return strings.ReplaceAll(url.QueryEscape(ToString(arg(args, 0))), "+", "%20"), nil
+	})
+	m.method(m.Global, "unescape", func(m *Machine, this Value, args []Value) (Value, error) {
+		s, err := url.PathUnescape(ToString(arg(args, 0)))
+
+		if err != nil {
+			return ToString(arg(args, 0)), nil
+		}
+
+		return s, nil
+	})
+
+	mathObject := NewObject()
+
+	for name, fn := range map[string]func(float64) float64{
+		"abs":   math.Abs,
+		"ceil":  math.Ceil,
+		"floor": math.Floor,
+		"round": func(x float64) float64 { return math.Floor(x + 0.5) },
+		"sqrt":  math.Sqrt,
+	} {
+		fn := fn
+
+		m.method(mathObject, name, func(m *Machine, this Value, args []Value) (Value, error) {
+			return fn(ToNumber(arg(args, 0))), nil
+		})
+	}
+
+	m.method(mathObject, "pow", func(m *Machine, this Value, args []Value) (Value, error) {
+		return math.Pow(ToNumber(arg(args, 0)), ToNumber(arg(args, 1))), nil
+	})
+	m.method(mathObject, "min", func(m *Machine, this Value, args []Value) (Value, error) {
+		return math.Min(ToNumber(arg(args, 0)), ToNumber(arg(args, 1))), nil
+	})
+	m.method(mathObject, "max", func(m *Machine, this Value, args []Value) (Value, error) {
+		return math.Max(ToNumber(arg(args, 0)), ToNumber(arg(args, 1))), nil
+	})
+	m.method(mathObject, "random", func(m *Machine, this Value, args []Value) (Value, error) {
+		// The sandbox is deterministic.
+		return float64(0), nil
+	})
+	m.Global.Set("Math", mathObject)
+
+	m.installStringMethods()
+	m.installArrayMethods()
+	m.installMovieClipMethods()
+}
+
+func (m *Machine) installStringMethods() {
+	p := m.stringPrototype
+	runes := func(this Value) []rune {
+		return []rune(ToString(this))
+	}
+	index := func(args []Value, i int, length int) int {
+		if _, ok := arg(args, i).(Undefined); ok {
+			return length
+		}
+
+		n := int(ToNumber(arg(args, i)))
+
+		if n < 0 {
+			return 0
+		}
+		if n > length {
+			return length
+		}
+
+		return n
+	}
+
+	m.method(p, "toString", func(m *Machine, this Value, args []Value) (Value, error) {
+		return ToString(this), nil
+	})
+	m.method(p, "charAt", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := runes(this)
+		i := int(ToNumber(arg(args, 0)))
+
+		if i < 0 || i >= len(s) {
+			return "", nil
+		}
+
+		return string(s[i]), nil
+	})
+	m.method(p, "charCodeAt", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := runes(this)
+		i := int(ToNumber(arg(args, 0)))
+
+		if i < 0 || i >= len(s) {
+			return math.NaN(), nil
+		}
+
+		return float64(s[i]), nil
+	})
+	m.method(p, "substr", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := runes(this)
+		start := int(ToNumber(arg(args, 0)))
+
+		if start < 0 {
+			start += len(s)
+		}
+
+		count := len(s)
+
+		if _, ok := arg(args, 1).(Undefined); !ok {
+			count = int(ToNumber(arg(args, 1)))
+		}
+
+		start, end := clampRange(start, count, len(s))
+
+		return string(s[start:end]), nil
+	})
+	m.method(p, "substring", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := runes(this)
+		start := index(args, 0, len(s))
+		end := index(args, 1, len(s))
+
+		if start > end {
+			start, end = end, start
+		}
+
+		return string(s[start:end]), nil
+	})
+	m.method(p, "slice", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := runes(this)
+		bound := func(i int) int {
+			if _, ok := arg(args, i).(Undefined); ok {
+				return len(s)
+			}
+
+			n := int(ToNumber(arg(args, i)))
+
+			if n < 0 {
+				n += len(s)
+			}
+
+			return index([]Value{float64(n)}, 0, len(s))
+		}
+		start, end := bound(0), bound(1)
+
+		if start > end {
+			return "", nil
+		}
+
+		return string(s[start:end]), nil
+	})
+	m.method(p, "indexOf", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := ToString(this)
+		i := strings.Index(s, ToString(arg(args, 0)))
+
+		if i < 0 {
+			return float64(-1), nil
+		}
+
+		return float64(utf8.RuneCountInString(s[:i])), nil
+	})
+	m.method(p, "lastIndexOf", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := ToString(this)
+		i := strings.LastIndex(s, ToString(arg(args, 0)))
+
+		if i < 0 {
+			return float64(-1), nil
+		}
+
+		return float64(utf8.RuneCountInString(s[:i])), nil
+	})
+	m.method(p, "toUpperCase", func(m *Machine, this Value, args []Value) (Value, error) {
+		return strings.ToUpper(ToString(this)), nil
+	})
+	m.method(p, "toLowerCase", func(m *Machine, this Value, args []Value) (Value, error) {
+		return strings.ToLower(ToString(this)), nil
+	})
+	m.method(p, "concat", func(m *Machine, this Value, args []Value) (Value, error) {
+		s := ToString(this)
+
+		for _, a := range args {
+			s += ToString(a)
+		}
+
+		return s, nil
+	})
+	m.method(p, "split", func(m *Machine, this Value, args []Value) (Value, error) {
+		var parts []Value
+
+		if _, ok := arg(args, 0).(Undefined); ok {
+			parts = []Value{ToString(this)}
+		} else {
+			separator := ToString(arg(args, 0))
+
+			if separator == "" {
+				for _, r := range runes(this) {
+					parts = append(parts, string(r))
+				}
+			} else {
+				for _, part := range strings.Split(ToString(this), separator) {
+					parts = append(parts, part)
+				}
+			}
+		}
+
+		a := NewArray(parts...)
+		a.Prototype = m.arrayPrototype
+
+		return a, nil
+	})
+}
+
+func (m *Machine) installArrayMethods() {
+	p := m.arrayPrototype
+	length := func(o *Object) int {
+		return int(ToNumber(o.Get("length")))
+	}
+
+	m.method(p, "toString", func(m *Machine, this Value, args []Value) (Value, error) {
+		return ToString(this), nil
+	})
+	m.method(p, "join", func(m *Machine, this Value, args []Value) (Value, error) {
+		o, ok := this.(*Object)
+
+		if !ok {
+			return "", nil
+		}
+
+		separator := ","
+
+		if _, ok := arg(args, 0).(Undefined); !ok {
+			separator = ToString(arg(args, 0))
+		}
+
+		return joinArray(o, separator), nil
+	})
+	m.method(p, "push", func(m *Machine, this Value, args []Value) (Value, error) {
+		o, ok := this.(*Object)
+
+		if !ok {
+			return Undefined{}, nil
+		}
+		for _, a := range args {
+			o.Set(strconv.Itoa(length(o)), a)
+		}
+
+		return float64(length(o)), nil
+	})
+	m.method(p, "pop", func(m *Machine, this Value, args []Value) (Value, error) {
+		o, ok := this.(*Object)
+
+		if !ok || length(o) == 0 {
+			return Undefined{}, nil
+		}
+
+		last := strconv.Itoa(length(o) - 1)
+		value := o.Get(last)
+		o.Delete(last)
+		o.Set("length", float64(length(o)-1))
+
+		return value, nil
+	})
+	m.method(p, "reverse", func(m *Machine, this Value, args []Value) (Value, error) {
+		o, ok := this.(*Object)
+
+		if !ok {
+			return Undefined{}, nil
+		}
+
+		n := length(o)
+
+		for i := 0; i < n/2; i++ {
+			a, b := strconv.Itoa(i), strconv.Itoa(n-1-i)
+			va, vb := o.Get(a), o.Get(b)
+			o.Set(a, vb)
+			o.Set(b, va)
+		}
+
+		return o, nil
+	})
+}
+
+// level returns the target of the level given as the argument of
+// loadMovieNum and loadVariablesNum.
+func level(v Value) string {
+	return "_level" + strconv.Itoa(int(ToNumber(v)))
+}
+
+// isLevel reports whether the target is a level such as "_level1".
+func isLevel(target string) bool {
+	n := strings.TrimPrefix(target, "_level")
+
+	if n == target || n == "" {
+		return false
+	}
+	for _, r := range n {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// targetPath returns the target path of the movie clip, which is empty
+// unless it is _root because the sandbox has no display list.
+func (m *Machine) targetPath(this Value) string {
+	if this == Value(m.Root) {
+		return "_root"
+	}
+
+	return ""
+}
+
+// installMovieClipMethods defines the methods of MovieClip which load URLs.
+// They are also called on the values which are not objects, since the clips
+// on the stage are not modeled.
+func (m *Machine) installMovieClipMethods() {
+	p := m.movieClipPrototype
+
+	m.method(p, "getURL", func(m *Machine, this Value, args []Value) (Value, error) {
+		url := ToString(arg(args, 0))
+		window := ""
+
+		if _, ok := arg(args, 1).(Undefined); !ok {
+			window = ToString(arg(args, 1))
+		}
+		if command := strings.TrimPrefix(url, "FSCommand:"); command != url {
+			m.emit(EventFSCommand, command, window)
+		} else {
+			m.emit(EventGetURL, url, window)
+		}
+
+		return Undefined{}, nil
+	})
+	m.method(p, "loadMovie", func(m *Machine, this Value, args []Value) (Value, error) {
+		m.emit(EventLoadMovie, ToString(arg(args, 0)), m.targetPath(this))
+
+		return Undefined{}, nil
+	})
+	m.method(p, "loadMovieNum", func(m *Machine, this Value, args []Value) (Value, error) {
+		m.emit(EventLoadMovie, ToString(arg(args, 0)), level(arg(args, 1)))
+
+		return Undefined{}, nil
+	})
+	m.method(p, "loadVariables", func(m *Machine, this Value, args []Value) (Value, error) {
+		m.emit(EventLoadVariables, ToString(arg(args, 0)), m.targetPath(this))
+
+		return Undefined{}, nil
+	})
+	m.method(p, "loadVariablesNum", func(m *Machine, this Value, args []Value) (Value, error) {
+		m.emit(EventLoadVariables, ToString(arg(args, 0)), level(arg(args, 1)))
+
+		return Undefined{}, nil
+	})
+}
@@ -0,0 +1,356 @@
+package avm1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Exit is the destination of the edges leaving the code, such as return.
+const Exit = -1
+
+// EdgeKind is the kind of an Edge.
+type EdgeKind uint8
+
+const (
+	EdgeFallthrough EdgeKind = iota + 1
+	EdgeJump
+	EdgeTrue
+	EdgeFalse
+	EdgeFunction
+	EdgeCatch
+	EdgeFinally
+	EdgeReturn
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case EdgeFallthrough:
+		return "Fallthrough"
+	case EdgeJump:
+		return "Jump"
+	case EdgeTrue:
+		return "True"
+	case EdgeFalse:
+		return "False"
+	case EdgeFunction:
+		return "Function"
+	case EdgeCatch:
+		return "Catch"
+	case EdgeFinally:
+		return "Finally"
+	case EdgeReturn:
+		return "Return"
+	default:
+		return fmt.Sprintf("EdgeKind(%d)", uint8(k))
+	}
+}
+
+// Edge connects the basic blocks From and To, which are indices of
+// Graph.Blocks. To is Exit when the edge leaves the code.
+type Edge struct {
+	From int
+	To   int
+	Kind EdgeKind
+}
+
+// BasicBlock is the actions from Start to End, which are entered only at
+// Start and left only at the last action.
+type BasicBlock struct {
+	Start     int
+	End       int
+	Reachable bool
+}
+
+// TryRegion is the blocks of ActionTry as ranges of action indices. A range
+// is [2]int{-1, -1} when the sizes of the blocks are not resolved.
+type TryRegion struct {
+	Action  int
+	Try     [2]int
+	Catch   [2]int
+	Finally [2]int
+}
+
+// Graph is the control-flow graph of the actions. The bodies of functions
+// are part of the graph, and entered by the EdgeFunction edges from their
+// definitions.
+type Graph struct {
+	Actions []Action
+	Blocks  []*BasicBlock
+	Edges   []Edge
+	Regions []TryRegion
+	// InvalidBranches is the indices of the branch actions whose offset lands
+	// in the middle of an action.
+	InvalidBranches []int
+}
+
+// BuildGraph builds the control-flow graph of the decoded actions and marks
+// the blocks reachable from the first action.
+func BuildGraph(actions []Action) *Graph {
+	g := &Graph{Actions: actions}
+
+	if len(actions) == 0 {
+		return g
+	}
+
+	leaders := map[int]bool{0: true}
+	// scopeEnd is the end of the innermost function body for each action.
+	scopeEnd := make([]int, len(actions))
+
+	for i := range scopeEnd {
+		scopeEnd[i] = len(actions)
+	}
+
+	lead := func(index int) {
+		if index >= 0 && index < len(actions) {
+			leaders[index] = true
+		}
+	}
+
+	for i, action := range actions {
+		if branch := branchOf(action); branch != nil {
+			if branch.Target < 0 {
+				g.InvalidBranches = append(g.InvalidBranches, i)
+			}
+
+			lead(branch.Target)
+			lead(i + 1)
+		}
+		switch action.Code() {
+		case CodeReturn, CodeThrow, CodeEnd:
+			lead(i + 1)
+		}
+
+		ends := blockEnds(i, blocksOf(action))
+
+		if len(ends) > 0 {
+			lead(i + 1)
+		}
+		for _, end := range ends {
+			lead(end)
+		}
+		valid := len(ends) > 0 && ends[len(ends)-1] <= len(actions)
+
+		switch action.(type) {
+		case *ActionDefineFunction, *ActionDefineFunction2:
+			if !valid {
+				break
+			}
+			for j := i + 1; j < ends[0]; j++ {
+				scopeEnd[j] = ends[0]
+			}
+		case *ActionTry:
+			region := TryRegion{Action: i, Try: [2]int{-1, -1}, Catch: [2]int{-1, -1}, Finally: [2]int{-1, -1}}
+
+			if valid {
+				region.Try = [2]int{i + 1, ends[0]}
+				region.Catch = [2]int{ends[0], ends[1]}
+				region.Finally = [2]int{ends[1], ends[2]}
+			}
+
+			g.Regions = append(g.Regions, region)
+		}
+	}
+
+	var starts []int
+
+	for index := range leaders {
+		starts = append(starts, index)
+	}
+
+	sort.Ints(starts)
+
+	blockOf := map[int]int{}
+
+	for n, start := range starts {
+		end := len(actions)
+
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+
+		blockOf[start] = n
+		g.Blocks = append(g.Blocks, &BasicBlock{Start: start, End: end})
+	}
+	for n, block := range g.Blocks {
+		g.addEdges(n, block, blockOf, scopeEnd)
+	}
+
+	g.markReachable()
+
+	return g
+}
+
+func (g *Graph) addEdges(n int, block *BasicBlock, blockOf map[int]int, scopeEnd []int) {
+	last := block.End - 1
+	edge := func(target int, kind EdgeKind) {
+		if target >= scopeEnd[last] {
+			if kind == EdgeFallthrough {
+				kind = EdgeReturn
+			}
+
+			g.Edges = append(g.Edges, Edge{From: n, To: Exit, Kind: kind})
+
+			return
+		}
+		if to, ok := blockOf[target]; ok {
+			g.Edges = append(g.Edges, Edge{From: n, To: to, Kind: kind})
+		}
+	}
+
+	switch v := g.Actions[last].(type) {
+	case *ActionJump:
+		if v.Target >= 0 {
+			edge(v.Target, EdgeJump)
+		}
+	case *ActionIf:
+		if v.Target >= 0 {
+			edge(v.Target, EdgeTrue)
+		}
+
+		edge(last+1, EdgeFalse)
+	case *ActionDefineFunction, *ActionDefineFunction2:
+		ends := blockEnds(last, blocksOf(v))
+
+		if ends == nil || ends[0] > len(g.Actions) {
+			edge(last+1, EdgeFallthrough)
+
+			break
+		}
+		if ends[0] > last+1 {
+			g.Edges = append(g.Edges, Edge{From: n, To: blockOf[last+1], Kind: EdgeFunction})
+		}
+
+		edge(ends[0], EdgeFallthrough)
+	case *ActionTry:
+		edge(last+1, EdgeFallthrough)
+
+		ends := blockEnds(last, blocksOf(v))
+
+		if ends == nil || ends[2] > len(g.Actions) {
+			break
+		}
+		if v.HasCatchBlock && ends[1] > ends[0] {
+			edge(ends[0], EdgeCatch)
+		}
+		if v.HasFinallyBlock && ends[2] > ends[1] {
+			edge(ends[1], EdgeFinally)
+		}
+	case ActionCode:
+		switch v {
+		case CodeReturn:
+			g.Edges = append(g.Edges, Edge{From: n, To: Exit, Kind: EdgeReturn})
+		case CodeThrow, CodeEnd:
+		default:
+			edge(last+1, EdgeFallthrough)
+		}
+	default:
+		edge(last+1, EdgeFallthrough)
+	}
+}
+
+func (g *Graph) markReachable() {
+	successors := map[int][]int{}
+
+	for _, e := range g.Edges {
+		if e.To != Exit {
+			successors[e.From] = append(successors[e.From], e.To)
+		}
+	}
+
+	queue := []int{0}
+	g.Blocks[0].Reachable = true
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, to := range successors[n] {
+			if !g.Blocks[to].Reachable {
+				g.Blocks[to].Reachable = true
+				queue = append(queue, to)
+			}
+		}
+	}
+}
+
+// Successors returns the edges leaving the block.
+func (g *Graph) Successors(block int) []Edge {
+	var edges []Edge
+
+	for _, e := range g.Edges {
+		if e.From == block {
+			edges = append(edges, e)
+		}
+	}
+
+	return edges
+}
+
+// Unreachable returns the indices of the blocks which cannot be reached from
+// the first action.
+func (g *Graph) Unreachable() []int {
+	var blocks []int
+
+	for n, block := range g.Blocks {
+		if !block.Reachable {
+			blocks = append(blocks, n)
+		}
+	}
+
+	return blocks
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`).Replace(s)
+}
+
+// DOT formats the graph in the Graphviz DOT language. Unreachable blocks are
+// drawn dashed.
+func (g *Graph) DOT() string {
+	labels := map[int]string{len(g.Actions): "exit"}
+
+	for n, block := range g.Blocks {
+		labels[block.Start] = fmt.Sprintf("b%d", n)
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString("digraph avm1 {\n")
+	builder.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	builder.WriteString("\texit [shape=oval];\n")
+
+	for n, block := range g.Blocks {
+		lines := []string{fmt.Sprintf("b%d:", n)}
+
+		for i := block.Start; i < block.End; i++ {
+			line, err := formatAction(g.Actions[i], i, labels)
+
+			if err != nil {
+				line = fmt.Sprintf("; %s", err)
+			}
+
+			lines = append(lines, "  "+line)
+		}
+
+		style := ""
+
+		if !block.Reachable {
+			style = ", style=dashed, color=gray"
+		}
+
+		fmt.Fprintf(builder, "\tb%d [label=\"%s\\l\"%s];\n", n, dotEscape(strings.Join(lines, "\n")), style)
+	}
+	for _, e := range g.Edges {
+		to := "exit"
+
+		if e.To != Exit {
+			to = fmt.Sprintf("b%d", e.To)
+		}
+
+		fmt.Fprintf(builder, "\tb%d -> %s [label=\"%s\"];\n", e.From, to, strings.ToLower(e.Kind.String()))
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
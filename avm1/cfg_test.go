@@ -0,0 +1,49 @@
+package avm1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGraph(t *testing.T) {
+	actions, err := Parse(`
+push 1
+if L1
+push "a"
+trace
+jump L2
+push "dead"
+trace
+L1:
+defineFunction "f" () end=L3
+  push 1
+  return
+L3:
+jump #-3
+L2:
+end
+`)
+
+	require.NoError(t, err)
+
+	g := BuildGraph(actions)
+
+	require.Len(t, g.Blocks, 7)
+	require.Equal(t, []Edge{
+		{From: 0, To: 3, Kind: EdgeTrue},
+		{From: 0, To: 1, Kind: EdgeFalse},
+		{From: 1, To: 6, Kind: EdgeJump},
+		{From: 2, To: 3, Kind: EdgeFallthrough},
+		{From: 3, To: 4, Kind: EdgeFunction},
+		{From: 3, To: 5, Kind: EdgeFallthrough},
+		{From: 4, To: Exit, Kind: EdgeReturn},
+	}, g.Edges)
+	require.Equal(t, []int{2}, g.Unreachable())
+	require.Equal(t, []int{10}, g.InvalidBranches)
+
+	dot := g.DOT()
+
+	require.Contains(t, dot, `b0 -> b3 [label="true"];`)
+	require.Contains(t, dot, `b2 [label="b2:\l  push \"dead\"\l  trace\l", style=dashed, color=gray];`)
+}
@@ -0,0 +1,314 @@
+package avm1
+
+import (
+	"strings"
+)
+
+// globalPath returns the path of the expression such as _global.com.example
+// without the leading _global.
+func globalPath(e expr) (string, bool) {
+	member, ok := e.(*exprMember)
+
+	if !ok {
+		return "", false
+	}
+
+	name, ok := member.name.(*exprString)
+
+	if !ok || !isIdentifier(name.value) {
+		return "", false
+	}
+	if root, ok := member.object.(*exprName); ok && root.name == "_global" {
+		return name.value, true
+	}
+
+	parent, ok := globalPath(member.object)
+
+	if !ok {
+		return "", false
+	}
+
+	return parent + "." + name.value, true
+}
+
+// guardedPath returns the path of the statement "if (!_global.path) { ... }".
+func guardedPath(s stmt) (string, *stmtIf, bool) {
+	guard, ok := s.(*stmtIf)
+
+	if !ok || len(guard.els) != 0 {
+		return "", nil, false
+	}
+
+	not, ok := guard.cond.(*exprUnary)
+
+	if !ok || not.op != "!" {
+		return "", nil, false
+	}
+
+	path, ok := globalPath(not.operand)
+
+	return path, guard, ok
+}
+
+type classMember struct {
+	static bool
+	name   string
+	value  expr
+}
+
+// class is a class recovered from the statements compiled by the AS2
+// compiler.
+type class struct {
+	path        string
+	superclass  expr
+	interfaces  []expr
+	constructor *exprFunction
+	members     []classMember
+}
+
+func (c *class) write(w *writer) {
+	header := "class " + c.path
+
+	if c.superclass != nil {
+		header += " extends " + c.superclass.format()
+	}
+	if len(c.interfaces) > 0 {
+		header += " implements " + formatList(c.interfaces)
+	}
+
+	w.line(header + " {")
+	w.depth += 1
+
+	for _, member := range c.members {
+		if _, ok := member.value.(*exprFunction); ok {
+			continue
+		}
+
+		prefix := "var "
+
+		if member.static {
+			prefix = "static var "
+		}
+
+		w.line(prefix + member.name + " = " + paren(member.value, precAssign) + ";")
+	}
+
+	constructor := *c.constructor
+	constructor.name = c.path[strings.LastIndex(c.path, ".")+1:]
+	w.line(constructor.format())
+
+	for _, member := range c.members {
+		fn, ok := member.value.(*exprFunction)
+
+		if !ok {
+			continue
+		}
+
+		method := *fn
+		method.name = member.name
+		prefix := ""
+
+		if member.static {
+			prefix = "static "
+		}
+		if name := strings.TrimPrefix(member.name, "__get__"); name != member.name {
+			method.name = "get " + name
+		}
+		if name := strings.TrimPrefix(member.name, "__set__"); name != member.name {
+			method.name = "set " + name
+		}
+
+		w.line(method.declare(prefix))
+	}
+
+	w.depth -= 1
+	w.line("}")
+}
+
+// recoverClass recovers the class from the body of the statement
+// "if (!_global.path) { ... }", which looks like the following.
+//
+//	register1 = function () { ... };
+//	_global.path = register1;
+//	register1 extends Base;
+//	register2 = register1.prototype;
+//	register2.method = function () { ... };
+//	register1.staticMethod = function () { ... };
+//	_global.ASSetPropFlags(register1.prototype, null, 1);
+func recoverClass(path string, body []stmt) (*class, bool) {
+	c := &class{path: path}
+	classRefs := map[string]bool{"_global." + path: true, path: true}
+	protoRefs := map[string]bool{"_global." + path + ".prototype": true, path + ".prototype": true}
+
+	var pending string
+	var pendingFunction *exprFunction
+
+	for _, s := range body {
+		switch v := s.(type) {
+		case *stmtExtends:
+			if !classRefs[v.subclass.format()] {
+				return nil, false
+			}
+
+			c.superclass = v.superclass
+		case *stmtImplements:
+			if !classRefs[v.class.format()] {
+				return nil, false
+			}
+
+			c.interfaces = append(c.interfaces, v.interfaces...)
+		case *stmtExpr:
+			if call, ok := v.e.(*exprCall); ok {
+				callee := call.callee.format()
+
+				if callee == "_global.ASSetPropFlags" || callee == "ASSetPropFlags" {
+					continue
+				}
+				if member, ok := call.callee.(*exprMember); ok && protoRefs[member.object.format()] {
+					if name, ok := member.name.(*exprString); ok && name.value == "addProperty" {
+						continue
+					}
+				}
+
+				return nil, false
+			}
+
+			assign, ok := v.e.(*exprAssign)
+
+			if !ok {
+				return nil, false
+			}
+
+			target := assign.target.format()
+
+			switch value := assign.value.(type) {
+			case *exprFunction:
+				if c.constructor == nil {
+					if _, ok := assign.target.(*exprRegister); ok {
+						pending, pendingFunction = target, value
+
+						continue
+					}
+					if classRefs[target] {
+						c.constructor = value
+
+						continue
+					}
+				}
+			case *exprRegister:
+				if classRefs[target] && value.format() == pending && pendingFunction != nil {
+					c.constructor = pendingFunction
+					classRefs[pending] = true
+					protoRefs[pending+".prototype"] = true
+
+					continue
+				}
+			case *exprMember:
+				if _, ok := assign.target.(*exprRegister); ok && protoRefs[value.format()] {
+					protoRefs[target] = true
+
+					continue
+				}
+			}
+
+			member, ok := assign.target.(*exprMember)
+
+			if !ok {
+				return nil, false
+			}
+
+			name, ok := member.name.(*exprString)
+
+			if !ok || !isIdentifier(name.value) {
+				return nil, false
+			}
+
+			switch object := member.object.format(); {
+			case protoRefs[object]:
+				c.members = append(c.members, classMember{name: name.value, value: assign.value})
+			case classRefs[object]:
+				c.members = append(c.members, classMember{static: true, name: name.value, value: assign.value})
+			default:
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	if c.constructor == nil {
+		return nil, false
+	}
+
+	return c, true
+}
+
+// isPackage reports whether the statement is "if (!_global.path) {
+// _global.path = new Object(); }", which creates a package.
+func isPackage(guard *stmtIf, path string) bool {
+	if len(guard.then) != 1 {
+		return false
+	}
+
+	s, ok := guard.then[0].(*stmtExpr)
+
+	if !ok {
+		return false
+	}
+
+	assign, ok := s.e.(*exprAssign)
+
+	if !ok {
+		return false
+	}
+
+	target, ok := globalPath(assign.target)
+
+	if !ok || target != path {
+		return false
+	}
+
+	call, ok := assign.value.(*exprCall)
+
+	return ok && call.isNew && len(call.args) == 0 && call.callee.format() == "Object"
+}
+
+// recoverClasses rewrites the classes compiled into the _global prototype
+// pattern as class declarations, and removes the packages of the classes.
+func recoverClasses(stmts []stmt) []stmt {
+	var classes []string
+
+	result := make([]stmt, len(stmts))
+
+	for i, s := range stmts {
+		result[i] = s
+
+		path, guard, ok := guardedPath(s)
+
+		if !ok {
+			continue
+		}
+		if c, ok := recoverClass(path, guard.then); ok {
+			result[i] = c
+			classes = append(classes, path)
+		}
+	}
+
+	var filtered []stmt
+
+	for _, s := range result {
+		if path, guard, ok := guardedPath(s); ok && isPackage(guard, path) {
+			used := false
+
+			for _, c := range classes {
+				used = used || strings.HasPrefix(c, path+".")
+			}
+			if used {
+				continue
+			}
+		}
+
+		filtered = append(filtered, s)
+	}
+
+	return filtered
+}
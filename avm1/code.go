@@ -0,0 +1,245 @@
+package avm1
+
+import "fmt"
+
+// ActionCode identifies an action. The actions whose code is 0x80 or greater
+// carry a payload. An ActionCode below 0x80 is an Action by itself.
+type ActionCode uint8
+
+const (
+	CodeEnd             ActionCode = 0x00
+	CodeNextFrame       ActionCode = 0x04
+	CodePreviousFrame   ActionCode = 0x05
+	CodePlay            ActionCode = 0x06
+	CodeStop            ActionCode = 0x07
+	CodeToggleQuality   ActionCode = 0x08
+	CodeStopSounds      ActionCode = 0x09
+	CodeAdd             ActionCode = 0x0a
+	CodeSubtract        ActionCode = 0x0b
+	CodeMultiply        ActionCode = 0x0c
+	CodeDivide          ActionCode = 0x0d
+	CodeEquals          ActionCode = 0x0e
+	CodeLess            ActionCode = 0x0f
+	CodeAnd             ActionCode = 0x10
+	CodeOr              ActionCode = 0x11
+	CodeNot             ActionCode = 0x12
+	CodeStringEquals    ActionCode = 0x13
+	CodeStringLength    ActionCode = 0x14
+	CodeStringExtract   ActionCode = 0x15
+	CodePop             ActionCode = 0x17
+	CodeToInteger       ActionCode = 0x18
+	CodeGetVariable     ActionCode = 0x1c
+	CodeSetVariable     ActionCode = 0x1d
+	CodeSetTarget2      ActionCode = 0x20
+	CodeStringAdd       ActionCode = 0x21
+	CodeGetProperty     ActionCode = 0x22
+	CodeSetProperty     ActionCode = 0x23
+	CodeCloneSprite     ActionCode = 0x24
+	CodeRemoveSprite    ActionCode = 0x25
+	CodeTrace           ActionCode = 0x26
+	CodeStartDrag       ActionCode = 0x27
+	CodeEndDrag         ActionCode = 0x28
+	CodeStringLess      ActionCode = 0x29
+	CodeThrow           ActionCode = 0x2a
+	CodeCastOp          ActionCode = 0x2b
+	CodeImplementsOp    ActionCode = 0x2c
+	CodeFSCommand2      ActionCode = 0x2d
+	CodeRandomNumber    ActionCode = 0x30
+	CodeMBStringLength  ActionCode = 0x31
+	CodeCharToAscii     ActionCode = 0x32
+	CodeAsciiToChar     ActionCode = 0x33
+	CodeGetTime         ActionCode = 0x34
+	CodeMBStringExtract ActionCode = 0x35
+	CodeMBCharToAscii   ActionCode = 0x36
+	CodeMBAsciiToChar   ActionCode = 0x37
+	CodeDelete          ActionCode = 0x3a
+	CodeDelete2         ActionCode = 0x3b
+	CodeDefineLocal     ActionCode = 0x3c
+	CodeCallFunction    ActionCode = 0x3d
+	CodeReturn          ActionCode = 0x3e
+	CodeModulo          ActionCode = 0x3f
+	CodeNewObject       ActionCode = 0x40
+	CodeDefineLocal2    ActionCode = 0x41
+	CodeInitArray       ActionCode = 0x42
+	CodeInitObject      ActionCode = 0x43
+	CodeTypeOf          ActionCode = 0x44
+	CodeTargetPath      ActionCode = 0x45
+	CodeEnumerate       ActionCode = 0x46
+	CodeAdd2            ActionCode = 0x47
+	CodeLess2           ActionCode = 0x48
+	CodeEquals2         ActionCode = 0x49
+	CodeToNumber        ActionCode = 0x4a
+	CodeToString        ActionCode = 0x4b
+	CodePushDuplicate   ActionCode = 0x4c
+	CodeStackSwap       ActionCode = 0x4d
+	CodeGetMember       ActionCode = 0x4e
+	CodeSetMember       ActionCode = 0x4f
+	CodeIncrement       ActionCode = 0x50
+	CodeDecrement       ActionCode = 0x51
+	CodeCallMethod      ActionCode = 0x52
+	CodeNewMethod       ActionCode = 0x53
+	CodeInstanceOf      ActionCode = 0x54
+	CodeEnumerate2      ActionCode = 0x55
+	CodeBitAnd          ActionCode = 0x60
+	CodeBitOr           ActionCode = 0x61
+	CodeBitXor          ActionCode = 0x62
+	CodeBitLShift       ActionCode = 0x63
+	CodeBitRShift       ActionCode = 0x64
+	CodeBitURShift      ActionCode = 0x65
+	CodeStrictEquals    ActionCode = 0x66
+	CodeGreater         ActionCode = 0x67
+	CodeStringGreater   ActionCode = 0x68
+	CodeExtends         ActionCode = 0x69
+	CodeGotoFrame       ActionCode = 0x81
+	CodeGetURL          ActionCode = 0x83
+	CodeStoreRegister   ActionCode = 0x87
+	CodeConstantPool    ActionCode = 0x88
+	CodeWaitForFrame    ActionCode = 0x8a
+	CodeSetTarget       ActionCode = 0x8b
+	CodeGotoLabel       ActionCode = 0x8c
+	CodeWaitForFrame2   ActionCode = 0x8d
+	CodeDefineFunction2 ActionCode = 0x8e
+	CodeTry             ActionCode = 0x8f
+	CodeWith            ActionCode = 0x94
+	CodePush            ActionCode = 0x96
+	CodeJump            ActionCode = 0x99
+	CodeGetURL2         ActionCode = 0x9a
+	CodeDefineFunction  ActionCode = 0x9b
+	CodeIf              ActionCode = 0x9d
+	CodeCall            ActionCode = 0x9e
+	CodeGotoFrame2      ActionCode = 0x9f
+)
+
+var codeNames = map[ActionCode]string{
+	CodeEnd:             "End",
+	CodeNextFrame:       "NextFrame",
+	CodePreviousFrame:   "PreviousFrame",
+	CodePlay:            "Play",
+	CodeStop:            "Stop",
+	CodeToggleQuality:   "ToggleQuality",
+	CodeStopSounds:      "StopSounds",
+	CodeAdd:             "Add",
+	CodeSubtract:        "Subtract",
+	CodeMultiply:        "Multiply",
+	CodeDivide:          "Divide",
+	CodeEquals:          "Equals",
+	CodeLess:            "Less",
+	CodeAnd:             "And",
+	CodeOr:              "Or",
+	CodeNot:             "Not",
+	CodeStringEquals:    "StringEquals",
+	CodeStringLength:    "StringLength",
+	CodeStringExtract:   "StringExtract",
+	CodePop:             "Pop",
+	CodeToInteger:       "ToInteger",
+	CodeGetVariable:     "GetVariable",
+	CodeSetVariable:     "SetVariable",
+	CodeSetTarget2:      "SetTarget2",
+	CodeStringAdd:       "StringAdd",
+	CodeGetProperty:     "GetProperty",
+	CodeSetProperty:     "SetProperty",
+	CodeCloneSprite:     "CloneSprite",
+	CodeRemoveSprite:    "RemoveSprite",
+	CodeTrace:           "Trace",
+	CodeStartDrag:       "StartDrag",
+	CodeEndDrag:         "EndDrag",
+	CodeStringLess:      "StringLess",
+	CodeThrow:           "Throw",
+	CodeCastOp:          "CastOp",
+	CodeImplementsOp:    "ImplementsOp",
+	CodeFSCommand2:      "FSCommand2",
+	CodeRandomNumber:    "RandomNumber",
+	CodeMBStringLength:  "MBStringLength",
+	CodeCharToAscii:     "CharToAscii",
+	CodeAsciiToChar:     "AsciiToChar",
+	CodeGetTime:         "GetTime",
+	CodeMBStringExtract: "MBStringExtract",
+	CodeMBCharToAscii:   "MBCharToAscii",
+	CodeMBAsciiToChar:   "MBAsciiToChar",
+	CodeDelete:          "Delete",
+	CodeDelete2:         "Delete2",
+	CodeDefineLocal:     "DefineLocal",
+	CodeCallFunction:    "CallFunction",
+	CodeReturn:          "Return",
+	CodeModulo:          "Modulo",
+	CodeNewObject:       "NewObject",
+	CodeDefineLocal2:    "DefineLocal2",
+	CodeInitArray:       "InitArray",
+	CodeInitObject:      "InitObject",
+	CodeTypeOf:          "TypeOf",
+	CodeTargetPath:      "TargetPath",
+	CodeEnumerate:       "Enumerate",
+	CodeAdd2:            "Add2",
+	CodeLess2:           "Less2",
+	CodeEquals2:         "Equals2",
+	CodeToNumber:        "ToNumber",
+	CodeToString:        "ToString",
+	CodePushDuplicate:   "PushDuplicate",
+	CodeStackSwap:       "StackSwap",
+	CodeGetMember:       "GetMember",
+	CodeSetMember:       "SetMember",
+	CodeIncrement:       "Increment",
+	CodeDecrement:       "Decrement",
+	CodeCallMethod:      "CallMethod",
+	CodeNewMethod:       "NewMethod",
+	CodeInstanceOf:      "InstanceOf",
+	CodeEnumerate2:      "Enumerate2",
+	CodeBitAnd:          "BitAnd",
+	CodeBitOr:           "BitOr",
+	CodeBitXor:          "BitXor",
+	CodeBitLShift:       "BitLShift",
+	CodeBitRShift:       "BitRShift",
+	CodeBitURShift:      "BitURShift",
+	CodeStrictEquals:    "StrictEquals",
+	CodeGreater:         "Greater",
+	CodeStringGreater:   "StringGreater",
+	CodeExtends:         "Extends",
+	CodeGotoFrame:       "GotoFrame",
+	CodeGetURL:          "GetURL",
+	CodeStoreRegister:   "StoreRegister",
+	CodeConstantPool:    "ConstantPool",
+	CodeWaitForFrame:    "WaitForFrame",
+	CodeSetTarget:       "SetTarget",
+	CodeGotoLabel:       "GotoLabel",
+	CodeWaitForFrame2:   "WaitForFrame2",
+	CodeDefineFunction2: "DefineFunction2",
+	CodeTry:             "Try",
+	CodeWith:            "With",
+	CodePush:            "Push",
+	CodeJump:            "Jump",
+	CodeGetURL2:         "GetURL2",
+	CodeDefineFunction:  "DefineFunction",
+	CodeIf:              "If",
+	CodeCall:            "Call",
+	CodeGotoFrame2:      "GotoFrame2",
+}
+
+func (c ActionCode) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ActionCode(0x%02x)", uint8(c))
+}
+
+// Code returns the code itself, so an ActionCode below 0x80 can be used as an
+// action without payload.
+func (c ActionCode) Code() ActionCode {
+	return c
+}
+
+// HasPayload reports whether the action has a length field and a payload.
+func (c ActionCode) HasPayload() bool {
+	return c >= 0x80
+}
+
+// LookupCode returns the code of the action name, such as "GetVariable".
+func LookupCode(name string) (ActionCode, bool) {
+	for code, codeName := range codeNames {
+		if codeName == name {
+			return code, true
+		}
+	}
+
+	return 0, false
+}
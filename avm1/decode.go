@@ -0,0 +1,497 @@
+package avm1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// payloadReader reads the fields of an action payload.
+type payloadReader struct {
+	data     []byte
+	position int
+}
+
+func (r *payloadReader) remaining() int {
+	return len(r.data) - r.position
+}
+
+func (r *payloadReader) readUint8() (uint8, error) {
+	if r.remaining() < 1 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	value := r.data[r.position]
+	r.position += 1
+
+	return value, nil
+}
+
+func (r *payloadReader) readUint16() (uint16, error) {
+	if r.remaining() < 2 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	value := binary.LittleEndian.Uint16(r.data[r.position:])
+	r.position += 2
+
+	return value, nil
+}
+
+func (r *payloadReader) readUint32() (uint32, error) {
+	if r.remaining() < 4 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	value := binary.LittleEndian.Uint32(r.data[r.position:])
+	r.position += 4
+
+	return value, nil
+}
+
+func (r *payloadReader) readString() (string, error) {
+	end := bytes.IndexByte(r.data[r.position:], 0x00)
+
+	if end < 0 {
+		return "", fmt.Errorf("string is not terminated")
+	}
+
+	value := string(r.data[r.position : r.position+end])
+	r.position += end + 1
+
+	return value, nil
+}
+
+// Decode decodes the ACTIONRECORD stream of DoAction, DoInitAction or a
+// button, including the ActionEndFlag and anything after it. The branch
+// targets and the block counts are resolved. An action whose payload cannot
+// be re-encoded to the same bytes is kept as *ActionRaw, so Encode returns
+// the original data.
+func Decode(data []byte) ([]Action, error) {
+	var actions []Action
+	var offsets []int
+
+	offset := 0
+
+	for offset < len(data) {
+		code := ActionCode(data[offset])
+		offsets = append(offsets, offset)
+
+		if !code.HasPayload() {
+			actions = append(actions, code)
+			offset += 1
+
+			continue
+		}
+		if offset+3 > len(data) {
+			return nil, fmt.Errorf("failed to decode %s at offset %d: length is truncated", code, offset)
+		}
+
+		length := int(binary.LittleEndian.Uint16(data[offset+1:]))
+
+		if offset+3+length > len(data) {
+			return nil, fmt.Errorf("failed to decode %s at offset %d: payload is truncated", code, offset)
+		}
+
+		payload := data[offset+3 : offset+3+length]
+
+		action, err := decodePayload(code, payload)
+
+		if err == nil {
+			if encoded, encodeErr := encodePayload(action); encodeErr != nil || !bytes.Equal(encoded, payload) {
+				err = fmt.Errorf("payload is not canonical")
+			}
+		}
+		if err != nil {
+			action = &ActionRaw{ActionCode: code, Data: append([]byte{}, payload...)}
+		}
+
+		actions = append(actions, action)
+		offset += 3 + length
+	}
+
+	offsets = append(offsets, offset)
+	resolve(actions, offsets)
+
+	return actions, nil
+}
+
+// resolve sets the branch targets and the block counts from the offsets of
+// the actions, which has the length of the stream as the last element.
+func resolve(actions []Action, offsets []int) {
+	index := make(map[int]int, len(offsets))
+
+	for i, offset := range offsets {
+		index[offset] = i
+	}
+
+	lookup := func(offset int) int {
+		if i, ok := index[offset]; ok {
+			return i
+		}
+
+		return -1
+	}
+
+	for i, action := range actions {
+		next := offsets[i+1]
+
+		var branch *Branch
+		var blocks []*Block
+
+		switch v := action.(type) {
+		case *ActionJump:
+			branch = &v.Branch
+		case *ActionIf:
+			branch = &v.Branch
+		case *ActionDefineFunction:
+			blocks = []*Block{&v.Body}
+		case *ActionDefineFunction2:
+			blocks = []*Block{&v.Body}
+		case *ActionWith:
+			blocks = []*Block{&v.Body}
+		case *ActionTry:
+			blocks = []*Block{&v.Try, &v.Catch, &v.Finally}
+		}
+		if branch != nil {
+			branch.Target = lookup(next + int(branch.Offset))
+		}
+
+		start := i + 1
+		startOffset := next
+
+		for j, block := range blocks {
+			end := lookup(startOffset + int(block.Size))
+
+			if end < 0 {
+				for _, rest := range blocks[j:] {
+					rest.Count = -1
+				}
+
+				break
+			}
+
+			block.Count = end - start
+			start = end
+			startOffset += int(block.Size)
+		}
+	}
+}
+
+func decodePayload(code ActionCode, payload []byte) (Action, error) {
+	r := &payloadReader{data: payload}
+
+	var action Action
+	var err error
+
+	switch code {
+	case CodeGotoFrame:
+		v := &ActionGotoFrame{}
+		v.Frame, err = r.readUint16()
+		action = v
+	case CodeGetURL:
+		v := &ActionGetURL{}
+
+		if v.URL, err = r.readString(); err == nil {
+			v.Target, err = r.readString()
+		}
+
+		action = v
+	case CodeStoreRegister:
+		v := &ActionStoreRegister{}
+		v.Register, err = r.readUint8()
+		action = v
+	case CodeConstantPool:
+		action, err = decodeConstantPool(r)
+	case CodeWaitForFrame:
+		v := &ActionWaitForFrame{}
+
+		if v.Frame, err = r.readUint16(); err == nil {
+			v.SkipCount, err = r.readUint8()
+		}
+
+		action = v
+	case CodeSetTarget:
+		v := &ActionSetTarget{}
+		v.TargetName, err = r.readString()
+		action = v
+	case CodeGotoLabel:
+		v := &ActionGotoLabel{}
+		v.Label, err = r.readString()
+		action = v
+	case CodeWaitForFrame2:
+		v := &ActionWaitForFrame2{}
+		v.SkipCount, err = r.readUint8()
+		action = v
+	case CodeDefineFunction2:
+		action, err = decodeDefineFunction2(r)
+	case CodeTry:
+		action, err = decodeTry(r)
+	case CodeWith:
+		v := &ActionWith{}
+		v.Body.Size, err = r.readUint16()
+		action = v
+	case CodePush:
+		action, err = decodePush(r)
+	case CodeJump:
+		v := &ActionJump{}
+
+		var offset uint16
+
+		offset, err = r.readUint16()
+		v.Offset = int16(offset)
+		action = v
+	case CodeIf:
+		v := &ActionIf{}
+
+		var offset uint16
+
+		offset, err = r.readUint16()
+		v.Offset = int16(offset)
+		action = v
+	case CodeGetURL2:
+		var flags uint8
+
+		flags, err = r.readUint8()
+		action = &ActionGetURL2{
+			SendVarsMethod: flags >> 6,
+			LoadTarget:     flags&0b10 != 0,
+			LoadVariables:  flags&0b1 != 0,
+		}
+	case CodeDefineFunction:
+		action, err = decodeDefineFunction(r)
+	case CodeCall:
+		action = &ActionCall{}
+	case CodeGotoFrame2:
+		v := &ActionGotoFrame2{}
+
+		var flags uint8
+
+		if flags, err = r.readUint8(); err == nil {
+			v.HasSceneBias = flags&0b10 != 0
+			v.Play = flags&0b1 != 0
+
+			if v.HasSceneBias {
+				v.SceneBias, err = r.readUint16()
+			}
+		}
+
+		action = v
+	default:
+		return nil, fmt.Errorf("unknown action code 0x%02x", uint8(code))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.remaining() != 0 {
+		return nil, fmt.Errorf("%d bytes remain in the payload", r.remaining())
+	}
+
+	return action, nil
+}
+
+func decodeConstantPool(r *payloadReader) (Action, error) {
+	count, err := r.readUint16()
+
+	if err != nil {
+		return nil, err
+	}
+
+	constants := make([]string, count)
+
+	for i := range constants {
+		if constants[i], err = r.readString(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ActionConstantPool{Constants: constants}, nil
+}
+
+func decodeDefineFunction(r *payloadReader) (Action, error) {
+	name, err := r.readString()
+
+	if err != nil {
+		return nil, err
+	}
+
+	numParams, err := r.readUint16()
+
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]string, numParams)
+
+	for i := range params {
+		if params[i], err = r.readString(); err != nil {
+			return nil, err
+		}
+	}
+
+	codeSize, err := r.readUint16()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ActionDefineFunction{
+		FunctionName: name,
+		Params:       params,
+		Body:         Block{Size: codeSize},
+	}
+
+	return result, nil
+}
+
+func decodeDefineFunction2(r *payloadReader) (Action, error) {
+	name, err := r.readString()
+
+	if err != nil {
+		return nil, err
+	}
+
+	numParams, err := r.readUint16()
+
+	if err != nil {
+		return nil, err
+	}
+
+	registerCount, err := r.readUint8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	flagsHigh, err := r.readUint8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	flagsLow, err := r.readUint8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]RegisterParam, numParams)
+
+	for i := range params {
+		if params[i].Register, err = r.readUint8(); err != nil {
+			return nil, err
+		}
+		if params[i].Name, err = r.readString(); err != nil {
+			return nil, err
+		}
+	}
+
+	codeSize, err := r.readUint16()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ActionDefineFunction2{
+		FunctionName:  name,
+		RegisterCount: registerCount,
+		Flags:         DefineFunction2Flags(uint16(flagsHigh)<<8 | uint16(flagsLow)),
+		Parameters:    params,
+		Body:          Block{Size: codeSize},
+	}
+
+	return result, nil
+}
+
+func decodeTry(r *payloadReader) (Action, error) {
+	flags, err := r.readUint8()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ActionTry{
+		CatchInRegister: flags&0b100 != 0,
+		HasFinallyBlock: flags&0b10 != 0,
+		HasCatchBlock:   flags&0b1 != 0,
+	}
+
+	for _, block := range []*Block{&result.Try, &result.Catch, &result.Finally} {
+		if block.Size, err = r.readUint16(); err != nil {
+			return nil, err
+		}
+	}
+	if result.CatchInRegister {
+		result.CatchRegister, err = r.readUint8()
+	} else {
+		result.CatchName, err = r.readString()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func decodePush(r *payloadReader) (Action, error) {
+	result := &ActionPush{}
+
+	for r.remaining() > 0 {
+		valueType, err := r.readUint8()
+
+		if err != nil {
+			return nil, err
+		}
+
+		value := PushValue{Type: PushType(valueType)}
+
+		switch value.Type {
+		case PushString:
+			value.String, err = r.readString()
+		case PushFloat:
+			var bits uint32
+
+			bits, err = r.readUint32()
+			value.Float = math.Float32frombits(bits)
+		case PushNull, PushUndefined:
+		case PushRegister:
+			value.Register, err = r.readUint8()
+		case PushBoolean:
+			var b uint8
+
+			b, err = r.readUint8()
+			value.Boolean = b != 0
+		case PushDouble:
+			var high, low uint32
+
+			if high, err = r.readUint32(); err == nil {
+				low, err = r.readUint32()
+			}
+
+			value.Double = math.Float64frombits(uint64(high)<<32 | uint64(low))
+		case PushInteger:
+			var bits uint32
+
+			bits, err = r.readUint32()
+			value.Integer = int32(bits)
+		case PushConstant8:
+			var index uint8
+
+			index, err = r.readUint8()
+			value.Constant = uint16(index)
+		case PushConstant16:
+			value.Constant, err = r.readUint16()
+		default:
+			return nil, fmt.Errorf("unknown push type: %d", valueType)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result.Values = append(result.Values, value)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package avm1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	actions := []Action{
+		&ActionConstantPool{Constants: []string{"x", "trace"}},
+		&ActionPush{Values: []PushValue{
+			{Type: PushConstant8, Constant: 0},
+			{Type: PushInteger, Integer: -3},
+			{Type: PushDouble, Double: 1.5},
+			{Type: PushFloat, Float: 0.25},
+			{Type: PushBoolean, Boolean: true},
+			{Type: PushNull},
+			{Type: PushUndefined},
+			{Type: PushRegister, Register: 2},
+			{Type: PushString, String: "hello"},
+			{Type: PushConstant16, Constant: 1},
+		}},
+		CodeSetVariable,
+		&ActionDefineFunction2{
+			FunctionName:  "f",
+			RegisterCount: 3,
+			Flags:         PreloadThis | SuppressArguments | PreloadGlobal,
+			Parameters:    []RegisterParam{{Register: 1, Name: "a"}, {Name: "b"}},
+			Body:          Block{Count: 2},
+		},
+		&ActionPush{Values: []PushValue{{Type: PushRegister, Register: 1}}},
+		CodeReturn,
+		&ActionTry{
+			HasCatchBlock: true,
+			CatchName:     "e",
+			Try:           Block{Count: 1},
+			Catch:         Block{Count: 1},
+			Finally:       Block{Count: 0},
+		},
+		CodeStop,
+		CodePlay,
+		&ActionIf{Branch: Branch{Target: 10}},
+		&ActionJump{Branch: Branch{Target: 0}},
+		&ActionGotoFrame2{HasSceneBias: true, Play: true, SceneBias: 4},
+		&ActionGetURL2{SendVarsMethod: SendVarsPost, LoadTarget: true},
+		&ActionWith{Body: Block{Count: 0}},
+		&ActionCall{},
+		CodeEnd,
+	}
+
+	require.NoError(t, Relocate(actions))
+
+	data, err := Encode(actions)
+
+	require.NoError(t, err)
+
+	decoded, err := Decode(data)
+
+	require.NoError(t, err)
+	require.Equal(t, actions, decoded)
+
+	encoded, err := Encode(decoded)
+
+	require.NoError(t, err)
+	require.Equal(t, data, encoded)
+}
+
+func TestDecodeKeepsNonCanonicalPayload(t *testing.T) {
+	data := []byte{
+		// Push true encoded as 2.
+		0x96, 0x02, 0x00, 0x05, 0x02,
+		// GotoFrame with a trailing byte.
+		0x81, 0x03, 0x00, 0x01, 0x00, 0xff,
+		// Jump into the middle of the previous action.
+		0x99, 0x02, 0x00, 0xfd, 0xff,
+		// Unknown action with payload.
+		0xf0, 0x01, 0x00, 0x2a,
+		0x00,
+	}
+
+	actions, err := Decode(data)
+
+	require.NoError(t, err)
+	require.Equal(t, &ActionRaw{ActionCode: CodePush, Data: []byte{0x05, 0x02}}, actions[0])
+	require.Equal(t, &ActionRaw{ActionCode: CodeGotoFrame, Data: []byte{0x01, 0x00, 0xff}}, actions[1])
+	require.Equal(t, -1, actions[2].(*ActionJump).Target)
+	require.Equal(t, ActionCode(0xf0), actions[3].Code())
+
+	encoded, err := Encode(actions)
+
+	require.NoError(t, err)
+	require.Equal(t, data, encoded)
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	_, err := Decode([]byte{0x96, 0x05, 0x00, 0x00})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,1058 @@
+package avm1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decompile lifts the actions into ActionScript 2 source. Conditionals,
+// loops, with, try and functions are recovered from the branches and the
+// blocks, and the classes compiled into the _global prototype pattern are
+// rewritten as class declarations. An action which does not fit any pattern
+// is written as a comment with its disassembly.
+func Decompile(actions []Action) (string, error) {
+	p := &program{actions: actions, labels: map[int]bool{}, used: map[int]bool{}}
+
+	stmts := p.decompile()
+
+	// The branches written as disassembly are known only after the first
+	// pass, so their targets are labeled in the second pass.
+	if len(p.used) > 0 {
+		p.labels, p.used = p.used, map[int]bool{}
+		stmts = p.decompile()
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+
+	w := &writer{}
+
+	for _, s := range recoverClasses(stmts) {
+		s.write(w)
+	}
+
+	return w.String(), nil
+}
+
+// program holds the state shared by the functions being decompiled.
+type program struct {
+	actions []Action
+	pool    []string
+	// labels is the targets to write as labels.
+	labels map[int]bool
+	// used is the targets of the branches written as disassembly.
+	used map[int]bool
+	err  error
+}
+
+func (p *program) decompile() []stmt {
+	p.pool = nil
+
+	s := &scope{program: p, registers: map[uint8]string{}}
+	f := s.run(0, len(p.actions), nil, nil)
+
+	return f.flush()
+}
+
+// loop is a loop enclosing the actions being decompiled.
+type loop struct {
+	head int
+	exit int
+}
+
+// scope is the registers of a function.
+type scope struct {
+	*program
+	registers map[uint8]string
+}
+
+func (s *scope) register(number uint8) *exprRegister {
+	name, ok := s.registers[number]
+
+	if !ok {
+		name = fmt.Sprintf("register%d", number)
+	}
+
+	return &exprRegister{number: number, name: name}
+}
+
+// frame is the stack and the statements of a range of actions.
+type frame struct {
+	stack []expr
+	stmts []stmt
+}
+
+func (f *frame) push(e expr) {
+	f.stack = append(f.stack, e)
+}
+
+// pop pops the stack. A value pushed before the decompiled actions is
+// written as $stack.
+func (f *frame) pop() expr {
+	if len(f.stack) == 0 {
+		return &exprName{name: "$stack"}
+	}
+
+	e := f.stack[len(f.stack)-1]
+	f.stack = f.stack[:len(f.stack)-1]
+
+	return e
+}
+
+func (f *frame) emit(s stmt) {
+	f.stmts = append(f.stmts, s)
+}
+
+// popArgs pops the number of arguments and the arguments.
+func (f *frame) popArgs() ([]expr, bool) {
+	n, ok := f.pop().(*exprNumber)
+
+	if !ok {
+		return nil, false
+	}
+
+	count, ok := n.integer()
+
+	if !ok {
+		return nil, false
+	}
+
+	args := make([]expr, count)
+
+	for i := range args {
+		args[i] = f.pop()
+	}
+
+	return args, true
+}
+
+// flush returns the statements followed by the values left on the stack.
+func (f *frame) flush() []stmt {
+	stmts := f.stmts
+
+	for _, e := range f.stack {
+		if !isPure(e) {
+			stmts = append(stmts, &stmtExpr{e: e})
+		}
+	}
+
+	return stmts
+}
+
+// run decompiles the actions from start to end with a copy of the stack.
+func (s *scope) run(start, end int, stack []expr, loops []loop) *frame {
+	f := &frame{stack: append([]expr{}, stack...)}
+
+	for i := start; i < end; {
+		if s.labels[i] {
+			f.emit(&stmtText{text: fmt.Sprintf("// L%d:", i)})
+		}
+		if next, ok := s.loop(f, i, end, loops); ok {
+			i = next
+
+			continue
+		}
+		if next, ok := s.logical(f, i, end, loops); ok {
+			i = next
+
+			continue
+		}
+
+		i = s.step(f, i, end, loops)
+	}
+
+	return f
+}
+
+// raw writes the action as disassembly.
+func (s *scope) raw(f *frame, i int) {
+	labels := map[int]string{}
+
+	if branch := branchOf(s.actions[i]); branch != nil && branch.Target >= 0 {
+		labels[branch.Target] = fmt.Sprintf("L%d", branch.Target)
+		s.used[branch.Target] = true
+	}
+
+	line, err := formatAction(s.actions[i], i, labels)
+
+	if err != nil && s.err == nil {
+		s.err = fmt.Errorf("failed to decompile actions[%d]: %w", i, err)
+	}
+
+	f.emit(&stmtText{text: "// " + line})
+}
+
+// jump returns break or continue for a branch to the target.
+func jump(target int, loops []loop) (stmt, bool) {
+	if len(loops) == 0 {
+		return nil, false
+	}
+
+	switch l := loops[len(loops)-1]; target {
+	case l.exit:
+		return &stmtText{text: "break;"}, true
+	case l.head:
+		return &stmtText{text: "continue;"}, true
+	default:
+		return nil, false
+	}
+}
+
+// loop decompiles a loop whose head is the action at i.
+func (s *scope) loop(f *frame, i, end int, loops []loop) (int, bool) {
+	for _, l := range loops {
+		if l.head == i {
+			return 0, false
+		}
+	}
+
+	j := -1
+
+	for k := end - 1; k >= i; k-- {
+		if branch := branchOf(s.actions[k]); branch != nil && branch.Target == i {
+			j = k
+
+			break
+		}
+	}
+	if j < 0 {
+		return 0, false
+	}
+
+	exit := j + 1
+	inner := append(append([]loop{}, loops...), loop{head: i, exit: exit})
+
+	if _, ok := s.actions[j].(*ActionIf); ok {
+		body := s.run(i, j, f.stack, inner)
+		cond := body.pop()
+
+		f.emit(&stmtDoWhile{body: body.stmts, cond: cond})
+
+		return exit, true
+	}
+	if s.forIn(f, i, j, inner) {
+		return exit, true
+	}
+
+	// The condition of a while loop is computed without any branch and
+	// leaves the loop when it is false.
+	for k := i; k < j; k++ {
+		if branch, ok := s.actions[k].(*ActionIf); ok && branch.Target == exit {
+			cond := s.run(i, k, f.stack, inner)
+
+			if len(cond.stmts) != 0 || len(cond.stack) != len(f.stack)+1 {
+				break
+			}
+
+			body := s.run(k+1, j, f.stack, inner)
+
+			f.emit(&stmtBlock{header: "while (" + negate(cond.pop()).format() + ")", body: body.stmts})
+
+			return exit, true
+		}
+		if branchOf(s.actions[k]) != nil || blocksOf(s.actions[k]) != nil {
+			break
+		}
+	}
+
+	body := s.run(i, j, f.stack, inner)
+
+	f.emit(&stmtBlock{header: "while (true)", body: body.stmts})
+
+	return exit, true
+}
+
+// forIn decompiles the loop over the names pushed by Enumerate, which is
+// compiled as follows.
+//
+//	L1:
+//	storeRegister r:0
+//	push null
+//	equals2
+//	if L2
+//	...
+//	jump L1
+//	L2:
+func (s *scope) forIn(f *frame, i, j int, loops []loop) bool {
+	if len(f.stack) == 0 || i+4 > j {
+		return false
+	}
+
+	enumerate, ok := f.stack[len(f.stack)-1].(*exprEnumerate)
+
+	if !ok {
+		return false
+	}
+
+	store, ok := s.actions[i].(*ActionStoreRegister)
+
+	if !ok {
+		return false
+	}
+	if push, ok := s.actions[i+1].(*ActionPush); !ok || len(push.Values) != 1 || push.Values[0].Type != PushNull {
+		return false
+	}
+	if code := s.actions[i+2].Code(); code != CodeEquals2 && code != CodeStrictEquals {
+		return false
+	}
+	if branch, ok := s.actions[i+3].(*ActionIf); !ok || branch.Target != j+1 {
+		return false
+	}
+
+	f.pop()
+
+	body := s.run(i+4, j, f.stack, loops)
+	key := s.register(store.Register).format()
+	stmts := body.stmts
+
+	if len(stmts) > 0 {
+		switch first := stmts[0].(type) {
+		case *stmtVar:
+			if r, ok := first.value.(*exprRegister); ok && r.number == store.Register {
+				key = "var " + first.name
+				stmts = stmts[1:]
+			}
+		case *stmtExpr:
+			if assign, ok := first.e.(*exprAssign); ok {
+				if r, ok := assign.value.(*exprRegister); ok && r.number == store.Register {
+					key = assign.target.format()
+					stmts = stmts[1:]
+				}
+			}
+		}
+	}
+
+	f.emit(&stmtBlock{header: "for (" + key + " in " + enumerate.object.format() + ")", body: stmts})
+
+	return true
+}
+
+// logical decompiles && and ||, which are compiled as follows.
+//
+//	pushDuplicate
+//	not    ; only for &&
+//	if L1
+//	pop
+//	...    ; the right operand
+//	L1:
+func (s *scope) logical(f *frame, i, end int, loops []loop) (int, bool) {
+	if s.actions[i] != CodePushDuplicate || len(f.stack) == 0 {
+		return 0, false
+	}
+
+	k := i + 1
+	op := "||"
+	prec := precOr
+
+	if k < end && s.actions[k] == CodeNot {
+		k += 1
+		op = "&&"
+		prec = precAnd
+	}
+	if k+1 >= end || s.actions[k+1] != CodePop {
+		return 0, false
+	}
+
+	branch, ok := s.actions[k].(*ActionIf)
+
+	if !ok || branch.Target <= k+1 || branch.Target > end {
+		return 0, false
+	}
+
+	left := f.stack[len(f.stack)-1]
+	right := s.run(k+2, branch.Target, f.stack[:len(f.stack)-1], loops)
+
+	if len(right.stmts) != 0 || len(right.stack) != len(f.stack) {
+		return 0, false
+	}
+
+	f.stack[len(f.stack)-1] = &exprBinary{op: op, prec: prec, left: left, right: right.pop()}
+
+	return branch.Target, true
+}
+
+// conditional decompiles the If action at i. The actions which follow the
+// If run when the condition is false.
+func (s *scope) conditional(f *frame, i, end int, loops []loop, branch *ActionIf) int {
+	target := branch.Target
+
+	if target <= i || target > end {
+		cond := f.pop()
+
+		if brk, ok := jump(target, loops); ok {
+			f.emit(&stmtIf{cond: cond, then: []stmt{brk}})
+		} else {
+			f.push(cond)
+			s.raw(f, i)
+		}
+
+		return i + 1
+	}
+
+	cond := f.pop()
+	thenEnd, elseEnd := target, -1
+
+	if target-1 > i {
+		if skip, ok := s.actions[target-1].(*ActionJump); ok && skip.Target > target && skip.Target <= end {
+			thenEnd, elseEnd = target-1, skip.Target
+		}
+	}
+
+	fall := s.run(i+1, thenEnd, f.stack, loops)
+
+	if elseEnd < 0 {
+		f.emit(&stmtIf{cond: negate(cond), then: fall.stmts})
+
+		return target
+	}
+
+	jumped := s.run(target, elseEnd, f.stack, loops)
+
+	if len(fall.stmts) == 0 && len(jumped.stmts) == 0 && len(fall.stack) == len(f.stack)+1 && len(jumped.stack) == len(f.stack)+1 {
+		if not, ok := cond.(*exprUnary); ok && not.op == "!" {
+			f.push(&exprTernary{cond: not.operand, yes: fall.pop(), no: jumped.pop()})
+		} else {
+			f.push(&exprTernary{cond: cond, yes: jumped.pop(), no: fall.pop()})
+		}
+
+		return elseEnd
+	}
+	if not, ok := cond.(*exprUnary); ok && not.op == "!" {
+		f.emit(&stmtIf{cond: not.operand, then: fall.stmts, els: jumped.stmts})
+	} else {
+		f.emit(&stmtIf{cond: cond, then: jumped.stmts, els: fall.stmts})
+	}
+
+	return elseEnd
+}
+
+// function decompiles the body of a function in a new scope.
+func (s *scope) function(name string, params []string, registers map[uint8]string, start, end int) *exprFunction {
+	inner := &scope{program: s.program, registers: registers}
+	body := inner.run(start, end, nil, nil)
+
+	return &exprFunction{name: name, params: params, body: body.flush()}
+}
+
+// blockEnd returns the index after the block starting at start, or -1 when
+// the block does not fit in the range.
+func blockEnd(start int, block Block, end int) int {
+	if block.Count < 0 || start+block.Count > end {
+		return -1
+	}
+
+	return start + block.Count
+}
+
+var properties = []string{
+	"_x", "_y", "_xscale", "_yscale", "_currentframe", "_totalframes", "_alpha", "_visible",
+	"_width", "_height", "_rotation", "_target", "_framesloaded", "_name", "_droptarget", "_url",
+	"_highquality", "_focusrect", "_soundbuftime", "_quality", "_xmouse", "_ymouse",
+}
+
+// property returns the name of the property index of GetProperty and
+// SetProperty.
+func property(e expr) expr {
+	if n, ok := e.(*exprNumber); ok {
+		if index, ok := n.integer(); ok && index < len(properties) {
+			return &exprName{name: properties[index]}
+		}
+	}
+
+	return e
+}
+
+// variable returns the variable whose name is the expression.
+func variable(name expr) expr {
+	if s, ok := name.(*exprString); ok && isPath(s.value) {
+		return &exprName{name: s.value}
+	}
+
+	return newCall("eval", name)
+}
+
+var binaryOperators = map[ActionCode]struct {
+	op   string
+	prec int
+}{
+	CodeAdd:           {"+", precAdditive},
+	CodeAdd2:          {"+", precAdditive},
+	CodeSubtract:      {"-", precAdditive},
+	CodeMultiply:      {"*", precMultiplicative},
+	CodeDivide:        {"/", precMultiplicative},
+	CodeModulo:        {"%", precMultiplicative},
+	CodeEquals:        {"==", precEquality},
+	CodeEquals2:       {"==", precEquality},
+	CodeStrictEquals:  {"===", precEquality},
+	CodeLess:          {"<", precRelational},
+	CodeLess2:         {"<", precRelational},
+	CodeGreater:       {">", precRelational},
+	CodeAnd:           {"&&", precAnd},
+	CodeOr:            {"||", precOr},
+	CodeStringAdd:     {"add", precAdditive},
+	CodeStringEquals:  {"eq", precEquality},
+	CodeStringLess:    {"lt", precRelational},
+	CodeStringGreater: {"gt", precRelational},
+	CodeBitAnd:        {"&", precBitAnd},
+	CodeBitOr:         {"|", precBitOr},
+	CodeBitXor:        {"^", precBitXor},
+	CodeBitLShift:     {"<<", precShift},
+	CodeBitRShift:     {">>", precShift},
+	CodeBitURShift:    {">>>", precShift},
+	CodeInstanceOf:    {"instanceof", precRelational},
+}
+
+// functions are the actions which pop one value and push the result of the
+// global function.
+var functions = map[ActionCode]string{
+	CodeToNumber:       "Number",
+	CodeToString:       "String",
+	CodeToInteger:      "int",
+	CodeStringLength:   "length",
+	CodeMBStringLength: "mblength",
+	CodeCharToAscii:    "ord",
+	CodeAsciiToChar:    "chr",
+	CodeMBCharToAscii:  "mbord",
+	CodeMBAsciiToChar:  "mbchr",
+	CodeRandomNumber:   "random",
+	CodeTargetPath:     "targetPath",
+}
+
+// commands are the actions which pop nothing and call the global function.
+var commands = map[ActionCode]string{
+	CodePlay:          "play",
+	CodeStop:          "stop",
+	CodeNextFrame:     "nextFrame",
+	CodePreviousFrame: "prevFrame",
+	CodeToggleQuality: "toggleHighQuality",
+	CodeStopSounds:    "stopAllSounds",
+	CodeEndDrag:       "stopDrag",
+}
+
+// step decompiles the action at i and returns the index of the next action.
+func (s *scope) step(f *frame, i, end int, loops []loop) int {
+	action := s.actions[i]
+
+	switch v := action.(type) {
+	case *ActionIf:
+		return s.conditional(f, i, end, loops, v)
+	case *ActionJump:
+		if brk, ok := jump(v.Target, loops); ok {
+			f.emit(brk)
+		} else {
+			s.raw(f, i)
+		}
+	case *ActionDefineFunction:
+		bodyEnd := blockEnd(i+1, v.Body, end)
+
+		if bodyEnd < 0 {
+			s.raw(f, i)
+
+			break
+		}
+
+		fn := s.function(v.FunctionName, v.Params, map[uint8]string{}, i+1, bodyEnd)
+
+		if fn.name != "" {
+			f.emit(&stmtFunction{function: fn})
+		} else {
+			f.push(fn)
+		}
+
+		return bodyEnd
+	case *ActionDefineFunction2:
+		bodyEnd := blockEnd(i+1, v.Body, end)
+
+		if bodyEnd < 0 {
+			s.raw(f, i)
+
+			break
+		}
+
+		registers := map[uint8]string{}
+		next := uint8(1)
+
+		for _, preload := range []struct {
+			flag DefineFunction2Flags
+			name string
+		}{
+			{PreloadThis, "this"},
+			{PreloadArguments, "arguments"},
+			{PreloadSuper, "super"},
+			{PreloadRoot, "_root"},
+			{PreloadParent, "_parent"},
+			{PreloadGlobal, "_global"},
+		} {
+			if v.Flags.Contains(preload.flag) {
+				registers[next] = preload.name
+				next += 1
+			}
+		}
+
+		params := make([]string, len(v.Parameters))
+
+		for j, param := range v.Parameters {
+			params[j] = param.Name
+
+			if param.Register != 0 {
+				registers[param.Register] = param.Name
+			}
+		}
+
+		fn := s.function(v.FunctionName, params, registers, i+1, bodyEnd)
+
+		if fn.name != "" {
+			f.emit(&stmtFunction{function: fn})
+		} else {
+			f.push(fn)
+		}
+
+		return bodyEnd
+	case *ActionWith:
+		bodyEnd := blockEnd(i+1, v.Body, end)
+
+		if bodyEnd < 0 {
+			s.raw(f, i)
+
+			break
+		}
+
+		object := f.pop()
+		body := s.run(i+1, bodyEnd, f.stack, loops)
+
+		f.emit(&stmtBlock{header: "with (" + object.format() + ")", body: body.stmts})
+
+		return bodyEnd
+	case *ActionTry:
+		return s.try(f, i, end, loops, v)
+	case *ActionWaitForFrame:
+		bodyEnd := i + 1 + int(v.SkipCount)
+
+		if bodyEnd > end {
+			s.raw(f, i)
+
+			break
+		}
+
+		body := s.run(i+1, bodyEnd, f.stack, loops)
+
+		f.emit(&stmtBlock{header: fmt.Sprintf("ifFrameLoaded(%d)", int(v.Frame)+1), body: body.stmts})
+
+		return bodyEnd
+	case *ActionWaitForFrame2:
+		bodyEnd := i + 1 + int(v.SkipCount)
+
+		if bodyEnd > end {
+			s.raw(f, i)
+
+			break
+		}
+
+		frame := f.pop()
+		body := s.run(i+1, bodyEnd, f.stack, loops)
+
+		f.emit(&stmtBlock{header: "ifFrameLoaded(" + frame.format() + ")", body: body.stmts})
+
+		return bodyEnd
+	case *ActionConstantPool:
+		s.pool = v.Constants
+	case *ActionPush:
+		for _, value := range v.Values {
+			f.push(s.pushValue(value))
+		}
+	case *ActionStoreRegister:
+		value := f.pop()
+		register := s.register(v.Register)
+
+		if r, ok := value.(*exprRegister); !ok || r.number != v.Register {
+			f.emit(&stmtExpr{e: &exprAssign{target: register, value: value}})
+		}
+
+		f.push(register)
+	case *ActionGotoFrame:
+		name := "gotoAndStop"
+
+		if i+1 < end && s.actions[i+1] == CodePlay {
+			name = "gotoAndPlay"
+			i += 1
+		}
+
+		f.emit(&stmtExpr{e: newCall(name, newNumber(float64(v.Frame)+1, 64))})
+	case *ActionGotoLabel:
+		name := "gotoAndStop"
+
+		if i+1 < end && s.actions[i+1] == CodePlay {
+			name = "gotoAndPlay"
+			i += 1
+		}
+
+		f.emit(&stmtExpr{e: newCall(name, &exprString{value: v.Label})})
+	case *ActionGotoFrame2:
+		frame := f.pop()
+		name := "gotoAndStop"
+
+		if v.Play {
+			name = "gotoAndPlay"
+		}
+		if v.HasSceneBias {
+			frame = &exprBinary{op: "+", prec: precAdditive, left: frame, right: newNumber(float64(v.SceneBias), 64)}
+		}
+
+		f.emit(&stmtExpr{e: newCall(name, frame)})
+	case *ActionGetURL:
+		if command := strings.TrimPrefix(v.URL, "FSCommand:"); command != v.URL {
+			f.emit(&stmtExpr{e: newCall("fscommand", &exprString{value: command}, &exprString{value: v.Target})})
+		} else {
+			f.emit(&stmtExpr{e: newCall("getURL", &exprString{value: v.URL}, &exprString{value: v.Target})})
+		}
+	case *ActionGetURL2:
+		target := f.pop()
+		url := f.pop()
+		args := []expr{url, target}
+
+		switch v.SendVarsMethod {
+		case SendVarsGet:
+			args = append(args, &exprString{value: "GET"})
+		case SendVarsPost:
+			args = append(args, &exprString{value: "POST"})
+		}
+
+		name := "getURL"
+
+		if v.LoadVariables {
+			name = "loadVariables"
+		} else if v.LoadTarget {
+			name = "loadMovie"
+		}
+
+		f.emit(&stmtExpr{e: newCall(name, args...)})
+	case *ActionSetTarget:
+		f.emit(&stmtExpr{e: newCall("setTarget", &exprString{value: v.TargetName})})
+	case *ActionCall:
+		f.emit(&stmtExpr{e: newCall("call", f.pop())})
+	case ActionCode:
+		if !s.operation(f, v) {
+			s.raw(f, i)
+		}
+	default:
+		s.raw(f, i)
+	}
+
+	return i + 1
+}
+
+// try decompiles the Try action at i.
+func (s *scope) try(f *frame, i, end int, loops []loop, v *ActionTry) int {
+	tryEnd := blockEnd(i+1, v.Try, end)
+
+	if tryEnd < 0 {
+		s.raw(f, i)
+
+		return i + 1
+	}
+
+	catchEnd := blockEnd(tryEnd, v.Catch, end)
+
+	if catchEnd < 0 {
+		s.raw(f, i)
+
+		return i + 1
+	}
+
+	finallyEnd := blockEnd(catchEnd, v.Finally, end)
+
+	if finallyEnd < 0 {
+		s.raw(f, i)
+
+		return i + 1
+	}
+
+	// The try block ends with a jump over the catch block.
+	bodyEnd := tryEnd
+
+	if tryEnd > i+1 {
+		if skip, ok := s.actions[tryEnd-1].(*ActionJump); ok && skip.Target == catchEnd {
+			bodyEnd = tryEnd - 1
+		}
+	}
+
+	result := &stmtTry{
+		body:        s.run(i+1, bodyEnd, f.stack, loops).stmts,
+		hasCatch:    v.HasCatchBlock,
+		catchName:   v.CatchName,
+		catchBody:   s.run(tryEnd, catchEnd, f.stack, loops).stmts,
+		hasFinally:  v.HasFinallyBlock,
+		finallyBody: s.run(catchEnd, finallyEnd, f.stack, loops).stmts,
+	}
+
+	if v.CatchInRegister {
+		result.catchName = s.register(v.CatchRegister).format()
+	}
+	if !result.hasCatch && !result.hasFinally {
+		result.hasFinally = true
+	}
+
+	f.emit(result)
+
+	return finallyEnd
+}
+
+func (s *scope) pushValue(value PushValue) expr {
+	switch value.Type {
+	case PushString:
+		return &exprString{value: value.String}
+	case PushFloat:
+		return newNumber(float64(value.Float), 32)
+	case PushNull:
+		return &exprName{name: "null"}
+	case PushUndefined:
+		return &exprName{name: "undefined"}
+	case PushRegister:
+		return s.register(value.Register)
+	case PushBoolean:
+		return &exprName{name: fmt.Sprint(value.Boolean)}
+	case PushDouble:
+		return newNumber(value.Double, 64)
+	case PushInteger:
+		return newNumber(float64(value.Integer), 64)
+	case PushConstant8, PushConstant16:
+		if int(value.Constant) < len(s.pool) {
+			return &exprString{value: s.pool[value.Constant]}
+		}
+
+		return &exprName{name: fmt.Sprintf("$constant%d", value.Constant)}
+	default:
+		return &exprName{name: "undefined"}
+	}
+}
+
+// operation decompiles the action without payload. It returns false when the
+// operands do not fit.
+func (s *scope) operation(f *frame, code ActionCode) bool {
+	if op, ok := binaryOperators[code]; ok {
+		right := f.pop()
+		left := f.pop()
+		f.push(&exprBinary{op: op.op, prec: op.prec, left: left, right: right})
+
+		return true
+	}
+	if name, ok := functions[code]; ok {
+		f.push(newCall(name, f.pop()))
+
+		return true
+	}
+	if name, ok := commands[code]; ok {
+		f.emit(&stmtExpr{e: newCall(name)})
+
+		return true
+	}
+
+	switch code {
+	case CodeEnd:
+	case CodeNot:
+		f.push(negate(f.pop()))
+	case CodeTypeOf:
+		f.push(&exprUnary{op: "typeof ", operand: f.pop()})
+	case CodeIncrement:
+		f.push(&exprBinary{op: "+", prec: precAdditive, left: f.pop(), right: newNumber(1, 64)})
+	case CodeDecrement:
+		f.push(&exprBinary{op: "-", prec: precAdditive, left: f.pop(), right: newNumber(1, 64)})
+	case CodeGetTime:
+		f.push(newCall("getTimer"))
+	case CodeStringExtract, CodeMBStringExtract:
+		count := f.pop()
+		index := f.pop()
+		name := "substring"
+
+		if code == CodeMBStringExtract {
+			name = "mbsubstring"
+		}
+
+		f.push(newCall(name, f.pop(), index, count))
+	case CodePop:
+		if e := f.pop(); !isPure(e) {
+			f.emit(&stmtExpr{e: e})
+		}
+	case CodePushDuplicate:
+		e := f.pop()
+		f.push(e)
+		f.push(e)
+	case CodeStackSwap:
+		a := f.pop()
+		b := f.pop()
+		f.push(a)
+		f.push(b)
+	case CodeGetVariable:
+		f.push(variable(f.pop()))
+	case CodeSetVariable:
+		value := f.pop()
+		name := f.pop()
+
+		if target := variable(name); isPure(target) {
+			f.emit(&stmtExpr{e: &exprAssign{target: target, value: value}})
+		} else {
+			f.emit(&stmtExpr{e: newCall("set", name, value)})
+		}
+	case CodeDefineLocal:
+		value := f.pop()
+		name := f.pop()
+
+		if n, ok := name.(*exprString); ok && isIdentifier(n.value) {
+			f.emit(&stmtVar{name: n.value, value: value})
+		} else {
+			f.emit(&stmtExpr{e: newCall("set", name, value)})
+		}
+	case CodeDefineLocal2:
+		name := f.pop()
+
+		if n, ok := name.(*exprString); ok && isIdentifier(n.value) {
+			f.emit(&stmtVar{name: n.value})
+		} else {
+			return false
+		}
+	case CodeGetMember:
+		name := f.pop()
+		f.push(&exprMember{object: f.pop(), name: name})
+	case CodeSetMember:
+		value := f.pop()
+		name := f.pop()
+		object := f.pop()
+		f.emit(&stmtExpr{e: &exprAssign{target: &exprMember{object: object, name: name}, value: value}})
+	case CodeDelete:
+		name := f.pop()
+		f.push(&exprUnary{op: "delete ", operand: &exprMember{object: f.pop(), name: name}})
+	case CodeDelete2:
+		f.push(&exprUnary{op: "delete ", operand: variable(f.pop())})
+	case CodeCallFunction, CodeNewObject:
+		callee := variable(f.pop())
+		args, ok := f.popArgs()
+
+		if !ok {
+			return false
+		}
+
+		f.push(&exprCall{callee: callee, args: args, isNew: code == CodeNewObject})
+	case CodeCallMethod, CodeNewMethod:
+		name := f.pop()
+		object := f.pop()
+		args, ok := f.popArgs()
+
+		if !ok {
+			return false
+		}
+
+		callee := expr(&exprMember{object: object, name: name})
+
+		if n, ok := name.(*exprName); ok && n.name == "undefined" {
+			callee = object
+		}
+		if n, ok := name.(*exprString); ok && n.value == "" {
+			callee = object
+		}
+
+		f.push(&exprCall{callee: callee, args: args, isNew: code == CodeNewMethod})
+	case CodeInitArray:
+		items, ok := f.popArgs()
+
+		if !ok {
+			return false
+		}
+
+		f.push(&exprArray{items: items})
+	case CodeInitObject:
+		n, ok := f.pop().(*exprNumber)
+
+		if !ok {
+			return false
+		}
+
+		count, ok := n.integer()
+
+		if !ok {
+			return false
+		}
+
+		object := &exprObject{names: make([]expr, count), values: make([]expr, count)}
+
+		for j := count - 1; j >= 0; j-- {
+			object.values[j] = f.pop()
+			object.names[j] = f.pop()
+		}
+
+		f.push(object)
+	case CodeReturn:
+		f.emit(&stmtReturn{value: f.pop()})
+	case CodeThrow:
+		f.emit(&stmtThrow{value: f.pop()})
+	case CodeTrace:
+		f.emit(&stmtExpr{e: newCall("trace", f.pop())})
+	case CodeRemoveSprite:
+		f.emit(&stmtExpr{e: newCall("removeMovieClip", f.pop())})
+	case CodeSetTarget2:
+		f.emit(&stmtExpr{e: newCall("setTarget", f.pop())})
+	case CodeExtends:
+		superclass := f.pop()
+		f.emit(&stmtExtends{subclass: f.pop(), superclass: superclass})
+	case CodeImplementsOp:
+		class := f.pop()
+		interfaces, ok := f.popArgs()
+
+		if !ok {
+			return false
+		}
+
+		f.emit(&stmtImplements{class: class, interfaces: interfaces})
+	case CodeCastOp:
+		object := f.pop()
+		f.push(&exprCall{callee: f.pop(), args: []expr{object}})
+	case CodeEnumerate:
+		f.push(&exprEnumerate{object: variable(f.pop())})
+	case CodeEnumerate2:
+		f.push(&exprEnumerate{object: f.pop()})
+	case CodeGetProperty:
+		index := f.pop()
+		f.push(newCall("getProperty", f.pop(), property(index)))
+	case CodeSetProperty:
+		value := f.pop()
+		index := f.pop()
+		f.emit(&stmtExpr{e: newCall("setProperty", f.pop(), property(index), value)})
+	case CodeCloneSprite:
+		depth := f.pop()
+		target := f.pop()
+		f.emit(&stmtExpr{e: newCall("duplicateMovieClip", f.pop(), target, depth)})
+	case CodeStartDrag:
+		target := f.pop()
+		lock := f.pop()
+		constrain, ok := f.pop().(*exprNumber)
+
+		if !ok {
+			return false
+		}
+
+		args := []expr{target, lock}
+
+		if constrain.value != 0 {
+			bounds := make([]expr, 4)
+
+			for j := 3; j >= 0; j-- {
+				bounds[j] = f.pop()
+			}
+
+			args = append(args, bounds...)
+		}
+
+		f.emit(&stmtExpr{e: newCall("startDrag", args...)})
+	case CodeFSCommand2:
+		args, ok := f.popArgs()
+
+		if !ok {
+			return false
+		}
+
+		f.emit(&stmtExpr{e: newCall("FSCommand2", args...)})
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,335 @@
+package avm1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decompileForTest(t *testing.T, text string) string {
+	t.Helper()
+
+	actions, err := Parse(text)
+
+	require.NoError(t, err)
+
+	source, err := Decompile(actions)
+
+	require.NoError(t, err)
+
+	return source
+}
+
+func TestDecompileStatements(t *testing.T) {
+	source := decompileForTest(t, `
+constantPool "i", "trace"
+push "i", 0
+setVariable
+L1:
+push "i"
+getVariable
+push 3
+less2
+not
+if L2
+push "i"
+getVariable
+push 1
+push "trace"
+callFunction
+pop
+push "i", "i"
+getVariable
+increment
+setVariable
+jump L1
+L2:
+push "i"
+getVariable
+push 3
+equals2
+pushDuplicate
+not
+if L3
+pop
+push "done"
+getVariable
+L3:
+not
+if L4
+push "yes"
+trace
+jump L5
+L4:
+push "no"
+trace
+L5:
+defineFunction2 "f" (r:1 "a") registers=2 flags=SuppressThis|SuppressArguments|SuppressSuper end=L6
+  push r:1, 2
+  multiply
+  return
+L6:
+push "x", 1, 1, "f"
+callFunction
+push 0
+greater
+if L7
+push "a"
+jump L8
+L7:
+push "b"
+L8:
+setVariable
+end
+`)
+
+	require.Equal(t, `i = 0;
+while (i < 3) {
+    trace(i);
+    i = i + 1;
+}
+if (i == 3 && done) {
+    trace("yes");
+} else {
+    trace("no");
+}
+function f(a) {
+    return a * 2;
+}
+x = f(1) > 0 ? "b" : "a";
+`, source)
+}
+
+func TestDecompileBlocks(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name: "with",
+			text: `
+push "clip"
+getVariable
+with end=L1
+  push "_x", 10
+  setVariable
+  play
+L1:
+`,
+			expected: `with (clip) {
+    _x = 10;
+    play();
+}
+`,
+		},
+		{
+			name: "try",
+			text: `
+try "e" hasCatch hasFinally catch=L2 finally=L3 end=L4
+  push 0, "load"
+  callFunction
+  pop
+  jump L3
+L2:
+  push "e"
+  getVariable
+  trace
+L3:
+  push 0, "close"
+  callFunction
+  pop
+L4:
+`,
+			expected: `try {
+    load();
+} catch (e) {
+    trace(e);
+} finally {
+    close();
+}
+`,
+		},
+		{
+			name: "try in register",
+			text: `
+try r:1 hasCatch catch=L2 finally=L2 end=L2
+  push "oops"
+  throw
+  jump L2
+L2:
+`,
+			expected: `try {
+    throw "oops";
+} catch (register1) {
+}
+`,
+		},
+		{
+			name: "for in",
+			text: `
+push "items"
+getVariable
+enumerate2
+L1:
+storeRegister r:0
+push null
+equals2
+if L2
+push "key", r:0
+setVariable
+push "key"
+getVariable
+trace
+jump L1
+L2:
+`,
+			expected: `for (key in items) {
+    trace(key);
+}
+`,
+		},
+		{
+			name: "do while",
+			text: `
+L1:
+push "i", "i"
+getVariable
+increment
+setVariable
+push "i"
+getVariable
+push 3
+less2
+if L1
+`,
+			expected: `do {
+    i = i + 1;
+} while (i < 3);
+`,
+		},
+		{
+			name: "break",
+			text: `
+L1:
+push "running"
+getVariable
+not
+if L3
+push "done"
+getVariable
+not
+if L2
+jump L3
+L2:
+push 0, "tick"
+callFunction
+pop
+jump L1
+L3:
+`,
+			expected: `while (running) {
+    if (done) {
+        break;
+    }
+    tick();
+}
+`,
+		},
+	} {
+		source := decompileForTest(t, c.text)
+
+		require.Equal(t, c.expected, source, c.name)
+	}
+}
+
+func TestDecompileClass(t *testing.T) {
+	source := decompileForTest(t, `
+push "_global"
+getVariable
+push "pkg"
+getMember
+not
+not
+if L1
+push "_global"
+getVariable
+push "pkg", 0, "Object"
+newObject
+setMember
+L1:
+push "_global"
+getVariable
+push "pkg"
+getMember
+push "Box"
+getMember
+not
+not
+if L4
+push "_global"
+getVariable
+push "pkg"
+getMember
+push "Box"
+defineFunction2 "" (r:2 "size") registers=3 flags=PreloadThis end=L2
+  push r:1, "size", r:2
+  setMember
+L2:
+storeRegister r:1
+setMember
+push r:1, "Base"
+getVariable
+extends
+push r:1, "prototype"
+getMember
+storeRegister r:2
+pop
+push r:2, "size", 0
+setMember
+push r:2, "area"
+defineFunction2 "" () registers=2 flags=PreloadThis end=L3
+  push r:1, "size"
+  getMember
+  push r:1, "size"
+  getMember
+  multiply
+  return
+L3:
+setMember
+push 1, null, r:2, 3, "_global"
+getVariable
+push "ASSetPropFlags"
+callMethod
+pop
+L4:
+`)
+
+	require.Equal(t, `class pkg.Box extends Base {
+    var size = 0;
+    function Box(size) {
+        this.size = size;
+    }
+    function area() {
+        return this.size * this.size;
+    }
+}
+`, source)
+}
+
+func TestDecompileFallback(t *testing.T) {
+	source := decompileForTest(t, `
+push "a"
+trace
+L1:
+stop
+jump #3
+raw 0xf0 01
+`)
+
+	require.Equal(t, `trace("a");
+stop();
+// jump #3
+// raw 0xf0 01
+`, source)
+}
@@ -0,0 +1,333 @@
+package avm1
+
+import (
+	"fmt"
+	"math"
+)
+
+// EncodeAction encodes a single ACTIONRECORD.
+func EncodeAction(action Action) ([]byte, error) {
+	if action == nil {
+		return nil, fmt.Errorf("cannot encode because action is nil")
+	}
+
+	code := action.Code()
+
+	if !code.HasPayload() {
+		if _, ok := action.(ActionCode); !ok {
+			return nil, fmt.Errorf("cannot encode %T because its code 0x%02x has no payload", action, uint8(code))
+		}
+
+		return []byte{uint8(code)}, nil
+	}
+
+	payload, err := encodePayload(action)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", code, err)
+	}
+	if len(payload) > 0xffff {
+		return nil, fmt.Errorf("failed to encode %s: too large payload: %d bytes", code, len(payload))
+	}
+
+	data := []byte{uint8(code), uint8(len(payload)), uint8(len(payload) >> 8)}
+
+	return append(data, payload...), nil
+}
+
+// Encode encodes the actions in order. The branch offsets and the block sizes
+// are written as they are, so call Relocate first after editing the actions.
+func Encode(actions []Action) ([]byte, error) {
+	var data []byte
+
+	for i, action := range actions {
+		actionData, err := EncodeAction(action)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode actions[%d]: %w", i, err)
+		}
+
+		data = append(data, actionData...)
+	}
+
+	return data, nil
+}
+
+// Offsets returns the byte offset of each action as encoded. The extra last
+// element is the length of the whole stream.
+func Offsets(actions []Action) ([]int, error) {
+	offsets := make([]int, len(actions)+1)
+
+	for i, action := range actions {
+		actionData, err := EncodeAction(action)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode actions[%d]: %w", i, err)
+		}
+
+		offsets[i+1] = offsets[i] + len(actionData)
+	}
+
+	return offsets, nil
+}
+
+// Relocate recomputes the branch offsets from the branch targets and the
+// block sizes from the block counts. Branches whose Target is -1 and blocks
+// whose Count is -1 are left as they are.
+func Relocate(actions []Action) error {
+	offsets, err := Offsets(actions)
+
+	if err != nil {
+		return err
+	}
+
+	blockSize := func(start, count int) (uint16, error) {
+		if start+count > len(actions) {
+			return 0, fmt.Errorf("block of %d actions exceeds the actions", count)
+		}
+
+		size := offsets[start+count] - offsets[start]
+
+		if size > 0xffff {
+			return 0, fmt.Errorf("too large block: %d bytes", size)
+		}
+
+		return uint16(size), nil
+	}
+
+	for i, action := range actions {
+		var branch *Branch
+		var blocks []*Block
+
+		switch v := action.(type) {
+		case *ActionJump:
+			branch = &v.Branch
+		case *ActionIf:
+			branch = &v.Branch
+		case *ActionDefineFunction:
+			blocks = []*Block{&v.Body}
+		case *ActionDefineFunction2:
+			blocks = []*Block{&v.Body}
+		case *ActionWith:
+			blocks = []*Block{&v.Body}
+		case *ActionTry:
+			blocks = []*Block{&v.Try, &v.Catch, &v.Finally}
+		}
+		if branch != nil && branch.Target >= 0 {
+			if branch.Target > len(actions) {
+				return fmt.Errorf("failed to relocate actions[%d]: target %d is out of range", i, branch.Target)
+			}
+
+			offset := offsets[branch.Target] - offsets[i+1]
+
+			if offset < math.MinInt16 || offset > math.MaxInt16 {
+				return fmt.Errorf("failed to relocate actions[%d]: too far branch: %d bytes", i, offset)
+			}
+
+			branch.Offset = int16(offset)
+		}
+
+		start := i + 1
+
+		for _, block := range blocks {
+			if block.Count < 0 {
+				break
+			}
+
+			size, err := blockSize(start, block.Count)
+
+			if err != nil {
+				return fmt.Errorf("failed to relocate actions[%d]: %w", i, err)
+			}
+
+			block.Size = size
+			start += block.Count
+		}
+	}
+
+	return nil
+}
+
+func appendString(data []byte, s string) []byte {
+	return append(append(data, s...), 0x00)
+}
+
+func appendUint16(data []byte, value uint16) []byte {
+	return append(data, uint8(value), uint8(value>>8))
+}
+
+func appendUint32(data []byte, value uint32) []byte {
+	return append(data, uint8(value), uint8(value>>8), uint8(value>>16), uint8(value>>24))
+}
+
+func encodePayload(action Action) ([]byte, error) {
+	var data []byte
+
+	switch v := action.(type) {
+	case *ActionRaw:
+		data = append(data, v.Data...)
+	case *ActionGotoFrame:
+		data = appendUint16(data, v.Frame)
+	case *ActionGetURL:
+		data = appendString(data, v.URL)
+		data = appendString(data, v.Target)
+	case *ActionStoreRegister:
+		data = append(data, v.Register)
+	case *ActionConstantPool:
+		if len(v.Constants) > 0xffff {
+			return nil, fmt.Errorf("too many constants: %d", len(v.Constants))
+		}
+
+		data = appendUint16(data, uint16(len(v.Constants)))
+
+		for _, constant := range v.Constants {
+			data = appendString(data, constant)
+		}
+	case *ActionWaitForFrame:
+		data = appendUint16(data, v.Frame)
+		data = append(data, v.SkipCount)
+	case *ActionSetTarget:
+		data = appendString(data, v.TargetName)
+	case *ActionGotoLabel:
+		data = appendString(data, v.Label)
+	case *ActionWaitForFrame2:
+		data = append(data, v.SkipCount)
+	case *ActionDefineFunction2:
+		if len(v.Parameters) > 0xffff {
+			return nil, fmt.Errorf("too many parameters: %d", len(v.Parameters))
+		}
+
+		data = appendString(data, v.FunctionName)
+		data = appendUint16(data, uint16(len(v.Parameters)))
+		data = append(data, v.RegisterCount, uint8(v.Flags>>8), uint8(v.Flags))
+
+		for _, param := range v.Parameters {
+			data = append(data, param.Register)
+			data = appendString(data, param.Name)
+		}
+
+		data = appendUint16(data, v.Body.Size)
+	case *ActionTry:
+		var flags uint8
+
+		if v.CatchInRegister {
+			flags |= 0b100
+		}
+		if v.HasFinallyBlock {
+			flags |= 0b10
+		}
+		if v.HasCatchBlock {
+			flags |= 0b1
+		}
+
+		data = append(data, flags)
+		data = appendUint16(data, v.Try.Size)
+		data = appendUint16(data, v.Catch.Size)
+		data = appendUint16(data, v.Finally.Size)
+
+		if v.CatchInRegister {
+			data = append(data, v.CatchRegister)
+		} else {
+			data = appendString(data, v.CatchName)
+		}
+	case *ActionWith:
+		data = appendUint16(data, v.Body.Size)
+	case *ActionPush:
+		for _, value := range v.Values {
+			valueData, err := encodePushValue(value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			data = append(data, valueData...)
+		}
+	case *ActionJump:
+		data = appendUint16(data, uint16(v.Offset))
+	case *ActionIf:
+		data = appendUint16(data, uint16(v.Offset))
+	case *ActionGetURL2:
+		flags := v.SendVarsMethod << 6
+
+		if v.LoadTarget {
+			flags |= 0b10
+		}
+		if v.LoadVariables {
+			flags |= 0b1
+		}
+
+		data = append(data, flags)
+	case *ActionDefineFunction:
+		if len(v.Params) > 0xffff {
+			return nil, fmt.Errorf("too many parameters: %d", len(v.Params))
+		}
+
+		data = appendString(data, v.FunctionName)
+		data = appendUint16(data, uint16(len(v.Params)))
+
+		for _, param := range v.Params {
+			data = appendString(data, param)
+		}
+
+		data = appendUint16(data, v.Body.Size)
+	case *ActionCall:
+	case *ActionGotoFrame2:
+		var flags uint8
+
+		if v.HasSceneBias {
+			flags |= 0b10
+		}
+		if v.Play {
+			flags |= 0b1
+		}
+
+		data = append(data, flags)
+
+		if v.HasSceneBias {
+			data = appendUint16(data, v.SceneBias)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action type %T", action)
+	}
+
+	return data, nil
+}
+
+func encodePushValue(value PushValue) ([]byte, error) {
+	data := []byte{uint8(value.Type)}
+
+	switch value.Type {
+	case PushString:
+		data = appendString(data, value.String)
+	case PushFloat:
+		data = appendUint32(data, math.Float32bits(value.Float))
+	case PushNull, PushUndefined:
+	case PushRegister:
+		data = append(data, value.Register)
+	case PushBoolean:
+		if value.Boolean {
+			data = append(data, 1)
+		} else {
+			data = append(data, 0)
+		}
+	case PushDouble:
+		// The high 32 bits come first, each half in little-endian.
+		bits := math.Float64bits(value.Double)
+		data = appendUint32(data, uint32(bits>>32))
+		data = appendUint32(data, uint32(bits))
+	case PushInteger:
+		data = appendUint32(data, uint32(value.Integer))
+	case PushConstant8:
+		if value.Constant > 0xff {
+			return nil, fmt.Errorf("constant %d does not fit in Constant8", value.Constant)
+		}
+
+		data = append(data, uint8(value.Constant))
+	case PushConstant16:
+		data = appendUint16(data, value.Constant)
+	default:
+		return nil, fmt.Errorf("unknown push type: %s", value.Type)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,354 @@
+package avm1
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Value is a value of the Machine. It is one of Undefined, Null, bool,
+// float64, string and *Object.
+type Value interface{}
+
+// Undefined is the undefined value.
+type Undefined struct{}
+
+// Null is the null value.
+type Null struct{}
+
+// NativeFunction implements a function of the Machine in Go.
+type NativeFunction func(m *Machine, this Value, args []Value) (Value, error)
+
+// Object is an object of the Machine. Arrays and functions are objects too.
+type Object struct {
+	Prototype  *Object
+	properties map[string]Value
+	keys       []string
+	native     NativeFunction
+	function   *function
+	isArray    bool
+}
+
+// NewObject returns an empty object.
+func NewObject() *Object {
+	return &Object{properties: map[string]Value{}}
+}
+
+// NewNativeFunction returns a function object which calls the Go function.
+func NewNativeFunction(native NativeFunction) *Object {
+	o := NewObject()
+	o.native = native
+
+	return o
+}
+
+// NewArray returns an array object holding the values.
+func NewArray(values ...Value) *Object {
+	o := NewObject()
+	o.isArray = true
+	o.Set("length", float64(0))
+
+	for i, value := range values {
+		o.Set(strconv.Itoa(i), value)
+	}
+
+	return o
+}
+
+// IsFunction reports whether the object can be called.
+func (o *Object) IsFunction() bool {
+	return o.native != nil || o.function != nil
+}
+
+// Get returns the property of the object or its prototypes.
+func (o *Object) Get(name string) Value {
+	for p, depth := o, 0; p != nil && depth < 256; p, depth = p.Prototype, depth+1 {
+		if name == "__proto__" && p == o {
+			if o.Prototype == nil {
+				return Undefined{}
+			}
+
+			return o.Prototype
+		}
+		if value, ok := p.properties[name]; ok {
+			return value
+		}
+	}
+
+	return Undefined{}
+}
+
+// Has reports whether the object itself has the property.
+func (o *Object) Has(name string) bool {
+	_, ok := o.properties[name]
+
+	return ok
+}
+
+// Set sets the property. Setting an index of an array updates its length.
+func (o *Object) Set(name string, value Value) {
+	if name == "__proto__" {
+		o.Prototype, _ = value.(*Object)
+
+		return
+	}
+	if _, ok := o.properties[name]; !ok {
+		o.keys = append(o.keys, name)
+	}
+
+	o.properties[name] = value
+
+	if !o.isArray {
+		return
+	}
+	if index, err := strconv.Atoi(name); err == nil && index >= 0 {
+		if length := ToNumber(o.properties["length"]); float64(index) >= length {
+			o.properties["length"] = float64(index + 1)
+		}
+	}
+}
+
+// Delete removes the property of the object itself.
+func (o *Object) Delete(name string) bool {
+	if _, ok := o.properties[name]; !ok {
+		return false
+	}
+
+	delete(o.properties, name)
+
+	for i, key := range o.keys {
+		if key == name {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+
+			break
+		}
+	}
+
+	return true
+}
+
+// Keys returns the names of the properties in the order of definition. The
+// length of an array is not included.
+func (o *Object) Keys() []string {
+	var keys []string
+
+	for _, key := range o.keys {
+		if !o.isArray || key != "length" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// ToBoolean converts the value to a boolean.
+func ToBoolean(v Value) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case *Object:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToNumber converts the value to a number.
+func ToNumber(v Value) float64 {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return 1
+		}
+
+		return 0
+	case float64:
+		return v
+	case string:
+		s := strings.TrimSpace(v)
+
+		if s == "" {
+			return 0
+		}
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			if n, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+				return float64(n)
+			}
+
+			return math.NaN()
+		}
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+
+		return math.NaN()
+	case *Object:
+		if v.isArray && ToNumber(v.Get("length")) == 1 {
+			return ToNumber(v.Get("0"))
+		}
+
+		return math.NaN()
+	default:
+		return math.NaN()
+	}
+}
+
+// ToInt32 converts the value to a 32-bit integer as the bitwise operators
+// do.
+func ToInt32(v Value) int32 {
+	n := ToNumber(v)
+
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0
+	}
+
+	return int32(uint32(int64(math.Mod(math.Trunc(n), 1<<32))))
+}
+
+// ToString converts the value to a string.
+func ToString(v Value) string {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN"
+		case math.IsInf(v, 1):
+			return "Infinity"
+		case math.IsInf(v, -1):
+			return "-Infinity"
+		case v == math.Trunc(v) && math.Abs(v) < 1e15:
+			return strconv.FormatFloat(v, 'f', 0, 64)
+		default:
+			return strconv.FormatFloat(v, 'g', 15, 64)
+		}
+	case string:
+		return v
+	case Null:
+		return "null"
+	case *Object:
+		if v.isArray {
+			return joinArray(v, ",")
+		}
+		if v.IsFunction() {
+			return "[type Function]"
+		}
+
+		return "[object Object]"
+	default:
+		return "undefined"
+	}
+}
+
+func joinArray(o *Object, separator string) string {
+	length := int(ToNumber(o.Get("length")))
+	items := make([]string, 0, length)
+
+	for i := 0; i < length; i++ {
+		item := o.Get(strconv.Itoa(i))
+
+		switch item.(type) {
+		case Undefined, Null:
+			items = append(items, "")
+		default:
+			items = append(items, ToString(item))
+		}
+	}
+
+	return strings.Join(items, separator)
+}
+
+// TypeOf returns the name of the type as the typeof operator does.
+func TypeOf(v Value) string {
+	switch v := v.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case Null:
+		return "null"
+	case *Object:
+		if v.IsFunction() {
+			return "function"
+		}
+
+		return "object"
+	default:
+		return "undefined"
+	}
+}
+
+// looseEquals compares the values as the == operator does.
+func looseEquals(a, b Value) bool {
+	switch a.(type) {
+	case Undefined, Null:
+		switch b.(type) {
+		case Undefined, Null:
+			return true
+		default:
+			return false
+		}
+	}
+	switch b.(type) {
+	case Undefined, Null:
+		return false
+	}
+
+	ao, aIsObject := a.(*Object)
+	bo, bIsObject := b.(*Object)
+
+	switch {
+	case aIsObject && bIsObject:
+		return ao == bo
+	case aIsObject || bIsObject:
+		return ToString(a) == ToString(b)
+	}
+
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+
+	if aIsString && bIsString {
+		return as == bs
+	}
+
+	return ToNumber(a) == ToNumber(b)
+}
+
+// strictEquals compares the values as the === operator does.
+func strictEquals(a, b Value) bool {
+	switch a := a.(type) {
+	case Undefined:
+		_, ok := b.(Undefined)
+
+		return ok
+	case Null:
+		_, ok := b.(Null)
+
+		return ok
+	case bool:
+		v, ok := b.(bool)
+
+		return ok && a == v
+	case float64:
+		v, ok := b.(float64)
+
+		return ok && a == v
+	case string:
+		v, ok := b.(string)
+
+		return ok && a == v
+	case *Object:
+		v, ok := b.(*Object)
+
+		return ok && a == v
+	default:
+		return false
+	}
+}
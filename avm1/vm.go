@@ -0,0 +1,1177 @@
+package avm1
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultBudget is the number of actions a new Machine may execute.
+const DefaultBudget = 1000000
+
+// maxCallDepth limits the recursion of function calls.
+const maxCallDepth = 256
+
+// EventKind is the kind of an Event.
+type EventKind uint8
+
+const (
+	EventGetURL EventKind = iota + 1
+	EventLoadMovie
+	EventLoadVariables
+	EventFSCommand
+	EventEval
+	EventTrace
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventGetURL:
+		return "GetURL"
+	case EventLoadMovie:
+		return "LoadMovie"
+	case EventLoadVariables:
+		return "LoadVariables"
+	case EventFSCommand:
+		return "FSCommand"
+	case EventEval:
+		return "Eval"
+	case EventTrace:
+		return "Trace"
+	default:
+		return fmt.Sprintf("EventKind(%d)", uint8(k))
+	}
+}
+
+// Event records the arguments of an action which would reach outside of the
+// sandbox, or which resolves a name at runtime.
+type Event struct {
+	Kind EventKind
+	Args []string
+}
+
+// String formats the event for the logs.
+func (e Event) String() string {
+	args := make([]string, len(e.Args))
+
+	for i, arg := range e.Args {
+		args[i] = strconv.Quote(arg)
+	}
+
+	return e.Kind.String() + "(" + strings.Join(args, ", ") + ")"
+}
+
+// BudgetExceededError is returned when the Machine executed more actions
+// than its budget.
+type BudgetExceededError struct {
+	Budget int
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("exceeded the budget of %d actions", e.Budget)
+}
+
+// ThrowError is returned when a thrown value is not caught.
+type ThrowError struct {
+	Value Value
+}
+
+func (e *ThrowError) Error() string {
+	return fmt.Sprintf("uncaught exception: %s", ToString(e.Value))
+}
+
+// UnsupportedActionError is returned when the Machine cannot execute the
+// action.
+type UnsupportedActionError struct {
+	Code ActionCode
+}
+
+func (e *UnsupportedActionError) Error() string {
+	return fmt.Sprintf("unsupported action: %s", e.Code)
+}
+
+// Machine executes actions without any I/O. The actions which load URLs,
+// call fscommand or trace are recorded as Events, and the timeline actions
+// such as play and gotoAndStop do nothing.
+type Machine struct {
+	// Global is _global, which holds the built-in objects.
+	Global *Object
+	// Root is _root, which holds the variables of the timeline.
+	Root *Object
+	// Budget is the number of actions to execute before Run fails.
+	Budget int
+	Events []Event
+
+	executed        int
+	depth           int
+	objectPrototype *Object
+	arrayPrototype  *Object
+	stringPrototype *Object
+	// movieClipPrototype is the prototype of _root, which has the methods
+	// loading URLs.
+	movieClipPrototype *Object
+}
+
+// NewMachine returns a Machine with the built-in objects and DefaultBudget.
+func NewMachine() *Machine {
+	m := &Machine{
+		Global: NewObject(),
+		Root:   NewObject(),
+		Budget: DefaultBudget,
+	}
+
+	m.installBuiltins()
+
+	return m
+}
+
+// Executed returns the number of actions executed so far.
+func (m *Machine) Executed() int {
+	return m.executed
+}
+
+// context is the state of the actions running at top level or in a
+// function.
+type context struct {
+	actions []Action
+	pool    []string
+	stack   []Value
+	// literals tells whether each value of the stack is a string or a
+	// constant written in ActionPush, so GetVariable of any other name is
+	// recorded as eval.
+	literals  []bool
+	registers []Value
+	this      Value
+	// locals holds the local variables, or nil at top level.
+	locals *Object
+	// chain is the locals of the enclosing functions from the outermost.
+	chain []*Object
+	// scopes is the objects of the with statements from the outermost.
+	scopes   []*Object
+	returned bool
+	result   Value
+}
+
+func (c *context) push(v Value) {
+	c.pushLiteral(v, false)
+}
+
+func (c *context) pushLiteral(v Value, literal bool) {
+	c.stack = append(c.stack, v)
+	c.literals = append(c.literals, literal)
+}
+
+// pop pops the stack, or returns undefined when the stack is empty as the
+// Flash Player does.
+func (c *context) pop() Value {
+	v, _ := c.popLiteral()
+
+	return v
+}
+
+// popLiteral pops the stack and tells whether the value is a literal.
+func (c *context) popLiteral() (Value, bool) {
+	if len(c.stack) == 0 {
+		return Undefined{}, false
+	}
+
+	n := len(c.stack) - 1
+	v, literal := c.stack[n], c.literals[n]
+	c.stack = c.stack[:n]
+	c.literals = c.literals[:n]
+
+	return v, literal
+}
+
+// popCount pops a count, clamped to the size of the stack.
+func (c *context) popCount() int {
+	n := ToNumber(c.pop())
+
+	if math.IsNaN(n) || n < 0 {
+		return 0
+	}
+	if n > float64(len(c.stack)) {
+		return len(c.stack)
+	}
+
+	return int(n)
+}
+
+// function is a function defined by DefineFunction or DefineFunction2.
+type function struct {
+	actions []Action
+	start   int
+	end     int
+	pool    []string
+	params  []string
+	define2 *ActionDefineFunction2
+	chain   []*Object
+	scopes  []*Object
+}
+
+// Run executes the actions at top level of _root.
+func (m *Machine) Run(actions []Action) error {
+	c := &context{
+		actions:   actions,
+		registers: make([]Value, 4),
+		this:      m.Root,
+	}
+
+	_, err := m.exec(c, 0, len(actions))
+
+	return err
+}
+
+// Call calls the function with the arguments.
+func (m *Machine) Call(fn Value, this Value, args []Value) (Value, error) {
+	o, ok := fn.(*Object)
+
+	if !ok || !o.IsFunction() {
+		return Undefined{}, nil
+	}
+	if o.native != nil {
+		return o.native(m, this, args)
+	}
+	if m.depth >= maxCallDepth {
+		return nil, fmt.Errorf("exceeded the call depth of %d", maxCallDepth)
+	}
+
+	m.depth += 1
+	defer func() { m.depth -= 1 }()
+
+	f := o.function
+	c := &context{
+		actions: f.actions,
+		pool:    f.pool,
+		this:    this,
+		locals:  NewObject(),
+		chain:   f.chain,
+		scopes:  f.scopes,
+	}
+	arguments := NewArray(args...)
+	arg := func(i int) Value {
+		if i < len(args) {
+			return args[i]
+		}
+
+		return Undefined{}
+	}
+
+	if f.define2 == nil {
+		c.registers = make([]Value, 4)
+		c.locals.Set("arguments", arguments)
+
+		for i, name := range f.params {
+			c.locals.Set(name, arg(i))
+		}
+	} else {
+		flags := f.define2.Flags
+		c.registers = make([]Value, 256)
+		next := 1
+
+		var super Value = Undefined{}
+
+		if object, ok := this.(*Object); ok && object.Prototype != nil && object.Prototype.Prototype != nil {
+			super = object.Prototype.Prototype
+		}
+
+		for _, preload := range []struct {
+			flag     DefineFunction2Flags
+			suppress DefineFunction2Flags
+			name     string
+			value    Value
+		}{
+			{PreloadThis, 0, "this", this},
+			{PreloadArguments, SuppressArguments, "arguments", arguments},
+			{PreloadSuper, SuppressSuper, "super", super},
+			{PreloadRoot, 0, "_root", m.Root},
+			{PreloadParent, 0, "_parent", m.Root},
+			{PreloadGlobal, 0, "_global", m.Global},
+		} {
+			switch {
+			case flags.Contains(preload.flag):
+				c.registers[next] = preload.value
+				next += 1
+			case preload.suppress != 0 && !flags.Contains(preload.suppress):
+				c.locals.Set(preload.name, preload.value)
+			}
+		}
+		for i, param := range f.define2.Parameters {
+			if param.Register != 0 {
+				c.registers[param.Register] = arg(i)
+			} else {
+				c.locals.Set(param.Name, arg(i))
+			}
+		}
+	}
+	if _, err := m.exec(c, f.start, f.end); err != nil {
+		return nil, err
+	}
+	if c.result == nil {
+		return Undefined{}, nil
+	}
+
+	return c.result, nil
+}
+
+// construct calls the function as a constructor.
+func (m *Machine) construct(fn Value, args []Value) (Value, error) {
+	o, ok := fn.(*Object)
+
+	if !ok || !o.IsFunction() {
+		return Undefined{}, nil
+	}
+
+	object := NewObject()
+	object.Prototype, _ = o.Get("prototype").(*Object)
+
+	result, err := m.Call(o, object, args)
+
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := result.(*Object); ok && o.native != nil {
+		return r, nil
+	}
+
+	return object, nil
+}
+
+func (m *Machine) newFunction(f *function) *Object {
+	prototype := NewObject()
+	prototype.Prototype = m.objectPrototype
+
+	o := NewObject()
+	o.function = f
+	o.Set("prototype", prototype)
+
+	return o
+}
+
+func (m *Machine) emit(kind EventKind, args ...string) {
+	m.Events = append(m.Events, Event{Kind: kind, Args: args})
+}
+
+// blockRange returns the end of the block starting at start.
+func blockRange(c *context, start int, block Block, i int) (int, error) {
+	if block.Count < 0 || start+block.Count > len(c.actions) {
+		return 0, fmt.Errorf("block of actions[%d] does not end at an action", i)
+	}
+
+	return start + block.Count, nil
+}
+
+// exec executes the actions from start to end. It returns the index of the
+// action to continue, which is end or the target of a branch leaving the
+// range.
+func (m *Machine) exec(c *context, start, end int) (int, error) {
+	for i := start; i < end; {
+		if c.returned {
+			return end, nil
+		}
+		if m.executed >= m.Budget {
+			return 0, &BudgetExceededError{Budget: m.Budget}
+		}
+
+		m.executed += 1
+
+		action := c.actions[i]
+		next := i + 1
+
+		switch v := action.(type) {
+		case *ActionJump:
+			if v.Target < 0 {
+				return 0, fmt.Errorf("actions[%d] jumps into the middle of an action", i)
+			}
+
+			next = v.Target
+		case *ActionIf:
+			if v.Target < 0 {
+				return 0, fmt.Errorf("actions[%d] jumps into the middle of an action", i)
+			}
+			if ToBoolean(c.pop()) {
+				next = v.Target
+			}
+		case *ActionDefineFunction, *ActionDefineFunction2:
+			var err error
+
+			if next, err = m.defineFunction(c, i); err != nil {
+				return 0, err
+			}
+		case *ActionWith:
+			bodyEnd, err := blockRange(c, i+1, v.Body, i)
+
+			if err != nil {
+				return 0, err
+			}
+
+			object, _ := c.pop().(*Object)
+
+			if object == nil {
+				next = bodyEnd
+
+				break
+			}
+
+			c.scopes = append(c.scopes, object)
+			next, err = m.exec(c, i+1, bodyEnd)
+			c.scopes = c.scopes[:len(c.scopes)-1]
+
+			if err != nil {
+				return 0, err
+			}
+		case *ActionTry:
+			var err error
+
+			if next, err = m.try(c, i, v); err != nil {
+				return 0, err
+			}
+		case *ActionWaitForFrame, *ActionWaitForFrame2:
+			// Every frame is loaded in the sandbox.
+			if _, ok := v.(*ActionWaitForFrame2); ok {
+				c.pop()
+			}
+		default:
+			if err := m.step(c, action); err != nil {
+				return 0, fmt.Errorf("failed to execute actions[%d]: %w", i, err)
+			}
+		}
+		if next < start || next > end {
+			return next, nil
+		}
+
+		i = next
+	}
+
+	return end, nil
+}
+
+func (m *Machine) defineFunction(c *context, i int) (int, error) {
+	f := &function{
+		actions: c.actions,
+		start:   i + 1,
+		pool:    c.pool,
+		chain:   c.chain,
+		scopes:  append([]*Object{}, c.scopes...),
+	}
+
+	if c.locals != nil {
+		f.chain = append(append([]*Object{}, c.chain...), c.locals)
+	}
+
+	var name string
+	var body Block
+
+	switch v := c.actions[i].(type) {
+	case *ActionDefineFunction:
+		name, body, f.params = v.FunctionName, v.Body, v.Params
+	case *ActionDefineFunction2:
+		name, body, f.define2 = v.FunctionName, v.Body, v
+	}
+
+	end, err := blockRange(c, i+1, body, i)
+
+	if err != nil {
+		return 0, err
+	}
+
+	f.end = end
+	o := m.newFunction(f)
+
+	if name == "" {
+		c.push(o)
+	} else {
+		m.defineLocal(c, name, o)
+	}
+
+	return end, nil
+}
+
+func (m *Machine) try(c *context, i int, v *ActionTry) (int, error) {
+	tryEnd, err := blockRange(c, i+1, v.Try, i)
+
+	if err != nil {
+		return 0, err
+	}
+
+	catchEnd, err := blockRange(c, tryEnd, v.Catch, i)
+
+	if err != nil {
+		return 0, err
+	}
+
+	finallyEnd, err := blockRange(c, catchEnd, v.Finally, i)
+
+	if err != nil {
+		return 0, err
+	}
+
+	next, err := m.exec(c, i+1, tryEnd)
+
+	var thrown *ThrowError
+
+	if errors.As(err, &thrown) && v.HasCatchBlock {
+		if v.CatchInRegister {
+			if int(v.CatchRegister) < len(c.registers) {
+				c.registers[v.CatchRegister] = thrown.Value
+			}
+		} else {
+			m.defineLocal(c, v.CatchName, thrown.Value)
+		}
+
+		next, err = m.exec(c, tryEnd, catchEnd)
+	} else if err == nil && next == tryEnd {
+		next = catchEnd
+	}
+	var exceeded *BudgetExceededError
+
+	if errors.As(err, &exceeded) {
+		return 0, err
+	}
+	if next == catchEnd {
+		next = finallyEnd
+	}
+	if v.HasFinallyBlock {
+		returned, result := c.returned, c.result
+		c.returned = false
+
+		if _, finallyErr := m.exec(c, catchEnd, finallyEnd); finallyErr != nil {
+			return 0, finallyErr
+		}
+		if !c.returned {
+			c.returned, c.result = returned, result
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return next, nil
+}
+
+// scopeChain returns the objects to look up variables from the innermost.
+func (m *Machine) scopeChain(c *context) []*Object {
+	var chain []*Object
+
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		chain = append(chain, c.scopes[i])
+	}
+	if c.locals != nil {
+		chain = append(chain, c.locals)
+	}
+	for i := len(c.chain) - 1; i >= 0; i-- {
+		chain = append(chain, c.chain[i])
+	}
+
+	return append(chain, m.Root, m.Global)
+}
+
+func (m *Machine) lookup(c *context, name string) Value {
+	switch name {
+	case "this":
+		return c.this
+	case "_global":
+		return m.Global
+	case "_root", "_level0", "_parent":
+		return m.Root
+	}
+	for _, o := range m.scopeChain(c) {
+		if o.Has(name) {
+			return o.Get(name)
+		}
+	}
+
+	return Undefined{}
+}
+
+// getVariable resolves the variable which may be a path such as "a.b.c" or
+// "/clip:name".
+func (m *Machine) getVariable(c *context, name string) Value {
+	if target, variable, ok := strings.Cut(name, ":"); ok {
+		var object Value = m.Root
+
+		if !strings.HasPrefix(target, "/") {
+			object = m.lookup(c, strings.Split(target, "/")[0])
+			target = strings.Join(strings.Split(target, "/")[1:], "/")
+		}
+		for _, part := range strings.Split(strings.Trim(target, "/"), "/") {
+			if part != "" {
+				object = m.getMember(object, part)
+			}
+		}
+
+		return m.getMember(object, variable)
+	}
+
+	parts := strings.Split(name, ".")
+	value := m.lookup(c, parts[0])
+
+	for _, part := range parts[1:] {
+		value = m.getMember(value, part)
+	}
+
+	return value
+}
+
+func (m *Machine) setVariable(c *context, name string, value Value) {
+	if i := strings.LastIndexAny(name, ".:"); i >= 0 {
+		if object, ok := m.getVariable(c, name[:i]).(*Object); ok {
+			object.Set(name[i+1:], value)
+		}
+
+		return
+	}
+	for _, o := range m.scopeChain(c) {
+		if o.Has(name) {
+			o.Set(name, value)
+
+			return
+		}
+	}
+
+	m.Root.Set(name, value)
+}
+
+func (m *Machine) defineLocal(c *context, name string, value Value) {
+	if c.locals != nil {
+		c.locals.Set(name, value)
+	} else {
+		m.Root.Set(name, value)
+	}
+}
+
+func (m *Machine) getMember(object Value, name string) Value {
+	switch v := object.(type) {
+	case *Object:
+		return v.Get(name)
+	case string:
+		if name == "length" {
+			return float64(utf8.RuneCountInString(v))
+		}
+
+		return m.stringPrototype.Get(name)
+	default:
+		return Undefined{}
+	}
+}
+
+func (m *Machine) pushValue(c *context, value PushValue) Value {
+	switch value.Type {
+	case PushString:
+		return value.String
+	case PushFloat:
+		return float64(value.Float)
+	case PushNull:
+		return Null{}
+	case PushRegister:
+		if int(value.Register) < len(c.registers) && c.registers[value.Register] != nil {
+			return c.registers[value.Register]
+		}
+
+		return Undefined{}
+	case PushBoolean:
+		return value.Boolean
+	case PushDouble:
+		return value.Double
+	case PushInteger:
+		return float64(value.Integer)
+	case PushConstant8, PushConstant16:
+		if int(value.Constant) < len(c.pool) {
+			return c.pool[value.Constant]
+		}
+
+		return Undefined{}
+	default:
+		return Undefined{}
+	}
+}
+
+// arithmetic are the binary operators on numbers.
+var arithmetic = map[ActionCode]func(a, b float64) Value{
+	CodeAdd:      func(a, b float64) Value { return a + b },
+	CodeSubtract: func(a, b float64) Value { return a - b },
+	CodeMultiply: func(a, b float64) Value { return a * b },
+	CodeDivide:   func(a, b float64) Value { return a / b },
+	CodeModulo:   func(a, b float64) Value { return math.Mod(a, b) },
+	CodeEquals:   func(a, b float64) Value { return a == b },
+	CodeLess:     func(a, b float64) Value { return a < b },
+}
+
+// bitwise are the binary operators on 32-bit integers.
+var bitwise = map[ActionCode]func(a, b int32) Value{
+	CodeBitAnd:     func(a, b int32) Value { return float64(a & b) },
+	CodeBitOr:      func(a, b int32) Value { return float64(a | b) },
+	CodeBitXor:     func(a, b int32) Value { return float64(a ^ b) },
+	CodeBitLShift:  func(a, b int32) Value { return float64(a << (uint32(b) & 31)) },
+	CodeBitRShift:  func(a, b int32) Value { return float64(a >> (uint32(b) & 31)) },
+	CodeBitURShift: func(a, b int32) Value { return float64(uint32(a) >> (uint32(b) & 31)) },
+}
+
+// compare implements Less2 and Greater, which return undefined for NaN.
+func compare(a, b Value) Value {
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+
+	if aIsString && bIsString {
+		return as < bs
+	}
+
+	x, y := ToNumber(a), ToNumber(b)
+
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return Undefined{}
+	}
+
+	return x < y
+}
+
+// step executes an action which does not branch.
+func (m *Machine) step(c *context, action Action) error {
+	code := action.Code()
+
+	if op, ok := arithmetic[code]; ok {
+		b := ToNumber(c.pop())
+		a := ToNumber(c.pop())
+		c.push(op(a, b))
+
+		return nil
+	}
+	if op, ok := bitwise[code]; ok {
+		b := ToInt32(c.pop())
+		a := ToInt32(c.pop())
+		c.push(op(a, b))
+
+		return nil
+	}
+
+	switch v := action.(type) {
+	case *ActionConstantPool:
+		c.pool = v.Constants
+	case *ActionPush:
+		for _, value := range v.Values {
+			switch value.Type {
+			case PushString, PushConstant8, PushConstant16:
+				c.pushLiteral(m.pushValue(c, value), true)
+			default:
+				c.push(m.pushValue(c, value))
+			}
+		}
+	case *ActionStoreRegister:
+		value := c.pop()
+		c.push(value)
+
+		if int(v.Register) < len(c.registers) {
+			c.registers[v.Register] = value
+		}
+	case *ActionGetURL:
+		if command := strings.TrimPrefix(v.URL, "FSCommand:"); command != v.URL {
+			m.emit(EventFSCommand, command, v.Target)
+		} else if isLevel(v.Target) {
+			m.emit(EventLoadMovie, v.URL, v.Target)
+		} else {
+			m.emit(EventGetURL, v.URL, v.Target)
+		}
+	case *ActionGetURL2:
+		target := ToString(c.pop())
+		url := ToString(c.pop())
+
+		switch {
+		case v.LoadVariables:
+			m.emit(EventLoadVariables, url, target)
+		case v.LoadTarget, isLevel(target):
+			m.emit(EventLoadMovie, url, target)
+		case strings.HasPrefix(url, "FSCommand:"):
+			m.emit(EventFSCommand, strings.TrimPrefix(url, "FSCommand:"), target)
+		default:
+			m.emit(EventGetURL, url, target)
+		}
+	case *ActionGotoFrame2, *ActionCall:
+		c.pop()
+	case *ActionGotoFrame, *ActionGotoLabel, *ActionSetTarget:
+	case ActionCode:
+		return m.operation(c, v)
+	default:
+		return &UnsupportedActionError{Code: code}
+	}
+
+	return nil
+}
+
+func (m *Machine) operation(c *context, code ActionCode) error {
+	switch code {
+	case CodeEnd, CodePlay, CodeStop, CodeNextFrame, CodePreviousFrame, CodeToggleQuality, CodeStopSounds, CodeEndDrag:
+	case CodeAdd2:
+		b := c.pop()
+		a := c.pop()
+
+		if a, ok := a.(*Object); ok && !a.isArray {
+			c.push(ToString(a) + ToString(b))
+
+			break
+		}
+
+		_, aIsString := a.(string)
+		_, bIsString := b.(string)
+
+		if aIsString || bIsString {
+			c.push(ToString(a) + ToString(b))
+		} else {
+			c.push(ToNumber(a) + ToNumber(b))
+		}
+	case CodeLess2:
+		b := c.pop()
+		c.push(compare(c.pop(), b))
+	case CodeGreater:
+		b := c.pop()
+		c.push(compare(b, c.pop()))
+	case CodeEquals2:
+		b := c.pop()
+		c.push(looseEquals(c.pop(), b))
+	case CodeStrictEquals:
+		b := c.pop()
+		c.push(strictEquals(c.pop(), b))
+	case CodeAnd:
+		b := ToBoolean(c.pop())
+		c.push(ToBoolean(c.pop()) && b)
+	case CodeOr:
+		b := ToBoolean(c.pop())
+		c.push(ToBoolean(c.pop()) || b)
+	case CodeNot:
+		c.push(!ToBoolean(c.pop()))
+	case CodeStringAdd:
+		b := ToString(c.pop())
+		c.push(ToString(c.pop()) + b)
+	case CodeStringEquals:
+		b := ToString(c.pop())
+		c.push(ToString(c.pop()) == b)
+	case CodeStringLess:
+		b := ToString(c.pop())
+		c.push(ToString(c.pop()) < b)
+	case CodeStringGreater:
+		b := ToString(c.pop())
+		c.push(ToString(c.pop()) > b)
+	case CodeStringLength:
+		c.push(float64(len(ToString(c.pop()))))
+	case CodeMBStringLength:
+		c.push(float64(utf8.RuneCountInString(ToString(c.pop()))))
+	case CodeStringExtract, CodeMBStringExtract:
+		count := int(ToNumber(c.pop()))
+		index := int(ToNumber(c.pop())) - 1
+		s := ToString(c.pop())
+
+		if code == CodeStringExtract {
+			start, end := clampRange(index, count, len(s))
+			c.push(s[start:end])
+		} else {
+			runes := []rune(s)
+			start, end := clampRange(index, count, len(runes))
+			c.push(string(runes[start:end]))
+		}
+	case CodeCharToAscii:
+		if s := ToString(c.pop()); s != "" {
+			c.push(float64(s[0]))
+		} else {
+			c.push(float64(0))
+		}
+	case CodeMBCharToAscii:
+		if r, size := utf8.DecodeRuneInString(ToString(c.pop())); size > 0 {
+			c.push(float64(r))
+		} else {
+			c.push(float64(0))
+		}
+	case CodeAsciiToChar:
+		c.push(string([]byte{uint8(ToNumber(c.pop()))}))
+	case CodeMBAsciiToChar:
+		c.push(string(rune(ToNumber(c.pop()))))
+	case CodeToInteger:
+		c.push(math.Trunc(ToNumber(c.pop())))
+	case CodeToNumber:
+		c.push(ToNumber(c.pop()))
+	case CodeToString:
+		c.push(ToString(c.pop()))
+	case CodeTypeOf:
+		c.push(TypeOf(c.pop()))
+	case CodeIncrement:
+		c.push(ToNumber(c.pop()) + 1)
+	case CodeDecrement:
+		c.push(ToNumber(c.pop()) - 1)
+	case CodeRandomNumber:
+		// The sandbox is deterministic.
+		c.pop()
+		c.push(float64(0))
+	case CodeGetTime:
+		c.push(float64(0))
+	case CodePop:
+		c.pop()
+	case CodePushDuplicate:
+		v, literal := c.popLiteral()
+		c.pushLiteral(v, literal)
+		c.pushLiteral(v, literal)
+	case CodeStackSwap:
+		a, aIsLiteral := c.popLiteral()
+		b, bIsLiteral := c.popLiteral()
+		c.pushLiteral(a, aIsLiteral)
+		c.pushLiteral(b, bIsLiteral)
+	case CodeGetVariable:
+		value, literal := c.popLiteral()
+		name := ToString(value)
+
+		if !literal {
+			m.emit(EventEval, name)
+		}
+
+		c.push(m.getVariable(c, name))
+	case CodeSetVariable:
+		value := c.pop()
+		m.setVariable(c, ToString(c.pop()), value)
+	case CodeDefineLocal:
+		value := c.pop()
+		m.defineLocal(c, ToString(c.pop()), value)
+	case CodeDefineLocal2:
+		name := ToString(c.pop())
+
+		if c.locals != nil && !c.locals.Has(name) {
+			c.locals.Set(name, Undefined{})
+		}
+	case CodeGetMember:
+		name := ToString(c.pop())
+		c.push(m.getMember(c.pop(), name))
+	case CodeSetMember:
+		value := c.pop()
+		name := ToString(c.pop())
+
+		if object, ok := c.pop().(*Object); ok {
+			object.Set(name, value)
+		}
+	case CodeDelete:
+		name := ToString(c.pop())
+		object, ok := c.pop().(*Object)
+		c.push(ok && object.Delete(name))
+	case CodeDelete2:
+		name := ToString(c.pop())
+		deleted := false
+
+		for _, o := range m.scopeChain(c) {
+			if o.Has(name) {
+				deleted = o.Delete(name)
+
+				break
+			}
+		}
+
+		c.push(deleted)
+	case CodeCallFunction, CodeNewObject:
+		name := ToString(c.pop())
+		args := m.popArgs(c)
+		fn := m.getVariable(c, name)
+
+		var result Value
+		var err error
+
+		if code == CodeNewObject {
+			result, err = m.construct(fn, args)
+		} else {
+			result, err = m.Call(fn, c.this, args)
+		}
+		if err != nil {
+			return err
+		}
+
+		c.push(result)
+	case CodeCallMethod, CodeNewMethod:
+		name := c.pop()
+		object := c.pop()
+		args := m.popArgs(c)
+		fn, this := object, Value(Undefined{})
+
+		if s, ok := name.(string); ok && s != "" {
+			fn, this = m.getMember(object, s), object
+		} else if _, ok := name.(Undefined); !ok {
+			fn, this = m.getMember(object, ToString(name)), object
+		}
+		if o, ok := fn.(*Object); !ok || !o.IsFunction() {
+			// The clips on the stage are not modeled, so the methods
+			// loading URLs are resolved on any value.
+			if s, ok := name.(string); ok && code == CodeCallMethod {
+				if method, ok := m.movieClipPrototype.Get(s).(*Object); ok {
+					fn = method
+				}
+			}
+		}
+
+		var result Value
+		var err error
+
+		if code == CodeNewMethod {
+			result, err = m.construct(fn, args)
+		} else {
+			result, err = m.Call(fn, this, args)
+		}
+		if err != nil {
+			return err
+		}
+
+		c.push(result)
+	case CodeInitArray:
+		array := NewArray(m.popArgs(c)...)
+		array.Prototype = m.arrayPrototype
+		c.push(array)
+	case CodeInitObject:
+		count := c.popCount()
+		object := NewObject()
+		object.Prototype = m.objectPrototype
+		values := make([]Value, count)
+		names := make([]string, count)
+
+		for i := count - 1; i >= 0; i-- {
+			values[i] = c.pop()
+			names[i] = ToString(c.pop())
+		}
+		for i := range names {
+			object.Set(names[i], values[i])
+		}
+
+		c.push(object)
+	case CodeReturn:
+		c.result = c.pop()
+		c.returned = true
+	case CodeThrow:
+		return &ThrowError{Value: c.pop()}
+	case CodeTrace:
+		m.emit(EventTrace, ToString(c.pop()))
+	case CodeExtends:
+		superclass, _ := c.pop().(*Object)
+		subclass, _ := c.pop().(*Object)
+
+		if superclass != nil && subclass != nil {
+			prototype := NewObject()
+			prototype.Prototype, _ = superclass.Get("prototype").(*Object)
+			prototype.Set("__constructor__", superclass)
+			subclass.Set("prototype", prototype)
+		}
+	case CodeImplementsOp:
+		c.pop()
+		m.popArgs(c)
+	case CodeInstanceOf:
+		constructor := c.pop()
+		c.push(m.instanceOf(c.pop(), constructor))
+	case CodeCastOp:
+		object := c.pop()
+
+		if m.instanceOf(object, c.pop()) {
+			c.push(object)
+		} else {
+			c.push(Null{})
+		}
+	case CodeEnumerate, CodeEnumerate2:
+		object := c.pop()
+
+		if code == CodeEnumerate {
+			object = m.getVariable(c, ToString(object))
+		}
+
+		c.push(Null{})
+
+		if o, ok := object.(*Object); ok {
+			for _, key := range o.Keys() {
+				c.push(key)
+			}
+		}
+	case CodeTargetPath:
+		c.pop()
+		c.push(Undefined{})
+	case CodeGetProperty:
+		c.pop()
+		c.pop()
+		c.push(Undefined{})
+	case CodeSetProperty:
+		c.pop()
+		c.pop()
+		c.pop()
+	case CodeSetTarget2, CodeRemoveSprite:
+		c.pop()
+	case CodeCloneSprite:
+		c.pop()
+		c.pop()
+		c.pop()
+	case CodeStartDrag:
+		c.pop()
+		c.pop()
+
+		if ToBoolean(c.pop()) {
+			for i := 0; i < 4; i++ {
+				c.pop()
+			}
+		}
+	case CodeFSCommand2:
+		args := m.popArgs(c)
+		strs := make([]string, len(args))
+
+		for i, arg := range args {
+			strs[i] = ToString(arg)
+		}
+
+		m.emit(EventFSCommand, strs...)
+	default:
+		return &UnsupportedActionError{Code: code}
+	}
+
+	return nil
+}
+
+// clampRange clamps the 0-based index and the count of the SWF 4 string
+// functions to the length.
+func clampRange(index, count, length int) (int, int) {
+	if index < 0 {
+		index = 0
+	}
+	if index > length {
+		index = length
+	}
+	if count < 0 || index+count > length {
+		count = length - index
+	}
+
+	return index, index + count
+}
+
+// popArgs pops the number of arguments and the arguments.
+func (m *Machine) popArgs(c *context) []Value {
+	args := make([]Value, c.popCount())
+
+	for i := range args {
+		args[i] = c.pop()
+	}
+
+	return args
+}
+
+func (m *Machine) instanceOf(object, constructor Value) bool {
+	o, ok := object.(*Object)
+
+	if !ok {
+		return false
+	}
+
+	ctor, ok := constructor.(*Object)
+
+	if !ok {
+		return false
+	}
+
+	prototype, ok := ctor.Get("prototype").(*Object)
+
+	if !ok {
+		return false
+	}
+	for p, depth := o.Prototype, 0; p != nil && depth < 256; p, depth = p.Prototype, depth+1 {
+		if p == prototype {
+			return true
+		}
+	}
+
+	return false
+}
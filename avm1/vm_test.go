@@ -0,0 +1,180 @@
+package avm1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runForTest(t *testing.T, m *Machine, text string) error {
+	t.Helper()
+
+	actions, err := Parse(text)
+
+	require.NoError(t, err)
+
+	return m.Run(actions)
+}
+
+func TestMachineResolvesComputedStrings(t *testing.T) {
+	m := NewMachine()
+	m.Root.Set("host", "example.com")
+
+	err := runForTest(t, m, `
+push 112, 116, 116, 104, 4, "String"
+getVariable
+push "fromCharCode"
+callMethod
+push "://"
+add2
+push "host"
+getVariable
+add2
+storeRegister r:1
+pop
+push "url", r:1
+setVariable
+push r:1, "_blank"
+getURL2 method=0
+push "a", "b"
+add2
+getVariable
+pop
+push 1
+try "e" hasCatch catch=L1 finally=L2 end=L2
+  push "oops"
+  throw
+L1:
+  push "e"
+  getVariable
+  trace
+L2:
+pop
+`)
+
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com", m.Root.Get("url"))
+	require.Equal(t, []Event{
+		{Kind: EventGetURL, Args: []string{"http://example.com", "_blank"}},
+		{Kind: EventEval, Args: []string{"ab"}},
+		{Kind: EventTrace, Args: []string{"oops"}},
+	}, m.Events)
+}
+
+func TestMachineRecordsEvalThroughRegisters(t *testing.T) {
+	m := NewMachine()
+	m.Root.Set("ab", "secret")
+
+	err := runForTest(t, m, `
+push "a", "b"
+add2
+storeRegister r:1
+pop
+push r:1
+getVariable
+pop
+defineFunction2 "f" (r:1 "name") registers=2 end=L1
+  push r:1
+  getVariable
+  return
+L1:
+push "x", 1, "f"
+callFunction
+pop
+push "ab"
+pushDuplicate
+getVariable
+pop
+getVariable
+pop
+`)
+
+	require.NoError(t, err)
+	require.Equal(t, []Event{
+		{Kind: EventEval, Args: []string{"ab"}},
+		{Kind: EventEval, Args: []string{"x"}},
+	}, m.Events)
+}
+
+func TestMachineCallsFunctions(t *testing.T) {
+	m := NewMachine()
+
+	err := runForTest(t, m, `
+defineFunction "twice" ("x") end=L1
+  push "x"
+  getVariable
+  push 2
+  multiply
+  return
+L1:
+push "y", 21, 1, "twice"
+callFunction
+setVariable
+push "s"
+push 3, 1, 2, "abcdef", "substr"
+callMethod
+setVariable
+`)
+
+	require.NoError(t, err)
+	require.Equal(t, float64(42), m.Root.Get("y"))
+	require.Equal(t, "bcd", m.Root.Get("s"))
+}
+
+func TestMachineBudget(t *testing.T) {
+	m := NewMachine()
+	m.Budget = 100
+
+	err := runForTest(t, m, "L1:\njump L1\n")
+
+	require.Equal(t, &BudgetExceededError{Budget: 100}, err)
+	require.Equal(t, 100, m.Executed())
+}
+
+func TestMachineRecordsMovieClipLoads(t *testing.T) {
+	m := NewMachine()
+
+	err := runForTest(t, m, `
+push "a.swf", 1, "_root"
+getVariable
+push "loadMovie"
+callMethod
+pop
+push "_self", "http://example.com", 2, "_root"
+getVariable
+push "clip"
+getMember
+push "getURL"
+callMethod
+pop
+push 3, "b.swf", 2, "this"
+getVariable
+push "loadMovieNum"
+callMethod
+pop
+push "vars.txt", 1, "clip"
+getVariable
+push "loadVariables"
+callMethod
+pop
+getURL "c.swf" "_level2"
+push "d.swf", "_level3"
+getURL2 method=0
+push "e.swf", "_root"
+getURL2 method=0 loadTarget
+push "f.txt", "_level4"
+getURL2 method=0 loadVariables
+`)
+
+	require.NoError(t, err)
+	require.Equal(t, []Event{
+		{Kind: EventLoadMovie, Args: []string{"a.swf", "_root"}},
+		{Kind: EventGetURL, Args: []string{"http://example.com", "_self"}},
+		{Kind: EventLoadMovie, Args: []string{"b.swf", "_level3"}},
+		{Kind: EventLoadVariables, Args: []string{"vars.txt", ""}},
+		{Kind: EventLoadMovie, Args: []string{"c.swf", "_level2"}},
+		{Kind: EventLoadMovie, Args: []string{"d.swf", "_level3"}},
+		{Kind: EventLoadMovie, Args: []string{"e.swf", "_root"}},
+		{Kind: EventLoadVariables, Args: []string{"f.txt", "_level4"}},
+	}, m.Events)
+}
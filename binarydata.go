@@ -0,0 +1,270 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode"
+	"unicode/utf8"
+)
+
+type BinaryDataKind int
+
+const (
+	BinaryDataUnknown BinaryDataKind = iota
+	BinaryDataSWF
+	BinaryDataPNG
+	BinaryDataJPEG
+	BinaryDataGIF
+	BinaryDataZlib
+	BinaryDataXML
+	BinaryDataJSON
+	BinaryDataAMF
+)
+
+func (k BinaryDataKind) String() string {
+	switch k {
+	case BinaryDataSWF:
+		return "SWF"
+	case BinaryDataPNG:
+		return "PNG"
+	case BinaryDataJPEG:
+		return "JPEG"
+	case BinaryDataGIF:
+		return "GIF"
+	case BinaryDataZlib:
+		return "zlib"
+	case BinaryDataXML:
+		return "XML"
+	case BinaryDataJSON:
+		return "JSON"
+	case BinaryDataAMF:
+		return "AMF"
+	default:
+		return "unknown"
+	}
+}
+
+// Extension returns the file extension for the kind including the dot.
+func (k BinaryDataKind) Extension() string {
+	switch k {
+	case BinaryDataSWF:
+		return ".swf"
+	case BinaryDataPNG:
+		return ".png"
+	case BinaryDataJPEG:
+		return ".jpg"
+	case BinaryDataGIF:
+		return ".gif"
+	case BinaryDataZlib:
+		return ".zlib"
+	case BinaryDataXML:
+		return ".xml"
+	case BinaryDataJSON:
+		return ".json"
+	case BinaryDataAMF:
+		return ".amf"
+	default:
+		return ".bin"
+	}
+}
+
+// DetectBinaryDataKind sniffs the format of the data from its signature.
+func DetectBinaryDataKind(data []byte) BinaryDataKind {
+	switch {
+	case isSWF(data):
+		return BinaryDataSWF
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return BinaryDataPNG
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8}), bytes.HasPrefix(data, []byte{0xff, 0xd9, 0xff, 0xd8}):
+		// The latter is the erroneous header written by old encoders.
+		return BinaryDataJPEG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return BinaryDataGIF
+	case isZlib(data):
+		return BinaryDataZlib
+	}
+
+	text := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text = bytes.TrimLeftFunc(text, unicode.IsSpace)
+
+	switch {
+	case len(text) > 0 && (text[0] == '{' || text[0] == '[') && json.Valid(data[len(data)-len(text):]):
+		return BinaryDataJSON
+	case isXML(text):
+		return BinaryDataXML
+	case isAMF(data):
+		return BinaryDataAMF
+	}
+
+	return BinaryDataUnknown
+}
+
+func isSWF(data []byte) bool {
+	if len(data) < 8 {
+		return false
+	}
+
+	switch string(data[:3]) {
+	case "FWS", "CWS", "ZWS":
+		return true
+	default:
+		return false
+	}
+}
+
+func isZlib(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+
+	// CM must be 8 (deflate), CINFO must be 7 or less and the header must be
+	// a multiple of 31.
+	return data[0]&0x0f == 8 && data[0]>>4 <= 7 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0
+}
+
+func isXML(text []byte) bool {
+	if bytes.HasPrefix(text, []byte("<?xml")) || bytes.HasPrefix(text, []byte("<!--")) || bytes.HasPrefix(text, []byte("<!DOCTYPE")) {
+		return true
+	}
+	if len(text) < 2 || text[0] != '<' || !utf8.Valid(text) {
+		return false
+	}
+
+	r, _ := utf8.DecodeRune(text[1:])
+
+	return (unicode.IsLetter(r) || r == '_') && bytes.Contains(text, []byte(">"))
+}
+
+// isAMF detects the data written by ByteArray.writeObject or a local shared
+// object. The AMF3 values must decode their headers, because a text file may
+// start with the same bytes as the markers, e.g. 0x0a is a line feed.
+func isAMF(data []byte) bool {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x00, 0xbf}) && len(data) >= 10 && bytes.Equal(data[6:10], []byte("TCSO")):
+		// Local shared object.
+		return true
+	case len(data) >= 2 && data[0] == 0x0a:
+		return isAMF3Object(data)
+	case len(data) >= 2 && data[0] == 0x09:
+		return isAMF3Array(data)
+	case len(data) >= 2 && data[0] == 0x11:
+		// AMF0 avmplus-object marker followed by an AMF3 value.
+		return data[1] <= 0x11
+	case len(data) >= 4 && data[0] == 0x03:
+		// AMF0 object whose first key is a short string.
+		length := int(data[1])<<8 | int(data[2])
+
+		return length > 0 && len(data) >= 3+length && utf8.Valid(data[3:3+length])
+	default:
+		return false
+	}
+}
+
+// isAMF3Object reports whether data starts with an AMF3 object with inline
+// traits. The traits cannot be a reference at the start of the data,
+// because no traits are defined yet.
+func isAMF3Object(data []byte) bool {
+	traits, offset, ok := readAMF3U29(data, 1)
+
+	if !ok || traits&0b11 != 0b11 {
+		return false
+	}
+
+	offset, ok = readAMF3String(data, offset)
+
+	if !ok {
+		return false
+	}
+	if traits&0b100 != 0 {
+		// Externalizable object, whose body is written by the class.
+		return true
+	}
+
+	count := int(traits >> 4)
+
+	for i := 0; i < count; i++ {
+		if offset, ok = readAMF3String(data, offset); !ok {
+			return false
+		}
+	}
+	if count > 0 {
+		return isAMF3Marker(data, offset)
+	}
+
+	return true
+}
+
+// isAMF3Array reports whether data starts with an AMF3 array with an inline
+// length.
+func isAMF3Array(data []byte) bool {
+	length, offset, ok := readAMF3U29(data, 1)
+
+	if !ok || length&0b1 == 0 {
+		return false
+	}
+
+	start := offset
+
+	// The first key of the associative part, which is empty when the array
+	// is dense.
+	offset, ok = readAMF3String(data, offset)
+
+	if !ok {
+		return false
+	}
+	if offset-start > 1 {
+		return isAMF3Marker(data, offset)
+	}
+	if count := int(length >> 1); count > 0 {
+		// Every element takes at least one byte.
+		return count <= len(data)-offset && isAMF3Marker(data, offset)
+	}
+
+	return true
+}
+
+func isAMF3Marker(data []byte, offset int) bool {
+	return offset < len(data) && data[offset] <= 0x11
+}
+
+// readAMF3U29 reads the variable length 29-bit integer at offset.
+func readAMF3U29(data []byte, offset int) (value uint32, next int, ok bool) {
+	for i := 0; i < 4; i++ {
+		if offset >= len(data) {
+			return 0, 0, false
+		}
+
+		b := data[offset]
+		offset += 1
+
+		if i == 3 {
+			return value<<8 | uint32(b), offset, true
+		}
+
+		value = value<<7 | uint32(b&0x7f)
+
+		if b&0x80 == 0 {
+			return value, offset, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// readAMF3String skips the inline UTF-8 string at offset. The references
+// are rejected, because no strings are defined at the start of the data.
+func readAMF3String(data []byte, offset int) (next int, ok bool) {
+	header, offset, ok := readAMF3U29(data, offset)
+
+	if !ok || header&0b1 == 0 {
+		return 0, false
+	}
+
+	length := int(header >> 1)
+
+	if length > len(data)-offset || !utf8.Valid(data[offset:offset+length]) {
+		return 0, false
+	}
+
+	return offset + length, true
+}
@@ -0,0 +1,35 @@
+package swf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectBinaryDataKind(t *testing.T) {
+	for _, c := range []struct {
+		data     []byte
+		expected BinaryDataKind
+	}{
+		{[]byte("FWS\x0a\x10\x00\x00\x00"), BinaryDataSWF},
+		{[]byte("ZWS\x0d\x10\x00\x00\x00"), BinaryDataSWF},
+		{[]byte("\x89PNG\r\n\x1a\n\x00\x00"), BinaryDataPNG},
+		{[]byte{0xff, 0xd8, 0xff, 0xe0}, BinaryDataJPEG},
+		{[]byte("GIF89a\x01\x00"), BinaryDataGIF},
+		{[]byte{0x78, 0x9c, 0x03, 0x00}, BinaryDataZlib},
+		{[]byte("\xef\xbb\xbf<?xml version=\"1.0\"?><a/>"), BinaryDataXML},
+		{[]byte("  <config><item/></config>"), BinaryDataXML},
+		{[]byte("\n{\"key\": [1, 2]}"), BinaryDataJSON},
+		{[]byte{0x0a, 0x0b, 0x01}, BinaryDataAMF},
+		{[]byte{0x0a, 0x13, 0x01, 0x03, 'x', 0x04, 0x01}, BinaryDataAMF},
+		{[]byte{0x09, 0x05, 0x01, 0x04, 0x01, 0x04, 0x02}, BinaryDataAMF},
+		{[]byte("\ncss text"), BinaryDataUnknown},
+		{[]byte("\nhello, world"), BinaryDataUnknown},
+		{[]byte("\tindented text"), BinaryDataUnknown},
+		{[]byte("\t\t"), BinaryDataUnknown},
+		{[]byte("{not json"), BinaryDataUnknown},
+		{nil, BinaryDataUnknown},
+	} {
+		require.Equal(t, c.expected, DetectBinaryDataKind(c.data), "%q", c.data)
+	}
+}
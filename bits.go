@@ -0,0 +1,131 @@
+package swf
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/moutend/go-bits"
+)
+
+// bitReader reads the bit fields of SWF records. Unlike bits.Buffer it never
+// reads ahead, so a record that ends in the middle of a byte does not consume
+// the bytes that follow it.
+type bitReader struct {
+	src       io.Reader
+	current   byte
+	remaining int
+}
+
+func newBitReader(src io.Reader) *bitReader {
+	return &bitReader{src: src}
+}
+
+func (r *bitReader) readBits(n int) (uint64, error) {
+	if n > 64 {
+		return 0, fmt.Errorf("cannot read %d bits at once", n)
+	}
+
+	var value uint64
+
+	for i := 0; i < n; i++ {
+		if r.remaining == 0 {
+			var b [1]byte
+
+			if _, err := io.ReadFull(r.src, b[:]); err != nil {
+				return 0, err
+			}
+
+			r.current = b[0]
+			r.remaining = 8
+		}
+
+		r.remaining -= 1
+		value = value<<1 | uint64((r.current>>r.remaining)&1)
+	}
+
+	return value, nil
+}
+
+func (r *bitReader) readSignedBits(n int) (int64, error) {
+	value, err := r.readBits(n)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return signExtend(value, n), nil
+}
+
+func (r *bitReader) readFlag() (bool, error) {
+	value, err := r.readBits(1)
+
+	return value == 1, err
+}
+
+// align discards the rest of the current byte.
+func (r *bitReader) align() {
+	r.remaining = 0
+}
+
+func signExtend(value uint64, n int) int64 {
+	if n == 0 || n >= 64 {
+		return int64(value)
+	}
+	if value&(1<<(n-1)) != 0 {
+		return int64(value) - int64(1)<<n
+	}
+
+	return int64(value)
+}
+
+// signedBits returns the number of bits required to store all values as SB.
+func signedBits(values ...int64) int {
+	n := 0
+
+	for _, value := range values {
+		if value < 0 {
+			value = ^value
+		}
+
+		required := 1
+
+		for value != 0 {
+			value >>= 1
+			required += 1
+		}
+		if required > n {
+			n = required
+		}
+	}
+
+	return n
+}
+
+// unsignedBits returns the number of bits required to store all values as UB.
+func unsignedBits(values ...uint64) int {
+	n := 0
+
+	for _, value := range values {
+		required := 0
+
+		for value != 0 {
+			value >>= 1
+			required += 1
+		}
+		if required > n {
+			n = required
+		}
+	}
+
+	return n
+}
+
+// appendFlag appends a UB[1] field. bits.Buffer.Append only fails when more
+// than 64 bits are appended at once, so the callers ignore its error.
+func appendFlag(buffer *bits.Buffer, flag bool) {
+	if flag {
+		buffer.Append(1, 1)
+	} else {
+		buffer.Append(0, 1)
+	}
+}
@@ -0,0 +1,90 @@
+package swf
+
+import "fmt"
+
+// Button joins a DefineButton or DefineButton2 with the DefineButtonCxform and
+// DefineButtonSound tags that refer to the same button ID.
+type Button struct {
+	ID      uint16
+	Define  *DefineButton
+	Define2 *DefineButton2
+	Cxform  *DefineButtonCxform
+	Sound   *DefineButtonSound
+}
+
+func (b *Button) String() string {
+	if b == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("Button{ID: %d, Characters: %d, Actions: %d, Cxform: %v, Sound: %v}", b.ID, len(b.Characters()), len(b.Actions()), b.Cxform != nil, b.Sound != nil)
+}
+
+// Characters returns the button records of the button.
+func (b *Button) Characters() []*ButtonRecord {
+	switch {
+	case b.Define2 != nil:
+		return b.Define2.Characters
+	case b.Define != nil:
+		return b.Define.Characters
+	default:
+		return nil
+	}
+}
+
+// Actions returns the handlers of the button. The actions of DefineButton are
+// returned as a single ButtonCondAction triggered by OverDownToOverUp.
+func (b *Button) Actions() []*ButtonCondAction {
+	switch {
+	case b.Define2 != nil:
+		return b.Define2.Actions
+	case b.Define != nil:
+		if len(b.Define.Actions) == 0 {
+			return nil
+		}
+
+		return []*ButtonCondAction{{OverDownToOverUp: true, Actions: b.Define.Actions}}
+	default:
+		return nil
+	}
+}
+
+// Buttons returns every button defined in the file in the order of
+// definition. The buttons are defined at top level only, because DefineSprite
+// cannot contain the definition tags.
+func (f *File) Buttons() []*Button {
+	if f == nil {
+		return nil
+	}
+
+	var buttons []*Button
+
+	index := map[uint16]*Button{}
+
+	for _, content := range f.Contents {
+		switch v := content.(type) {
+		case *DefineButton:
+			button := &Button{ID: v.ButtonID.Value, Define: v}
+			buttons = append(buttons, button)
+			index[button.ID] = button
+		case *DefineButton2:
+			button := &Button{ID: v.ButtonID.Value, Define2: v}
+			buttons = append(buttons, button)
+			index[button.ID] = button
+		}
+	}
+	for _, content := range f.Contents {
+		switch v := content.(type) {
+		case *DefineButtonCxform:
+			if button, ok := index[v.ButtonID.Value]; ok {
+				button.Cxform = v
+			}
+		case *DefineButtonSound:
+			if button, ok := index[v.ButtonID.Value]; ok {
+				button.Sound = v
+			}
+		}
+	}
+
+	return buttons
+}
@@ -0,0 +1,116 @@
+package swf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDefineButton(t *testing.T) {
+	body := []byte{
+		// ButtonID
+		0x01, 0x00,
+		// BUTTONRECORD with an empty MATRIX
+		0x0f, 0x02, 0x00, 0x01, 0x00, 0x00,
+		// CharacterEndFlag
+		0x00,
+		// ActionStop and ActionEndFlag
+		0x07, 0x00,
+	}
+	data := newTestTag(t, DefineButtonTagCode, body)
+
+	content, err := parseContent(bytes.NewBuffer(data))
+
+	require.NoError(t, err)
+
+	button, ok := content.(*DefineButton)
+
+	require.True(t, ok)
+	require.Equal(t, uint16(1), button.ButtonID.Value)
+	require.Len(t, button.Characters, 1)
+	require.Equal(t, uint16(2), button.Characters[0].CharacterID.Value)
+	require.Equal(t, []byte{0x07, 0x00}, button.Actions)
+
+	serialized, err := button.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, data, serialized)
+}
+
+func TestParseDefineButtonCxform(t *testing.T) {
+	// CXFORM with the add terms 0x10, 0x20 and 0x30 in 8 bits
+	body := []byte{0x01, 0x00, 0xa0, 0x40, 0x80, 0xc0}
+	data := newTestTag(t, DefineButtonCxformTagCode, body)
+
+	content, err := parseContent(bytes.NewBuffer(data))
+
+	require.NoError(t, err)
+
+	cxform, ok := content.(*DefineButtonCxform)
+
+	require.True(t, ok)
+	require.Equal(t, uint16(1), cxform.ButtonID.Value)
+	require.True(t, cxform.ColorTransform.HasAddTerms)
+	require.False(t, cxform.ColorTransform.HasMultTerms)
+	require.Equal(t, []int16{0x10, 0x20, 0x30}, []int16{cxform.ColorTransform.RedAddTerm, cxform.ColorTransform.GreenAddTerm, cxform.ColorTransform.BlueAddTerm})
+
+	serialized, err := cxform.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, data, serialized)
+}
+
+func TestParseDefineButtonSound(t *testing.T) {
+	body := []byte{
+		// ButtonID
+		0x01, 0x00,
+		// OverUpToIdle has no sound
+		0x00, 0x00,
+		// IdleToOverUp plays the sound 7 with the empty SOUNDINFO
+		0x07, 0x00, 0x00,
+		// OverUpToOverDown and OverDownToOverUp have no sound
+		0x00, 0x00,
+		0x00, 0x00,
+	}
+	data := newTestTag(t, DefineButtonSoundTagCode, body)
+
+	content, err := parseContent(bytes.NewBuffer(data))
+
+	require.NoError(t, err)
+
+	sound, ok := content.(*DefineButtonSound)
+
+	require.True(t, ok)
+	require.Equal(t, uint16(0), sound.ButtonSoundChar0.Value)
+	require.Nil(t, sound.ButtonSoundInfo0)
+	require.Equal(t, uint16(7), sound.ButtonSoundChar1.Value)
+	require.NotNil(t, sound.ButtonSoundInfo1)
+
+	serialized, err := sound.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, data, serialized)
+}
+
+func TestButtons(t *testing.T) {
+	button := &DefineButton{ButtonID: &Uint16{Value: 1}, Actions: []byte{0x07, 0x00}}
+	button2 := &DefineButton2{ButtonID: &Uint16{Value: 2}}
+	cxform := &DefineButtonCxform{ButtonID: &Uint16{Value: 1}}
+	sound := &DefineButtonSound{ButtonID: &Uint16{Value: 2}}
+	orphan := &DefineButtonSound{ButtonID: &Uint16{Value: 3}}
+	file := &File{Contents: ContentSlice{button, cxform, button2, sound, orphan}}
+
+	buttons := file.Buttons()
+
+	require.Len(t, buttons, 2)
+	require.Equal(t, uint16(1), buttons[0].ID)
+	require.Equal(t, button, buttons[0].Define)
+	require.Equal(t, cxform, buttons[0].Cxform)
+	require.Nil(t, buttons[0].Sound)
+	require.Equal(t, []*ButtonCondAction{{OverDownToOverUp: true, Actions: []byte{0x07, 0x00}}}, buttons[0].Actions())
+	require.Equal(t, uint16(2), buttons[1].ID)
+	require.Equal(t, button2, buttons[1].Define2)
+	require.Equal(t, sound, buttons[1].Sound)
+	require.Nil(t, buttons[1].Cxform)
+}
@@ -0,0 +1,163 @@
+package swf
+
+import (
+	"fmt"
+
+	"github.com/moutend/swf/abc"
+)
+
+// Class is an ActionScript 3 class defined in a DoAbc tag, joined with the
+// character linked to it by SymbolClass.
+type Class struct {
+	// Name is the qualified name of the class, such as "com.game.ui.Button".
+	Name       string
+	SuperName  string
+	Interfaces []string
+	Interface  bool
+	Traits     []*ClassTrait
+	// Abc is the DoAbc tag defining the class, and Script is the index of
+	// the script in it whose traits have the class.
+	Abc    *DoAbc
+	Script int
+	// Linked reports whether SymbolClass links the class to CharacterID.
+	// The CharacterID 0 denotes the main timeline, and then the class is
+	// the document class.
+	Linked      bool
+	CharacterID uint16
+}
+
+// ClassTrait is a field or a method of a class. Type is the type of the
+// field or the return type of the method.
+type ClassTrait struct {
+	Name   string
+	Kind   abc.TraitKind
+	Static bool
+	Type   string
+}
+
+func (c *Class) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	character := "none"
+
+	if c.Linked {
+		character = fmt.Sprint(c.CharacterID)
+	}
+
+	return fmt.Sprintf("Class{Name: %s, SuperName: %s, Traits: %d, CharacterID: %s}", c.Name, c.SuperName, len(c.Traits), character)
+}
+
+// Classes returns every class defined in the DoAbc tags in the order of
+// definition.
+func (f *File) Classes() ([]*Class, error) {
+	if f == nil {
+		return nil, fmt.Errorf("cannot list classes because File is nil")
+	}
+
+	symbols := f.classSymbols()
+
+	var classes []*Class
+
+	for i, content := range f.Contents {
+		v, ok := content.(*DoAbc)
+
+		if !ok {
+			continue
+		}
+
+		file, err := v.DecodeAbc()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode DoAbc (File.Contents[%d]): %w", i, err)
+		}
+
+		scripts := map[uint32]int{}
+
+		for j, script := range file.Scripts {
+			if script == nil {
+				continue
+			}
+			for _, trait := range script.Traits {
+				if trait != nil && trait.Kind == abc.TraitClass {
+					scripts[trait.Index] = j
+				}
+			}
+		}
+		for j, instance := range file.Instances {
+			if instance == nil {
+				continue
+			}
+
+			class := &Class{
+				Name:      file.QualifiedName(instance.Name),
+				Interface: instance.Flags&abc.InstanceInterface != 0,
+				Abc:       v,
+				Script:    -1,
+			}
+
+			if instance.SuperName != 0 {
+				class.SuperName = file.QualifiedName(instance.SuperName)
+			}
+			for _, name := range instance.Interfaces {
+				class.Interfaces = append(class.Interfaces, file.QualifiedName(name))
+			}
+
+			class.Traits = classTraits(file, instance.Traits, false)
+
+			if j < len(file.Classes) && file.Classes[j] != nil {
+				class.Traits = append(class.Traits, classTraits(file, file.Classes[j].Traits, true)...)
+			}
+			if script, ok := scripts[uint32(j)]; ok {
+				class.Script = script
+			}
+
+			class.CharacterID, class.Linked = symbols[class.Name]
+			classes = append(classes, class)
+		}
+	}
+
+	return classes, nil
+}
+
+func classTraits(file *abc.File, traits []*abc.Trait, static bool) []*ClassTrait {
+	var result []*ClassTrait
+
+	for _, trait := range traits {
+		if trait == nil {
+			continue
+		}
+
+		t := &ClassTrait{Name: file.Name(trait.Name), Kind: trait.Kind, Static: static}
+
+		switch trait.Kind {
+		case abc.TraitSlot, abc.TraitConst:
+			t.Type = file.QualifiedName(trait.Type)
+		case abc.TraitMethod, abc.TraitGetter, abc.TraitSetter, abc.TraitFunction:
+			if int(trait.Index) < len(file.Methods) && file.Methods[trait.Index] != nil {
+				t.Type = file.QualifiedName(file.Methods[trait.Index].ReturnType)
+			}
+		}
+
+		result = append(result, t)
+	}
+
+	return result
+}
+
+// DocumentClass returns the class of the main timeline.
+func (f *File) DocumentClass() (*Class, error) {
+	classes, err := f.Classes()
+
+	if err != nil {
+		return nil, err
+	}
+	for _, class := range classes {
+		if class.Linked && class.CharacterID == 0 {
+			return class, nil
+		}
+	}
+
+	return nil, fmt.Errorf("document class is not found")
+}
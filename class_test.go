@@ -0,0 +1,131 @@
+package swf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/moutend/swf/abc"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAbcFile returns an ABC file defining com.game.ui.Button extends
+// flash.display.Sprite and Main extends flash.display.MovieClip, each in its
+// own script.
+func newTestAbcFile() *abc.File {
+	return &abc.File{
+		MinorVersion: 16,
+		MajorVersion: 46,
+		ConstantPool: abc.ConstantPool{
+			Strings: []string{"", "com.game.ui", "Button", "flash.display", "Sprite", "", "Main", "MovieClip", "label", "String", "IEventDispatcher", "flash.events"},
+			Namespaces: []*abc.Namespace{
+				{},
+				{Kind: abc.KindPackageNamespace, Name: 1},
+				{Kind: abc.KindPackageNamespace, Name: 3},
+				{Kind: abc.KindPackageNamespace, Name: 5},
+				{Kind: abc.KindPackageNamespace, Name: 11},
+			},
+			Multinames: []*abc.Multiname{
+				{},
+				{Kind: abc.KindQName, Namespace: 1, Name: 2},
+				{Kind: abc.KindQName, Namespace: 2, Name: 4},
+				{Kind: abc.KindQName, Namespace: 3, Name: 6},
+				{Kind: abc.KindQName, Namespace: 2, Name: 7},
+				{Kind: abc.KindQName, Namespace: 3, Name: 8},
+				{Kind: abc.KindQName, Namespace: 3, Name: 9},
+				{Kind: abc.KindQName, Namespace: 4, Name: 10},
+			},
+		},
+		Methods: []*abc.Method{{}, {}, {}, {}, {}, {}},
+		Instances: []*abc.Instance{
+			{
+				Name:       1,
+				SuperName:  2,
+				Flags:      abc.InstanceSealed,
+				Interfaces: []uint32{7},
+				Init:       0,
+				Traits:     []*abc.Trait{{Name: 5, Kind: abc.TraitSlot, ID: 1, Type: 6}},
+			},
+			{Name: 3, SuperName: 4, Flags: abc.InstanceSealed, Init: 2},
+		},
+		Classes: []*abc.Class{{Init: 1}, {Init: 3}},
+		Scripts: []*abc.Script{
+			{Init: 4, Traits: []*abc.Trait{{Name: 1, Kind: abc.TraitClass, ID: 1, Index: 0}}},
+			{Init: 5, Traits: []*abc.Trait{{Name: 3, Kind: abc.TraitClass, ID: 1, Index: 1}}},
+		},
+	}
+}
+
+func TestClasses(t *testing.T) {
+	data, err := abc.Encode(newTestAbcFile())
+
+	require.NoError(t, err)
+
+	doAbc := append([]byte{0x01, 0x00, 0x00, 0x00}, "frame1\x00"...)
+	doAbc = append(doAbc, data...)
+
+	symbolClass := []byte{0x02, 0x00, 0x03, 0x00}
+	symbolClass = append(symbolClass, "com.game.ui.Button\x00"...)
+	symbolClass = append(symbolClass, 0x00, 0x00)
+	symbolClass = append(symbolClass, "Main\x00"...)
+
+	file, err := Parse(bytes.NewReader(newTestSWF(t,
+		newTestTag(t, DoAbcTagCode, doAbc),
+		newTestTag(t, SymbolClassTagCode, symbolClass),
+	)))
+
+	require.NoError(t, err)
+
+	classes, err := file.Classes()
+
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+
+	button := classes[0]
+
+	require.Equal(t, "com.game.ui.Button", button.Name)
+	require.Equal(t, "flash.display.Sprite", button.SuperName)
+	require.Equal(t, []string{"flash.events.IEventDispatcher"}, button.Interfaces)
+	require.Equal(t, []*ClassTrait{{Name: "label", Kind: abc.TraitSlot, Type: "String"}}, button.Traits)
+	require.Equal(t, "frame1", button.Abc.Name)
+	require.Equal(t, 0, button.Script)
+	require.True(t, button.Linked)
+	require.Equal(t, uint16(3), button.CharacterID)
+
+	main, err := file.DocumentClass()
+
+	require.NoError(t, err)
+	require.Equal(t, "Main", main.Name)
+	require.Equal(t, "flash.display.MovieClip", main.SuperName)
+	require.Equal(t, 1, main.Script)
+}
+
+func TestClassSymbols(t *testing.T) {
+	file := &File{Contents: ContentSlice{
+		&SymbolClass{Symbols: []*Symbol{
+			{Name: "Orphan"},
+			{Name: "Main", CharacterID: &Uint16{Value: 0}},
+			{Name: "Button", CharacterID: &Uint16{Value: 3}},
+		}},
+		&SymbolClass{Symbols: []*Symbol{
+			{Name: "Button", CharacterID: &Uint16{Value: 4}},
+			{Name: "Orphan", CharacterID: &Uint16{Value: 5}},
+		}},
+	}}
+
+	// The first link wins and the link without CharacterID is ignored.
+	require.Equal(t, map[string]uint16{"Main": 0, "Button": 3, "Orphan": 5}, file.classSymbols())
+
+	characterID, ok := file.ClassCharacterID("Button")
+
+	require.True(t, ok)
+	require.Equal(t, uint16(3), characterID)
+
+	characterID, ok = file.ClassCharacterID("Orphan")
+
+	require.True(t, ok)
+	require.Equal(t, uint16(5), characterID)
+
+	_, ok = file.ClassCharacterID("Missing")
+
+	require.False(t, ok)
+}
@@ -3,6 +3,7 @@ package swf
 import (
 	"bytes"
 	"compress/zlib"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -95,6 +96,9 @@ func (f *File) Serialize() ([]byte, error) {
 	body = append(body, frameCountData...)
 	body = append(body, contentsData...)
 
+	// The FileSize is the length of the uncompressed file.
+	fileSize := &Uint32{Value: uint32(len(body) + 8)}
+
 	if f.Signature.Value == SignatureCompressed {
 		buffer := &bytes.Buffer{}
 		compressed := zlib.NewWriter(buffer)
@@ -102,14 +106,13 @@ func (f *File) Serialize() ([]byte, error) {
 		if _, err := io.Copy(compressed, bytes.NewBuffer(body)); err != nil {
 			return nil, err
 		}
-
-		defer compressed.Close()
+		if err := compressed.Close(); err != nil {
+			return nil, err
+		}
 
 		body = buffer.Bytes()
 	}
 
-	fileSize := &Uint32{Value: uint32(len(body) + 8)}
-
 	fileSizeData, err := fileSize.Serialize()
 
 	if err != nil {
@@ -130,7 +133,33 @@ func (f *File) Serialize() ([]byte, error) {
 	return result, nil
 }
 
+// DefaultMaxEmbedDepth is the nesting limit of embedded SWF files used when
+// ParseOptions.MaxDepth is 0.
+const DefaultMaxEmbedDepth = 8
+
+type ParseOptions struct {
+	// ParseEmbeddedSWF parses the SWF files embedded in DefineBinaryData into
+	// DefineBinaryData.File.
+	ParseEmbeddedSWF bool
+	// MaxDepth limits the nesting of the embedded SWF files.
+	MaxDepth int
+}
+
 func Parse(src io.Reader) (*File, error) {
+	return ParseWithOptions(src, nil)
+}
+
+func ParseWithOptions(src io.Reader, options *ParseOptions) (*File, error) {
+	if options == nil {
+		options = &ParseOptions{}
+	}
+
+	return parseFile(src, options, 0, map[[sha256.Size]byte]bool{})
+}
+
+// parseFile parses the file at the depth. The ancestors holds the hashes of the
+// files being parsed in order to detect a file which embeds itself.
+func parseFile(src io.Reader, options *ParseOptions, depth int, ancestors map[[sha256.Size]byte]bool) (*File, error) {
 	signature, err := ReadSignature(src)
 
 	if err != nil {
@@ -205,9 +234,62 @@ func Parse(src io.Reader) (*File, error) {
 		Contents:   contents,
 	}
 
+	if options.ParseEmbeddedSWF {
+		parseEmbeddedFiles(file, options, depth, ancestors)
+	}
+
 	return file, nil
 }
 
+// parseEmbeddedFiles parses the SWF files embedded in DefineBinaryData. A
+// file which fails to parse is left as Data with DefineBinaryData.FileError,
+// so that a corrupted or lookalike blob does not make the host unreadable.
+func parseEmbeddedFiles(file *File, options *ParseOptions, depth int, ancestors map[[sha256.Size]byte]bool) {
+	maxDepth := options.MaxDepth
+
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxEmbedDepth
+	}
+	if depth >= maxDepth {
+		return
+	}
+
+	for _, content := range file.Contents {
+		binaryData, ok := content.(*DefineBinaryData)
+
+		if !ok || binaryData.Kind() != BinaryDataSWF {
+			continue
+		}
+		if bytes.HasPrefix(binaryData.Data, []byte(`ZWS`)) {
+			binaryData.FileError = fmt.Errorf("LZMA compressed SWF is not supported")
+
+			continue
+		}
+
+		hash := sha256.Sum256(binaryData.Data)
+
+		if ancestors[hash] {
+			binaryData.FileError = fmt.Errorf("SWF embeds itself")
+
+			continue
+		}
+
+		ancestors[hash] = true
+
+		embedded, err := parseFile(bytes.NewReader(binaryData.Data), options, depth+1, ancestors)
+
+		delete(ancestors, hash)
+
+		if err != nil {
+			binaryData.FileError = err
+
+			continue
+		}
+
+		binaryData.File = embedded
+	}
+}
+
 type Content interface {
 	TagCode() TagCode
 	String() string
@@ -435,3 +517,44 @@ func parseContent(src io.Reader) (Content, error) {
 
 	return content, err
 }
+
+// serializeTag encodes the RECORDHEADER followed by the body. The long form is
+// used when the body requires it or when the original tag used it.
+func serializeTag(tagCode TagCode, extended *Uint32, body []byte) ([]byte, error) {
+	length := len(body)
+
+	if int64(length) > int64(^uint32(0)) {
+		return nil, fmt.Errorf("failed to serialize %s: too large body: %d bytes", tagCode, length)
+	}
+
+	var data []byte
+
+	if extended != nil || length >= 0b111111 {
+		tagData, err := (&Uint16{Value: uint16(tagCode)<<6 | 0b111111}).Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		extendedData, err := (&Uint32{Value: uint32(length)}).Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, tagData...)
+		data = append(data, extendedData...)
+	} else {
+		tagData, err := (&Uint16{Value: uint16(tagCode)<<6 | uint16(length)}).Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, tagData...)
+	}
+
+	data = append(data, body...)
+
+	return data, nil
+}
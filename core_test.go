@@ -0,0 +1,200 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSWF returns an uncompressed SWF file which contains the tags
+// followed by End.
+func newTestSWF(t *testing.T, tags ...[]byte) []byte {
+	rectangleData, err := newRectangle(0, 8000, 0, 6000).Serialize()
+
+	require.NoError(t, err)
+
+	var body []byte
+
+	body = append(body, rectangleData...)
+	body = append(body, 0x00, 0x18, 0x01, 0x00)
+
+	for _, tag := range tags {
+		body = append(body, tag...)
+	}
+
+	body = append(body, 0x00, 0x00)
+
+	header := []byte("FWS\x0a\x00\x00\x00\x00")
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(body)+8))
+
+	return append(header, body...)
+}
+
+func newTestTag(t *testing.T, code TagCode, body []byte) []byte {
+	tag, err := serializeTag(code, nil, body)
+
+	require.NoError(t, err)
+
+	return tag
+}
+
+func newTestBinaryDataTag(t *testing.T, id uint16, data []byte) []byte {
+	body := []byte{uint8(id), uint8(id >> 8), 0, 0, 0, 0}
+	body = append(body, data...)
+
+	tag, err := serializeTag(DefineBinaryDataTagCode, nil, body)
+
+	require.NoError(t, err)
+
+	return tag
+}
+
+func TestParseEmbeddedSWF(t *testing.T) {
+	innermost := newTestSWF(t, newTestBinaryDataTag(t, 1, []byte("<a/>")))
+	inner := newTestSWF(t, newTestBinaryDataTag(t, 2, innermost))
+	outer := newTestSWF(t, newTestBinaryDataTag(t, 3, inner))
+
+	file, err := ParseWithOptions(bytes.NewReader(outer), &ParseOptions{ParseEmbeddedSWF: true})
+
+	require.NoError(t, err)
+
+	innerFile := file.Contents[0].(*DefineBinaryData).File
+
+	require.NotNil(t, innerFile)
+
+	innermostFile := innerFile.Contents[0].(*DefineBinaryData).File
+
+	require.NotNil(t, innermostFile)
+
+	innermostFile.Contents[0].(*DefineBinaryData).Data = []byte("<edited/>")
+
+	serialized, err := file.Serialize()
+
+	require.NoError(t, err)
+
+	reparsed, err := ParseWithOptions(bytes.NewReader(serialized), &ParseOptions{ParseEmbeddedSWF: true})
+
+	require.NoError(t, err)
+	require.Equal(t, []byte("<edited/>"), reparsed.Contents[0].(*DefineBinaryData).File.Contents[0].(*DefineBinaryData).File.Contents[0].(*DefineBinaryData).Data)
+
+	limited, err := ParseWithOptions(bytes.NewReader(outer), &ParseOptions{ParseEmbeddedSWF: true, MaxDepth: 1})
+
+	require.NoError(t, err)
+	require.NotNil(t, limited.Contents[0].(*DefineBinaryData).File)
+	require.Nil(t, limited.Contents[0].(*DefineBinaryData).File.Contents[0].(*DefineBinaryData).File)
+}
+
+func TestParseEmbeddedSWFErrors(t *testing.T) {
+	corrupt := []byte("FWS\x0a\xff\x00\x00\x00broken")
+	lzma := []byte("ZWS\x0d\x00\x00\x00\x00lzma")
+	outer := newTestSWF(t, newTestBinaryDataTag(t, 1, corrupt), newTestBinaryDataTag(t, 2, lzma))
+
+	file, err := ParseWithOptions(bytes.NewReader(outer), &ParseOptions{ParseEmbeddedSWF: true})
+
+	require.NoError(t, err)
+
+	for i, data := range [][]byte{corrupt, lzma} {
+		binaryData := file.Contents[i].(*DefineBinaryData)
+
+		require.Nil(t, binaryData.File)
+		require.Error(t, binaryData.FileError)
+		require.Equal(t, data, binaryData.Data)
+	}
+
+	serialized, err := file.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, outer, serialized)
+}
+
+func TestParseEmbeddedSWFLimits(t *testing.T) {
+	inner := newTestSWF(t, newTestBinaryDataTag(t, 1, []byte("<a/>")))
+	file, err := Parse(bytes.NewReader(newTestSWF(t, newTestBinaryDataTag(t, 2, inner))))
+
+	require.NoError(t, err)
+
+	// The file being parsed is registered as an ancestor of itself, as if it
+	// embedded itself.
+	binaryData := file.Contents[0].(*DefineBinaryData)
+	parseEmbeddedFiles(file, &ParseOptions{ParseEmbeddedSWF: true}, 0, map[[sha256.Size]byte]bool{sha256.Sum256(inner): true})
+
+	require.Nil(t, binaryData.File)
+	require.EqualError(t, binaryData.FileError, "SWF embeds itself")
+
+	nested := newTestSWF(t, newTestBinaryDataTag(t, 1, []byte("<a/>")))
+
+	for i := 0; i <= DefaultMaxEmbedDepth; i++ {
+		nested = newTestSWF(t, newTestBinaryDataTag(t, uint16(i+2), nested))
+	}
+
+	file, err = ParseWithOptions(bytes.NewReader(nested), &ParseOptions{ParseEmbeddedSWF: true})
+
+	require.NoError(t, err)
+
+	depth := 0
+
+	for file != nil {
+		binaryData = file.Contents[0].(*DefineBinaryData)
+		file = binaryData.File
+		depth += 1
+	}
+
+	// The file at DefaultMaxEmbedDepth keeps its embedded SWF unparsed.
+	require.Equal(t, DefaultMaxEmbedDepth+1, depth)
+	require.Equal(t, BinaryDataSWF, binaryData.Kind())
+	require.NoError(t, binaryData.FileError)
+}
+
+func TestSerializeFileSize(t *testing.T) {
+	data := newTestSWF(t, newTestBinaryDataTag(t, 1, []byte("data")))
+	file, err := Parse(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	// FileSize is recomputed from the contents.
+	file.Contents[0].(*DefineBinaryData).Data = []byte("longer data")
+
+	serialized, err := file.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, uint32(len(serialized)), binary.LittleEndian.Uint32(serialized[4:8]))
+
+	file.Signature.Value = SignatureCompressed
+
+	compressed, err := file.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, uint32(len(serialized)), binary.LittleEndian.Uint32(compressed[4:8]))
+
+	// The zlib stream is closed, so it has the Adler-32 checksum at the end.
+	reader, err := zlib.NewReader(bytes.NewReader(compressed[8:]))
+
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(reader)
+
+	require.NoError(t, err)
+	require.Equal(t, serialized[8:], body)
+}
+
+func TestSerializeCompressedFile(t *testing.T) {
+	file, err := Parse(bytes.NewReader(newTestSWF(t, newTestBinaryDataTag(t, 1, []byte("data")))))
+
+	require.NoError(t, err)
+
+	file.Signature.Value = SignatureCompressed
+
+	data, err := file.Serialize()
+
+	require.NoError(t, err)
+
+	compressed, err := Parse(bytes.NewReader(data))
+
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), compressed.Contents[0].(*DefineBinaryData).Data)
+}
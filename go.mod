@@ -2,11 +2,13 @@ module github.com/moutend/swf
 
 go 1.18
 
-require github.com/stretchr/testify v1.8.0
+require (
+	github.com/moutend/go-bits v0.0.0-20220815004102-a69f4b9494b2
+	github.com/stretchr/testify v1.8.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/moutend/go-bits v0.0.0-20220815004102-a69f4b9494b2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,244 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// NotJPEGError is returned when the image data of a JPEG tag is PNG or GIF.
+type NotJPEGError struct {
+	Kind BinaryDataKind
+}
+
+func (e *NotJPEGError) Error() string {
+	return fmt.Sprintf("image data is %s, not JPEG", e.Kind)
+}
+
+// ExportJPEG merges the encoding tables and the image data into a JPEG file
+// which image/jpeg can decode. The SOI and EOI markers found before the scan,
+// including the erroneous EOI SOI pair written by old encoders, are removed.
+// The tables may be empty when the image data contains them.
+func ExportJPEG(tables, imageData []byte) ([]byte, error) {
+	data := []byte{0xff, 0xd8}
+
+	data, hasScan, err := appendJPEGSegments(data, tables)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JPEG tables: %w", err)
+	}
+	if hasScan {
+		return nil, fmt.Errorf("JPEG tables must not contain image data")
+	}
+
+	data, hasScan, err = appendJPEGSegments(data, imageData)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JPEG image data: %w", err)
+	}
+	if !hasScan {
+		return nil, fmt.Errorf("JPEG image data has no scan")
+	}
+
+	data = append(data, 0xff, 0xd9)
+
+	return data, nil
+}
+
+// appendJPEGSegments appends the marker segments of src except for SOI and
+// EOI. Once it reaches SOS, it appends the rest of src without the trailing
+// EOI and reports that the scan was found.
+func appendJPEGSegments(dst, src []byte) ([]byte, bool, error) {
+	i := 0
+
+	for i < len(src) {
+		if src[i] != 0xff {
+			return nil, false, fmt.Errorf("invalid marker at %d: 0x%02x", i, src[i])
+		}
+
+		// Skip fill bytes.
+		for i+1 < len(src) && src[i+1] == 0xff {
+			i += 1
+		}
+		if i+1 >= len(src) {
+			break
+		}
+
+		marker := src[i+1]
+
+		switch {
+		case marker == 0xd8, marker == 0xd9:
+			i += 2
+		case marker == 0x01, marker >= 0xd0 && marker <= 0xd7:
+			dst = append(dst, src[i:i+2]...)
+			i += 2
+		case marker == 0xda:
+			scan := src[i:]
+
+			for len(scan) >= 2 && scan[len(scan)-2] == 0xff && scan[len(scan)-1] == 0xd9 {
+				scan = scan[:len(scan)-2]
+			}
+
+			dst = append(dst, scan...)
+
+			return dst, true, nil
+		default:
+			if i+4 > len(src) {
+				return nil, false, fmt.Errorf("truncated marker 0x%02x at %d", marker, i)
+			}
+
+			length := int(src[i+2])<<8 | int(src[i+3])
+
+			if length < 2 || i+2+length > len(src) {
+				return nil, false, fmt.Errorf("invalid length of marker 0x%02x at %d: %d", marker, i, length)
+			}
+
+			dst = append(dst, src[i:i+2+length]...)
+			i += 2 + length
+		}
+	}
+
+	return dst, false, nil
+}
+
+// JpegTables returns the JpegTables tag of the file, or nil when the file
+// has none.
+func (f *File) JpegTables() *JpegTables {
+	if f == nil {
+		return nil
+	}
+
+	for _, content := range f.Contents {
+		if tables, ok := content.(*JpegTables); ok {
+			return tables
+		}
+	}
+
+	return nil
+}
+
+func decompressAlphaPlane(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress alpha plane: %w", err)
+	}
+
+	defer reader.Close()
+
+	plane, err := io.ReadAll(reader)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress alpha plane: %w", err)
+	}
+
+	return plane, nil
+}
+
+// decodeJpegWithAlpha decodes the image data of DefineBitsJpeg3 or
+// DefineBitsJpeg4. The alpha plane applies to JPEG data only.
+func decodeJpegWithAlpha(imageData, alphaData []byte) (*image.NRGBA, error) {
+	var img image.Image
+	var err error
+
+	switch kind := DetectBinaryDataKind(imageData); kind {
+	case BinaryDataJPEG:
+		var jpegData []byte
+
+		if jpegData, err = ExportJPEG(nil, imageData); err != nil {
+			return nil, err
+		}
+
+		img, err = jpeg.Decode(bytes.NewReader(jpegData))
+	case BinaryDataPNG:
+		img, err = png.Decode(bytes.NewReader(imageData))
+	case BinaryDataGIF:
+		img, err = gif.Decode(bytes.NewReader(imageData))
+	default:
+		return nil, fmt.Errorf("unsupported image data: %s", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	result := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+
+	if DetectBinaryDataKind(imageData) != BinaryDataJPEG {
+		return result, nil
+	}
+
+	plane, err := decompressAlphaPlane(alphaData)
+
+	if err != nil {
+		return nil, err
+	}
+	if len(plane) == 0 {
+		return result, nil
+	}
+	if len(plane) < bounds.Dx()*bounds.Dy() {
+		return nil, fmt.Errorf("alpha plane must have %d bytes but got %d", bounds.Dx()*bounds.Dy(), len(plane))
+	}
+
+	for i := 0; i < bounds.Dx()*bounds.Dy(); i++ {
+		result.Pix[i*4+3] = plane[i]
+	}
+
+	return result, nil
+}
+
+// NewDefineBitsJpeg3 builds DefineBitsJpeg3 from a JPEG file and an alpha
+// mask of the same size. The alpha of each pixel of the mask is used.
+func NewDefineBitsJpeg3(characterID uint16, jpegData []byte, mask image.Image) (*DefineBitsJpeg3, error) {
+	config, err := jpeg.DecodeConfig(bytes.NewReader(jpegData))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build DefineBitsJpeg3: %w", err)
+	}
+
+	bounds := mask.Bounds()
+
+	if bounds.Dx() != config.Width || bounds.Dy() != config.Height {
+		return nil, fmt.Errorf("failed to build DefineBitsJpeg3: mask must be %dx%d but got %dx%d", config.Width, config.Height, bounds.Dx(), bounds.Dy())
+	}
+
+	plane := make([]byte, 0, config.Width*config.Height)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			plane = append(plane, color.AlphaModel.Convert(mask.At(x, y)).(color.Alpha).A)
+		}
+	}
+
+	compressed := &bytes.Buffer{}
+	writer := zlib.NewWriter(compressed)
+
+	if _, err := writer.Write(plane); err != nil {
+		return nil, fmt.Errorf("failed to build DefineBitsJpeg3: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build DefineBitsJpeg3: %w", err)
+	}
+
+	result := &DefineBitsJpeg3{
+		CharacterID:     &Uint16{Value: characterID},
+		AlphaDataOffset: &Uint32{Value: uint32(len(jpegData))},
+		ImageData:       append([]byte{}, jpegData...),
+		BitmapAlphaData: compressed.Bytes(),
+	}
+
+	return result, nil
+}
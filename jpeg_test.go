@@ -0,0 +1,135 @@
+package swf
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJPEG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+
+	buffer := &bytes.Buffer{}
+
+	require.NoError(t, jpeg.Encode(buffer, img, nil))
+
+	return buffer.Bytes()
+}
+
+// splitTestJPEG splits the JPEG into the DQT and DHT tables and the rest in
+// the way DefineBits and JpegTables store them.
+func splitTestJPEG(t *testing.T, data []byte) (tables, imageData []byte) {
+	tables = []byte{0xff, 0xd8}
+	imageData = []byte{0xff, 0xd8}
+
+	for i := 2; i < len(data); {
+		marker := data[i+1]
+
+		if marker == 0xda {
+			imageData = append(imageData, data[i:]...)
+
+			break
+		}
+
+		length := int(data[i+2])<<8 | int(data[i+3])
+
+		if marker == 0xdb || marker == 0xc4 {
+			tables = append(tables, data[i:i+2+length]...)
+		} else {
+			imageData = append(imageData, data[i:i+2+length]...)
+		}
+
+		i += 2 + length
+	}
+
+	tables = append(tables, 0xff, 0xd9)
+
+	return tables, imageData
+}
+
+func TestExportJPEG(t *testing.T) {
+	original := newTestJPEG(t)
+	tables, imageData := splitTestJPEG(t, original)
+
+	bits := &DefineBits{CharacterID: &Uint16{Value: 1}, JpegData: imageData}
+	exported, err := bits.ExportJPEG(&JpegTables{JpegData: tables})
+
+	require.NoError(t, err)
+
+	img, err := jpeg.Decode(bytes.NewReader(exported))
+
+	require.NoError(t, err)
+	require.Equal(t, 16, img.Bounds().Dx())
+
+	jpeg2 := &DefineBitsJpeg2{
+		CharacterID: &Uint16{Value: 2},
+		ImageData:   append([]byte{0xff, 0xd9, 0xff, 0xd8}, original...),
+	}
+
+	require.Equal(t, BinaryDataJPEG, jpeg2.Kind())
+
+	exported, err = jpeg2.ExportJPEG()
+
+	require.NoError(t, err)
+
+	_, err = jpeg.Decode(bytes.NewReader(exported))
+
+	require.NoError(t, err)
+}
+
+func TestExportJPEGWithPNG(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	require.NoError(t, png.Encode(buffer, image.NewGray(image.Rect(0, 0, 1, 1))))
+
+	jpeg2 := &DefineBitsJpeg2{CharacterID: &Uint16{Value: 1}, ImageData: buffer.Bytes()}
+
+	_, err := jpeg2.ExportJPEG()
+
+	var notJPEG *NotJPEGError
+
+	require.True(t, errors.As(err, &notJPEG))
+	require.Equal(t, BinaryDataPNG, notJPEG.Kind)
+}
+
+func TestDefineBitsJpeg3Image(t *testing.T) {
+	mask := image.NewAlpha(image.Rect(0, 0, 16, 16))
+	mask.SetAlpha(3, 4, color.Alpha{0x40})
+
+	jpeg3, err := NewDefineBitsJpeg3(1, newTestJPEG(t), mask)
+
+	require.NoError(t, err)
+
+	data, err := jpeg3.Serialize()
+
+	require.NoError(t, err)
+
+	parsed, err := parseContent(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	plane, err := parsed.(*DefineBitsJpeg3).AlphaPlane()
+
+	require.NoError(t, err)
+	require.Len(t, plane, 256)
+
+	img, err := parsed.(*DefineBitsJpeg3).Image()
+
+	require.NoError(t, err)
+
+	nrgba := img.(*image.NRGBA)
+
+	require.Equal(t, uint8(0x40), nrgba.NRGBAAt(3, 4).A)
+	require.Equal(t, uint8(0x00), nrgba.NRGBAAt(0, 0).A)
+	require.InDelta(t, 0x80, int(nrgba.NRGBAAt(3, 4).R), 2)
+}
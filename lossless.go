@@ -0,0 +1,280 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+const (
+	BitmapFormatColorMapped8 = 3
+	BitmapFormatRGB15        = 4
+	BitmapFormatRGB24        = 5
+)
+
+// decodeLossless decodes the zlib compressed COLORMAPDATA, BITMAPDATA or
+// ALPHABITMAPDATA. The colors of DefineBitsLossless2 are premultiplied by
+// alpha.
+func decodeLossless(format uint8, width, height, colorTableSize int, zlibData []byte, hasAlpha bool) (image.Image, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(zlibData))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(0, 0, width, height)
+
+	switch format {
+	case BitmapFormatColorMapped8:
+		entrySize := 3
+
+		if hasAlpha {
+			entrySize = 4
+		}
+
+		// Each row is padded to a multiple of 32 bits.
+		stride := (width + 3) &^ 3
+
+		if len(data) < colorTableSize*entrySize+stride*height {
+			return nil, fmt.Errorf("too short bitmap data: %d bytes", len(data))
+		}
+
+		palette := make(color.Palette, colorTableSize)
+
+		for i := range palette {
+			entry := data[i*entrySize : (i+1)*entrySize]
+
+			if hasAlpha {
+				palette[i] = premultipliedRGBA(entry[0], entry[1], entry[2], entry[3])
+			} else {
+				palette[i] = color.RGBA{entry[0], entry[1], entry[2], 0xff}
+			}
+		}
+
+		pixels := data[colorTableSize*entrySize:]
+		img := image.NewPaletted(bounds, palette)
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				index := pixels[y*stride+x]
+
+				if int(index) >= colorTableSize {
+					return nil, fmt.Errorf("color index out of range: %d", index)
+				}
+
+				img.Pix[y*img.Stride+x] = index
+			}
+		}
+
+		return img, nil
+	case BitmapFormatRGB15:
+		if hasAlpha {
+			return nil, fmt.Errorf("bitmap format %d is not allowed in DefineBitsLossless2", format)
+		}
+
+		stride := (width*2 + 3) &^ 3
+
+		if len(data) < stride*height {
+			return nil, fmt.Errorf("too short bitmap data: %d bytes", len(data))
+		}
+
+		img := image.NewRGBA(bounds)
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				offset := y*stride + x*2
+				pix15 := uint16(data[offset])<<8 | uint16(data[offset+1])
+				i := img.PixOffset(x, y)
+
+				img.Pix[i+0] = expand5(uint8(pix15 >> 10))
+				img.Pix[i+1] = expand5(uint8(pix15 >> 5))
+				img.Pix[i+2] = expand5(uint8(pix15))
+				img.Pix[i+3] = 0xff
+			}
+		}
+
+		return img, nil
+	case BitmapFormatRGB24:
+		if len(data) < width*height*4 {
+			return nil, fmt.Errorf("too short bitmap data: %d bytes", len(data))
+		}
+
+		img := image.NewRGBA(bounds)
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				pixel := data[(y*width+x)*4:]
+				i := img.PixOffset(x, y)
+				alpha := uint8(0xff)
+
+				// The first byte is reserved in PIX24 and is the alpha in ARGB.
+				if hasAlpha {
+					alpha = pixel[0]
+				}
+
+				c := premultipliedRGBA(pixel[1], pixel[2], pixel[3], alpha)
+
+				img.Pix[i+0] = c.R
+				img.Pix[i+1] = c.G
+				img.Pix[i+2] = c.B
+				img.Pix[i+3] = c.A
+			}
+		}
+
+		return img, nil
+	default:
+		return nil, fmt.Errorf("invalid bitmap format: %d", format)
+	}
+}
+
+// premultipliedRGBA clamps the premultiplied components to alpha because the
+// Flash Player accepts larger values.
+func premultipliedRGBA(red, green, blue, alpha uint8) color.RGBA {
+	return color.RGBA{minUint8(red, alpha), minUint8(green, alpha), minUint8(blue, alpha), alpha}
+}
+
+func minUint8(a, b uint8) uint8 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// expand5 converts a 5-bit color component to 8 bits.
+func expand5(value uint8) uint8 {
+	value &= 0b11111
+
+	return value<<3 | value>>2
+}
+
+// NewLosslessBitmap encodes the image into a bitmap tag with the character
+// ID. The result is a *DefineBitsLossless when the image is opaque and a
+// *DefineBitsLossless2 otherwise. The colormapped format is used when the
+// image has 256 colors or fewer.
+func NewLosslessBitmap(characterID uint16, img image.Image) (Content, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > 0xffff || height > 0xffff {
+		return nil, fmt.Errorf("failed to encode bitmap: too large image: %dx%d", width, height)
+	}
+
+	// Go's color.RGBA is premultiplied by alpha as the SWF bitmaps are.
+	pixels := make([]color.RGBA, 0, width*height)
+	isOpaque := true
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+
+			if c.A != 0xff {
+				isOpaque = false
+			}
+
+			pixels = append(pixels, c)
+		}
+	}
+
+	palette := []color.RGBA{}
+	indices := map[color.RGBA]int{}
+
+	for _, c := range pixels {
+		if _, ok := indices[c]; ok {
+			continue
+		}
+		if len(palette) == 256 {
+			palette = nil
+
+			break
+		}
+
+		indices[c] = len(palette)
+		palette = append(palette, c)
+	}
+
+	data := &bytes.Buffer{}
+
+	var format uint8
+	var colorTableSize *Uint8
+
+	if len(palette) > 0 {
+		format = BitmapFormatColorMapped8
+		colorTableSize = &Uint8{Value: uint8(len(palette) - 1), value: uint8(len(palette) - 1)}
+
+		for _, c := range palette {
+			if isOpaque {
+				data.Write([]byte{c.R, c.G, c.B})
+			} else {
+				data.Write([]byte{c.R, c.G, c.B, c.A})
+			}
+		}
+
+		stride := (width + 3) &^ 3
+		row := make([]byte, stride)
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				row[x] = uint8(indices[pixels[y*width+x]])
+			}
+
+			data.Write(row)
+		}
+	} else {
+		format = BitmapFormatRGB24
+
+		for _, c := range pixels {
+			if isOpaque {
+				// The first byte of PIX24 is reserved.
+				data.Write([]byte{0x00, c.R, c.G, c.B})
+			} else {
+				data.Write([]byte{c.A, c.R, c.G, c.B})
+			}
+		}
+	}
+
+	compressed := &bytes.Buffer{}
+	writer := zlib.NewWriter(compressed)
+
+	if _, err := writer.Write(data.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to encode bitmap: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode bitmap: %w", err)
+	}
+
+	if isOpaque {
+		result := &DefineBitsLossless{
+			CharacterID:          &Uint16{Value: characterID},
+			BitmapFormat:         &Uint8{Value: format, value: format},
+			BitmapWidth:          &Uint16{Value: uint16(width)},
+			BitmapHeight:         &Uint16{Value: uint16(height)},
+			BitmapColorTableSize: colorTableSize,
+			ZlibBitmapData:       compressed.Bytes(),
+		}
+
+		return result, nil
+	}
+
+	result := &DefineBitsLossless2{
+		CharacterID:          &Uint16{Value: characterID},
+		BitmapFormat:         &Uint8{Value: format, value: format},
+		BitmapWidth:          &Uint16{Value: uint16(width)},
+		BitmapHeight:         &Uint16{Value: uint16(height)},
+		BitmapColorTableSize: colorTableSize,
+		ZlibBitmapData:       compressed.Bytes(),
+	}
+
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func compressForTest(t *testing.T, data []byte) []byte {
+	buffer := &bytes.Buffer{}
+	writer := zlib.NewWriter(buffer)
+
+	_, err := writer.Write(data)
+
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func TestDefineBitsLosslessImage(t *testing.T) {
+	// 3x2 colormapped bitmap whose rows are padded to 4 bytes.
+	colorMapData := []byte{
+		0xff, 0x00, 0x00,
+		0x00, 0x00, 0xff,
+		0, 1, 0, 0,
+		1, 1, 0, 0,
+	}
+	lossless := &DefineBitsLossless{
+		CharacterID:          &Uint16{Value: 1},
+		BitmapFormat:         &Uint8{Value: BitmapFormatColorMapped8},
+		BitmapWidth:          &Uint16{Value: 3},
+		BitmapHeight:         &Uint16{Value: 2},
+		BitmapColorTableSize: &Uint8{Value: 1},
+		ZlibBitmapData:       compressForTest(t, colorMapData),
+	}
+
+	img, err := lossless.Image()
+
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{0xff, 0x00, 0x00, 0xff}, img.At(0, 0))
+	require.Equal(t, color.RGBA{0x00, 0x00, 0xff, 0xff}, img.At(1, 0))
+	require.Equal(t, color.RGBA{0x00, 0x00, 0xff, 0xff}, img.At(0, 1))
+
+	// 1x1 premultiplied ARGB bitmap with a component larger than alpha.
+	lossless2 := &DefineBitsLossless2{
+		CharacterID:    &Uint16{Value: 2},
+		BitmapFormat:   &Uint8{Value: BitmapFormatRGB24},
+		BitmapWidth:    &Uint16{Value: 1},
+		BitmapHeight:   &Uint16{Value: 1},
+		ZlibBitmapData: compressForTest(t, []byte{0x80, 0x40, 0x90, 0x00}),
+	}
+
+	img, err = lossless2.Image()
+
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{0x40, 0x80, 0x00, 0x80}, img.At(0, 0))
+}
+
+func TestNewLosslessBitmap(t *testing.T) {
+	opaque := image.NewNRGBA(image.Rect(0, 0, 5, 3))
+
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0xff
+	}
+
+	opaque.SetNRGBA(1, 2, color.NRGBA{0x10, 0x20, 0x30, 0xff})
+
+	content, err := NewLosslessBitmap(1, opaque)
+
+	require.NoError(t, err)
+
+	lossless, ok := content.(*DefineBitsLossless)
+
+	require.True(t, ok)
+	require.Equal(t, uint8(BitmapFormatColorMapped8), lossless.BitmapFormat.Value)
+
+	img, err := lossless.Image()
+
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{0x10, 0x20, 0x30, 0xff}, img.At(1, 2))
+	require.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, img.At(4, 2))
+
+	// 300 colors with a translucent pixel.
+	translucent := image.NewNRGBA(image.Rect(0, 0, 30, 10))
+
+	for i := 0; i < 300; i++ {
+		translucent.SetNRGBA(i%30, i/30, color.NRGBA{uint8(i), uint8(i >> 8), 0x00, 0xff})
+	}
+
+	translucent.SetNRGBA(0, 0, color.NRGBA{0xff, 0x00, 0x00, 0x80})
+
+	content, err = NewLosslessBitmap(2, translucent)
+
+	require.NoError(t, err)
+
+	lossless2, ok := content.(*DefineBitsLossless2)
+
+	require.True(t, ok)
+	require.Equal(t, uint8(BitmapFormatRGB24), lossless2.BitmapFormat.Value)
+
+	data, err := lossless2.Serialize()
+
+	require.NoError(t, err)
+
+	parsed, err := parseContent(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	img, err = parsed.(*DefineBitsLossless2).Image()
+
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{0x80, 0x00, 0x00, 0x80}, img.At(0, 0))
+	require.Equal(t, color.RGBA{0x2b, 0x01, 0x00, 0xff}, img.At(29, 9))
+}
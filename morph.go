@@ -0,0 +1,392 @@
+package swf
+
+import (
+	"fmt"
+	"math"
+)
+
+// At returns the shape displayed by the player at the ratio, where 0 is the
+// start shape and 65535 is the end shape. The result is a DefineShape3 shape
+// for DefineMorphShape and a DefineShape4 shape for DefineMorphShape2. It
+// fails when the start shape and the end shape have different numbers of
+// edges or gradient records, which the player cannot pair.
+func (m *MorphShape) At(ratio uint16) (*Shape, error) {
+	if m == nil {
+		return nil, fmt.Errorf("cannot interpolate because MorphShape is nil")
+	}
+
+	t := float64(ratio) / 65535
+	shapeVersion := 3
+
+	if m.morphVersion() >= 2 {
+		shapeVersion = 4
+	}
+
+	result := &Shape{
+		ID:          m.ID,
+		ShapeBounds: lerpRectangle(m.StartBounds, m.EndBounds, t),
+	}
+
+	if m.morphVersion() >= 2 {
+		result.EdgeBounds = lerpRectangle(m.StartEdgeBounds, m.EndEdgeBounds, t)
+		result.Flags = &Uint8{Value: m.Flags.Value & 0b11, value: m.Flags.Value & 0b11}
+	}
+
+	fillStyles := make([]*FillStyle, len(m.MorphFillStyles))
+
+	for i, fillStyle := range m.MorphFillStyles {
+		v, err := lerpFillStyle(fillStyle, t)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate MorphShape.MorphFillStyles[%d]: %w", i, err)
+		}
+
+		fillStyles[i] = v
+	}
+
+	lineStyles := make([]*LineStyle, len(m.MorphLineStyles))
+
+	for i, lineStyle := range m.MorphLineStyles {
+		v, err := lerpLineStyle(lineStyle, t, shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate MorphShape.MorphLineStyles[%d]: %w", i, err)
+		}
+
+		lineStyles[i] = v
+	}
+
+	var numBits uint8
+
+	if m.StartNumBits != nil {
+		numBits = m.StartNumBits.Value
+	}
+
+	result.ShapeStyles = &ShapeStyles{
+		NumFillStyles: countUint8(len(fillStyles)),
+		FillStyles:    fillStyles,
+		NumLineStyles: countUint8(len(lineStyles)),
+		LineStyles:    lineStyles,
+		NumBits:       &Uint8{Value: numBits, value: numBits},
+	}
+
+	if len(fillStyles) >= 0xff {
+		result.ShapeStyles.NumFillStyles2 = &Uint16{Value: uint16(len(fillStyles))}
+	}
+	if len(lineStyles) >= 0xff {
+		result.ShapeStyles.NumLineStyles2 = &Uint16{Value: uint16(len(lineStyles))}
+	}
+
+	records, err := lerpEdges(m.StartEdges, m.EndEdges, t)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate MorphShape edges: %w", err)
+	}
+
+	result.ShapeRecords = records
+
+	return result, nil
+}
+
+func countUint8(count int) *Uint8 {
+	if count >= 0xff {
+		return &Uint8{Value: 0xff, value: 0xff}
+	}
+
+	return &Uint8{Value: uint8(count), value: uint8(count)}
+}
+
+func lerpInt(start, end int64, t float64) int64 {
+	return int64(math.Round(float64(start) + float64(end-start)*t))
+}
+
+func lerpRectangle(start, end *Rectangle, t float64) *Rectangle {
+	if start == nil || end == nil {
+		return nil
+	}
+
+	startMinX, startMaxX, startMinY, startMaxY := start.Values()
+	endMinX, endMaxX, endMinY, endMaxY := end.Values()
+
+	return newRectangle(
+		lerpInt(startMinX, endMinX, t),
+		lerpInt(startMaxX, endMaxX, t),
+		lerpInt(startMinY, endMinY, t),
+		lerpInt(startMaxY, endMaxY, t),
+	)
+}
+
+func lerpColor(start, end *Color, t float64) *Color {
+	if start == nil || end == nil {
+		return nil
+	}
+
+	channel := func(s, e uint8) uint8 {
+		return uint8(lerpInt(int64(s), int64(e), t))
+	}
+
+	return newRGBA(
+		channel(start.Red, end.Red),
+		channel(start.Green, end.Green),
+		channel(start.Blue, end.Blue),
+		channel(start.Alpha, end.Alpha),
+	)
+}
+
+func lerpMatrix(start, end *Matrix, t float64) *Matrix {
+	if start == nil || end == nil {
+		return nil
+	}
+
+	startA, startB, startC, startD, startTX, startTY := start.Values()
+	endA, endB, endC, endD, endTX, endTY := end.Values()
+
+	return newMatrix(
+		lerpInt(startA, endA, t),
+		lerpInt(startB, endB, t),
+		lerpInt(startC, endC, t),
+		lerpInt(startD, endD, t),
+		lerpInt(startTX, endTX, t),
+		lerpInt(startTY, endTY, t),
+	)
+}
+
+func lerpUint16(start, end *Uint16, t float64) *Uint16 {
+	if start == nil || end == nil {
+		return nil
+	}
+
+	return &Uint16{Value: uint16(lerpInt(int64(start.Value), int64(end.Value), t))}
+}
+
+// lerpFixed8 interpolates signed 8.8 fixed-point numbers stored as Uint16.
+func lerpFixed8(start, end *Uint16, t float64) *Uint16 {
+	if start == nil || end == nil {
+		return nil
+	}
+
+	return &Uint16{Value: uint16(int16(lerpInt(int64(int16(start.Value)), int64(int16(end.Value)), t)))}
+}
+
+func lerpGradient(m *MorphGradient, t float64) (*Gradient, error) {
+	if m == nil || m.Start == nil || m.End == nil {
+		return nil, nil
+	}
+	if len(m.Start.Records) != len(m.End.Records) {
+		return nil, fmt.Errorf("start gradient has %d records but end gradient has %d records", len(m.Start.Records), len(m.End.Records))
+	}
+
+	records := make([]*GradientRecord, len(m.Start.Records))
+
+	for i := range records {
+		start := m.Start.Records[i]
+		end := m.End.Records[i]
+
+		if start == nil || end == nil || start.Ratio == nil || end.Ratio == nil {
+			return nil, fmt.Errorf("gradient record %d is broken", i)
+		}
+
+		ratio := uint8(lerpInt(int64(start.Ratio.Value), int64(end.Ratio.Value), t))
+
+		records[i] = &GradientRecord{
+			Ratio: &Uint8{Value: ratio, value: ratio},
+			Color: lerpColor(start.Color, end.Color, t),
+		}
+	}
+
+	flags := &GradientFlags{NumRecords: uint8(len(records))}
+
+	if m.Flags != nil {
+		flags.Spread = m.Flags.Spread
+		flags.Interporation = m.Flags.Interporation
+	}
+
+	return &Gradient{
+		Matrix:  lerpMatrix(m.Start.Matrix, m.End.Matrix, t),
+		Flags:   flags,
+		Records: records,
+	}, nil
+}
+
+func lerpFillStyle(m *MorphFillStyle, t float64) (*FillStyle, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	var err error
+
+	result := &FillStyle{
+		Type: &Uint8{Value: m.Type.Value, value: m.Type.Value},
+	}
+
+	switch m.Type.Value {
+	case 0x00:
+		result.Color = lerpColor(m.Start.Color, m.End.Color, t)
+	case 0x10, 0x12:
+		result.Gradient, err = lerpGradient(m.MorphGradient, t)
+	case 0x13:
+		result.Gradient, err = lerpGradient(m.MorphGradient, t)
+		result.FocalPoint = lerpFixed8(m.StartFocalPoint, m.EndFocalPoint, t)
+	case 0x40, 0x41, 0x42, 0x43:
+		result.ID = m.ID
+		result.Matrix = lerpMatrix(m.Start.Matrix, m.End.Matrix, t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func lerpLineStyle(m *MorphLineStyle, t float64, shapeVersion int) (*LineStyle, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	result := &LineStyle{
+		Width: lerpUint16(m.StartWidth, m.EndWidth, t),
+	}
+
+	if shapeVersion < 4 {
+		result.Color = lerpColor(m.StartColor, m.EndColor, t)
+
+		return result, nil
+	}
+
+	result.Flags = m.Flags
+	result.Miter = m.Miter
+
+	if m.Flags == nil || !m.Flags.Contains(LineStyleFlagHasFill) {
+		result.FillStyle = &FillStyle{Color: lerpColor(m.StartColor, m.EndColor, t)}
+
+		return result, nil
+	}
+
+	fillStyle, err := lerpFillStyle(m.FillStyle, t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.FillStyle = fillStyle
+
+	return result, nil
+}
+
+type morphPoint struct {
+	x, y int64
+}
+
+func (p morphPoint) lerp(q morphPoint, t float64) morphPoint {
+	return morphPoint{lerpInt(p.x, q.x, t), lerpInt(p.y, q.y, t)}
+}
+
+// lerpEdges pairs the start edges with the end edges the same way the player
+// does. The end edges have no style changes except for moves, and a straight
+// edge paired with a curved edge is treated as a curve whose control point is
+// at the middle of the line. It fails when the shapes have different numbers
+// of edges.
+func lerpEdges(startEdges, endEdges []*ShapeRecord, t float64) ([]*ShapeRecord, error) {
+	var result []*ShapeRecord
+	var startPen, endPen, pen morphPoint
+
+	i, j := 0, 0
+
+	for i < len(startEdges) {
+		start := startEdges[i]
+
+		var end *ShapeRecord
+
+		if j < len(endEdges) {
+			end = endEdges[j]
+		}
+		if end != nil && !end.IsEdge() && start.IsEdge() {
+			// A move in the end shape only.
+			if x, y, ok := end.MoveTo(); ok {
+				endPen = morphPoint{x, y}
+			}
+
+			j += 1
+
+			continue
+		}
+		if !start.IsEdge() {
+			record := &ShapeRecord{
+				IsEdgeRecordValue: start.IsEdgeRecordValue,
+				FlagsValue:        start.FlagsValue,
+				StyleChangeData:   &StyleChangeData{},
+			}
+
+			*record.StyleChangeData = *start.StyleChangeData
+
+			if end != nil && !end.IsEdge() {
+				if x, y, ok := end.MoveTo(); ok {
+					endPen = morphPoint{x, y}
+				}
+
+				j += 1
+			}
+			if x, y, ok := start.MoveTo(); ok {
+				startPen = morphPoint{x, y}
+				pen = startPen.lerp(endPen, t)
+
+				numBits := uint64(signedBits(pen.x, pen.y))
+				mask := uint64(1)<<numBits - 1
+				moveTo1 := uint64(pen.x) & mask
+				moveTo2 := uint64(pen.y) & mask
+
+				record.StyleChangeData.NumBitsValue = &numBits
+				record.StyleChangeData.MoveToValue1 = &moveTo1
+				record.StyleChangeData.MoveToValue2 = &moveTo2
+			}
+
+			result = append(result, record)
+			i += 1
+
+			continue
+		}
+		if end == nil {
+			return nil, fmt.Errorf("end shape has fewer edges than start shape")
+		}
+
+		startControl, startAnchor := edgePoints(start, startPen)
+		endControl, endAnchor := edgePoints(end, endPen)
+		anchor := startAnchor.lerp(endAnchor, t)
+
+		if start.IsStraightEdge() && end.IsStraightEdge() {
+			result = append(result, newStraightEdgeRecord(anchor.x-pen.x, anchor.y-pen.y))
+		} else {
+			control := startControl.lerp(endControl, t)
+			result = append(result, newCurvedEdgeRecord(control.x-pen.x, control.y-pen.y, anchor.x-control.x, anchor.y-control.y))
+		}
+
+		startPen, endPen, pen = startAnchor, endAnchor, anchor
+		i += 1
+		j += 1
+	}
+	for ; j < len(endEdges); j++ {
+		if endEdges[j] != nil && endEdges[j].IsEdge() {
+			return nil, fmt.Errorf("end shape has more edges than start shape")
+		}
+	}
+
+	return result, nil
+}
+
+// edgePoints returns the absolute control point and anchor point of the edge
+// which starts at the pen.
+func edgePoints(edge *ShapeRecord, pen morphPoint) (control, anchor morphPoint) {
+	controlX, controlY, anchorX, anchorY := edge.Deltas()
+
+	if edge.IsStraightEdge() {
+		anchor = morphPoint{pen.x + anchorX, pen.y + anchorY}
+		control = morphPoint{pen.x + anchorX/2, pen.y + anchorY/2}
+
+		return control, anchor
+	}
+
+	control = morphPoint{pen.x + controlX, pen.y + controlY}
+	anchor = morphPoint{control.x + anchorX, control.y + anchorY}
+
+	return control, anchor
+}
@@ -0,0 +1,182 @@
+package swf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newMoveToRecord(x, y int64, fillStyle1 *uint64) *ShapeRecord {
+	isEdgeRecord := uint64(0)
+	flags := uint64(0b1)
+	numBits := uint64(signedBits(x, y))
+	mask := uint64(1)<<numBits - 1
+	moveTo1, moveTo2 := uint64(x)&mask, uint64(y)&mask
+
+	if fillStyle1 != nil {
+		flags |= 0b100
+	}
+
+	return &ShapeRecord{
+		IsEdgeRecordValue: &isEdgeRecord,
+		FlagsValue:        &flags,
+		StyleChangeData: &StyleChangeData{
+			NumBitsValue:    &numBits,
+			MoveToValue1:    &moveTo1,
+			MoveToValue2:    &moveTo2,
+			FillStyle1Value: fillStyle1,
+		},
+	}
+}
+
+func TestMorphShapeAt(t *testing.T) {
+	fillStyle1 := uint64(1)
+	morphShape := &MorphShape{
+		ID:          &Uint16{Value: 1},
+		StartBounds: newRectangle(0, 100, 0, 100),
+		EndBounds:   newRectangle(-100, 300, -100, 300),
+		MorphFillStyles: []*MorphFillStyle{{
+			Type:  &Uint8{},
+			Start: &FillStyle{Color: newRGBA(0, 0, 0, 255)},
+			End:   &FillStyle{Color: newRGBA(255, 255, 255, 255)},
+		}},
+		MorphLineStyles: []*MorphLineStyle{{
+			StartWidth: &Uint16{Value: 20},
+			EndWidth:   &Uint16{Value: 40},
+			StartColor: newRGBA(255, 0, 0, 255),
+			EndColor:   newRGBA(0, 0, 255, 255),
+		}},
+		StartNumBits: &Uint8{Value: 0x11},
+		StartEdges: []*ShapeRecord{
+			newMoveToRecord(0, 0, &fillStyle1),
+			newStraightEdgeRecord(100, 0),
+			newStraightEdgeRecord(0, 100),
+			newStraightEdgeRecord(-100, -100),
+		},
+		EndNumBits: &Uint8{},
+		EndEdges: []*ShapeRecord{
+			newMoveToRecord(-100, -100, nil),
+			newStraightEdgeRecord(400, 0),
+			newCurvedEdgeRecord(0, 200, 0, 200),
+			newStraightEdgeRecord(-400, -400),
+		},
+	}
+
+	body, err := morphShape.Serialize()
+
+	require.NoError(t, err)
+
+	parsed, err := ReadDefineMorphShape(bytes.NewReader(body), 1)
+
+	require.NoError(t, err)
+	require.Len(t, parsed.StartEdges, 4)
+	require.Len(t, parsed.EndEdges, 4)
+
+	serialized, err := parsed.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, body, serialized)
+
+	start, err := parsed.At(0)
+
+	require.NoError(t, err)
+
+	require.Equal(t, uint16(20), start.ShapeStyles.LineStyles[0].Width.Value)
+	require.Equal(t, uint8(0), start.ShapeStyles.FillStyles[0].Color.Red)
+
+	_, _, x, y := start.ShapeRecords[1].Deltas()
+
+	require.Equal(t, []int64{100, 0}, []int64{x, y})
+
+	middle, err := parsed.At(32768)
+
+	require.NoError(t, err)
+
+	require.Equal(t, uint16(30), middle.ShapeStyles.LineStyles[0].Width.Value)
+	require.Equal(t, uint8(128), middle.ShapeStyles.FillStyles[0].Color.Red)
+
+	x, y, ok := middle.ShapeRecords[0].MoveTo()
+
+	require.True(t, ok)
+	require.Equal(t, []int64{-50, -50}, []int64{x, y})
+
+	_, _, x, y = middle.ShapeRecords[1].Deltas()
+
+	require.Equal(t, []int64{250, 0}, []int64{x, y})
+	require.False(t, middle.ShapeRecords[2].IsStraightEdge())
+
+	end, err := parsed.At(65535)
+
+	require.NoError(t, err)
+
+	controlX, controlY, anchorX, anchorY := end.ShapeRecords[2].Deltas()
+
+	require.Equal(t, []int64{0, 200, 0, 200}, []int64{controlX, controlY, anchorX, anchorY})
+
+	minX, maxX, minY, maxY := end.ShapeBounds.Values()
+
+	require.Equal(t, []int64{-100, 300, -100, 300}, []int64{minX, maxX, minY, maxY})
+}
+
+func TestMorphShapeAtMismatched(t *testing.T) {
+	newRecord := func(ratio uint8, gray uint8) *GradientRecord {
+		return &GradientRecord{Ratio: &Uint8{Value: ratio}, Color: newRGBA(gray, gray, gray, 255)}
+	}
+	morphShape := &MorphShape{
+		ID:          &Uint16{Value: 1},
+		StartBounds: newRectangle(0, 100, 0, 100),
+		EndBounds:   newRectangle(0, 100, 0, 100),
+		MorphFillStyles: []*MorphFillStyle{{
+			Type: &Uint8{Value: 0x10},
+			MorphGradient: &MorphGradient{
+				Start: &Gradient{Records: []*GradientRecord{newRecord(0, 0), newRecord(255, 0)}},
+				End:   &Gradient{Records: []*GradientRecord{newRecord(0, 255)}},
+			},
+		}},
+		StartEdges: []*ShapeRecord{
+			newMoveToRecord(0, 0, nil),
+			newStraightEdgeRecord(100, 0),
+			newStraightEdgeRecord(0, 100),
+		},
+		EndEdges: []*ShapeRecord{
+			newMoveToRecord(0, 0, nil),
+			newStraightEdgeRecord(100, 100),
+		},
+	}
+
+	_, err := morphShape.At(32768)
+
+	require.ErrorContains(t, err, "2 records but end gradient has 1 records")
+
+	morphShape.MorphFillStyles = nil
+
+	_, err = morphShape.At(32768)
+
+	require.ErrorContains(t, err, "end shape has fewer edges")
+
+	morphShape.StartEdges, morphShape.EndEdges = morphShape.EndEdges, morphShape.StartEdges
+
+	_, err = morphShape.At(32768)
+
+	require.ErrorContains(t, err, "end shape has more edges")
+}
+
+func TestShapeRecordIsAxisAlignedValue(t *testing.T) {
+	record := newStraightEdgeRecord(100, 100)
+
+	require.Equal(t, record.IsGeneralLineValue, record.IsAxisAlignedValue)
+
+	// The records built with the deprecated field alone are still encoded
+	// as the general lines.
+	record.IsGeneralLineValue = nil
+
+	data, err := serializeShapeRecords([]*ShapeRecord{record}, 1, 0, 0)
+
+	require.NoError(t, err)
+
+	parsed, err := ReadShapeRecord(bytes.NewReader(data), &ShapeContext{})
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), *parsed.IsGeneralLineValue)
+}
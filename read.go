@@ -3,6 +3,7 @@ package swf
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -407,6 +408,26 @@ func (r *Rectangle) Serialize() ([]byte, error) {
 	return data, nil
 }
 
+// Values returns the signed coordinates in twips.
+func (r *Rectangle) Values() (minX, maxX, minY, maxY int64) {
+	n := r.BitsPerField
+
+	return signExtend(uint64(r.MinX), n), signExtend(uint64(r.MaxX), n), signExtend(uint64(r.MinY), n), signExtend(uint64(r.MaxY), n)
+}
+
+func newRectangle(minX, maxX, minY, maxY int64) *Rectangle {
+	n := signedBits(minX, maxX, minY, maxY)
+	mask := uint64(1)<<n - 1
+
+	return &Rectangle{
+		BitsPerField: n,
+		MinX:         uint32(uint64(minX) & mask),
+		MaxX:         uint32(uint64(maxX) & mask),
+		MinY:         uint32(uint64(minY) & mask),
+		MaxY:         uint32(uint64(maxY) & mask),
+	}
+}
+
 func ReadRectangle(src io.Reader) (*Rectangle, error) {
 	data := &bytes.Buffer{}
 
@@ -528,6 +549,22 @@ func (c *Color) Serialize() ([]byte, error) {
 	}
 }
 
+func newRGBA(red, green, blue, alpha uint8) *Color {
+	return &Color{red, green, blue, alpha, bytes.NewBuffer([]byte{red, green, blue, alpha})}
+}
+
+// serializeColor encodes RGBA, or RGB when hasAlpha is false.
+func serializeColor(c *Color, hasAlpha bool) []byte {
+	if c == nil {
+		c = &Color{Alpha: 0xff}
+	}
+	if hasAlpha {
+		return []byte{c.Red, c.Green, c.Blue, c.Alpha}
+	}
+
+	return []byte{c.Red, c.Green, c.Blue}
+}
+
 func ReadRGB(src io.Reader) (*Color, error) {
 	w := &bytes.Buffer{}
 	r := io.TeeReader(src, w)
@@ -562,6 +599,9 @@ func ReadRGBA(src io.Reader) (*Color, error) {
 	if err := binary.Read(r, binary.LittleEndian, &blue); err != nil {
 		return nil, fmt.Errorf("failed to read RGBA color: %w", err)
 	}
+	if err := binary.Read(r, binary.LittleEndian, &alpha); err != nil {
+		return nil, fmt.Errorf("failed to read RGBA color: %w", err)
+	}
 
 	return &Color{red, green, blue, alpha, w}, nil
 }
@@ -583,29 +623,111 @@ type Matrix struct {
 	TY               uint32
 }
 
-func ReadMatrix(src io.Reader) (*Matrix, error) {
+// Values returns the signed values of the matrix. The scale and the rotate
+// values are 16.16 fixed-point numbers and the translate values are twips.
+func (m *Matrix) Values() (a, b, c, d, tx, ty int64) {
+	a, d = 1<<16, 1<<16
+
+	if m.HasScale {
+		a = signExtend(uint64(m.A), int(m.NumScaleBits))
+		d = signExtend(uint64(m.D), int(m.NumScaleBits))
+	}
+	if m.HasRotate {
+		b = signExtend(uint64(m.B), int(m.NumRotateBits))
+		c = signExtend(uint64(m.C), int(m.NumRotateBits))
+	}
+
+	tx = signExtend(uint64(m.TX), int(m.NumTranslateBits))
+	ty = signExtend(uint64(m.TY), int(m.NumTranslateBits))
+
+	return a, b, c, d, tx, ty
+}
+
+func newMatrix(a, b, c, d, tx, ty int64) *Matrix {
 	matrix := &Matrix{}
+
+	if a != 1<<16 || d != 1<<16 {
+		n := signedBits(a, d)
+		mask := uint64(1)<<n - 1
+
+		matrix.HasScale = true
+		matrix.NumScaleBits = uint8(n)
+		matrix.A = uint32(uint64(a) & mask)
+		matrix.D = uint32(uint64(d) & mask)
+	}
+	if b != 0 || c != 0 {
+		n := signedBits(b, c)
+		mask := uint64(1)<<n - 1
+
+		matrix.HasRotate = true
+		matrix.NumRotateBits = uint8(n)
+		matrix.B = uint32(uint64(b) & mask)
+		matrix.C = uint32(uint64(c) & mask)
+	}
+	if tx != 0 || ty != 0 {
+		n := signedBits(tx, ty)
+		mask := uint64(1)<<n - 1
+
+		matrix.NumTranslateBits = uint8(n)
+		matrix.TX = uint32(uint64(tx) & mask)
+		matrix.TY = uint32(uint64(ty) & mask)
+	}
+
+	return matrix
+}
+
+func (m *Matrix) Serialize() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+
 	buffer := &bits.Buffer{}
 
-	hasScale, err := buffer.Scan(src, 1)
+	appendFlag(buffer, m.HasScale)
+
+	if m.HasScale {
+		buffer.Append(uint64(m.NumScaleBits), 5)
+		buffer.Append(uint64(m.A), int(m.NumScaleBits))
+		buffer.Append(uint64(m.D), int(m.NumScaleBits))
+	}
+	appendFlag(buffer, m.HasRotate)
+
+	if m.HasRotate {
+		buffer.Append(uint64(m.NumRotateBits), 5)
+		buffer.Append(uint64(m.B), int(m.NumRotateBits))
+		buffer.Append(uint64(m.C), int(m.NumRotateBits))
+	}
+
+	buffer.Append(uint64(m.NumTranslateBits), 5)
+	buffer.Append(uint64(m.TX), int(m.NumTranslateBits))
+	buffer.Append(uint64(m.TY), int(m.NumTranslateBits))
+
+	return buffer.Bytes(), nil
+}
+
+func ReadMatrix(src io.Reader) (*Matrix, error) {
+	matrix := &Matrix{}
+	reader := newBitReader(src)
+
+	hasScale, err := reader.readFlag()
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Matrix.HasScale: %w", err)
 	}
-	if hasScale == 1 {
-		numScaleBits, err := buffer.Scan(src, 5)
+	if hasScale {
+		numScaleBits, err := reader.readBits(5)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Matrix.NumScaleBits: %w", err)
 		}
 
-		a, err := buffer.Scan(src, int(numScaleBits))
+		a, err := reader.readBits(int(numScaleBits))
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Matrix.A: %w", err)
 		}
 
-		d, err := buffer.Scan(src, int(numScaleBits))
+		d, err := reader.readBits(int(numScaleBits))
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Matrix.D: %w", err)
@@ -617,25 +739,25 @@ func ReadMatrix(src io.Reader) (*Matrix, error) {
 		matrix.D = uint32(d)
 	}
 
-	hasRotate, err := buffer.Scan(src, 1)
+	hasRotate, err := reader.readFlag()
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Matrix.HasRotate: %w", err)
 	}
-	if hasRotate == 1 {
-		numRotateBits, err := buffer.Scan(src, 5)
+	if hasRotate {
+		numRotateBits, err := reader.readBits(5)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Matrix.NumRotateBits: %w", err)
 		}
 
-		b, err := buffer.Scan(src, int(numRotateBits))
+		b, err := reader.readBits(int(numRotateBits))
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Matrix.B: %w", err)
 		}
 
-		c, err := buffer.Scan(src, int(numRotateBits))
+		c, err := reader.readBits(int(numRotateBits))
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Matrix.C: %w", err)
@@ -647,19 +769,19 @@ func ReadMatrix(src io.Reader) (*Matrix, error) {
 		matrix.C = uint32(c)
 	}
 
-	numTranslateBits, err := buffer.Scan(src, 5)
+	numTranslateBits, err := reader.readBits(5)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Matrix.NumTranslateBits: %w", err)
 	}
 
-	tx, err := buffer.Scan(src, int(numTranslateBits))
+	tx, err := reader.readBits(int(numTranslateBits))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Matrix.TX: %w", err)
 	}
 
-	ty, err := buffer.Scan(src, int(numTranslateBits))
+	ty, err := reader.readBits(int(numTranslateBits))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Matrix.TY: %w", err)
@@ -677,6 +799,17 @@ type GradientRecord struct {
 	Color *Color
 }
 
+func (g *GradientRecord) Serialize(shapeVersion int) ([]byte, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	data := []byte{g.Ratio.Value}
+	data = append(data, serializeColor(g.Color, shapeVersion >= 3)...)
+
+	return data, nil
+}
+
 func ReadGradientRecord(src io.Reader, shapeVersion int) (*GradientRecord, error) {
 	ratio, err := ReadUint8(src)
 
@@ -709,6 +842,14 @@ type GradientFlags struct {
 	Interporation uint8
 }
 
+func (g *GradientFlags) Serialize() ([]byte, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	return []byte{g.Spread<<6 | g.Interporation<<4 | g.NumRecords&0b1111}, nil
+}
+
 func ReadGradientFlags(src io.Reader) (*GradientFlags, error) {
 	flags, err := ReadUint8(src)
 
@@ -735,6 +876,48 @@ type Gradient struct {
 	Records []*GradientRecord
 }
 
+func (g *Gradient) Serialize(shapeVersion int) ([]byte, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	matrixData, err := g.Matrix.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	flags := &GradientFlags{NumRecords: uint8(len(g.Records))}
+
+	if g.Flags != nil {
+		flags.Spread = g.Flags.Spread
+		flags.Interporation = g.Flags.Interporation
+	}
+
+	flagsData, err := flags.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	data = append(data, matrixData...)
+	data = append(data, flagsData...)
+
+	for i, record := range g.Records {
+		recordData, err := record.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize Gradient.Records[%d]: %w", i, err)
+		}
+
+		data = append(data, recordData...)
+	}
+
+	return data, nil
+}
+
 func ReadGradient(src io.Reader, shapeVersion int) (*Gradient, error) {
 	matrix, err := ReadMatrix(src)
 
@@ -775,6 +958,65 @@ type MorphGradient struct {
 	End   *Gradient
 }
 
+func (m *MorphGradient) Serialize(shapeVersion int) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	if len(m.Start.Records) != len(m.End.Records) {
+		return nil, fmt.Errorf("failed to serialize MorphGradient: %d start records but %d end records", len(m.Start.Records), len(m.End.Records))
+	}
+
+	startMatrixData, err := m.Start.Matrix.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	endMatrixData, err := m.End.Matrix.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	flags := &GradientFlags{NumRecords: uint8(len(m.Start.Records))}
+
+	if m.Flags != nil {
+		flags.Spread = m.Flags.Spread
+		flags.Interporation = m.Flags.Interporation
+	}
+
+	flagsData, err := flags.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	data = append(data, startMatrixData...)
+	data = append(data, endMatrixData...)
+	data = append(data, flagsData...)
+
+	for i := range m.Start.Records {
+		startData, err := m.Start.Records[i].Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, err
+		}
+
+		endData, err := m.End.Records[i].Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, startData...)
+		data = append(data, endData...)
+	}
+
+	return data, nil
+}
+
 func ReadMorphGradient(src io.Reader, shapeVersion int) (*MorphGradient, error) {
 	startMatrix, err := ReadMatrix(src)
 
@@ -818,6 +1060,11 @@ func ReadMorphGradient(src io.Reader, shapeVersion int) (*MorphGradient, error)
 		endRecords[i] = endRecord
 	}
 
+	start.Flags = flags
+	start.Records = startRecords
+	end.Flags = flags
+	end.Records = endRecords
+
 	result := &MorphGradient{
 		Flags: flags,
 		Start: start,
@@ -836,6 +1083,59 @@ type FillStyle struct {
 	Matrix     *Matrix
 }
 
+func (f *FillStyle) Serialize(shapeVersion int) ([]byte, error) {
+	if f == nil {
+		return nil, nil
+	}
+	if f.Type == nil {
+		return nil, fmt.Errorf("failed to serialize FillStyle: Type is nil")
+	}
+
+	data := []byte{f.Type.Value}
+
+	switch f.Type.Value {
+	case 0x00:
+		data = append(data, serializeColor(f.Color, shapeVersion >= 3)...)
+	case 0x10, 0x12, 0x13:
+		gradientData, err := f.Gradient.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize FillStyle.Gradient: %w", err)
+		}
+
+		data = append(data, gradientData...)
+
+		if f.Type.Value == 0x13 {
+			focalPointData, err := f.FocalPoint.Serialize()
+
+			if err != nil {
+				return nil, err
+			}
+
+			data = append(data, focalPointData...)
+		}
+	case 0x40, 0x41, 0x42, 0x43:
+		idData, err := f.ID.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		matrixData, err := f.Matrix.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, idData...)
+		data = append(data, matrixData...)
+	default:
+		return nil, fmt.Errorf("failed to serialize FillStyle: invalid type: %d", f.Type.Value)
+	}
+
+	return data, nil
+}
+
 func ReadFillStyle(src io.Reader, shapeVersion int) (*FillStyle, error) {
 	fillStyleType, err := ReadUint8(src)
 
@@ -916,6 +1216,74 @@ type MorphFillStyle struct {
 	ID              *Uint16
 }
 
+func (m *MorphFillStyle) Serialize(shapeVersion int) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	if m.Type == nil {
+		return nil, fmt.Errorf("failed to serialize MorphFillStyle: Type is nil")
+	}
+
+	data := []byte{m.Type.Value}
+
+	switch m.Type.Value {
+	case 0x00:
+		data = append(data, serializeColor(m.Start.Color, shapeVersion >= 3)...)
+		data = append(data, serializeColor(m.End.Color, shapeVersion >= 3)...)
+	case 0x10, 0x12, 0x13:
+		morphGradientData, err := m.MorphGradient.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize MorphFillStyle.MorphGradient: %w", err)
+		}
+
+		data = append(data, morphGradientData...)
+
+		if m.Type.Value == 0x13 {
+			startFocalPointData, err := m.StartFocalPoint.Serialize()
+
+			if err != nil {
+				return nil, err
+			}
+
+			endFocalPointData, err := m.EndFocalPoint.Serialize()
+
+			if err != nil {
+				return nil, err
+			}
+
+			data = append(data, startFocalPointData...)
+			data = append(data, endFocalPointData...)
+		}
+	case 0x40, 0x41, 0x42, 0x43:
+		idData, err := m.ID.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		startMatrixData, err := m.Start.Matrix.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		endMatrixData, err := m.End.Matrix.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, idData...)
+		data = append(data, startMatrixData...)
+		data = append(data, endMatrixData...)
+	default:
+		return nil, fmt.Errorf("failed to serialize MorphFillStyle: invalid type: %d", m.Type.Value)
+	}
+
+	return data, nil
+}
+
 func ReadMorphFillStyle(src io.Reader, shapeVersion int) (*MorphFillStyle, error) {
 	fillStyleType, err := ReadUint8(src)
 
@@ -1035,6 +1403,63 @@ type LineStyle struct {
 	Miter     *Uint16
 }
 
+func (l *LineStyle) Serialize(shapeVersion int) ([]byte, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	data, err := l.Width.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+	if shapeVersion < 4 {
+		data = append(data, serializeColor(l.Color, shapeVersion >= 3)...)
+
+		return data, nil
+	}
+	if l.Flags == nil {
+		return nil, fmt.Errorf("failed to serialize LineStyle: Flags is nil")
+	}
+
+	flagsData, err := l.Flags.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(data, flagsData...)
+
+	if l.Flags.Value&LineStyleFlagJoinStyle == JoinStyleMiter {
+		miterData, err := l.Miter.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, miterData...)
+	}
+	if l.Flags.Contains(LineStyleFlagHasFill) {
+		fillStyleData, err := l.FillStyle.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize LineStyle.FillStyle: %w", err)
+		}
+
+		data = append(data, fillStyleData...)
+	} else {
+		color := l.Color
+
+		if l.FillStyle != nil {
+			color = l.FillStyle.Color
+		}
+
+		data = append(data, serializeColor(color, true)...)
+	}
+
+	return data, nil
+}
+
 func ReadLineStyle(src io.Reader, shapeVersion int) (*LineStyle, error) {
 	width, err := ReadUint16(src)
 
@@ -1118,16 +1543,77 @@ type ShapeStyles struct {
 	NumBits        *Uint8
 }
 
-func ReadShapeStyles(src io.Reader, shapeVersion int) (*ShapeStyles, error) {
-	numFillStyles, err := ReadUint8(src)
+func serializeStyleCount(count, shapeVersion int) ([]byte, error) {
+	switch {
+	case count < 0xff:
+		return []byte{uint8(count)}, nil
+	case shapeVersion >= 2 && count <= 0xffff:
+		return []byte{0xff, uint8(count), uint8(count >> 8)}, nil
+	default:
+		return nil, fmt.Errorf("too many styles: %d", count)
+	}
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to read ShapeStyles.NumFillStyles: %w", err)
+func (s *ShapeStyles) Serialize(shapeVersion int) ([]byte, error) {
+	if s == nil {
+		return nil, nil
 	}
 
-	result := &ShapeStyles{NumFillStyles: numFillStyles}
+	data, err := serializeStyleCount(len(s.FillStyles), shapeVersion)
 
-	var numFillStylesInt int
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize ShapeStyles.FillStyles: %w", err)
+	}
+
+	for i, fillStyle := range s.FillStyles {
+		fillStyleData, err := fillStyle.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize ShapeStyles.FillStyles[%d]: %w", i, err)
+		}
+
+		data = append(data, fillStyleData...)
+	}
+
+	countData, err := serializeStyleCount(len(s.LineStyles), shapeVersion)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize ShapeStyles.LineStyles: %w", err)
+	}
+
+	data = append(data, countData...)
+
+	for i, lineStyle := range s.LineStyles {
+		lineStyleData, err := lineStyle.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize ShapeStyles.LineStyles[%d]: %w", i, err)
+		}
+
+		data = append(data, lineStyleData...)
+	}
+
+	numBits := uint8(unsignedBits(uint64(len(s.FillStyles))))<<4 | uint8(unsignedBits(uint64(len(s.LineStyles))))
+
+	if s.NumBits != nil {
+		numBits = s.NumBits.Value
+	}
+
+	data = append(data, numBits)
+
+	return data, nil
+}
+
+func ReadShapeStyles(src io.Reader, shapeVersion int) (*ShapeStyles, error) {
+	numFillStyles, err := ReadUint8(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ShapeStyles.NumFillStyles: %w", err)
+	}
+
+	result := &ShapeStyles{NumFillStyles: numFillStyles}
+
+	var numFillStylesInt int
 
 	if numFillStyles.Value == 0xff && shapeVersion >= 2 {
 		numFillStyles2, err := ReadUint16(src)
@@ -1187,7 +1673,7 @@ func ReadShapeStyles(src io.Reader, shapeVersion int) (*ShapeStyles, error) {
 		lineStyle, err := ReadLineStyle(src, shapeVersion)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to read ShapeStyles.LineStyles[%d]: %w", err)
+			return nil, fmt.Errorf("failed to read ShapeStyles.LineStyles[%d]: %w", i, err)
 		}
 
 		lineStyles[i] = lineStyle
@@ -1211,6 +1697,7 @@ type ShapeContext struct {
 	ShapeVersion int
 	NumFillBits  uint8
 	NumLineBits  uint8
+	reader       *bitReader
 }
 
 type StyleChangeData struct {
@@ -1223,11 +1710,13 @@ type StyleChangeData struct {
 	ShapeStyles     *ShapeStyles
 }
 
+// ShapeRecord represents SHAPERECORD. The values are stored as they are
+// encoded, e.g. DeltaXValue holds the two's complement of NumBitsValue bits.
 type ShapeRecord struct {
 	IsEdgeRecordValue   *uint64
 	IsStraightEdgeValue *uint64
 	NumBitsValue        *uint64
-	IsAxisAlignedValue  *uint64
+	IsGeneralLineValue  *uint64
 	IsVerticalValue     *uint64
 	DeltaXValue         *uint64
 	DeltaYValue         *uint64
@@ -1237,12 +1726,132 @@ type ShapeRecord struct {
 	AnchorDeltaYValue   *uint64
 	FlagsValue          *uint64
 	StyleChangeData     *StyleChangeData
+
+	// Deprecated: use IsGeneralLineValue. IsAxisAlignedValue holds the same
+	// value and is kept for compatibility.
+	IsAxisAlignedValue *uint64
+}
+
+func (s *ShapeRecord) IsEdge() bool {
+	return s.IsEdgeRecordValue != nil && *s.IsEdgeRecordValue == 1
+}
+
+func (s *ShapeRecord) IsStraightEdge() bool {
+	return s.IsEdge() && s.IsStraightEdgeValue != nil && *s.IsStraightEdgeValue == 1
+}
+
+// Deltas returns the signed deltas of the edge. A straight edge is reported
+// as an anchor delta.
+func (s *ShapeRecord) Deltas() (controlX, controlY, anchorX, anchorY int64) {
+	if !s.IsEdge() || s.NumBitsValue == nil {
+		return 0, 0, 0, 0
+	}
+
+	n := int(*s.NumBitsValue)
+	value := func(v *uint64) int64 {
+		if v == nil {
+			return 0
+		}
+
+		return signExtend(*v, n)
+	}
+
+	if s.IsStraightEdge() {
+		return 0, 0, value(s.DeltaXValue), value(s.DeltaYValue)
+	}
+
+	return value(s.ControlDeltaXValue), value(s.ControlDeltaYValue), value(s.AnchorDeltaXValue), value(s.AnchorDeltaYValue)
+}
+
+// MoveTo returns the absolute position of the style change record and
+// whether the record has it.
+func (s *ShapeRecord) MoveTo() (x, y int64, ok bool) {
+	if s.IsEdge() || s.StyleChangeData == nil || s.StyleChangeData.NumBitsValue == nil {
+		return 0, 0, false
+	}
+
+	n := int(*s.StyleChangeData.NumBitsValue)
+
+	return signExtend(*s.StyleChangeData.MoveToValue1, n), signExtend(*s.StyleChangeData.MoveToValue2, n), true
+}
+
+func newStraightEdgeRecord(deltaX, deltaY int64) *ShapeRecord {
+	numBits := uint64(signedBits(deltaX, deltaY))
+
+	if numBits < 2 {
+		numBits = 2
+	}
+
+	isEdgeRecord := uint64(1)
+	isStraightEdge := uint64(1)
+	result := &ShapeRecord{
+		IsEdgeRecordValue:   &isEdgeRecord,
+		IsStraightEdgeValue: &isStraightEdge,
+		NumBitsValue:        &numBits,
+	}
+
+	mask := uint64(1)<<numBits - 1
+	dx := uint64(deltaX) & mask
+	dy := uint64(deltaY) & mask
+
+	switch {
+	case deltaX != 0 && deltaY != 0:
+		isGeneralLine := uint64(1)
+		result.IsGeneralLineValue = &isGeneralLine
+		result.IsAxisAlignedValue = &isGeneralLine
+		result.DeltaXValue = &dx
+		result.DeltaYValue = &dy
+	case deltaX != 0:
+		isGeneralLine, isVertical := uint64(0), uint64(0)
+		result.IsGeneralLineValue = &isGeneralLine
+		result.IsAxisAlignedValue = &isGeneralLine
+		result.IsVerticalValue = &isVertical
+		result.DeltaXValue = &dx
+	default:
+		isGeneralLine, isVertical := uint64(0), uint64(1)
+		result.IsGeneralLineValue = &isGeneralLine
+		result.IsAxisAlignedValue = &isGeneralLine
+		result.IsVerticalValue = &isVertical
+		result.DeltaYValue = &dy
+	}
+
+	return result
+}
+
+func newCurvedEdgeRecord(controlX, controlY, anchorX, anchorY int64) *ShapeRecord {
+	numBits := uint64(signedBits(controlX, controlY, anchorX, anchorY))
+
+	if numBits < 2 {
+		numBits = 2
+	}
+
+	mask := uint64(1)<<numBits - 1
+	isEdgeRecord := uint64(1)
+	isStraightEdge := uint64(0)
+	cx, cy := uint64(controlX)&mask, uint64(controlY)&mask
+	ax, ay := uint64(anchorX)&mask, uint64(anchorY)&mask
+
+	return &ShapeRecord{
+		IsEdgeRecordValue:   &isEdgeRecord,
+		IsStraightEdgeValue: &isStraightEdge,
+		NumBitsValue:        &numBits,
+		ControlDeltaXValue:  &cx,
+		ControlDeltaYValue:  &cy,
+		AnchorDeltaXValue:   &ax,
+		AnchorDeltaYValue:   &ay,
+	}
 }
 
 func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, error) {
-	buffer := &bits.Buffer{}
+	// Shape records are not byte aligned, so the bits left by the previous
+	// record must be kept.
+	if shapeContext.reader == nil || shapeContext.reader.src != src {
+		shapeContext.reader = newBitReader(src)
+	}
+
+	reader := shapeContext.reader
 
-	isEdgeRecordValue, err := buffer.Scan(src, 1)
+	isEdgeRecordValue, err := reader.readBits(1)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to read ShapeRecord.IsEdgeRecordValue: %w", err)
@@ -1252,7 +1861,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 	result := &ShapeRecord{IsEdgeRecordValue: &isEdgeRecordValue}
 
 	if isEdgeRecord {
-		isStraightEdgeValue, err := buffer.Scan(src, 1)
+		isStraightEdgeValue, err := reader.readBits(1)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read ShapeRecord.IsStraightEdgeValue: %w", err)
@@ -1261,7 +1870,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 		isStraightEdge := isStraightEdgeValue == 1
 		result.IsStraightEdgeValue = &isStraightEdgeValue
 
-		numBitsValue, err := buffer.Scan(src, 4)
+		numBitsValue, err := reader.readBits(4)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read ShapeRecord.NumBitsValue: %w", err)
@@ -1272,26 +1881,29 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 		if isStraightEdge {
 			// StraightEdge
-			isAxisAlignedValue, err := buffer.Scan(src, 1)
+			isGeneralLineValue, err := reader.readBits(1)
 
 			if err != nil {
-				return nil, fmt.Errorf("failed to read ShapeRecord.IsAxisAlignedValue: %w", err)
+				return nil, fmt.Errorf("failed to read ShapeRecord.IsGeneralLineValue: %w", err)
 			}
 
-			isAxisAligned := isAxisAlignedValue == 1
-			result.IsAxisAlignedValue = &isAxisAlignedValue
+			isGeneralLine := isGeneralLineValue == 1
+			isVertical := false
+			result.IsGeneralLineValue = &isGeneralLineValue
+			result.IsAxisAlignedValue = &isGeneralLineValue
 
-			isVerticalValue, err := buffer.Scan(src, 1)
+			if !isGeneralLine {
+				isVerticalValue, err := reader.readBits(1)
 
-			if err != nil {
-				return nil, fmt.Errorf("failed to read ShapeRecord.IsVerticalValue: %w", err)
-			}
-
-			isVertical := isAxisAligned && isVerticalValue == 1
-			result.IsVerticalValue = &isVerticalValue
+				if err != nil {
+					return nil, fmt.Errorf("failed to read ShapeRecord.IsVerticalValue: %w", err)
+				}
 
-			if !isAxisAligned || !isVertical {
-				deltaXValue, err := buffer.Scan(src, int(numBitsValue))
+				isVertical = isVerticalValue == 1
+				result.IsVerticalValue = &isVerticalValue
+			}
+			if isGeneralLine || !isVertical {
+				deltaXValue, err := reader.readBits(int(numBitsValue))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read ShapeRecord.DeltaXValue: %w", err)
@@ -1299,8 +1911,8 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 				result.DeltaXValue = &deltaXValue
 			}
-			if !isAxisAligned || isVertical {
-				deltaYValue, err := buffer.Scan(src, int(numBitsValue))
+			if isGeneralLine || isVertical {
+				deltaYValue, err := reader.readBits(int(numBitsValue))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read ShapeRecord.DeltaYValue: %w", err)
@@ -1310,7 +1922,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 			}
 		} else {
 			// CurvedEdge
-			controlDeltaXValue, err := buffer.Scan(src, int(numBitsValue))
+			controlDeltaXValue, err := reader.readBits(int(numBitsValue))
 
 			if err != nil {
 				return nil, fmt.Errorf("failed to read ShapeRecord.ControlDeltaXValue: %w", err)
@@ -1318,7 +1930,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 			result.ControlDeltaXValue = &controlDeltaXValue
 
-			controlDeltaYValue, err := buffer.Scan(src, int(numBitsValue))
+			controlDeltaYValue, err := reader.readBits(int(numBitsValue))
 
 			if err != nil {
 				return nil, fmt.Errorf("failed to read ShapeRecord.ControlDeltaYValue: %w", err)
@@ -1326,7 +1938,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 			result.ControlDeltaYValue = &controlDeltaYValue
 
-			anchorDeltaXValue, err := buffer.Scan(src, int(numBitsValue))
+			anchorDeltaXValue, err := reader.readBits(int(numBitsValue))
 
 			if err != nil {
 				return nil, fmt.Errorf("failed to read ShapeRecord.AnchorDeltaXValue: %w", err)
@@ -1334,7 +1946,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 			result.AnchorDeltaXValue = &anchorDeltaXValue
 
-			anchorDeltaYValue, err := buffer.Scan(src, int(numBitsValue))
+			anchorDeltaYValue, err := reader.readBits(int(numBitsValue))
 
 			if err != nil {
 				return nil, fmt.Errorf("failed to read ShapeRecord.AnchorDeltaYValue: %w", err)
@@ -1343,7 +1955,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 			result.AnchorDeltaYValue = &anchorDeltaYValue
 		}
 	} else {
-		flagsValue, err := buffer.Scan(src, 5)
+		flagsValue, err := reader.readBits(5)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read ShapeRecord.FlagsValue: %w", err)
@@ -1357,7 +1969,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 			if (flagsValue & 0b1) != 0 {
 				// move
-				numBitsValue, err := buffer.Scan(src, 5)
+				numBitsValue, err := reader.readBits(5)
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read StyleChangeData.NumBitsValue: %w", err)
@@ -1365,7 +1977,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 				newStyle.NumBitsValue = &numBitsValue
 
-				moveToValue1, err := buffer.Scan(src, int(numBitsValue))
+				moveToValue1, err := reader.readBits(int(numBitsValue))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read StyleChangeData.MoveToValue1: %w", err)
@@ -1373,7 +1985,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 				newStyle.MoveToValue1 = &moveToValue1
 
-				moveToValue2, err := buffer.Scan(src, int(numBitsValue))
+				moveToValue2, err := reader.readBits(int(numBitsValue))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read StyleChangeData.MoveToValue2: %w", err)
@@ -1382,7 +1994,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 				newStyle.MoveToValue2 = &moveToValue2
 			}
 			if (flagsValue & 0b10) != 0 {
-				fillStyle0Value, err := buffer.Scan(src, int(shapeContext.NumFillBits))
+				fillStyle0Value, err := reader.readBits(int(shapeContext.NumFillBits))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read StyleChangeData.FillStyle0Value: %w", err)
@@ -1391,7 +2003,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 				newStyle.FillStyle0Value = &fillStyle0Value
 			}
 			if (flagsValue & 0b100) != 0 {
-				fillStyle1Value, err := buffer.Scan(src, int(shapeContext.NumFillBits))
+				fillStyle1Value, err := reader.readBits(int(shapeContext.NumFillBits))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read StyleChangeData.FillStyle1Value: %w", err)
@@ -1400,7 +2012,7 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 				newStyle.FillStyle1Value = &fillStyle1Value
 			}
 			if (flagsValue & 0b1000) != 0 {
-				lineStyleValue, err := buffer.Scan(src, int(shapeContext.NumLineBits))
+				lineStyleValue, err := reader.readBits(int(shapeContext.NumLineBits))
 
 				if err != nil {
 					return nil, fmt.Errorf("failed to read StyleChangeData.LineStyleValue: %w", err)
@@ -1409,6 +2021,9 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 				newStyle.LineStyleValue = &lineStyleValue
 			}
 			if (flagsValue & 0b10000) != 0 {
+				// The new styles are byte aligned.
+				reader.align()
+
 				newShapeStyles, err := ReadShapeStyles(src, shapeContext.ShapeVersion)
 
 				if err != nil {
@@ -1422,6 +2037,9 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 
 			result.StyleChangeData = newStyle
 		} else {
+			// The next structure is byte aligned.
+			reader.align()
+
 			return nil, nil
 		}
 	}
@@ -1429,6 +2047,138 @@ func ReadShapeRecord(src io.Reader, shapeContext *ShapeContext) (*ShapeRecord, e
 	return result, nil
 }
 
+// serializeShapeRecords encodes the shape records followed by the
+// EndShapeRecord.
+func serializeShapeRecords(records []*ShapeRecord, shapeVersion int, numFillBits, numLineBits uint8) ([]byte, error) {
+	var data []byte
+
+	buffer := &bits.Buffer{}
+	value := func(v *uint64) uint64 {
+		if v == nil {
+			return 0
+		}
+
+		return *v
+	}
+
+	for i, record := range records {
+		if record.IsEdge() {
+			numBits := int(value(record.NumBitsValue))
+
+			if numBits < 2 || numBits > 17 {
+				return nil, fmt.Errorf("failed to serialize ShapeRecords[%d]: invalid NumBits: %d", i, numBits)
+			}
+
+			buffer.Append(1, 1)
+
+			if !record.IsStraightEdge() {
+				buffer.Append(0, 1)
+				buffer.Append(uint64(numBits-2), 4)
+				buffer.Append(value(record.ControlDeltaXValue), numBits)
+				buffer.Append(value(record.ControlDeltaYValue), numBits)
+				buffer.Append(value(record.AnchorDeltaXValue), numBits)
+				buffer.Append(value(record.AnchorDeltaYValue), numBits)
+
+				continue
+			}
+
+			buffer.Append(1, 1)
+			buffer.Append(uint64(numBits-2), 4)
+
+			isGeneralLine := record.IsGeneralLineValue
+
+			if isGeneralLine == nil {
+				isGeneralLine = record.IsAxisAlignedValue
+			}
+			if value(isGeneralLine) == 1 {
+				buffer.Append(1, 1)
+				buffer.Append(value(record.DeltaXValue), numBits)
+				buffer.Append(value(record.DeltaYValue), numBits)
+
+				continue
+			}
+
+			buffer.Append(0, 1)
+
+			if value(record.IsVerticalValue) == 1 {
+				buffer.Append(1, 1)
+				buffer.Append(value(record.DeltaYValue), numBits)
+			} else {
+				buffer.Append(0, 1)
+				buffer.Append(value(record.DeltaXValue), numBits)
+			}
+
+			continue
+		}
+
+		style := record.StyleChangeData
+
+		if style == nil {
+			return nil, fmt.Errorf("failed to serialize ShapeRecords[%d]: StyleChangeData is nil", i)
+		}
+
+		var flags uint64
+
+		if style.NumBitsValue != nil {
+			flags |= 0b1
+		}
+		if style.FillStyle0Value != nil {
+			flags |= 0b10
+		}
+		if style.FillStyle1Value != nil {
+			flags |= 0b100
+		}
+		if style.LineStyleValue != nil {
+			flags |= 0b1000
+		}
+		if style.ShapeStyles != nil {
+			flags |= 0b10000
+		}
+		if flags == 0 {
+			return nil, fmt.Errorf("failed to serialize ShapeRecords[%d]: no style change", i)
+		}
+
+		buffer.Append(0, 1)
+		buffer.Append(flags, 5)
+
+		if style.NumBitsValue != nil {
+			numBits := int(*style.NumBitsValue)
+
+			buffer.Append(uint64(numBits), 5)
+			buffer.Append(value(style.MoveToValue1), numBits)
+			buffer.Append(value(style.MoveToValue2), numBits)
+		}
+		if style.FillStyle0Value != nil {
+			buffer.Append(*style.FillStyle0Value, int(numFillBits))
+		}
+		if style.FillStyle1Value != nil {
+			buffer.Append(*style.FillStyle1Value, int(numFillBits))
+		}
+		if style.LineStyleValue != nil {
+			buffer.Append(*style.LineStyleValue, int(numLineBits))
+		}
+		if style.ShapeStyles != nil {
+			stylesData, err := style.ShapeStyles.Serialize(shapeVersion)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize ShapeRecords[%d]: %w", i, err)
+			}
+
+			data = append(data, buffer.Bytes()...)
+			data = append(data, stylesData...)
+			buffer = &bits.Buffer{}
+			numFillBits = style.ShapeStyles.NumBits.Value >> 4
+			numLineBits = style.ShapeStyles.NumBits.Value & 0b1111
+		}
+	}
+
+	// EndShapeRecord
+	buffer.Append(0, 6)
+	data = append(data, buffer.Bytes()...)
+
+	return data, nil
+}
+
 type Shape struct {
 	ID           *Uint16
 	ShapeBounds  *Rectangle
@@ -1510,3 +2260,1597 @@ func ReadDefineShape(src io.Reader, swfVersion, shapeVersion int) (*Shape, error
 
 	return result, nil
 }
+
+type ColorTransform struct {
+	HasAlpha      bool
+	HasAddTerms   bool
+	HasMultTerms  bool
+	NumBits       uint8
+	RedMultTerm   int16
+	GreenMultTerm int16
+	BlueMultTerm  int16
+	AlphaMultTerm int16
+	RedAddTerm    int16
+	GreenAddTerm  int16
+	BlueAddTerm   int16
+	AlphaAddTerm  int16
+}
+
+func (c *ColorTransform) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+	if c.HasAlpha {
+		return fmt.Sprintf("ColorTransform{Mult: [%d %d %d %d], Add: [%d %d %d %d]}", c.RedMultTerm, c.GreenMultTerm, c.BlueMultTerm, c.AlphaMultTerm, c.RedAddTerm, c.GreenAddTerm, c.BlueAddTerm, c.AlphaAddTerm)
+	}
+
+	return fmt.Sprintf("ColorTransform{Mult: [%d %d %d], Add: [%d %d %d]}", c.RedMultTerm, c.GreenMultTerm, c.BlueMultTerm, c.RedAddTerm, c.GreenAddTerm, c.BlueAddTerm)
+}
+
+func (c *ColorTransform) terms() (mult []int16, add []int16) {
+	mult = []int16{c.RedMultTerm, c.GreenMultTerm, c.BlueMultTerm}
+	add = []int16{c.RedAddTerm, c.GreenAddTerm, c.BlueAddTerm}
+
+	if c.HasAlpha {
+		mult = append(mult, c.AlphaMultTerm)
+		add = append(add, c.AlphaAddTerm)
+	}
+
+	return mult, add
+}
+
+func (c *ColorTransform) Serialize() ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	mult, add := c.terms()
+
+	var values []int64
+
+	if c.HasMultTerms {
+		for _, v := range mult {
+			values = append(values, int64(v))
+		}
+	}
+	if c.HasAddTerms {
+		for _, v := range add {
+			values = append(values, int64(v))
+		}
+	}
+
+	numBits := int(c.NumBits)
+
+	if required := signedBits(values...); required > numBits {
+		numBits = required
+	}
+	if numBits > 0b1111 {
+		return nil, fmt.Errorf("failed to serialize ColorTransform: %d bits are required", numBits)
+	}
+
+	buffer := &bits.Buffer{}
+
+	appendFlag(buffer, c.HasAddTerms)
+	appendFlag(buffer, c.HasMultTerms)
+	buffer.Append(uint64(numBits), 4)
+
+	for _, value := range values {
+		buffer.Append(uint64(value), numBits)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// ReadColorTransform reads CXFORM, or CXFORMWITHALPHA when hasAlpha is true.
+func ReadColorTransform(src io.Reader, hasAlpha bool) (*ColorTransform, error) {
+	reader := newBitReader(src)
+	result := &ColorTransform{
+		HasAlpha:      hasAlpha,
+		RedMultTerm:   256,
+		GreenMultTerm: 256,
+		BlueMultTerm:  256,
+		AlphaMultTerm: 256,
+	}
+
+	hasAddTerms, err := reader.readFlag()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ColorTransform.HasAddTerms: %w", err)
+	}
+
+	hasMultTerms, err := reader.readFlag()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ColorTransform.HasMultTerms: %w", err)
+	}
+
+	numBits, err := reader.readBits(4)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ColorTransform.NumBits: %w", err)
+	}
+
+	result.HasAddTerms = hasAddTerms
+	result.HasMultTerms = hasMultTerms
+	result.NumBits = uint8(numBits)
+
+	numTerms := 3
+
+	if hasAlpha {
+		numTerms = 4
+	}
+	if hasMultTerms {
+		terms := []*int16{&result.RedMultTerm, &result.GreenMultTerm, &result.BlueMultTerm, &result.AlphaMultTerm}
+
+		for i := 0; i < numTerms; i++ {
+			value, err := reader.readSignedBits(int(numBits))
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ColorTransform mult terms: %w", err)
+			}
+
+			*terms[i] = int16(value)
+		}
+	}
+	if hasAddTerms {
+		terms := []*int16{&result.RedAddTerm, &result.GreenAddTerm, &result.BlueAddTerm, &result.AlphaAddTerm}
+
+		for i := 0; i < numTerms; i++ {
+			value, err := reader.readSignedBits(int(numBits))
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ColorTransform add terms: %w", err)
+			}
+
+			*terms[i] = int16(value)
+		}
+	}
+
+	return result, nil
+}
+
+const (
+	DropShadowFilterID    = 0
+	BlurFilterID          = 1
+	GlowFilterID          = 2
+	BevelFilterID         = 3
+	GradientGlowFilterID  = 4
+	ConvolutionFilterID   = 5
+	ColorMatrixFilterID   = 6
+	GradientBevelFilterID = 7
+)
+
+// Filter represents a FILTER record. Only the fields used by the filter type
+// identified by ID are set.
+type Filter struct {
+	ID *Uint8
+	// DropShadow, Glow, Bevel (shadow color) and Convolution (default color).
+	Color *Color
+	// Bevel.
+	HighlightColor *Color
+	// GradientGlow and GradientBevel.
+	GradientColors []*Color
+	GradientRatios []uint8
+	// FIXED values.
+	BlurX    float64
+	BlurY    float64
+	Angle    float64
+	Distance float64
+	// FIXED8 value.
+	Strength        float64
+	InnerShadow     bool
+	Knockout        bool
+	CompositeSource bool
+	OnTop           bool
+	Passes          uint8
+	// Convolution.
+	MatrixX       uint8
+	MatrixY       uint8
+	Divisor       float32
+	Bias          float32
+	Matrix        []float32
+	Clamp         bool
+	PreserveAlpha bool
+	// ColorMatrix.
+	ColorMatrix []float32
+}
+
+func (f *Filter) String() string {
+	if f == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("Filter{ID: %d}", f.ID.Value)
+}
+
+func (f *Filter) Serialize() ([]byte, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	data := &bytes.Buffer{}
+	data.WriteByte(f.ID.Value)
+
+	writeColor := func(c *Color) {
+		if c == nil {
+			c = &Color{}
+		}
+
+		data.Write([]byte{c.Red, c.Green, c.Blue, c.Alpha})
+	}
+	writeFixed := func(values ...float64) {
+		for _, value := range values {
+			binary.Write(data, binary.LittleEndian, int32(math.Round(value*65536)))
+		}
+	}
+	writeFloat := func(values ...float32) {
+		for _, value := range values {
+			binary.Write(data, binary.LittleEndian, math.Float32bits(value))
+		}
+	}
+	strength := func() {
+		binary.Write(data, binary.LittleEndian, int16(math.Round(f.Strength*256)))
+	}
+	flags := func(n int) {
+		buffer := &bits.Buffer{}
+
+		appendFlag(buffer, f.InnerShadow)
+		appendFlag(buffer, f.Knockout)
+		appendFlag(buffer, f.CompositeSource)
+
+		if n == 4 {
+			appendFlag(buffer, f.OnTop)
+		}
+
+		buffer.Append(uint64(f.Passes), n)
+		data.Write(buffer.Bytes())
+	}
+
+	switch f.ID.Value {
+	case DropShadowFilterID:
+		writeColor(f.Color)
+		writeFixed(f.BlurX, f.BlurY, f.Angle, f.Distance)
+		strength()
+		flags(5)
+	case BlurFilterID:
+		writeFixed(f.BlurX, f.BlurY)
+		data.WriteByte(f.Passes << 3)
+	case GlowFilterID:
+		writeColor(f.Color)
+		writeFixed(f.BlurX, f.BlurY)
+		strength()
+		flags(5)
+	case BevelFilterID:
+		writeColor(f.Color)
+		writeColor(f.HighlightColor)
+		writeFixed(f.BlurX, f.BlurY, f.Angle, f.Distance)
+		strength()
+		flags(4)
+	case GradientGlowFilterID, GradientBevelFilterID:
+		if len(f.GradientColors) != len(f.GradientRatios) {
+			return nil, fmt.Errorf("failed to serialize Filter: %d colors but %d ratios", len(f.GradientColors), len(f.GradientRatios))
+		}
+
+		data.WriteByte(uint8(len(f.GradientColors)))
+
+		for _, c := range f.GradientColors {
+			writeColor(c)
+		}
+
+		data.Write(f.GradientRatios)
+		writeFixed(f.BlurX, f.BlurY, f.Angle, f.Distance)
+		strength()
+		flags(4)
+	case ConvolutionFilterID:
+		if len(f.Matrix) != int(f.MatrixX)*int(f.MatrixY) {
+			return nil, fmt.Errorf("failed to serialize Filter: matrix must have %d values but got %d", int(f.MatrixX)*int(f.MatrixY), len(f.Matrix))
+		}
+
+		data.Write([]byte{f.MatrixX, f.MatrixY})
+		writeFloat(f.Divisor, f.Bias)
+		writeFloat(f.Matrix...)
+		writeColor(f.Color)
+
+		var flag uint8
+
+		if f.Clamp {
+			flag |= 0b10
+		}
+		if f.PreserveAlpha {
+			flag |= 0b1
+		}
+
+		data.WriteByte(flag)
+	case ColorMatrixFilterID:
+		if len(f.ColorMatrix) != 20 {
+			return nil, fmt.Errorf("failed to serialize Filter: color matrix must have 20 values but got %d", len(f.ColorMatrix))
+		}
+
+		writeFloat(f.ColorMatrix...)
+	default:
+		return nil, fmt.Errorf("failed to serialize Filter: invalid ID: %d", f.ID.Value)
+	}
+
+	return data.Bytes(), nil
+}
+
+func ReadFilter(src io.Reader) (*Filter, error) {
+	id, err := ReadUint8(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Filter.ID: %w", err)
+	}
+
+	result := &Filter{ID: id}
+
+	readFixed := func(dst ...*float64) error {
+		for _, d := range dst {
+			var value int32
+
+			if err := binary.Read(src, binary.LittleEndian, &value); err != nil {
+				return err
+			}
+
+			*d = float64(value) / 65536
+		}
+
+		return nil
+	}
+	readFloat := func(dst ...*float32) error {
+		for _, d := range dst {
+			var value uint32
+
+			if err := binary.Read(src, binary.LittleEndian, &value); err != nil {
+				return err
+			}
+
+			*d = math.Float32frombits(value)
+		}
+
+		return nil
+	}
+	readStrength := func() error {
+		var value int16
+
+		if err := binary.Read(src, binary.LittleEndian, &value); err != nil {
+			return err
+		}
+
+		result.Strength = float64(value) / 256
+
+		return nil
+	}
+	readFlags := func(n int) error {
+		reader := newBitReader(src)
+		dst := []*bool{&result.InnerShadow, &result.Knockout, &result.CompositeSource}
+
+		if n == 4 {
+			dst = append(dst, &result.OnTop)
+		}
+		for _, d := range dst {
+			flag, err := reader.readFlag()
+
+			if err != nil {
+				return err
+			}
+
+			*d = flag
+		}
+
+		passes, err := reader.readBits(n)
+
+		if err != nil {
+			return err
+		}
+
+		result.Passes = uint8(passes)
+
+		return nil
+	}
+
+	switch id.Value {
+	case DropShadowFilterID:
+		if result.Color, err = ReadRGBA(src); err != nil {
+			break
+		}
+		if err = readFixed(&result.BlurX, &result.BlurY, &result.Angle, &result.Distance); err != nil {
+			break
+		}
+		if err = readStrength(); err != nil {
+			break
+		}
+
+		err = readFlags(5)
+	case BlurFilterID:
+		if err = readFixed(&result.BlurX, &result.BlurY); err != nil {
+			break
+		}
+
+		var passes *Uint8
+
+		if passes, err = ReadUint8(src); err != nil {
+			break
+		}
+
+		result.Passes = passes.Value >> 3
+	case GlowFilterID:
+		if result.Color, err = ReadRGBA(src); err != nil {
+			break
+		}
+		if err = readFixed(&result.BlurX, &result.BlurY); err != nil {
+			break
+		}
+		if err = readStrength(); err != nil {
+			break
+		}
+
+		err = readFlags(5)
+	case BevelFilterID:
+		if result.Color, err = ReadRGBA(src); err != nil {
+			break
+		}
+		if result.HighlightColor, err = ReadRGBA(src); err != nil {
+			break
+		}
+		if err = readFixed(&result.BlurX, &result.BlurY, &result.Angle, &result.Distance); err != nil {
+			break
+		}
+		if err = readStrength(); err != nil {
+			break
+		}
+
+		err = readFlags(4)
+	case GradientGlowFilterID, GradientBevelFilterID:
+		var numColors *Uint8
+
+		if numColors, err = ReadUint8(src); err != nil {
+			break
+		}
+
+		result.GradientColors = make([]*Color, numColors.Value)
+
+		for i := range result.GradientColors {
+			if result.GradientColors[i], err = ReadRGBA(src); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+
+		result.GradientRatios = make([]uint8, numColors.Value)
+
+		if _, err = io.ReadFull(src, result.GradientRatios); err != nil {
+			break
+		}
+		if err = readFixed(&result.BlurX, &result.BlurY, &result.Angle, &result.Distance); err != nil {
+			break
+		}
+		if err = readStrength(); err != nil {
+			break
+		}
+
+		err = readFlags(4)
+	case ConvolutionFilterID:
+		size := make([]byte, 2)
+
+		if _, err = io.ReadFull(src, size); err != nil {
+			break
+		}
+
+		result.MatrixX = size[0]
+		result.MatrixY = size[1]
+
+		if err = readFloat(&result.Divisor, &result.Bias); err != nil {
+			break
+		}
+
+		result.Matrix = make([]float32, int(size[0])*int(size[1]))
+
+		for i := range result.Matrix {
+			if err = readFloat(&result.Matrix[i]); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+		if result.Color, err = ReadRGBA(src); err != nil {
+			break
+		}
+
+		var flags *Uint8
+
+		if flags, err = ReadUint8(src); err != nil {
+			break
+		}
+
+		result.Clamp = flags.Value&0b10 != 0
+		result.PreserveAlpha = flags.Value&0b1 != 0
+	case ColorMatrixFilterID:
+		result.ColorMatrix = make([]float32, 20)
+
+		for i := range result.ColorMatrix {
+			if err = readFloat(&result.ColorMatrix[i]); err != nil {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("failed to read Filter: invalid ID: %d", id.Value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Filter (ID: %d): %w", id.Value, err)
+	}
+
+	return result, nil
+}
+
+// ReadFilterList reads FILTERLIST.
+func ReadFilterList(src io.Reader) ([]*Filter, error) {
+	numFilters, err := ReadUint8(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FilterList.NumFilters: %w", err)
+	}
+
+	filters := make([]*Filter, numFilters.Value)
+
+	for i := range filters {
+		filter, err := ReadFilter(src)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FilterList[%d]: %w", i, err)
+		}
+
+		filters[i] = filter
+	}
+
+	return filters, nil
+}
+
+func serializeFilterList(filters []*Filter) ([]byte, error) {
+	if len(filters) > 0xff {
+		return nil, fmt.Errorf("failed to serialize FilterList: too many filters: %d", len(filters))
+	}
+
+	data := []byte{uint8(len(filters))}
+
+	for i, filter := range filters {
+		filterData, err := filter.Serialize()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize FilterList[%d]: %w", i, err)
+		}
+
+		data = append(data, filterData...)
+	}
+
+	return data, nil
+}
+
+type SoundEnvelope struct {
+	Pos44      *Uint32
+	LeftLevel  *Uint16
+	RightLevel *Uint16
+}
+
+type SoundInfo struct {
+	SyncStop        bool
+	SyncNoMultiple  bool
+	HasEnvelope     bool
+	HasLoops        bool
+	HasOutPoint     bool
+	HasInPoint      bool
+	InPoint         *Uint32
+	OutPoint        *Uint32
+	LoopCount       *Uint16
+	EnvelopeRecords []*SoundEnvelope
+}
+
+func (s *SoundInfo) String() string {
+	if s == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("SoundInfo{SyncStop: %v, SyncNoMultiple: %v, InPoint: %v, OutPoint: %v, LoopCount: %v, Envelopes: %d}", s.SyncStop, s.SyncNoMultiple, s.InPoint, s.OutPoint, s.LoopCount, len(s.EnvelopeRecords))
+}
+
+func (s *SoundInfo) Serialize() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	var flags uint8
+
+	for i, flag := range []bool{s.SyncStop, s.SyncNoMultiple, s.HasEnvelope, s.HasLoops, s.HasOutPoint, s.HasInPoint} {
+		if flag {
+			flags |= 1 << (5 - i)
+		}
+	}
+
+	data := []byte{flags}
+
+	if s.HasInPoint {
+		inPointData, err := s.InPoint.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, inPointData...)
+	}
+	if s.HasOutPoint {
+		outPointData, err := s.OutPoint.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, outPointData...)
+	}
+	if s.HasLoops {
+		loopCountData, err := s.LoopCount.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, loopCountData...)
+	}
+	if s.HasEnvelope {
+		if len(s.EnvelopeRecords) > 0xff {
+			return nil, fmt.Errorf("failed to serialize SoundInfo: too many envelope records: %d", len(s.EnvelopeRecords))
+		}
+
+		data = append(data, uint8(len(s.EnvelopeRecords)))
+
+		for _, envelope := range s.EnvelopeRecords {
+			for _, v := range []interface{ Serialize() ([]byte, error) }{envelope.Pos44, envelope.LeftLevel, envelope.RightLevel} {
+				vData, err := v.Serialize()
+
+				if err != nil {
+					return nil, err
+				}
+
+				data = append(data, vData...)
+			}
+		}
+	}
+
+	return data, nil
+}
+
+func ReadSoundInfo(src io.Reader) (*SoundInfo, error) {
+	flags, err := ReadUint8(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SoundInfo flags: %w", err)
+	}
+
+	result := &SoundInfo{
+		SyncStop:       flags.Value&0b100000 != 0,
+		SyncNoMultiple: flags.Value&0b10000 != 0,
+		HasEnvelope:    flags.Value&0b1000 != 0,
+		HasLoops:       flags.Value&0b100 != 0,
+		HasOutPoint:    flags.Value&0b10 != 0,
+		HasInPoint:     flags.Value&0b1 != 0,
+	}
+
+	if result.HasInPoint {
+		if result.InPoint, err = ReadUint32(src); err != nil {
+			return nil, fmt.Errorf("failed to read SoundInfo.InPoint: %w", err)
+		}
+	}
+	if result.HasOutPoint {
+		if result.OutPoint, err = ReadUint32(src); err != nil {
+			return nil, fmt.Errorf("failed to read SoundInfo.OutPoint: %w", err)
+		}
+	}
+	if result.HasLoops {
+		if result.LoopCount, err = ReadUint16(src); err != nil {
+			return nil, fmt.Errorf("failed to read SoundInfo.LoopCount: %w", err)
+		}
+	}
+	if result.HasEnvelope {
+		numPoints, err := ReadUint8(src)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SoundInfo.EnvPoints: %w", err)
+		}
+
+		result.EnvelopeRecords = make([]*SoundEnvelope, numPoints.Value)
+
+		for i := range result.EnvelopeRecords {
+			envelope := &SoundEnvelope{}
+
+			if envelope.Pos44, err = ReadUint32(src); err != nil {
+				return nil, fmt.Errorf("failed to read SoundInfo.EnvelopeRecords[%d].Pos44: %w", i, err)
+			}
+			if envelope.LeftLevel, err = ReadUint16(src); err != nil {
+				return nil, fmt.Errorf("failed to read SoundInfo.EnvelopeRecords[%d].LeftLevel: %w", i, err)
+			}
+			if envelope.RightLevel, err = ReadUint16(src); err != nil {
+				return nil, fmt.Errorf("failed to read SoundInfo.EnvelopeRecords[%d].RightLevel: %w", i, err)
+			}
+
+			result.EnvelopeRecords[i] = envelope
+		}
+	}
+
+	return result, nil
+}
+
+const (
+	BlendModeNormal     = 1
+	BlendModeLayer      = 2
+	BlendModeMultiply   = 3
+	BlendModeScreen     = 4
+	BlendModeLighten    = 5
+	BlendModeDarken     = 6
+	BlendModeDifference = 7
+	BlendModeAdd        = 8
+	BlendModeSubtract   = 9
+	BlendModeInvert     = 10
+	BlendModeAlpha      = 11
+	BlendModeErase      = 12
+	BlendModeOverlay    = 13
+	BlendModeHardlight  = 14
+)
+
+type ButtonRecord struct {
+	HasBlendMode   bool
+	HasFilterList  bool
+	StateHitTest   bool
+	StateDown      bool
+	StateOver      bool
+	StateUp        bool
+	CharacterID    *Uint16
+	PlaceDepth     *Uint16
+	PlaceMatrix    *Matrix
+	ColorTransform *ColorTransform
+	Filters        []*Filter
+	BlendMode      *Uint8
+}
+
+func (b *ButtonRecord) String() string {
+	if b == nil {
+		return "<nil>"
+	}
+
+	var states []string
+
+	if b.StateUp {
+		states = append(states, "Up")
+	}
+	if b.StateOver {
+		states = append(states, "Over")
+	}
+	if b.StateDown {
+		states = append(states, "Down")
+	}
+	if b.StateHitTest {
+		states = append(states, "HitTest")
+	}
+
+	return fmt.Sprintf("ButtonRecord{CharacterID: %d, PlaceDepth: %d, States: %v}", b.CharacterID.Value, b.PlaceDepth.Value, states)
+}
+
+func (b *ButtonRecord) Serialize(buttonVersion int) ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	var flags uint8
+
+	for i, flag := range []bool{b.HasBlendMode, b.HasFilterList, b.StateHitTest, b.StateDown, b.StateOver, b.StateUp} {
+		if flag {
+			flags |= 1 << (5 - i)
+		}
+	}
+	if flags == 0 {
+		return nil, fmt.Errorf("failed to serialize ButtonRecord: at least one flag must be set")
+	}
+
+	data := []byte{flags}
+
+	characterIDData, err := b.CharacterID.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	placeDepthData, err := b.PlaceDepth.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	placeMatrixData, err := b.PlaceMatrix.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(data, characterIDData...)
+	data = append(data, placeDepthData...)
+	data = append(data, placeMatrixData...)
+
+	if buttonVersion < 2 {
+		return data, nil
+	}
+
+	colorTransform := b.ColorTransform
+
+	if colorTransform == nil {
+		colorTransform = &ColorTransform{HasAlpha: true}
+	}
+
+	colorTransformData, err := colorTransform.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(data, colorTransformData...)
+
+	if b.HasFilterList {
+		filtersData, err := serializeFilterList(b.Filters)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, filtersData...)
+	}
+	if b.HasBlendMode {
+		blendModeData, err := b.BlendMode.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, blendModeData...)
+	}
+
+	return data, nil
+}
+
+// ReadButtonRecord reads BUTTONRECORD. It returns nil when it reaches the
+// CharacterEndFlag.
+func ReadButtonRecord(src io.Reader, buttonVersion int) (*ButtonRecord, error) {
+	flags, err := ReadUint8(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ButtonRecord flags: %w", err)
+	}
+	if flags.Value == 0 {
+		return nil, nil
+	}
+
+	result := &ButtonRecord{
+		HasBlendMode:  flags.Value&0b100000 != 0,
+		HasFilterList: flags.Value&0b10000 != 0,
+		StateHitTest:  flags.Value&0b1000 != 0,
+		StateDown:     flags.Value&0b100 != 0,
+		StateOver:     flags.Value&0b10 != 0,
+		StateUp:       flags.Value&0b1 != 0,
+	}
+
+	if result.CharacterID, err = ReadUint16(src); err != nil {
+		return nil, fmt.Errorf("failed to read ButtonRecord.CharacterID: %w", err)
+	}
+	if result.PlaceDepth, err = ReadUint16(src); err != nil {
+		return nil, fmt.Errorf("failed to read ButtonRecord.PlaceDepth: %w", err)
+	}
+	if result.PlaceMatrix, err = ReadMatrix(src); err != nil {
+		return nil, fmt.Errorf("failed to read ButtonRecord.PlaceMatrix: %w", err)
+	}
+	if buttonVersion < 2 {
+		return result, nil
+	}
+	if result.ColorTransform, err = ReadColorTransform(src, true); err != nil {
+		return nil, fmt.Errorf("failed to read ButtonRecord.ColorTransform: %w", err)
+	}
+	if result.HasFilterList {
+		if result.Filters, err = ReadFilterList(src); err != nil {
+			return nil, fmt.Errorf("failed to read ButtonRecord.Filters: %w", err)
+		}
+	}
+	if result.HasBlendMode {
+		if result.BlendMode, err = ReadUint8(src); err != nil {
+			return nil, fmt.Errorf("failed to read ButtonRecord.BlendMode: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func readButtonRecords(src io.Reader, buttonVersion int) ([]*ButtonRecord, error) {
+	records := []*ButtonRecord{}
+
+	for {
+		record, err := ReadButtonRecord(src, buttonVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ButtonRecords[%d]: %w", len(records), err)
+		}
+		if record == nil {
+			break
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func serializeButtonRecords(records []*ButtonRecord, buttonVersion int) ([]byte, error) {
+	var data []byte
+
+	for i, record := range records {
+		recordData, err := record.Serialize(buttonVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize ButtonRecords[%d]: %w", i, err)
+		}
+
+		data = append(data, recordData...)
+	}
+
+	// CharacterEndFlag
+	data = append(data, 0)
+
+	return data, nil
+}
+
+// Key codes of ButtonCondAction.KeyPress. Values from 32 to 126 are ASCII
+// characters.
+const (
+	KeyLeft      = 1
+	KeyRight     = 2
+	KeyHome      = 3
+	KeyEnd       = 4
+	KeyInsert    = 5
+	KeyDelete    = 6
+	KeyBackspace = 8
+	KeyEnter     = 13
+	KeyUp        = 14
+	KeyDown      = 15
+	KeyPageUp    = 16
+	KeyPageDown  = 17
+	KeyTab       = 18
+	KeyEscape    = 19
+)
+
+var keyNames = map[uint8]string{
+	KeyLeft:      "<Left>",
+	KeyRight:     "<Right>",
+	KeyHome:      "<Home>",
+	KeyEnd:       "<End>",
+	KeyInsert:    "<Insert>",
+	KeyDelete:    "<Delete>",
+	KeyBackspace: "<Backspace>",
+	KeyEnter:     "<Enter>",
+	KeyUp:        "<Up>",
+	KeyDown:      "<Down>",
+	KeyPageUp:    "<PageUp>",
+	KeyPageDown:  "<PageDown>",
+	KeyTab:       "<Tab>",
+	KeyEscape:    "<Escape>",
+}
+
+// KeyName returns a readable name of the key code, e.g. "<Enter>" or "a".
+func KeyName(keyCode uint8) string {
+	if name, ok := keyNames[keyCode]; ok {
+		return name
+	}
+	if keyCode >= 32 && keyCode <= 126 {
+		return string(rune(keyCode))
+	}
+
+	return fmt.Sprintf("<%d>", keyCode)
+}
+
+type ButtonCondAction struct {
+	IdleToOverDown    bool
+	OutDownToIdle     bool
+	OutDownToOverDown bool
+	OverDownToOutDown bool
+	OverDownToOverUp  bool
+	OverUpToOverDown  bool
+	OverUpToIdle      bool
+	IdleToOverUp      bool
+	KeyPress          uint8
+	OverDownToIdle    bool
+	// Actions holds the ACTIONRECORD stream including the ActionEndFlag.
+	Actions []byte
+}
+
+// Conditions returns the names of the conditions that trigger the actions.
+func (b *ButtonCondAction) Conditions() []string {
+	if b == nil {
+		return nil
+	}
+
+	var conditions []string
+
+	for _, c := range []struct {
+		flag bool
+		name string
+	}{
+		{b.IdleToOverUp, "RollOver"},
+		{b.OverUpToIdle, "RollOut"},
+		{b.OverUpToOverDown, "Press"},
+		{b.OverDownToOverUp, "Release"},
+		{b.OverDownToOutDown, "DragOut"},
+		{b.OutDownToOverDown, "DragOver"},
+		{b.OutDownToIdle, "ReleaseOutside"},
+		{b.IdleToOverDown, "IdleToOverDown"},
+		{b.OverDownToIdle, "OverDownToIdle"},
+	} {
+		if c.flag {
+			conditions = append(conditions, c.name)
+		}
+	}
+	if b.KeyPress != 0 {
+		conditions = append(conditions, "KeyPress "+KeyName(b.KeyPress))
+	}
+
+	return conditions
+}
+
+func (b *ButtonCondAction) String() string {
+	if b == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("ButtonCondAction{Conditions: %v, Actions: %d bytes}", b.Conditions(), len(b.Actions))
+}
+
+// Serialize encodes BUTTONCONDACTION. The CondActionSize is set to 0 when
+// isLast is true.
+func (b *ButtonCondAction) Serialize(isLast bool) ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	size := 4 + len(b.Actions)
+
+	if isLast {
+		size = 0
+	}
+	if size > 0xffff {
+		return nil, fmt.Errorf("failed to serialize ButtonCondAction: too large actions: %d bytes", len(b.Actions))
+	}
+
+	var flags uint8
+
+	for i, flag := range []bool{b.IdleToOverDown, b.OutDownToIdle, b.OutDownToOverDown, b.OverDownToOutDown, b.OverDownToOverUp, b.OverUpToOverDown, b.OverUpToIdle, b.IdleToOverUp} {
+		if flag {
+			flags |= 1 << (7 - i)
+		}
+	}
+
+	keyPress := b.KeyPress << 1
+
+	if b.OverDownToIdle {
+		keyPress |= 1
+	}
+
+	data := []byte{uint8(size), uint8(size >> 8), flags, keyPress}
+	data = append(data, b.Actions...)
+
+	return data, nil
+}
+
+// ReadButtonCondActions reads BUTTONCONDACTION records until the end of src.
+func ReadButtonCondActions(src io.Reader) ([]*ButtonCondAction, error) {
+	actions := []*ButtonCondAction{}
+
+	for {
+		size, err := ReadUint16(src)
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ButtonCondActions[%d].CondActionSize: %w", len(actions), err)
+		}
+
+		conditions := make([]byte, 2)
+
+		if _, err := io.ReadFull(src, conditions); err != nil {
+			return nil, fmt.Errorf("failed to read ButtonCondActions[%d] conditions: %w", len(actions), err)
+		}
+
+		action := &ButtonCondAction{
+			IdleToOverDown:    conditions[0]&0b10000000 != 0,
+			OutDownToIdle:     conditions[0]&0b1000000 != 0,
+			OutDownToOverDown: conditions[0]&0b100000 != 0,
+			OverDownToOutDown: conditions[0]&0b10000 != 0,
+			OverDownToOverUp:  conditions[0]&0b1000 != 0,
+			OverUpToOverDown:  conditions[0]&0b100 != 0,
+			OverUpToIdle:      conditions[0]&0b10 != 0,
+			IdleToOverUp:      conditions[0]&0b1 != 0,
+			KeyPress:          conditions[1] >> 1,
+			OverDownToIdle:    conditions[1]&0b1 != 0,
+		}
+
+		if size.Value == 0 {
+			action.Actions, err = io.ReadAll(src)
+		} else if size.Value < 4 {
+			err = fmt.Errorf("invalid CondActionSize: %d", size.Value)
+		} else {
+			action.Actions = make([]byte, size.Value-4)
+			_, err = io.ReadFull(src, action.Actions)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ButtonCondActions[%d].Actions: %w", len(actions), err)
+		}
+
+		actions = append(actions, action)
+
+		if size.Value == 0 {
+			break
+		}
+	}
+
+	return actions, nil
+}
+
+type MorphLineStyle struct {
+	StartWidth *Uint16
+	EndWidth   *Uint16
+	StartColor *Color
+	EndColor   *Color
+	// The following fields are used by MORPHLINESTYLE2 only. The flags are
+	// the same as the ones of LineStyle.
+	Flags     *Uint16
+	Miter     *Uint16
+	FillStyle *MorphFillStyle
+}
+
+func (m *MorphLineStyle) Serialize(morphVersion int) ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	var data []byte
+
+	for _, v := range []*Uint16{m.StartWidth, m.EndWidth} {
+		vData, err := v.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, vData...)
+	}
+	if morphVersion < 2 {
+		data = append(data, serializeColor(m.StartColor, true)...)
+		data = append(data, serializeColor(m.EndColor, true)...)
+
+		return data, nil
+	}
+	if m.Flags == nil {
+		return nil, fmt.Errorf("failed to serialize MorphLineStyle: Flags is nil")
+	}
+
+	flagsData, err := m.Flags.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(data, flagsData...)
+
+	if m.Flags.Value&LineStyleFlagJoinStyle == JoinStyleMiter {
+		miterData, err := m.Miter.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, miterData...)
+	}
+	if m.Flags.Contains(LineStyleFlagHasFill) {
+		fillStyleData, err := m.FillStyle.Serialize(4)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize MorphLineStyle.FillStyle: %w", err)
+		}
+
+		data = append(data, fillStyleData...)
+	} else {
+		data = append(data, serializeColor(m.StartColor, true)...)
+		data = append(data, serializeColor(m.EndColor, true)...)
+	}
+
+	return data, nil
+}
+
+// ReadMorphLineStyle reads MORPHLINESTYLE, or MORPHLINESTYLE2 when
+// morphVersion is 2.
+func ReadMorphLineStyle(src io.Reader, morphVersion int) (*MorphLineStyle, error) {
+	startWidth, err := ReadUint16(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphLineStyle.StartWidth: %w", err)
+	}
+
+	endWidth, err := ReadUint16(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphLineStyle.EndWidth: %w", err)
+	}
+
+	result := &MorphLineStyle{
+		StartWidth: startWidth,
+		EndWidth:   endWidth,
+	}
+
+	if morphVersion >= 2 {
+		flags, err := ReadUint16(src)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MorphLineStyle.Flags: %w", err)
+		}
+
+		result.Flags = flags
+
+		if flags.Value&LineStyleFlagJoinStyle == JoinStyleMiter {
+			miter, err := ReadUint16(src)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read MorphLineStyle.Miter: %w", err)
+			}
+
+			result.Miter = miter
+		}
+		if flags.Contains(LineStyleFlagHasFill) {
+			fillStyle, err := ReadMorphFillStyle(src, 4)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to read MorphLineStyle.FillStyle: %w", err)
+			}
+
+			result.FillStyle = fillStyle
+
+			return result, nil
+		}
+	}
+
+	startColor, err := ReadRGBA(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphLineStyle.StartColor: %w", err)
+	}
+
+	endColor, err := ReadRGBA(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphLineStyle.EndColor: %w", err)
+	}
+
+	result.StartColor = startColor
+	result.EndColor = endColor
+
+	return result, nil
+}
+
+type MorphShape struct {
+	ID          *Uint16
+	StartBounds *Rectangle
+	EndBounds   *Rectangle
+	// The following fields are used by DefineMorphShape2 only.
+	StartEdgeBounds *Rectangle
+	EndEdgeBounds   *Rectangle
+	Flags           *Uint8
+
+	Offset          *Uint32
+	MorphFillStyles []*MorphFillStyle
+	MorphLineStyles []*MorphLineStyle
+	StartNumBits    *Uint8
+	StartEdges      []*ShapeRecord
+	EndNumBits      *Uint8
+	EndEdges        []*ShapeRecord
+}
+
+func (m *MorphShape) morphVersion() int {
+	if m.StartEdgeBounds != nil {
+		return 2
+	}
+
+	return 1
+}
+
+// UsesNonScalingStrokes is available for DefineMorphShape2 only.
+func (m *MorphShape) UsesNonScalingStrokes() bool {
+	return m.Flags != nil && m.Flags.Value&0b10 != 0
+}
+
+// UsesScalingStrokes is available for DefineMorphShape2 only.
+func (m *MorphShape) UsesScalingStrokes() bool {
+	return m.Flags != nil && m.Flags.Value&0b1 != 0
+}
+
+// Serialize encodes the body of DefineMorphShape or DefineMorphShape2. The
+// Offset is recomputed.
+func (m *MorphShape) Serialize() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	morphVersion := m.morphVersion()
+	shapeVersion := 3
+
+	if morphVersion >= 2 {
+		shapeVersion = 4
+	}
+
+	var data []byte
+
+	idData, err := m.ID.Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(data, idData...)
+
+	bounds := []*Rectangle{m.StartBounds, m.EndBounds}
+
+	if morphVersion >= 2 {
+		bounds = append(bounds, m.StartEdgeBounds, m.EndEdgeBounds)
+	}
+	for _, rectangle := range bounds {
+		rectangleData, err := rectangle.Serialize()
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, rectangleData...)
+	}
+	if morphVersion >= 2 {
+		var flags uint8
+
+		if m.Flags != nil {
+			flags = m.Flags.Value
+		}
+
+		data = append(data, flags)
+	}
+
+	var styles []byte
+
+	countData, err := serializeStyleCount(len(m.MorphFillStyles), 2)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize MorphShape.MorphFillStyles: %w", err)
+	}
+
+	styles = append(styles, countData...)
+
+	for i, fillStyle := range m.MorphFillStyles {
+		fillStyleData, err := fillStyle.Serialize(shapeVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize MorphShape.MorphFillStyles[%d]: %w", i, err)
+		}
+
+		styles = append(styles, fillStyleData...)
+	}
+
+	countData, err = serializeStyleCount(len(m.MorphLineStyles), 2)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize MorphShape.MorphLineStyles: %w", err)
+	}
+
+	styles = append(styles, countData...)
+
+	for i, lineStyle := range m.MorphLineStyles {
+		lineStyleData, err := lineStyle.Serialize(morphVersion)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize MorphShape.MorphLineStyles[%d]: %w", i, err)
+		}
+
+		styles = append(styles, lineStyleData...)
+	}
+
+	var startNumBits, endNumBits uint8
+
+	if m.StartNumBits != nil {
+		startNumBits = m.StartNumBits.Value
+	}
+	if m.EndNumBits != nil {
+		endNumBits = m.EndNumBits.Value
+	}
+
+	startEdgesData, err := serializeShapeRecords(m.StartEdges, shapeVersion, startNumBits>>4, startNumBits&0b1111)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize MorphShape.StartEdges: %w", err)
+	}
+
+	endEdgesData, err := serializeShapeRecords(m.EndEdges, shapeVersion, endNumBits>>4, endNumBits&0b1111)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize MorphShape.EndEdges: %w", err)
+	}
+
+	offsetData, err := (&Uint32{Value: uint32(len(styles) + 1 + len(startEdgesData))}).Serialize()
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = append(data, offsetData...)
+	data = append(data, styles...)
+	data = append(data, startNumBits)
+	data = append(data, startEdgesData...)
+	data = append(data, endNumBits)
+	data = append(data, endEdgesData...)
+
+	return data, nil
+}
+
+func readStyleCount(src io.Reader) (int, error) {
+	count, err := ReadUint8(src)
+
+	if err != nil {
+		return 0, err
+	}
+	if count.Value != 0xff {
+		return int(count.Value), nil
+	}
+
+	extended, err := ReadUint16(src)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(extended.Value), nil
+}
+
+func readMorphEdges(src io.Reader, shapeVersion int) (*Uint8, []*ShapeRecord, error) {
+	numBits, err := ReadUint8(src)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read NumBits: %w", err)
+	}
+
+	shapeContext := &ShapeContext{
+		ShapeVersion: shapeVersion,
+		NumFillBits:  numBits.Value >> 4,
+		NumLineBits:  numBits.Value & 0b1111,
+	}
+
+	records := []*ShapeRecord{}
+
+	for {
+		record, err := ReadShapeRecord(src, shapeContext)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read ShapeRecords[%d]: %w", len(records), err)
+		}
+		if record == nil {
+			break
+		}
+
+		records = append(records, record)
+	}
+
+	return numBits, records, nil
+}
+
+// ReadDefineMorphShape reads the body of DefineMorphShape, or the body of
+// DefineMorphShape2 when morphVersion is 2.
+func ReadDefineMorphShape(src io.Reader, morphVersion int) (*MorphShape, error) {
+	shapeVersion := 3
+
+	if morphVersion >= 2 {
+		shapeVersion = 4
+	}
+
+	id, err := ReadUint16(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.ID: %w", err)
+	}
+
+	startBounds, err := ReadRectangle(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.StartBounds: %w", err)
+	}
+
+	endBounds, err := ReadRectangle(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.EndBounds: %w", err)
+	}
+
+	result := &MorphShape{
+		ID:          id,
+		StartBounds: startBounds,
+		EndBounds:   endBounds,
+	}
+
+	if morphVersion >= 2 {
+		if result.StartEdgeBounds, err = ReadRectangle(src); err != nil {
+			return nil, fmt.Errorf("failed to read MorphShape.StartEdgeBounds: %w", err)
+		}
+		if result.EndEdgeBounds, err = ReadRectangle(src); err != nil {
+			return nil, fmt.Errorf("failed to read MorphShape.EndEdgeBounds: %w", err)
+		}
+		if result.Flags, err = ReadUint8(src); err != nil {
+			return nil, fmt.Errorf("failed to read MorphShape.Flags: %w", err)
+		}
+	}
+	if result.Offset, err = ReadUint32(src); err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.Offset: %w", err)
+	}
+
+	numFillStyles, err := readStyleCount(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.MorphFillStyleCount: %w", err)
+	}
+
+	result.MorphFillStyles = make([]*MorphFillStyle, numFillStyles)
+
+	for i := range result.MorphFillStyles {
+		if result.MorphFillStyles[i], err = ReadMorphFillStyle(src, shapeVersion); err != nil {
+			return nil, fmt.Errorf("failed to read MorphShape.MorphFillStyles[%d]: %w", i, err)
+		}
+	}
+
+	numLineStyles, err := readStyleCount(src)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.MorphLineStyleCount: %w", err)
+	}
+
+	result.MorphLineStyles = make([]*MorphLineStyle, numLineStyles)
+
+	for i := range result.MorphLineStyles {
+		if result.MorphLineStyles[i], err = ReadMorphLineStyle(src, morphVersion); err != nil {
+			return nil, fmt.Errorf("failed to read MorphShape.MorphLineStyles[%d]: %w", i, err)
+		}
+	}
+	if result.StartNumBits, result.StartEdges, err = readMorphEdges(src, shapeVersion); err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.StartEdges: %w", err)
+	}
+	if result.EndNumBits, result.EndEdges, err = readMorphEdges(src, shapeVersion); err != nil {
+		return nil, fmt.Errorf("failed to read MorphShape.EndEdges: %w", err)
+	}
+
+	return result, nil
+}
+
+// readString reads a null-terminated STRING.
+func readString(src io.Reader) (string, error) {
+	var data []byte
+	var b [1]byte
+
+	for {
+		if _, err := io.ReadFull(src, b[:]); err != nil {
+			return "", err
+		}
+		if b[0] == 0x00 {
+			break
+		}
+
+		data = append(data, b[0])
+	}
+
+	return string(data), nil
+}
+
+func serializeString(s string) []byte {
+	return append([]byte(s), 0x00)
+}
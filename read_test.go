@@ -61,3 +61,54 @@ func TestReadRGB(t *testing.T) {
 	require.Len(t, data, 3)
 	require.Equal(t, data, []byte{0x11, 0x22, 0x33})
 }
+
+func TestReadRGBA(t *testing.T) {
+	input := bytes.NewBuffer([]byte{0x12, 0x34, 0x56, 0x78, 0x90})
+
+	color, err := ReadRGBA(input)
+
+	require.NoError(t, err)
+	require.NotNil(t, color)
+
+	require.Equal(t, uint8(0x12), color.Red)
+	require.Equal(t, uint8(0x34), color.Green)
+	require.Equal(t, uint8(0x56), color.Blue)
+	require.Equal(t, uint8(0x78), color.Alpha)
+
+	require.Equal(t, []byte{0x12, 0x34, 0x56, 0x78}, color.Bytes())
+	require.Equal(t, []byte{0x90}, input.Bytes())
+
+	data, err := color.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x12, 0x34, 0x56, 0x78}, data)
+}
+
+func TestReadMatrix(t *testing.T) {
+	for _, values := range [][6]int64{
+		{1 << 16, 0, 0, 1 << 16, 0, 0},
+		{1 << 16, 0, 0, 1 << 16, -200, 300},
+		{-1 << 15, 1 << 14, -1 << 14, 3 << 16, 40, -20},
+	} {
+		data, err := newMatrix(values[0], values[1], values[2], values[3], values[4], values[5]).Serialize()
+
+		require.NoError(t, err)
+
+		// The byte after the matrix must be left unread.
+		input := bytes.NewBuffer(append(data, 0xff))
+
+		matrix, err := ReadMatrix(input)
+
+		require.NoError(t, err)
+		require.Equal(t, []byte{0xff}, input.Bytes())
+
+		a, b, c, d, tx, ty := matrix.Values()
+
+		require.Equal(t, values, [6]int64{a, b, c, d, tx, ty})
+
+		serialized, err := matrix.Serialize()
+
+		require.NoError(t, err)
+		require.Equal(t, data, serialized)
+	}
+}
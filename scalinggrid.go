@@ -0,0 +1,69 @@
+package swf
+
+import "fmt"
+
+// ScalingGrid joins a DefineScalingGrid with the sprite or the button it
+// applies to.
+type ScalingGrid struct {
+	CharacterID uint16
+	Grid        *DefineScalingGrid
+	Sprite      *DefineSprite
+	Button      *Button
+}
+
+func (s *ScalingGrid) String() string {
+	if s == nil {
+		return "<nil>"
+	}
+
+	target := "unknown"
+
+	switch {
+	case s.Sprite != nil:
+		target = "DefineSprite"
+	case s.Button != nil:
+		target = "Button"
+	}
+
+	return fmt.Sprintf("ScalingGrid{CharacterID: %d, Target: %s, Splitter: %s}", s.CharacterID, target, s.Grid.Splitter)
+}
+
+// ScalingGrids returns every 9-sliced symbol in the file.
+func (f *File) ScalingGrids() []*ScalingGrid {
+	if f == nil {
+		return nil
+	}
+
+	sprites := map[uint16]*DefineSprite{}
+	buttons := map[uint16]*Button{}
+
+	for _, content := range f.Contents {
+		if sprite, ok := content.(*DefineSprite); ok {
+			sprites[sprite.ID.Value] = sprite
+		}
+	}
+	for _, button := range f.Buttons() {
+		buttons[button.ID] = button
+	}
+
+	var grids []*ScalingGrid
+
+	for _, content := range f.Contents {
+		grid, ok := content.(*DefineScalingGrid)
+
+		if !ok {
+			continue
+		}
+
+		id := grid.CharacterID.Value
+
+		grids = append(grids, &ScalingGrid{
+			CharacterID: id,
+			Grid:        grid,
+			Sprite:      sprites[id],
+			Button:      buttons[id],
+		})
+	}
+
+	return grids
+}
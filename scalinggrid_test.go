@@ -0,0 +1,97 @@
+package swf
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScalingGrid(id uint16, minX, maxX, minY, maxY int64) *DefineScalingGrid {
+	return &DefineScalingGrid{
+		CharacterID: &Uint16{Value: id},
+		Splitter:    newRectangle(minX, maxX, minY, maxY),
+	}
+}
+
+func TestParseDefineScalingGrid(t *testing.T) {
+	splitterData, err := newRectangle(100, 900, 200, 800).Serialize()
+
+	require.NoError(t, err)
+
+	body := append([]byte{0x03, 0x00}, splitterData...)
+	data := newTestTag(t, DefineScalingGridTagCode, body)
+
+	content, err := parseContent(bytes.NewBuffer(data))
+
+	require.NoError(t, err)
+
+	grid, ok := content.(*DefineScalingGrid)
+
+	require.True(t, ok)
+	require.Equal(t, uint16(3), grid.CharacterID.Value)
+
+	minX, maxX, minY, maxY := grid.Splitter.Values()
+
+	require.Equal(t, []int64{100, 900, 200, 800}, []int64{minX, maxX, minY, maxY})
+
+	serialized, err := grid.Serialize()
+
+	require.NoError(t, err)
+	require.Equal(t, data, serialized)
+}
+
+func TestDefineScalingGridSlices(t *testing.T) {
+	bounds := image.Rect(0, 0, 1000, 1000)
+	grid := newTestScalingGrid(1, 100, 900, 200, 800)
+
+	source, destination := grid.Slices(bounds, 2000, 1500)
+
+	require.Equal(t, image.Rect(0, 0, 100, 200), source[0])
+	require.Equal(t, image.Rect(100, 200, 900, 800), source[4])
+	require.Equal(t, image.Rect(900, 800, 1000, 1000), source[8])
+	require.Equal(t, image.Rect(0, 0, 100, 200), destination[0])
+	require.Equal(t, image.Rect(100, 200, 1900, 1300), destination[4])
+	require.Equal(t, image.Rect(1900, 1300, 2000, 1500), destination[8])
+
+	// The corners are shrunk proportionally when they do not fit.
+	_, destination = grid.Slices(bounds, 100, 200)
+
+	require.Equal(t, image.Rect(0, 0, 50, 100), destination[0])
+	require.True(t, destination[4].Empty())
+	require.Equal(t, image.Rect(50, 100, 100, 200), destination[8])
+
+	// The splitter outside of the bounds is ignored, so the whole character
+	// is scaled as the center slice.
+	outside := newTestScalingGrid(1, 2000, 3000, 2000, 3000)
+	source, destination = outside.Slices(bounds, 500, 400)
+
+	require.Equal(t, bounds, source[4])
+	require.Equal(t, image.Rect(0, 0, 500, 400), destination[4])
+	require.True(t, destination[0].Empty())
+	require.True(t, destination[8].Empty())
+}
+
+func TestScalingGrids(t *testing.T) {
+	sprite := &DefineSprite{ID: &Uint16{Value: 5}, NumFrames: &Uint16{Value: 1}}
+	button := &DefineButton2{ButtonID: &Uint16{Value: 6}}
+	file := &File{Contents: ContentSlice{
+		sprite,
+		button,
+		newTestScalingGrid(5, 0, 100, 0, 100),
+		newTestScalingGrid(6, 0, 100, 0, 100),
+		newTestScalingGrid(7, 0, 100, 0, 100),
+	}}
+
+	grids := file.ScalingGrids()
+
+	require.Len(t, grids, 3)
+	require.Equal(t, sprite, grids[0].Sprite)
+	require.Nil(t, grids[0].Button)
+	require.Equal(t, button, grids[1].Button.Define2)
+	require.Nil(t, grids[1].Sprite)
+	require.Equal(t, uint16(7), grids[2].CharacterID)
+	require.Nil(t, grids[2].Sprite)
+	require.Nil(t, grids[2].Button)
+}
@@ -0,0 +1,188 @@
+package swf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+)
+
+// UnsupportedVideoCodecError is returned when the frames of a video stream
+// cannot be decoded.
+type UnsupportedVideoCodecError struct {
+	Codec VideoCodec
+}
+
+func (e *UnsupportedVideoCodecError) Error() string {
+	return fmt.Sprintf("unsupported video codec: %s", e.Codec)
+}
+
+// ScreenVideoDecoder decodes Screen Video packets. An inter frame updates
+// only some blocks, so the decoder keeps the previous frame.
+type ScreenVideoDecoder struct {
+	frame *image.RGBA
+}
+
+// NewScreenVideoDecoder returns a decoder which waits for a key frame.
+func NewScreenVideoDecoder() *ScreenVideoDecoder {
+	return &ScreenVideoDecoder{}
+}
+
+// Decode applies the packet to the current frame and returns a copy of it.
+// It fails when an inter frame comes before a key frame or changes the size
+// of the image.
+func (d *ScreenVideoDecoder) Decode(videoData []byte) (image.Image, error) {
+	header, err := readScreenVideoHeader(videoData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	frameType, err := detectScreenVideoFrameType(VideoCodecScreenVideo, videoData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(0, 0, header.ImageWidth, header.ImageHeight)
+
+	if frameType == VideoFrameTypeKey {
+		d.frame = image.NewRGBA(bounds)
+	}
+	if d.frame == nil {
+		return nil, fmt.Errorf("failed to decode Screen Video: inter frame before key frame")
+	}
+	if d.frame.Bounds() != bounds {
+		return nil, fmt.Errorf("failed to decode Screen Video: inter frame changes size from %v to %v", d.frame.Bounds().Size(), bounds.Size())
+	}
+
+	offset := 4
+
+	// The blocks are stored from the bottom left to the top right, row by row.
+	for row := 0; row < header.rows(); row++ {
+		for column := 0; column < header.columns(); column++ {
+			if offset+2 > len(videoData) {
+				return nil, fmt.Errorf("failed to decode Screen Video: block (%d, %d) is missing", column, row)
+			}
+
+			size := int(binary.BigEndian.Uint16(videoData[offset:]))
+			offset += 2
+
+			if size == 0 {
+				continue
+			}
+			if offset+size > len(videoData) {
+				return nil, fmt.Errorf("failed to decode Screen Video: block (%d, %d) is truncated", column, row)
+			}
+			if err := d.decodeBlock(header, column, row, videoData[offset:offset+size]); err != nil {
+				return nil, fmt.Errorf("failed to decode Screen Video: block (%d, %d): %w", column, row, err)
+			}
+
+			offset += size
+		}
+	}
+
+	result := image.NewRGBA(bounds)
+
+	copy(result.Pix, d.frame.Pix)
+
+	return result, nil
+}
+
+func (d *ScreenVideoDecoder) decodeBlock(header screenVideoHeader, column, row int, data []byte) error {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	pixels, err := io.ReadAll(reader)
+
+	if err != nil {
+		return err
+	}
+
+	x0 := column * header.BlockWidth
+	bottom := row * header.BlockHeight
+	width := header.BlockWidth
+	height := header.BlockHeight
+
+	if x0+width > header.ImageWidth {
+		width = header.ImageWidth - x0
+	}
+	if bottom+height > header.ImageHeight {
+		height = header.ImageHeight - bottom
+	}
+	if len(pixels) != width*height*3 {
+		return fmt.Errorf("pixels must be %d bytes but got %d", width*height*3, len(pixels))
+	}
+
+	// The lines of a block are stored from the bottom, and each pixel is BGR.
+	for i := 0; i < height; i++ {
+		y := header.ImageHeight - 1 - (bottom + i)
+
+		for x := 0; x < width; x++ {
+			src := pixels[(i*width+x)*3:]
+			dst := d.frame.Pix[d.frame.PixOffset(x0+x, y):]
+
+			dst[0], dst[1], dst[2], dst[3] = src[2], src[1], src[0], 0xff
+		}
+	}
+
+	return nil
+}
+
+// Images decodes the frames of the stream. Only Screen Video is supported;
+// the other codecs return an *UnsupportedVideoCodecError.
+func (s *VideoStream) Images() ([]image.Image, error) {
+	if s == nil || s.Define == nil {
+		return nil, fmt.Errorf("cannot decode because VideoStream is nil")
+	}
+	if s.Define.CodecID != VideoCodecScreenVideo {
+		return nil, &UnsupportedVideoCodecError{Codec: s.Define.CodecID}
+	}
+
+	decoder := NewScreenVideoDecoder()
+	images := make([]image.Image, len(s.Frames))
+
+	for i, frame := range s.Frames {
+		img, err := decoder.Decode(frame.VideoData)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame %d: %w", frame.FrameNum.Value, err)
+		}
+
+		images[i] = img
+	}
+
+	return images, nil
+}
+
+// ExportPNGSequence decodes the frames of the stream and encodes each of them
+// as a PNG file, in the order of Frames.
+func (s *VideoStream) ExportPNGSequence() ([][]byte, error) {
+	images, err := s.Images()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]byte, len(images))
+
+	for i, img := range images {
+		buffer := &bytes.Buffer{}
+
+		if err := png.Encode(buffer, img); err != nil {
+			return nil, fmt.Errorf("failed to encode frame %d: %w", s.Frames[i].FrameNum.Value, err)
+		}
+
+		result[i] = buffer.Bytes()
+	}
+
+	return result, nil
+}
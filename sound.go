@@ -0,0 +1,321 @@
+package swf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// SoundFormat is the codec of a sound.
+type SoundFormat uint8
+
+const (
+	SoundFormatUncompressedNativeEndian SoundFormat = 0
+	SoundFormatADPCM                    SoundFormat = 1
+	SoundFormatMP3                      SoundFormat = 2
+	SoundFormatUncompressedLittleEndian SoundFormat = 3
+	SoundFormatNellymoser16kHz          SoundFormat = 4
+	SoundFormatNellymoser8kHz           SoundFormat = 5
+	SoundFormatNellymoser               SoundFormat = 6
+	SoundFormatSpeex                    SoundFormat = 11
+)
+
+func (f SoundFormat) String() string {
+	switch f {
+	case SoundFormatUncompressedNativeEndian:
+		return "UncompressedNativeEndian"
+	case SoundFormatADPCM:
+		return "ADPCM"
+	case SoundFormatMP3:
+		return "MP3"
+	case SoundFormatUncompressedLittleEndian:
+		return "UncompressedLittleEndian"
+	case SoundFormatNellymoser16kHz:
+		return "Nellymoser16kHz"
+	case SoundFormatNellymoser8kHz:
+		return "Nellymoser8kHz"
+	case SoundFormatNellymoser:
+		return "Nellymoser"
+	case SoundFormatSpeex:
+		return "Speex"
+	default:
+		return fmt.Sprintf("SoundFormat(%d)", uint8(f))
+	}
+}
+
+// SoundRate is the sampling rate of a sound.
+type SoundRate uint8
+
+const (
+	SoundRate5512  SoundRate = 0
+	SoundRate11025 SoundRate = 1
+	SoundRate22050 SoundRate = 2
+	SoundRate44100 SoundRate = 3
+)
+
+// Hz returns the sampling rate in hertz. The rate of the Nellymoser 8kHz and
+// 16kHz codecs is fixed by the format, not by this field.
+func (r SoundRate) Hz() int {
+	switch r {
+	case SoundRate5512:
+		return 5512
+	case SoundRate11025:
+		return 11025
+	case SoundRate22050:
+		return 22050
+	default:
+		return 44100
+	}
+}
+
+func (r SoundRate) String() string {
+	return fmt.Sprintf("%dHz", r.Hz())
+}
+
+// UnsupportedSoundFormatError is returned when sound data cannot be exported
+// in the requested container.
+type UnsupportedSoundFormatError struct {
+	Format SoundFormat
+}
+
+func (e *UnsupportedSoundFormatError) Error() string {
+	return fmt.Sprintf("unsupported sound format: %s", e.Format)
+}
+
+// soundFlags is the byte shared by DefineSound and the sound stream heads.
+// The format occupies the upper 4 bits, followed by the rate, the size and
+// the type.
+type soundFlags struct {
+	Format   SoundFormat
+	Rate     SoundRate
+	Is16Bit  bool
+	IsStereo bool
+}
+
+func parseSoundFlags(value uint8) soundFlags {
+	return soundFlags{
+		Format:   SoundFormat(value >> 4),
+		Rate:     SoundRate(value >> 2 & 0b11),
+		Is16Bit:  value&0b10 != 0,
+		IsStereo: value&0b1 != 0,
+	}
+}
+
+func (f soundFlags) serialize() uint8 {
+	value := uint8(f.Format)<<4 | uint8(f.Rate&0b11)<<2
+
+	if f.Is16Bit {
+		value |= 0b10
+	}
+	if f.IsStereo {
+		value |= 0b1
+	}
+
+	return value
+}
+
+// EncodeWAV wraps uncompressed samples into a RIFF WAVE file. The samples of
+// a stereo sound are interleaved, 8-bit samples are unsigned and 16-bit
+// samples are signed. 16-bit samples are swapped into little-endian when
+// bigEndian is true.
+func EncodeWAV(samples []byte, sampleRate, channels, bitsPerSample int, bigEndian bool) ([]byte, error) {
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("failed to encode WAV: invalid channels: %d", channels)
+	}
+	if bitsPerSample != 8 && bitsPerSample != 16 {
+		return nil, fmt.Errorf("failed to encode WAV: invalid bits per sample: %d", bitsPerSample)
+	}
+
+	blockAlign := channels * bitsPerSample / 8
+	length := len(samples) - len(samples)%blockAlign
+	data := make([]byte, 44+length)
+
+	copy(data[0:], "RIFF")
+	binary.LittleEndian.PutUint32(data[4:], uint32(36+length))
+	copy(data[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(data[16:], 16)
+	binary.LittleEndian.PutUint16(data[20:], 1)
+	binary.LittleEndian.PutUint16(data[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(data[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(data[28:], uint32(sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(data[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(data[34:], uint16(bitsPerSample))
+	copy(data[36:], "data")
+	binary.LittleEndian.PutUint32(data[40:], uint32(length))
+	copy(data[44:], samples[:length])
+
+	if bigEndian && bitsPerSample == 16 {
+		for i := 44; i+1 < len(data); i += 2 {
+			data[i], data[i+1] = data[i+1], data[i]
+		}
+	}
+
+	return data, nil
+}
+
+// isBigEndian reports whether the uncompressed samples of the format are
+// big-endian when the native byte order is order.
+func isBigEndian(format SoundFormat, order binary.ByteOrder) bool {
+	return format == SoundFormatUncompressedNativeEndian && order == binary.BigEndian
+}
+
+// EncodeWAV16 wraps signed 16-bit samples into a RIFF WAVE file. The samples
+// of a stereo sound are interleaved.
+func EncodeWAV16(samples []int16, sampleRate, channels int) ([]byte, error) {
+	data := make([]byte, len(samples)*2)
+
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+
+	return EncodeWAV(data, sampleRate, channels, 16, false)
+}
+
+// Apply renders the samples the way the player plays them with the sound
+// info. The samples are signed 16-bit samples at sampleRate, interleaved when
+// stereo is true. The in point, the out point and the envelope positions are
+// counted in 44.1kHz samples from the beginning of the sound, and the
+// envelope positions keep counting across loops. A mono sound is scaled by
+// the average of the left and right levels.
+func (s *SoundInfo) Apply(samples []int16, sampleRate int, stereo bool) []int16 {
+	channels := 1
+
+	if stereo {
+		channels = 2
+	}
+
+	frames := len(samples) / channels
+
+	if s == nil || sampleRate <= 0 {
+		return append([]int16{}, samples[:frames*channels]...)
+	}
+
+	toFrame := func(pos44 uint32) int {
+		frame := int(uint64(pos44) * uint64(sampleRate) / 44100)
+
+		if frame > frames {
+			return frames
+		}
+
+		return frame
+	}
+
+	start, end := 0, frames
+
+	if s.HasInPoint && s.InPoint != nil {
+		start = toFrame(s.InPoint.Value)
+	}
+	if s.HasOutPoint && s.OutPoint != nil {
+		end = toFrame(s.OutPoint.Value)
+	}
+	if end < start {
+		end = start
+	}
+
+	loops := 1
+
+	if s.HasLoops && s.LoopCount != nil && s.LoopCount.Value > 1 {
+		loops = int(s.LoopCount.Value)
+	}
+
+	segment := samples[start*channels : end*channels]
+	result := make([]int16, 0, len(segment)*loops)
+
+	for i := 0; i < loops; i++ {
+		result = append(result, segment...)
+	}
+	if !s.HasEnvelope || len(s.EnvelopeRecords) == 0 {
+		return result
+	}
+
+	offset := float64(start) * 44100 / float64(sampleRate)
+
+	for i := 0; i < len(result)/channels; i++ {
+		left, right := s.envelopeLevels(offset + float64(i)*44100/float64(sampleRate))
+
+		if stereo {
+			result[i*2] = scaleSample(result[i*2], left)
+			result[i*2+1] = scaleSample(result[i*2+1], right)
+		} else {
+			result[i] = scaleSample(result[i], (left+right)/2)
+		}
+	}
+
+	return result
+}
+
+// envelopeLevels interpolates the envelope at the position. The levels range
+// from 0 to 1.
+func (s *SoundInfo) envelopeLevels(pos44 float64) (left, right float64) {
+	records := s.EnvelopeRecords
+	level := func(r *SoundEnvelope) (float64, float64) {
+		return float64(r.LeftLevel.Value) / 32768, float64(r.RightLevel.Value) / 32768
+	}
+
+	if pos44 <= float64(records[0].Pos44.Value) {
+		return level(records[0])
+	}
+
+	for i := 1; i < len(records); i++ {
+		from, to := float64(records[i-1].Pos44.Value), float64(records[i].Pos44.Value)
+
+		if pos44 > to {
+			continue
+		}
+
+		fromLeft, fromRight := level(records[i-1])
+		toLeft, toRight := level(records[i])
+		t := (pos44 - from) / (to - from)
+
+		return fromLeft + (toLeft-fromLeft)*t, fromRight + (toRight-fromRight)*t
+	}
+
+	return level(records[len(records)-1])
+}
+
+func scaleSample(sample int16, level float64) int16 {
+	value := math.Round(float64(sample) * level)
+
+	if value > 32767 {
+		return 32767
+	}
+	if value < -32768 {
+		return -32768
+	}
+
+	return int16(value)
+}
+
+// StartSoundTarget returns the DefineSound played by a StartSound or
+// StartSound2 tag. StartSound2 is resolved through SymbolClass. It returns nil
+// when the sound is not defined in the file.
+func (f *File) StartSoundTarget(content Content) *DefineSound {
+	if f == nil {
+		return nil
+	}
+
+	var id uint16
+
+	switch v := content.(type) {
+	case *StartSound:
+		id = v.SoundID.Value
+	case *StartSound2:
+		characterID, ok := f.ClassCharacterID(v.SoundClassName)
+
+		if !ok {
+			return nil
+		}
+
+		id = characterID
+	default:
+		return nil
+	}
+
+	for _, content := range f.Contents {
+		if v, ok := content.(*DefineSound); ok && v.SoundID.Value == id {
+			return v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefineSoundExport(t *testing.T) {
+	pcm := &DefineSound{
+		SoundID:          &Uint16{Value: 1},
+		SoundFormat:      SoundFormatUncompressedLittleEndian,
+		SoundRate:        SoundRate22050,
+		Is16Bit:          true,
+		IsStereo:         true,
+		SoundSampleCount: &Uint32{Value: 1},
+		SoundData:        []byte{0x01, 0x02, 0x03, 0x04},
+	}
+
+	data, err := pcm.Serialize()
+
+	require.NoError(t, err)
+
+	content, err := parseContent(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	parsed := content.(*DefineSound)
+
+	require.Equal(t, SoundFormatUncompressedLittleEndian, parsed.SoundFormat)
+	require.Equal(t, 22050, parsed.SoundRate.Hz())
+	require.True(t, parsed.Is16Bit)
+	require.True(t, parsed.IsStereo)
+
+	wav, err := parsed.ExportWAV()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte("RIFF"), wav[:4])
+	require.Equal(t, []byte{0x01, 0x02, 0x03, 0x04}, wav[44:])
+
+	_, err = parsed.ExportMP3()
+
+	var unsupported *UnsupportedSoundFormatError
+
+	require.True(t, errors.As(err, &unsupported))
+	require.Equal(t, SoundFormatUncompressedLittleEndian, unsupported.Format)
+
+	mp3 := &DefineSound{SoundFormat: SoundFormatMP3, SoundData: []byte{0x00, 0x00, 0xff, 0xfb}}
+
+	frames, err := mp3.ExportMP3()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xff, 0xfb}, frames)
+}
+
+func TestDefineSoundExportByteOrder(t *testing.T) {
+	native := &DefineSound{
+		SoundFormat: SoundFormatUncompressedNativeEndian,
+		SoundRate:   SoundRate11025,
+		Is16Bit:     true,
+		SoundData:   []byte{0x01, 0x02, 0xff, 0xfe},
+	}
+
+	wav, err := native.ExportWAVWithByteOrder(binary.BigEndian)
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x02, 0x01, 0xfe, 0xff}, wav[44:])
+
+	samples, err := native.SamplesWithByteOrder(binary.BigEndian)
+
+	require.NoError(t, err)
+	require.Equal(t, []int16{0x0102, -2}, samples)
+
+	// Native-endian samples are little-endian by default.
+	wav, err = native.ExportWAV()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02, 0xff, 0xfe}, wav[44:])
+
+	samples, err = native.Samples()
+
+	require.NoError(t, err)
+	require.Equal(t, []int16{0x0201, -257}, samples)
+
+	// The little-endian format ignores the byte order.
+	native.SoundFormat = SoundFormatUncompressedLittleEndian
+
+	wav, err = native.ExportWAVWithByteOrder(binary.BigEndian)
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02, 0xff, 0xfe}, wav[44:])
+}
+
+func TestSoundInfoApply(t *testing.T) {
+	info := &SoundInfo{
+		HasInPoint:  true,
+		InPoint:     &Uint32{Value: 1},
+		HasOutPoint: true,
+		OutPoint:    &Uint32{Value: 3},
+		HasLoops:    true,
+		LoopCount:   &Uint16{Value: 2},
+		HasEnvelope: true,
+		EnvelopeRecords: []*SoundEnvelope{
+			{Pos44: &Uint32{Value: 1}, LeftLevel: &Uint16{Value: 32768}, RightLevel: &Uint16{Value: 0}},
+			{Pos44: &Uint32{Value: 3}, LeftLevel: &Uint16{Value: 16384}, RightLevel: &Uint16{Value: 32768}},
+		},
+	}
+	samples := []int16{1, 1, 100, 100, 200, 200, 300, 300}
+
+	result := info.Apply(samples, 44100, true)
+
+	require.Equal(t, []int16{100, 0, 150, 100, 50, 100, 100, 200}, result)
+}
+
+func TestStartSoundTarget(t *testing.T) {
+	sound := newTestTag(t, DefineSoundTagCode, []byte{0x07, 0x00, 0x32, 0x00, 0x00, 0x00, 0x00})
+	symbolClass := newTestTag(t, SymbolClassTagCode, []byte("\x01\x00\x07\x00Beep\x00"))
+	startSound := newTestTag(t, StartSoundTagCode, []byte{0x07, 0x00, 0x00})
+	startSound2 := newTestTag(t, StartSound2TagCode, []byte("Beep\x00\x20"))
+
+	file, err := Parse(bytes.NewReader(newTestSWF(t, sound, symbolClass, startSound, startSound2)))
+
+	require.NoError(t, err)
+
+	define := file.Contents[0].(*DefineSound)
+
+	require.Same(t, define, file.StartSoundTarget(file.Contents[2]))
+	require.Same(t, define, file.StartSoundTarget(file.Contents[3]))
+	require.True(t, file.Contents[3].(*StartSound2).SoundInfo.SyncStop)
+}
@@ -0,0 +1,202 @@
+package swf
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/moutend/swf/adpcm"
+)
+
+// SoundStreamFrame is a SoundStreamBlock with the frame which plays it.
+type SoundStreamFrame struct {
+	// Frame is the zero-based index of the frame in the timeline.
+	Frame int
+	Block *SoundStreamBlock
+}
+
+// SoundStream joins a SoundStreamHead or SoundStreamHead2 with the
+// SoundStreamBlock tags which follow it in the same timeline.
+type SoundStream struct {
+	// SpriteID is the ID of the sprite which plays the stream, or 0 for the
+	// main timeline.
+	SpriteID uint16
+	Head     *SoundStreamHead
+	Head2    *SoundStreamHead2
+	Blocks   []*SoundStreamFrame
+}
+
+func (s *SoundStream) String() string {
+	if s == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("SoundStream{SpriteID: %d, Format: %s, Blocks: %d}", s.SpriteID, s.Format(), len(s.Blocks))
+}
+
+func (s *SoundStream) flags() soundFlags {
+	switch {
+	case s.Head2 != nil:
+		return s.Head2.streamFlags()
+	case s.Head != nil:
+		return s.Head.streamFlags()
+	default:
+		return soundFlags{}
+	}
+}
+
+// Format returns the codec of the stream.
+func (s *SoundStream) Format() SoundFormat {
+	return s.flags().Format
+}
+
+// Data returns the samples of all blocks as a single stream. The SampleCount
+// and SeekSamples fields of MP3 blocks are removed, so MP3 data is a plain
+// MP3 stream. ADPCM blocks are decoded into 16-bit little-endian samples
+// because each block restarts the ADPCM state. It returns an
+// *UnsupportedSoundFormatError for the other compressed codecs.
+func (s *SoundStream) Data() ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("cannot export because SoundStream is nil")
+	}
+
+	flags := s.flags()
+
+	var data []byte
+
+	for _, block := range s.Blocks {
+		payload := block.Block.StreamSoundData
+
+		switch flags.Format {
+		case SoundFormatUncompressedNativeEndian, SoundFormatUncompressedLittleEndian:
+			data = append(data, payload...)
+		case SoundFormatMP3:
+			if len(payload) < 4 {
+				return nil, fmt.Errorf("failed to export SoundStream: MP3 block at frame %d is too short", block.Frame)
+			}
+
+			data = append(data, payload[4:]...)
+		case SoundFormatADPCM:
+			if len(payload) == 0 {
+				continue
+			}
+
+			samples, err := adpcm.DecodeBytes(payload, flags.IsStereo)
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to export SoundStream: block at frame %d: %w", block.Frame, err)
+			}
+
+			data = append(data, samples...)
+		default:
+			return nil, &UnsupportedSoundFormatError{Format: flags.Format}
+		}
+	}
+
+	return data, nil
+}
+
+// ExportMP3 returns the MP3 frames of all blocks as a plain MP3 stream.
+func (s *SoundStream) ExportMP3() ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("cannot export because SoundStream is nil")
+	}
+	if format := s.Format(); format != SoundFormatMP3 {
+		return nil, &UnsupportedSoundFormatError{Format: format}
+	}
+
+	return s.Data()
+}
+
+// ExportWAV returns the samples of all blocks as a WAV file. Native-endian
+// samples are treated as little-endian the same way as DefineSound.
+func (s *SoundStream) ExportWAV() ([]byte, error) {
+	return s.ExportWAVWithByteOrder(binary.LittleEndian)
+}
+
+// ExportWAVWithByteOrder is the same as ExportWAV, but reads native-endian
+// 16-bit samples in the byte order.
+func (s *SoundStream) ExportWAVWithByteOrder(order binary.ByteOrder) ([]byte, error) {
+	if s == nil {
+		return nil, fmt.Errorf("cannot export because SoundStream is nil")
+	}
+	if order == nil {
+		return nil, fmt.Errorf("cannot export because order is nil")
+	}
+
+	flags := s.flags()
+	bitsPerSample := 8
+
+	switch flags.Format {
+	case SoundFormatUncompressedNativeEndian, SoundFormatUncompressedLittleEndian:
+		if flags.Is16Bit {
+			bitsPerSample = 16
+		}
+	case SoundFormatADPCM:
+		bitsPerSample = 16
+	default:
+		return nil, &UnsupportedSoundFormatError{Format: flags.Format}
+	}
+
+	data, err := s.Data()
+
+	if err != nil {
+		return nil, err
+	}
+
+	channels := 1
+
+	if flags.IsStereo {
+		channels = 2
+	}
+
+	return EncodeWAV(data, flags.Rate.Hz(), channels, bitsPerSample, isBigEndian(flags.Format, order))
+}
+
+// SoundStreams returns the streaming sounds of the main timeline and of the
+// sprites. Each head starts a new stream in its timeline. It fails when a
+// block has no head, when a frame has more than one block, or when an MP3
+// block lacks its SampleCount and SeekSamples fields.
+func (f *File) SoundStreams() ([]*SoundStream, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	var streams []*SoundStream
+
+	current := map[uint16]*SoundStream{}
+
+	err := walkTimelines(f.Contents, func(spriteID uint16, frame int, content Content) error {
+		switch v := content.(type) {
+		case *SoundStreamHead:
+			stream := &SoundStream{SpriteID: spriteID, Head: v}
+			streams = append(streams, stream)
+			current[spriteID] = stream
+		case *SoundStreamHead2:
+			stream := &SoundStream{SpriteID: spriteID, Head2: v}
+			streams = append(streams, stream)
+			current[spriteID] = stream
+		case *SoundStreamBlock:
+			stream, ok := current[spriteID]
+
+			if !ok {
+				return fmt.Errorf("SoundStreamBlock at frame %d of sprite %d has no SoundStreamHead", frame, spriteID)
+			}
+			if n := len(stream.Blocks); n > 0 && stream.Blocks[n-1].Frame == frame {
+				return fmt.Errorf("frame %d of sprite %d has more than one SoundStreamBlock", frame, spriteID)
+			}
+			if stream.Format() == SoundFormatMP3 && len(v.StreamSoundData) < 4 {
+				return fmt.Errorf("MP3 SoundStreamBlock at frame %d of sprite %d is too short", frame, spriteID)
+			}
+
+			stream.Blocks = append(stream.Blocks, &SoundStreamFrame{Frame: frame, Block: v})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect sound streams: %w", err)
+	}
+
+	return streams, nil
+}
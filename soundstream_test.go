@@ -0,0 +1,105 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoundStreams(t *testing.T) {
+	// MP3 at 44100Hz, 16-bit stereo with LatencySeek.
+	mp3Head := newTestTag(t, SoundStreamHeadTagCode, []byte{0x0f, 0x2f, 0x40, 0x04, 0x00, 0x00})
+	// Uncompressed little-endian at 5512Hz, 8-bit mono.
+	pcmHead := newTestTag(t, SoundStreamHead2TagCode, []byte{0x00, 0x30, 0x02, 0x00})
+
+	var sprite []byte
+
+	sprite = append(sprite, pcmHead...)
+	sprite = append(sprite, newTestTag(t, ShowFrameTagCode, nil)...)
+	sprite = append(sprite, newTestTag(t, SoundStreamBlockTagCode, []byte{0x80, 0x81})...)
+	sprite = append(sprite, newTestTag(t, ShowFrameTagCode, nil)...)
+	sprite = append(sprite, 0x00, 0x00)
+
+	spriteTag := []byte{0xff, 0x09, 0, 0, 0, 0, 0x05, 0x00, 0x02, 0x00}
+	binary.LittleEndian.PutUint32(spriteTag[2:], uint32(4+len(sprite)))
+	spriteTag = append(spriteTag, sprite...)
+
+	data := newTestSWF(t,
+		mp3Head,
+		newTestTag(t, SoundStreamBlockTagCode, []byte{0x40, 0x04, 0x00, 0x00, 0xff, 0xfb}),
+		spriteTag,
+		newTestTag(t, ShowFrameTagCode, nil),
+		newTestTag(t, SoundStreamBlockTagCode, []byte{0x40, 0x04, 0x00, 0x00, 0x90, 0x00}),
+		newTestTag(t, ShowFrameTagCode, nil),
+	)
+
+	file, err := Parse(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	streams, err := file.SoundStreams()
+
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+
+	require.Equal(t, uint16(0), streams[0].SpriteID)
+	require.Equal(t, SoundFormatMP3, streams[0].Format())
+	require.Equal(t, 0, streams[0].Blocks[0].Frame)
+	require.Equal(t, 1, streams[0].Blocks[1].Frame)
+
+	mp3, err := streams[0].ExportMP3()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xff, 0xfb, 0x90, 0x00}, mp3)
+
+	require.Equal(t, uint16(5), streams[1].SpriteID)
+	require.Equal(t, 1, streams[1].Blocks[0].Frame)
+
+	wav, err := streams[1].ExportWAV()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x80, 0x81}, wav[44:])
+}
+
+func TestSoundStreamsWithoutHead(t *testing.T) {
+	data := newTestSWF(t, newTestTag(t, SoundStreamBlockTagCode, []byte{0x00}))
+
+	file, err := Parse(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	_, err = file.SoundStreams()
+
+	require.Error(t, err)
+}
+
+func TestSoundStreamExportWAVByteOrder(t *testing.T) {
+	// Uncompressed native-endian at 5512Hz, 16-bit mono.
+	head := newTestTag(t, SoundStreamHead2TagCode, []byte{0x02, 0x02, 0x01, 0x00})
+	data := newTestSWF(t,
+		head,
+		newTestTag(t, SoundStreamBlockTagCode, []byte{0x01, 0x02}),
+		newTestTag(t, ShowFrameTagCode, nil),
+	)
+
+	file, err := Parse(bytes.NewReader(data))
+
+	require.NoError(t, err)
+
+	streams, err := file.SoundStreams()
+
+	require.NoError(t, err)
+	require.Len(t, streams, 1)
+
+	wav, err := streams[0].ExportWAVWithByteOrder(binary.BigEndian)
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x02, 0x01}, wav[44:])
+
+	wav, err = streams[0].ExportWAV()
+
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02}, wav[44:])
+}
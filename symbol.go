@@ -0,0 +1,38 @@
+package swf
+
+// ClassCharacterID returns the character linked to the ActionScript 3 class
+// by SymbolClass.
+func (f *File) ClassCharacterID(name string) (uint16, bool) {
+	characterID, ok := f.classSymbols()[name]
+
+	return characterID, ok
+}
+
+// classSymbols returns the characters linked to the ActionScript 3 classes
+// by SymbolClass. The first link wins when a class is linked more than once,
+// and the symbols without CharacterID are ignored.
+func (f *File) classSymbols() map[string]uint16 {
+	symbols := map[string]uint16{}
+
+	if f == nil {
+		return symbols
+	}
+
+	for _, content := range f.Contents {
+		v, ok := content.(*SymbolClass)
+
+		if !ok {
+			continue
+		}
+		for _, symbol := range v.Symbols {
+			if symbol == nil || symbol.CharacterID == nil {
+				continue
+			}
+			if _, ok := symbols[symbol.Name]; !ok {
+				symbols[symbol.Name] = symbol.CharacterID.Value
+			}
+		}
+	}
+
+	return symbols
+}
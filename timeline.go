@@ -0,0 +1,35 @@
+package swf
+
+// walkTimelines calls fn for every content with the timeline which the content
+// belongs to. The spriteID is 0 for the main timeline and the frame is the
+// zero-based index of the frame. The contents of a sprite follow the
+// DefineSprite and end with its End tag.
+func walkTimelines(contents ContentSlice, fn func(spriteID uint16, frame int, content Content) error) error {
+	type timeline struct {
+		spriteID uint16
+		frame    int
+	}
+
+	stack := []*timeline{{}}
+
+	for _, content := range contents {
+		current := stack[len(stack)-1]
+
+		if err := fn(current.spriteID, current.frame, content); err != nil {
+			return err
+		}
+
+		switch v := content.(type) {
+		case *DefineSprite:
+			stack = append(stack, &timeline{spriteID: v.ID.Value})
+		case *ShowFrame:
+			current.frame += 1
+		case *End:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// VideoCodec is the codec of a video stream. The values are shared with the
+// CodecID of FLV video tags.
+type VideoCodec uint8
+
+const (
+	VideoCodecSorensonH263  VideoCodec = 2
+	VideoCodecScreenVideo   VideoCodec = 3
+	VideoCodecVP6           VideoCodec = 4
+	VideoCodecVP6Alpha      VideoCodec = 5
+	VideoCodecScreenVideoV2 VideoCodec = 6
+)
+
+func (c VideoCodec) String() string {
+	switch c {
+	case VideoCodecSorensonH263:
+		return "SorensonH263"
+	case VideoCodecScreenVideo:
+		return "ScreenVideo"
+	case VideoCodecVP6:
+		return "VP6"
+	case VideoCodecVP6Alpha:
+		return "VP6Alpha"
+	case VideoCodecScreenVideoV2:
+		return "ScreenVideoV2"
+	default:
+		return fmt.Sprintf("VideoCodec(%d)", uint8(c))
+	}
+}
+
+// VideoFrameType is the frame type of FLV video tags.
+type VideoFrameType uint8
+
+const (
+	VideoFrameTypeKey             VideoFrameType = 1
+	VideoFrameTypeInter           VideoFrameType = 2
+	VideoFrameTypeDisposableInter VideoFrameType = 3
+)
+
+func (t VideoFrameType) String() string {
+	switch t {
+	case VideoFrameTypeKey:
+		return "Key"
+	case VideoFrameTypeInter:
+		return "Inter"
+	case VideoFrameTypeDisposableInter:
+		return "DisposableInter"
+	default:
+		return fmt.Sprintf("VideoFrameType(%d)", uint8(t))
+	}
+}
+
+// DetectVideoFrameType returns the frame type of a video packet. H.263 and VP6
+// packets tell the type in their picture headers. A Screen Video packet is a
+// key frame when it updates every block.
+func DetectVideoFrameType(codec VideoCodec, videoData []byte) (VideoFrameType, error) {
+	switch codec {
+	case VideoCodecSorensonH263:
+		return detectH263FrameType(videoData)
+	case VideoCodecScreenVideo, VideoCodecScreenVideoV2:
+		return detectScreenVideoFrameType(codec, videoData)
+	case VideoCodecVP6:
+		if len(videoData) < 1 {
+			return 0, fmt.Errorf("VP6 packet is empty")
+		}
+		if videoData[0]&0x80 == 0 {
+			return VideoFrameTypeKey, nil
+		}
+
+		return VideoFrameTypeInter, nil
+	case VideoCodecVP6Alpha:
+		if len(videoData) < 4 {
+			return 0, fmt.Errorf("VP6 alpha packet is too short")
+		}
+		if videoData[3]&0x80 == 0 {
+			return VideoFrameTypeKey, nil
+		}
+
+		return VideoFrameTypeInter, nil
+	default:
+		return 0, fmt.Errorf("unsupported video codec: %s", codec)
+	}
+}
+
+func detectH263FrameType(videoData []byte) (VideoFrameType, error) {
+	reader := newBitReader(bytes.NewReader(videoData))
+
+	startCode, err := reader.readBits(17)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read H.263 PictureStartCode: %w", err)
+	}
+	if startCode != 1 {
+		return 0, fmt.Errorf("invalid H.263 PictureStartCode: %d", startCode)
+	}
+
+	// Version and TemporalReference.
+	if _, err := reader.readBits(13); err != nil {
+		return 0, fmt.Errorf("failed to read H.263 picture header: %w", err)
+	}
+
+	pictureSize, err := reader.readBits(3)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read H.263 PictureSize: %w", err)
+	}
+
+	switch pictureSize {
+	case 0:
+		_, err = reader.readBits(16)
+	case 1:
+		_, err = reader.readBits(32)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read H.263 custom picture size: %w", err)
+	}
+
+	pictureType, err := reader.readBits(2)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read H.263 PictureType: %w", err)
+	}
+
+	switch pictureType {
+	case 0:
+		return VideoFrameTypeKey, nil
+	case 1:
+		return VideoFrameTypeInter, nil
+	case 2:
+		return VideoFrameTypeDisposableInter, nil
+	default:
+		return 0, fmt.Errorf("invalid H.263 PictureType: %d", pictureType)
+	}
+}
+
+// screenVideoHeader is the header shared by Screen Video and Screen Video V2
+// packets. The sizes are in pixels.
+type screenVideoHeader struct {
+	BlockWidth  int
+	ImageWidth  int
+	BlockHeight int
+	ImageHeight int
+}
+
+func (h screenVideoHeader) columns() int {
+	return (h.ImageWidth + h.BlockWidth - 1) / h.BlockWidth
+}
+
+func (h screenVideoHeader) rows() int {
+	return (h.ImageHeight + h.BlockHeight - 1) / h.BlockHeight
+}
+
+func readScreenVideoHeader(videoData []byte) (screenVideoHeader, error) {
+	if len(videoData) < 4 {
+		return screenVideoHeader{}, fmt.Errorf("Screen Video packet is too short")
+	}
+
+	first := binary.BigEndian.Uint16(videoData[0:])
+	second := binary.BigEndian.Uint16(videoData[2:])
+
+	header := screenVideoHeader{
+		BlockWidth:  (int(first>>12) + 1) * 16,
+		ImageWidth:  int(first & 0xfff),
+		BlockHeight: (int(second>>12) + 1) * 16,
+		ImageHeight: int(second & 0xfff),
+	}
+
+	return header, nil
+}
+
+func detectScreenVideoFrameType(codec VideoCodec, videoData []byte) (VideoFrameType, error) {
+	header, err := readScreenVideoHeader(videoData)
+
+	if err != nil {
+		return 0, err
+	}
+
+	offset := 4
+
+	if codec == VideoCodecScreenVideoV2 {
+		if len(videoData) < 5 {
+			return 0, fmt.Errorf("Screen Video V2 packet is too short")
+		}
+		if videoData[4]&0b1 != 0 {
+			// The blocks follow the palette, whose layout is not documented.
+			return VideoFrameTypeInter, nil
+		}
+
+		offset = 5
+	}
+
+	for i := 0; i < header.columns()*header.rows(); i++ {
+		if offset+2 > len(videoData) {
+			return 0, fmt.Errorf("Screen Video block %d is missing", i)
+		}
+
+		size := int(binary.BigEndian.Uint16(videoData[offset:]))
+
+		if size == 0 {
+			return VideoFrameTypeInter, nil
+		}
+
+		offset += 2 + size
+	}
+
+	return VideoFrameTypeKey, nil
+}
+
+// VideoStream joins a DefineVideoStream with its VideoFrame tags.
+type VideoStream struct {
+	Define *DefineVideoStream
+	// Frames is sorted by FrameNum.
+	Frames []*VideoFrame
+}
+
+func (s *VideoStream) String() string {
+	if s == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("VideoStream{CharacterID: %d, CodecID: %s, Frames: %d}", s.Define.CharacterID.Value, s.Define.CodecID, len(s.Frames))
+}
+
+// VideoStreams returns every video stream defined in the file with the
+// frames which refer to it.
+func (f *File) VideoStreams() []*VideoStream {
+	if f == nil {
+		return nil
+	}
+
+	var streams []*VideoStream
+
+	index := map[uint16]*VideoStream{}
+
+	for _, content := range f.Contents {
+		switch v := content.(type) {
+		case *DefineVideoStream:
+			stream := &VideoStream{Define: v}
+			streams = append(streams, stream)
+			index[v.CharacterID.Value] = stream
+		case *VideoFrame:
+			if stream, ok := index[v.StreamID.Value]; ok {
+				stream.Frames = append(stream.Frames, v)
+			}
+		}
+	}
+	for _, stream := range streams {
+		sort.SliceStable(stream.Frames, func(i, j int) bool {
+			return stream.Frames[i].FrameNum.Value < stream.Frames[j].FrameNum.Value
+		})
+	}
+
+	return streams
+}
+
+// ExportFLV returns the frames of the stream as an FLV file. The timestamp of
+// each frame is computed from its FrameNum and the frame rate.
+func (s *VideoStream) ExportFLV(frameRate float64) ([]byte, error) {
+	if s == nil || s.Define == nil {
+		return nil, fmt.Errorf("cannot export because VideoStream is nil")
+	}
+	if frameRate <= 0 {
+		return nil, fmt.Errorf("failed to export VideoStream: invalid frame rate: %f", frameRate)
+	}
+
+	codec := s.Define.CodecID
+	data := []byte{'F', 'L', 'V', 0x01, 0x01, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00}
+
+	for _, frame := range s.Frames {
+		frameType, err := DetectVideoFrameType(codec, frame.VideoData)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to export VideoStream: frame %d: %w", frame.FrameNum.Value, err)
+		}
+
+		body := []byte{uint8(frameType)<<4 | uint8(codec)}
+
+		switch codec {
+		case VideoCodecVP6, VideoCodecVP6Alpha:
+			// The FLV tag has a byte for cropping the decoded image. The
+			// OffsetToAlpha of VP6Alpha follows it as is, because it is
+			// big-endian in both SWF and FLV.
+			body = append(body, 0x00)
+			body = append(body, frame.VideoData...)
+		default:
+			body = append(body, frame.VideoData...)
+		}
+		if len(body) > 0xffffff {
+			return nil, fmt.Errorf("failed to export VideoStream: frame %d is too large", frame.FrameNum.Value)
+		}
+
+		timestamp := uint32(math.Round(float64(frame.FrameNum.Value) * 1000 / frameRate))
+
+		data = append(data,
+			0x09,
+			uint8(len(body)>>16), uint8(len(body)>>8), uint8(len(body)),
+			uint8(timestamp>>16), uint8(timestamp>>8), uint8(timestamp), uint8(timestamp>>24),
+			0x00, 0x00, 0x00,
+		)
+		data = append(data, body...)
+		data = append(data, 0, 0, 0, 0)
+
+		binary.BigEndian.PutUint32(data[len(data)-4:], uint32(11+len(body)))
+	}
+
+	return data, nil
+}
+
+// ExportFLV returns the video stream of the character as an FLV file whose
+// timestamps follow the frame rate of the file.
+func (f *File) ExportFLV(characterID uint16) ([]byte, error) {
+	if f == nil {
+		return nil, fmt.Errorf("cannot export because File is nil")
+	}
+
+	for _, stream := range f.VideoStreams() {
+		if stream.Define.CharacterID.Value == characterID {
+			return stream.ExportFLV(f.FrameRate.Value)
+		}
+	}
+
+	return nil, fmt.Errorf("video stream %d is not defined", characterID)
+}
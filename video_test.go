@@ -0,0 +1,134 @@
+package swf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportFLV(t *testing.T) {
+	define := newTestTag(t, DefineVideoStreamTagCode, []byte{0x01, 0x00, 0x02, 0x00, 0x60, 0x01, 0x20, 0x01, 0x00, 0x02})
+	keyFrame := newTestTag(t, VideoFrameTagCode, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80, 0x01, 0x00})
+	interFrame := newTestTag(t, VideoFrameTagCode, []byte{0x01, 0x00, 0x03, 0x00, 0x00, 0x00, 0x80, 0x01, 0x20})
+
+	file, err := Parse(bytes.NewReader(newTestSWF(t, define, keyFrame, interFrame)))
+
+	require.NoError(t, err)
+
+	streams := file.VideoStreams()
+
+	require.Len(t, streams, 1)
+	require.Equal(t, VideoCodecSorensonH263, streams[0].Define.CodecID)
+	require.Len(t, streams[0].Frames, 2)
+
+	flv, err := file.ExportFLV(1)
+
+	require.NoError(t, err)
+	require.Equal(t, []byte("FLV"), flv[:3])
+
+	// The first tag follows the header and PreviousTagSize0.
+	first := flv[13:]
+
+	require.Equal(t, uint8(0x09), first[0])
+	require.Equal(t, uint8(0x12), first[11])
+
+	second := first[11+6+4:]
+
+	// 3 frames at 24fps is 125ms.
+	require.Equal(t, []byte{0x00, 0x00, 0x7d}, second[4:7])
+	require.Equal(t, uint8(0x22), second[11])
+	require.Equal(t, uint32(17), binary.BigEndian.Uint32(second[17:]))
+
+	// The VP6Alpha packet is copied after the cropping byte as is.
+	define = newTestTag(t, DefineVideoStreamTagCode, []byte{0x01, 0x00, 0x01, 0x00, 0x10, 0x00, 0x10, 0x00, 0x00, 0x05})
+	// OffsetToAlpha is 0x000102 followed by a key frame of VP6.
+	videoData := []byte{0x00, 0x01, 0x02, 0x00, 0x46, 0x08}
+	frame := newTestTag(t, VideoFrameTagCode, append([]byte{0x01, 0x00, 0x00, 0x00}, videoData...))
+
+	file, err = Parse(bytes.NewReader(newTestSWF(t, define, frame)))
+
+	require.NoError(t, err)
+
+	flv, err = file.ExportFLV(1)
+
+	require.NoError(t, err)
+
+	tag := flv[13:]
+
+	require.Equal(t, uint8(0x09), tag[0])
+	require.Equal(t, []byte{0x00, 0x00, uint8(2 + len(videoData))}, tag[1:4])
+	require.Equal(t, uint8(0x15), tag[11])
+	require.Equal(t, append([]byte{0x00}, videoData...), tag[12:12+1+len(videoData)])
+}
+
+func TestDetectScreenVideoFrameType(t *testing.T) {
+	// 16x16 image with a single 16x16 block.
+	header := []byte{0x00, 0x10, 0x00, 0x10}
+
+	frameType, err := DetectVideoFrameType(VideoCodecScreenVideo, append(header, 0x00, 0x01, 0xff))
+
+	require.NoError(t, err)
+	require.Equal(t, VideoFrameTypeKey, frameType)
+
+	frameType, err = DetectVideoFrameType(VideoCodecScreenVideo, append(header, 0x00, 0x00))
+
+	require.NoError(t, err)
+	require.Equal(t, VideoFrameTypeInter, frameType)
+}
+
+func TestScreenVideoDecoder(t *testing.T) {
+	// 20x17 image with 16x16 blocks, so the edge blocks are partial.
+	header := []byte{0x00, 0x14, 0x00, 0x11}
+	block := func(width, height int, b, g, r uint8) []byte {
+		var pixels []byte
+
+		for i := 0; i < width*height; i++ {
+			pixels = append(pixels, b, g, r)
+		}
+
+		data := compressForTest(t, pixels)
+
+		return append([]byte{uint8(len(data) >> 8), uint8(len(data))}, data...)
+	}
+
+	var key []byte
+
+	key = append(key, header...)
+	key = append(key, block(16, 16, 0xff, 0x00, 0x00)...)
+	key = append(key, block(4, 16, 0x00, 0xff, 0x00)...)
+	key = append(key, block(16, 1, 0x00, 0x00, 0xff)...)
+	key = append(key, block(4, 1, 0xff, 0xff, 0xff)...)
+
+	var inter []byte
+
+	inter = append(inter, header...)
+	inter = append(inter, 0x00, 0x00, 0x00, 0x00)
+	inter = append(inter, block(16, 1, 0x00, 0x00, 0x00)...)
+	inter = append(inter, 0x00, 0x00)
+
+	decoder := NewScreenVideoDecoder()
+
+	_, err := decoder.Decode(inter)
+
+	require.Error(t, err)
+
+	img, err := decoder.Decode(key)
+
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 20, 17), img.Bounds())
+	require.Equal(t, color.RGBA{0x00, 0x00, 0xff, 0xff}, img.At(0, 16))
+	require.Equal(t, color.RGBA{0x00, 0xff, 0x00, 0xff}, img.At(19, 16))
+	require.Equal(t, color.RGBA{0xff, 0x00, 0x00, 0xff}, img.At(0, 0))
+	require.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, img.At(19, 0))
+
+	img, err = decoder.Decode(inter)
+
+	require.NoError(t, err)
+	require.Equal(t, color.RGBA{0x00, 0x00, 0x00, 0xff}, img.At(0, 0))
+	require.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, img.At(19, 0))
+	require.Equal(t, color.RGBA{0x00, 0x00, 0xff, 0xff}, img.At(0, 16))
+}